@@ -43,6 +43,7 @@ const (
 	ctxUserID    contextID = "userID"    // a field name of a context that contains userID
 	ctxCredsDir  contextID = "credDir"   // a field of a context that contains path to directory with credentials
 	ctxUserCreds contextID = "userCreds" // a field of a context that contains user credentials
+	ctxUserToken contextID = "userToken" // a field of a context that contains user token (as is)
 )
 
 type (
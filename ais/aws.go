@@ -127,6 +127,7 @@ func createSession(ct context.Context) *session.Session {
 		}
 		// default session
 		return session.Must(session.NewSessionWithOptions(session.Options{
+			Config:            aws.Config{MaxRetries: awsMaxRetries()},
 			SharedConfigState: session.SharedConfigEnable}))
 	}
 
@@ -134,6 +135,7 @@ func createSession(ct context.Context) *session.Session {
 	if creds == nil {
 		glog.Errorf("Failed to retrieve %s credentials %s", cmn.ProviderAmazon, userID)
 		return session.Must(session.NewSessionWithOptions(session.Options{
+			Config:            aws.Config{MaxRetries: awsMaxRetries()},
 			SharedConfigState: session.SharedConfigEnable}))
 	}
 
@@ -141,10 +143,19 @@ func createSession(ct context.Context) *session.Session {
 	conf := aws.Config{
 		Region:      aws.String(creds.region),
 		Credentials: awsCreds,
+		MaxRetries:  awsMaxRetries(),
 	}
 	return session.Must(session.NewSessionWithOptions(session.Options{Config: conf}))
 }
 
+// no SDK retries when the requests are retried by cloudReqs (nil: SDK default)
+func awsMaxRetries() *int {
+	if cmn.GCO.Get().CloudReq.Provider(cmn.ProviderAmazon).MaxRetries > 0 {
+		return aws.Int(0)
+	}
+	return nil
+}
+
 func awsErrorToAISError(awsError error) (error, int) {
 	if reqErr, ok := awsError.(awserr.RequestFailure); ok {
 		if reqErr.Code() == s3.ErrCodeNoSuchBucket {
@@ -326,6 +337,9 @@ func (awsimpl *awsimpl) headobject(ct context.Context, lom *cluster.LOM) (objmet
 	}
 	size := strconv.FormatInt(*headOutput.ContentLength, 10)
 	objmeta[cmn.HeaderObjSize] = size
+	for k, v := range awsCustomMD(headOutput.Metadata) {
+		objmeta[cmn.HeaderObjCustomPrefix+k] = v
+	}
 	if glog.FastV(4, glog.SmoduleAIS) {
 		glog.Infof("HEAD %s", lom)
 	}
@@ -368,6 +382,7 @@ func (awsimpl *awsimpl) getobj(ctx context.Context, workFQN string, lom *cluster
 	if obj.VersionId != nil {
 		lom.SetVersion(*obj.VersionId)
 	}
+	lom.SetCustomMD(awsCustomMD(obj.Metadata))
 	roi := &recvObjInfo{
 		t:            awsimpl.t,
 		lom:          lom,
@@ -390,7 +405,10 @@ func (awsimpl *awsimpl) putobj(ct context.Context, file *os.File, lom *cluster.L
 		uploadoutput *s3manager.UploadOutput
 	)
 	cksumType, cksumValue := lom.Cksum().Get()
-	md := make(map[string]*string)
+	md := make(map[string]*string, len(lom.CustomMD())+2)
+	for k, v := range lom.CustomMD() {
+		md[k] = aws.String(v)
+	}
 	md[awsChecksumType] = aws.String(cksumType)
 	md[awsChecksumVal] = aws.String(cksumValue)
 
@@ -430,3 +448,17 @@ func (awsimpl *awsimpl) deleteobj(ct context.Context, lom *cluster.LOM) (err err
 	}
 	return
 }
+
+// returns user-defined metadata, i.e. the object's metadata minus the keys used internally
+func awsCustomMD(md map[string]*string) (customMD cmn.SimpleKVs) {
+	for k, v := range md {
+		if v == nil || strings.EqualFold(k, awsChecksumType) || strings.EqualFold(k, awsChecksumVal) {
+			continue
+		}
+		if customMD == nil {
+			customMD = make(cmn.SimpleKVs, len(md))
+		}
+		customMD[strings.ToLower(k)] = *v
+	}
+	return
+}
@@ -0,0 +1,589 @@
+// +build azure
+
+// Package ais provides core functionality for the AIStore object storage.
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package ais
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NVIDIA/aistore/3rdparty/glog"
+	"github.com/NVIDIA/aistore/cluster"
+	"github.com/NVIDIA/aistore/cmn"
+	jsoniter "github.com/json-iterator/go"
+)
+
+//
+// Azure Blob Storage is accessed via its REST API (https://docs.microsoft.com/en-us/rest/api/storageservices/)
+// with requests signed by the storage account's shared key. Azure containers are AIS Cloud buckets,
+// Azure blobs - Cloud objects.
+//
+
+const (
+	azureChecksumType = "ais_cksum_type" // Azure metadata names must be valid C# identifiers
+	azureChecksumVal  = "ais_cksum_val"
+	azureMetaPrefix   = "x-ms-meta-"
+
+	azureAPIVersion  = "2019-12-12"
+	azureMaxPageSize = 5000
+
+	// environment
+	azureConnStringEnv = "AZURE_STORAGE_CONNECTION_STRING"
+	azureAccountEnv    = "AZURE_STORAGE_ACCOUNT"
+	azureKeyEnv        = "AZURE_STORAGE_KEY"
+
+	// Azurite (local emulator) well-known account
+	azuriteAccount  = "devstoreaccount1"
+	azuriteKey      = "Eby8vdM02xNOcqFlqUwJPLlmEtlCDXJ1OUzFT50uSRZ6IFsuFq2UVErCz4I6tq/K1SZFPTOtr/KBHBeksoGMGw=="
+	azuriteEndpoint = "http://127.0.0.1:10000/" + azuriteAccount
+
+	azureErrContainerNotFound = "ContainerNotFound"
+)
+
+//======
+//
+// implements cloudif
+//
+//======
+type (
+	azureCreds struct {
+		account  string
+		key      []byte // decoded account key
+		endpoint string // blob service endpoint, e.g. https://account.blob.core.windows.net
+	}
+
+	azureimpl struct {
+		t      *targetrunner
+		client *http.Client
+	}
+
+	// XML responses
+	azureError struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	azureContainerList struct {
+		Containers []struct {
+			Name string `xml:"Name"`
+		} `xml:"Containers>Container"`
+		NextMarker string `xml:"NextMarker"`
+	}
+	azureBlobList struct {
+		Blobs      []azureBlob `xml:"Blobs>Blob"`
+		NextMarker string      `xml:"NextMarker"`
+	}
+	azureBlob struct {
+		Name             string `xml:"Name"`
+		VersionID        string `xml:"VersionId"`
+		IsCurrentVersion string `xml:"IsCurrentVersion"`
+		Properties       struct {
+			LastModified  string `xml:"Last-Modified"`
+			ContentLength int64  `xml:"Content-Length"`
+			ContentMD5    string `xml:"Content-MD5"`
+			Etag          string `xml:"Etag"`
+		} `xml:"Properties"`
+	}
+)
+
+var (
+	_ cloudif = &azureimpl{}
+)
+
+func newAzureProvider(t *targetrunner) *azureimpl {
+	return &azureimpl{t: t, client: cmn.NewClient(cmn.ClientArgs{IdleConnsPerHost: 100})}
+}
+
+//======
+//
+// credentials
+//
+//======
+
+// Parses Azure storage connection string, e.g.:
+//   DefaultEndpointsProtocol=https;AccountName=NAME;AccountKey=KEY;EndpointSuffix=core.windows.net
+// BlobEndpoint (if defined) overrides the default endpoint; UseDevelopmentStorage=true selects Azurite
+func parseAzureConnString(s string) (*azureCreds, error) {
+	var (
+		protocol = "https"
+		suffix   = "core.windows.net"
+		creds    = &azureCreds{}
+		key      string
+	)
+	for _, kv := range strings.Split(strings.TrimSpace(s), ";") {
+		if kv == "" {
+			continue
+		}
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid connection string entry %q", kv)
+		}
+		v := strings.TrimSpace(parts[1])
+		switch strings.TrimSpace(parts[0]) {
+		case "AccountName":
+			creds.account = v
+		case "AccountKey":
+			key = v
+		case "BlobEndpoint":
+			creds.endpoint = strings.TrimSuffix(v, "/")
+		case "DefaultEndpointsProtocol":
+			protocol = v
+		case "EndpointSuffix":
+			suffix = v
+		case "UseDevelopmentStorage":
+			if v == "true" {
+				creds.account, key, creds.endpoint = azuriteAccount, azuriteKey, azuriteEndpoint
+			}
+		}
+	}
+	if creds.account == "" || key == "" {
+		return nil, errors.New("connection string must define account name and key")
+	}
+	return newAzureCreds(creds.account, key, creds.endpoint, protocol, suffix)
+}
+
+func newAzureCreds(account, key, endpoint, protocol, suffix string) (*azureCreds, error) {
+	decoded, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account key: %v", err)
+	}
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("%s://%s.blob.%s", protocol, account, suffix)
+	}
+	return &azureCreds{account: account, key: decoded, endpoint: endpoint}, nil
+}
+
+// default credentials are taken from environment: either connection string or account name and key
+func defaultAzureCreds() (*azureCreds, error) {
+	if s := os.Getenv(azureConnStringEnv); s != "" {
+		return parseAzureConnString(s)
+	}
+	account, key := os.Getenv(azureAccountEnv), os.Getenv(azureKeyEnv)
+	if account == "" || key == "" {
+		return nil, fmt.Errorf("%s credentials are not defined: set %s or %s and %s",
+			cmn.ProviderAzure, azureConnStringEnv, azureAccountEnv, azureKeyEnv)
+	}
+	return newAzureCreds(account, key, "", "https", "core.windows.net")
+}
+
+// If Authn is enabled and the user has Azure credentials - the connection string
+// stored in authn under the "azure" provider - the request is signed with the
+// user's account key. Otherwise, default credentials are used.
+func azureCredsFromContext(ct context.Context) (*azureCreds, error) {
+	userID := getStringFromContext(ct, ctxUserID)
+	userCreds := userCredsFromContext(ct)
+	if userID == "" || userCreds == nil {
+		return defaultAzureCreds()
+	}
+	raw, ok := userCreds[cmn.ProviderAzure]
+	if raw == "" || !ok {
+		return defaultAzureCreds()
+	}
+	creds, err := parseAzureConnString(raw)
+	if err != nil {
+		glog.Errorf("Failed to retrieve %s credentials %s: %v", cmn.ProviderAzure, userID, err)
+		return defaultAzureCreds()
+	}
+	return creds, nil
+}
+
+// Shared Key authorization, see
+// https://docs.microsoft.com/en-us/rest/api/storageservices/authorize-with-shared-key
+func (creds *azureCreds) signature(req *http.Request) string {
+	var (
+		hdr           = req.Header
+		contentLength string
+		xms           = make([]string, 0, 8)
+		query         = req.URL.Query()
+		params        = make([]string, 0, len(query))
+		sb            strings.Builder
+	)
+	if req.ContentLength > 0 {
+		contentLength = strconv.FormatInt(req.ContentLength, 10)
+	}
+	for _, s := range []string{req.Method, hdr.Get("Content-Encoding"), hdr.Get("Content-Language"), contentLength,
+		hdr.Get("Content-MD5"), hdr.Get("Content-Type"), "" /*Date: x-ms-date is used instead*/,
+		hdr.Get("If-Modified-Since"), hdr.Get("If-Match"), hdr.Get("If-None-Match"),
+		hdr.Get("If-Unmodified-Since"), hdr.Get("Range")} {
+		sb.WriteString(s)
+		sb.WriteByte('\n')
+	}
+	// canonicalized headers
+	for k := range hdr {
+		if lk := strings.ToLower(k); strings.HasPrefix(lk, "x-ms-") {
+			xms = append(xms, lk)
+		}
+	}
+	sort.Strings(xms)
+	for _, k := range xms {
+		sb.WriteString(k + ":" + strings.TrimSpace(hdr.Get(k)) + "\n")
+	}
+	// canonicalized resource
+	sb.WriteString("/" + creds.account + req.URL.EscapedPath())
+	for k := range query {
+		params = append(params, k)
+	}
+	sort.Strings(params)
+	for _, k := range params {
+		values := query[k]
+		sort.Strings(values)
+		sb.WriteString("\n" + strings.ToLower(k) + ":" + strings.Join(values, ","))
+	}
+	mac := hmac.New(sha256.New, creds.key)
+	mac.Write([]byte(sb.String()))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+//======
+//
+// requests
+//
+//======
+
+// executes signed request to the Blob service; on success, the caller must close the response body
+func (azi *azureimpl) do(ct context.Context, method, container, blob string, query url.Values, hdr http.Header,
+	body io.Reader, size int64) (resp *http.Response, err error, errcode int) {
+	creds, err := azureCredsFromContext(ct)
+	if err != nil {
+		return nil, err, http.StatusUnauthorized
+	}
+	u, err := url.Parse(creds.endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s endpoint %q: %v", cmn.ProviderAzure, creds.endpoint, err),
+			http.StatusInternalServerError
+	}
+	u.Path += "/" + container
+	if blob != "" {
+		u.Path += "/" + blob
+	}
+	u.RawQuery = query.Encode()
+	req, err := http.NewRequest(method, u.String(), body)
+	if err != nil {
+		return nil, err, http.StatusInternalServerError
+	}
+	req = req.WithContext(ct)
+	for k, v := range hdr {
+		req.Header[k] = v
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	req.Header.Set("x-ms-date", time.Now().UTC().Format(http.TimeFormat))
+	req.Header.Set("x-ms-version", azureAPIVersion)
+	req.Header.Set("Authorization", "SharedKey "+creds.account+":"+creds.signature(req))
+
+	if resp, err = azi.client.Do(req); err != nil {
+		return nil, err, http.StatusInternalServerError
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		err, errcode = azureErrorToAISError(resp)
+		resp.Body.Close()
+		return nil, err, errcode
+	}
+	return
+}
+
+func azureErrorToAISError(resp *http.Response) (error, int) {
+	var (
+		azerr   = &azureError{Code: resp.Header.Get("x-ms-error-code")}
+		b, _    = ioutil.ReadAll(resp.Body)
+		errcode = resp.StatusCode
+	)
+	if len(b) > 0 {
+		xml.Unmarshal(b, azerr)
+	}
+	if azerr.Code == azureErrContainerNotFound {
+		return cmn.ErrorCloudBucketDoesNotExist, http.StatusNotFound
+	}
+	if azerr.Code == "" {
+		azerr.Code = http.StatusText(errcode)
+	}
+	if azerr.Message != "" {
+		return fmt.Errorf("%s: %s", azerr.Code, strings.TrimSpace(azerr.Message)), errcode
+	}
+	return errors.New(azerr.Code), errcode
+}
+
+func azureDecodeXML(resp *http.Response, v interface{}) (err error, errcode int) {
+	defer resp.Body.Close()
+	if err = xml.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode %s response: %v", cmn.ProviderAzure, err), http.StatusInternalServerError
+	}
+	return
+}
+
+// Azure always generates ETag and, if versioning is enabled for the storage
+// account, version ID; the latter, if present, takes precedence
+func azureVersion(hdr http.Header) string {
+	if v := hdr.Get("x-ms-version-id"); v != "" {
+		return v
+	}
+	return strings.Trim(hdr.Get("ETag"), "\"")
+}
+
+// Content-MD5 is base64-encoded; returns hex-encoded MD5 (or "" if not set)
+func azureMD5(b64 string) string {
+	if b64 == "" {
+		return ""
+	}
+	b, err := base64.StdEncoding.DecodeString(b64)
+	if err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+//==================
+//
+// bucket operations
+//
+//==================
+func (azi *azureimpl) listbucket(ct context.Context, bucket string, msg *cmn.SelectMsg) (jsbytes []byte, err error, errcode int) {
+	if glog.FastV(4, glog.SmoduleAIS) {
+		glog.Infof("listbucket %s", bucket)
+	}
+	var (
+		resp     *http.Response
+		list     = &azureBlobList{}
+		versions = strings.Contains(msg.Props, cmn.GetPropsVersion)
+		query    = url.Values{"restype": []string{"container"}, "comp": []string{"list"}}
+	)
+	if msg.Prefix != "" {
+		query.Set("prefix", msg.Prefix)
+	}
+	if msg.PageMarker != "" {
+		query.Set("marker", msg.PageMarker)
+	}
+	if msg.PageSize != 0 {
+		if msg.PageSize > azureMaxPageSize {
+			glog.Warningf("Azure maximum page size is %d (%d requested). Returning the first %d keys",
+				azureMaxPageSize, msg.PageSize, azureMaxPageSize)
+			msg.PageSize = azureMaxPageSize
+		}
+		query.Set("maxresults", strconv.Itoa(msg.PageSize))
+	}
+	if versions {
+		query.Set("include", "versions")
+	}
+	if resp, err, errcode = azi.do(ct, http.MethodGet, bucket, "", query, nil, nil, 0); err != nil {
+		return
+	}
+	if err, errcode = azureDecodeXML(resp, list); err != nil {
+		return
+	}
+
+	var reslist = cmn.BucketList{Entries: make([]*cmn.BucketEntry, 0, len(list.Blobs))}
+	for _, blob := range list.Blobs {
+		if blob.VersionID != "" && blob.IsCurrentVersion != "true" {
+			continue // older version
+		}
+		entry := &cmn.BucketEntry{Name: blob.Name}
+		if strings.Contains(msg.Props, cmn.GetPropsSize) {
+			entry.Size = blob.Properties.ContentLength
+		}
+		if strings.Contains(msg.Props, cmn.GetPropsCtime) {
+			if t, err := http.ParseTime(blob.Properties.LastModified); err == nil {
+				entry.Ctime = cmn.FormatTime(t, msg.TimeFormat)
+			}
+		}
+		if strings.Contains(msg.Props, cmn.GetPropsChecksum) {
+			entry.Checksum = azureMD5(blob.Properties.ContentMD5)
+		}
+		if versions {
+			if blob.VersionID != "" {
+				entry.Version = blob.VersionID
+			} else {
+				entry.Version = strings.Trim(blob.Properties.Etag, "\"")
+			}
+		}
+		reslist.Entries = append(reslist.Entries, entry)
+	}
+	if glog.FastV(4, glog.SmoduleAIS) {
+		glog.Infof("listbucket count %d", len(reslist.Entries))
+	}
+	// Azure marker is opaque - pass it through as is
+	reslist.PageMarker = list.NextMarker
+
+	jsbytes, err = jsoniter.Marshal(reslist)
+	cmn.AssertNoErr(err)
+	return
+}
+
+func (azi *azureimpl) headbucket(ct context.Context, bucket string) (bucketprops cmn.SimpleKVs, err error, errcode int) {
+	if glog.FastV(4, glog.SmoduleAIS) {
+		glog.Infof("headbucket %s", bucket)
+	}
+	bucketprops = make(cmn.SimpleKVs)
+	query := url.Values{"restype": []string{"container"}}
+	resp, err, errcode := azi.do(ct, http.MethodHead, bucket, "", query, nil, nil, 0)
+	if err != nil {
+		return
+	}
+	resp.Body.Close()
+	bucketprops[cmn.HeaderCloudProvider] = cmn.ProviderAzure
+	// every write generates new ETag - report versioning enabled to detect changes on getobj etc.
+	bucketprops[cmn.HeaderBucketVerEnabled] = "true"
+	return
+}
+
+func (azi *azureimpl) getbucketnames(ct context.Context) (buckets []string, err error, errcode int) {
+	var (
+		resp  *http.Response
+		query = url.Values{"comp": []string{"list"}}
+	)
+	buckets = make([]string, 0, 16)
+	for {
+		list := &azureContainerList{}
+		if resp, err, errcode = azi.do(ct, http.MethodGet, "", "", query, nil, nil, 0); err != nil {
+			return
+		}
+		if err, errcode = azureDecodeXML(resp, list); err != nil {
+			return
+		}
+		for _, container := range list.Containers {
+			buckets = append(buckets, container.Name)
+		}
+		if list.NextMarker == "" {
+			break
+		}
+		query.Set("marker", list.NextMarker)
+	}
+	return
+}
+
+//============
+//
+// object meta
+//
+//============
+func (azi *azureimpl) headobject(ct context.Context, lom *cluster.LOM) (objmeta cmn.SimpleKVs, err error, errcode int) {
+	objmeta = make(cmn.SimpleKVs)
+	resp, err, errcode := azi.do(ct, http.MethodHead, lom.Bucket, lom.Objname, nil, nil, nil, 0)
+	if err != nil {
+		return
+	}
+	resp.Body.Close()
+	objmeta[cmn.HeaderCloudProvider] = cmn.ProviderAzure
+	objmeta[cmn.HeaderObjVersion] = azureVersion(resp.Header)
+	objmeta[cmn.HeaderObjSize] = resp.Header.Get("Content-Length")
+	for k, v := range azureCustomMD(resp.Header) {
+		objmeta[cmn.HeaderObjCustomPrefix+k] = v
+	}
+	if glog.FastV(4, glog.SmoduleAIS) {
+		glog.Infof("HEAD %s", lom)
+	}
+	return
+}
+
+//=======================
+//
+// object data operations
+//
+//=======================
+func (azi *azureimpl) getobj(ctx context.Context, workFQN string, lom *cluster.LOM) (err error, errcode int) {
+	var cksumToCheck cmn.Cksummer
+	resp, err, errcode := azi.do(ctx, http.MethodGet, lom.Bucket, lom.Objname, nil, nil, nil, 0)
+	if err != nil {
+		return
+	}
+	// may not have ais metadata
+	lom.SetCksum(cmn.NewCksum(resp.Header.Get(azureMetaPrefix+azureChecksumType),
+		resp.Header.Get(azureMetaPrefix+azureChecksumVal)))
+	// Content-MD5 is set if the blob was uploaded in one shot (or by the client)
+	if md5 := azureMD5(resp.Header.Get("Content-MD5")); md5 != "" {
+		cksumToCheck = cmn.NewCksum(cmn.ChecksumMD5, md5)
+	}
+	lom.SetVersion(azureVersion(resp.Header))
+	lom.SetCustomMD(azureCustomMD(resp.Header))
+	roi := &recvObjInfo{
+		t:            azi.t,
+		lom:          lom,
+		r:            resp.Body,
+		cksumToCheck: cksumToCheck,
+		workFQN:      workFQN,
+		cold:         true,
+	}
+	if err = roi.writeToFile(); err != nil {
+		return
+	}
+	if glog.FastV(4, glog.SmoduleAIS) {
+		glog.Infof("GET %s", lom)
+	}
+	return
+}
+
+// NOTE: single-shot Put Blob - the blob size is limited to 5000MiB
+func (azi *azureimpl) putobj(ct context.Context, file *os.File, lom *cluster.LOM) (version string, err error, errcode int) {
+	finfo, err := file.Stat()
+	if err != nil {
+		return "", err, http.StatusInternalServerError
+	}
+	hdr := make(http.Header, len(lom.CustomMD())+3)
+	hdr.Set("x-ms-blob-type", "BlockBlob")
+	for k, v := range lom.CustomMD() {
+		hdr.Set(azureMetaPrefix+k, v)
+	}
+	if lom.Cksum() != nil {
+		cksumType, cksumValue := lom.Cksum().Get()
+		hdr.Set(azureMetaPrefix+azureChecksumType, cksumType)
+		hdr.Set(azureMetaPrefix+azureChecksumVal, cksumValue)
+	}
+	resp, err, errcode := azi.do(ct, http.MethodPut, lom.Bucket, lom.Objname, nil, hdr, file, finfo.Size())
+	if err != nil {
+		return
+	}
+	resp.Body.Close()
+	version = azureVersion(resp.Header)
+	if glog.FastV(4, glog.SmoduleAIS) {
+		glog.Infof("PUT %s, version %s", lom, version)
+	}
+	return
+}
+
+func (azi *azureimpl) deleteobj(ct context.Context, lom *cluster.LOM) (err error, errcode int) {
+	resp, err, errcode := azi.do(ct, http.MethodDelete, lom.Bucket, lom.Objname, nil, nil, nil, 0)
+	if err != nil {
+		return
+	}
+	resp.Body.Close()
+	if glog.FastV(4, glog.SmoduleAIS) {
+		glog.Infof("DELETE %s", lom)
+	}
+	return
+}
+
+// returns user-defined metadata, i.e. the object's metadata minus the keys used internally
+func azureCustomMD(hdr http.Header) (customMD cmn.SimpleKVs) {
+	for k := range hdr {
+		lk := strings.ToLower(k)
+		if !strings.HasPrefix(lk, azureMetaPrefix) {
+			continue
+		}
+		name := strings.TrimPrefix(lk, azureMetaPrefix)
+		if name == azureChecksumType || name == azureChecksumVal {
+			continue
+		}
+		if customMD == nil {
+			customMD = make(cmn.SimpleKVs, 4)
+		}
+		customMD[name] = hdr.Get(k)
+	}
+	return
+}
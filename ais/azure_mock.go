@@ -0,0 +1,16 @@
+// +build !azure
+
+// Package ais provides core functionality for the AIStore object storage.
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package ais
+
+type (
+	azureimpl struct { // mock
+		emptyCloud
+		t *targetrunner
+	}
+)
+
+func newAzureProvider(t *targetrunner) *azureimpl { return &azureimpl{emptyCloud{}, t} }
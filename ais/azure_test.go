@@ -0,0 +1,411 @@
+// +build azure
+
+// Package ais provides core functionality for the AIStore object storage.
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package ais
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NVIDIA/aistore/cluster"
+	"github.com/NVIDIA/aistore/cmn"
+	"github.com/NVIDIA/aistore/memsys"
+	jsoniter "github.com/json-iterator/go"
+)
+
+// azurite is a minimal in-memory stand-in for the Azure Blob service (path-style,
+// as Azurite): verifies Shared Key signatures and serves containers and block blobs
+type (
+	azurite struct {
+		mtx        sync.Mutex
+		creds      *azureCreds
+		containers map[string]map[string]*azuriteBlob
+		etag       int
+	}
+	azuriteBlob struct {
+		data  []byte
+		md    http.Header
+		etag  string
+		mtime time.Time
+	}
+)
+
+func newAzurite(t *testing.T, account, key string) (*azurite, *httptest.Server) {
+	creds, err := newAzureCreds(account, key, "", "http", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	az := &azurite{creds: creds, containers: make(map[string]map[string]*azuriteBlob)}
+	srv := httptest.NewServer(az)
+	creds.endpoint = srv.URL + "/" + account
+	return az, srv
+}
+
+func (az *azurite) fail(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("x-ms-error-code", code)
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "<?xml version=\"1.0\" encoding=\"utf-8\"?><Error><Code>%s</Code><Message>%s</Message></Error>",
+		code, code)
+}
+
+func (az *azurite) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	auth := r.Header.Get("Authorization")
+	if auth != "SharedKey "+az.creds.account+":"+az.creds.signature(r) {
+		az.fail(w, http.StatusForbidden, "AuthenticationFailed")
+		return
+	}
+	// /account/container/blob
+	items := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 3)
+	if items[0] != az.creds.account {
+		az.fail(w, http.StatusBadRequest, "InvalidUri")
+		return
+	}
+	az.mtx.Lock()
+	defer az.mtx.Unlock()
+	if len(items) < 2 || items[1] == "" {
+		az.listContainers(w)
+		return
+	}
+	blobs, ok := az.containers[items[1]]
+	if !ok {
+		az.fail(w, http.StatusNotFound, azureErrContainerNotFound)
+		return
+	}
+	if len(items) < 3 {
+		if r.Method == http.MethodHead {
+			return
+		}
+		az.listBlobs(w, r, blobs)
+		return
+	}
+	name := items[2]
+	switch r.Method {
+	case http.MethodPut:
+		data, _ := ioutil.ReadAll(r.Body)
+		az.etag++
+		blob := &azuriteBlob{data: data, md: make(http.Header), etag: fmt.Sprintf("\"0x%X\"", az.etag), mtime: time.Now()}
+		for k, v := range r.Header {
+			if strings.HasPrefix(strings.ToLower(k), azureMetaPrefix) {
+				blob.md[k] = v
+			}
+		}
+		blobs[name] = blob
+		w.Header().Set("ETag", blob.etag)
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodGet, http.MethodHead:
+		blob, ok := blobs[name]
+		if !ok {
+			az.fail(w, http.StatusNotFound, "BlobNotFound")
+			return
+		}
+		sum := md5.Sum(blob.data)
+		for k, v := range blob.md {
+			w.Header()[k] = v
+		}
+		w.Header().Set("ETag", blob.etag)
+		w.Header().Set("Content-MD5", base64.StdEncoding.EncodeToString(sum[:]))
+		w.Header().Set("Content-Length", strconv.Itoa(len(blob.data)))
+		if r.Method == http.MethodGet {
+			w.Write(blob.data)
+		}
+	case http.MethodDelete:
+		if _, ok := blobs[name]; !ok {
+			az.fail(w, http.StatusNotFound, "BlobNotFound")
+			return
+		}
+		delete(blobs, name)
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func (az *azurite) listContainers(w http.ResponseWriter) {
+	names := make([]string, 0, len(az.containers))
+	for name := range az.containers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprint(w, "<EnumerationResults><Containers>")
+	for _, name := range names {
+		fmt.Fprintf(w, "<Container><Name>%s</Name></Container>", name)
+	}
+	fmt.Fprint(w, "</Containers><NextMarker/></EnumerationResults>")
+}
+
+// the marker is the name of the first blob of the next page
+func (az *azurite) listBlobs(w http.ResponseWriter, r *http.Request, blobs map[string]*azuriteBlob) {
+	var (
+		query      = r.URL.Query()
+		names      = make([]string, 0, len(blobs))
+		maxresults = azureMaxPageSize
+		marker     string
+	)
+	if s := query.Get("maxresults"); s != "" {
+		maxresults, _ = strconv.Atoi(s)
+	}
+	for name := range blobs {
+		if strings.HasPrefix(name, query.Get("prefix")) && name >= query.Get("marker") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if len(names) > maxresults {
+		marker = names[maxresults]
+		names = names[:maxresults]
+	}
+	fmt.Fprint(w, "<EnumerationResults><Blobs>")
+	for _, name := range names {
+		blob := blobs[name]
+		sum := md5.Sum(blob.data)
+		fmt.Fprint(w, "<Blob><Name>")
+		xml.EscapeText(w, []byte(name))
+		fmt.Fprintf(w, "</Name><Properties><Last-Modified>%s</Last-Modified><Etag>%s</Etag>"+
+			"<Content-Length>%d</Content-Length><Content-MD5>%s</Content-MD5></Properties></Blob>",
+			blob.mtime.UTC().Format(http.TimeFormat), blob.etag, len(blob.data),
+			base64.StdEncoding.EncodeToString(sum[:]))
+	}
+	fmt.Fprintf(w, "</Blobs><NextMarker>%s</NextMarker></EnumerationResults>", marker)
+}
+
+func (az *azurite) connString() string {
+	return fmt.Sprintf("AccountName=%s;AccountKey=%s;BlobEndpoint=%s", az.creds.account,
+		base64.StdEncoding.EncodeToString(az.creds.key), az.creds.endpoint)
+}
+
+func TestAzureConnString(t *testing.T) {
+	key := base64.StdEncoding.EncodeToString([]byte("secret"))
+	tests := []struct {
+		s        string
+		account  string
+		endpoint string
+		ok       bool
+	}{
+		{"DefaultEndpointsProtocol=https;AccountName=acc;AccountKey=" + key + ";EndpointSuffix=core.windows.net",
+			"acc", "https://acc.blob.core.windows.net", true},
+		{"AccountName=acc;AccountKey=" + key, "acc", "https://acc.blob.core.windows.net", true},
+		{"AccountName=acc;AccountKey=" + key + ";BlobEndpoint=http://localhost:10000/acc/;",
+			"acc", "http://localhost:10000/acc", true},
+		{"UseDevelopmentStorage=true", azuriteAccount, azuriteEndpoint, true},
+		{"AccountName=acc", "", "", false},
+		{"AccountName=acc;AccountKey=not-base64!", "", "", false},
+		{"AccountName", "", "", false},
+	}
+	for _, tt := range tests {
+		creds, err := parseAzureConnString(tt.s)
+		if (err == nil) != tt.ok {
+			t.Errorf("%q: unexpected error %v", tt.s, err)
+			continue
+		}
+		if err == nil && (creds.account != tt.account || creds.endpoint != tt.endpoint) {
+			t.Errorf("%q: got (%q, %q), want (%q, %q)", tt.s, creds.account, creds.endpoint, tt.account, tt.endpoint)
+		}
+	}
+}
+
+// Azure credentials of the authn user take precedence over the default ones
+func TestAzureAuthnCreds(t *testing.T) {
+	az, srv := newAzurite(t, "authnaccount", base64.StdEncoding.EncodeToString([]byte("authnkey")))
+	defer srv.Close()
+	az.containers["authnbucket"] = make(map[string]*azuriteBlob)
+
+	os.Setenv(azureConnStringEnv, "UseDevelopmentStorage=true")
+	defer os.Unsetenv(azureConnStringEnv)
+
+	ctx := context.WithValue(context.Background(), ctxUserID, "user")
+	ctx = context.WithValue(ctx, ctxUserCreds, cmn.SimpleKVs{cmn.ProviderAzure: az.connString()})
+	azi := newAzureProvider(nil)
+	buckets, err, _ := azi.getbucketnames(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(buckets) != 1 || buckets[0] != "authnbucket" {
+		t.Fatalf("expected [authnbucket], got %v", buckets)
+	}
+
+	// wrong key
+	ctx = context.WithValue(ctx, ctxUserCreds, cmn.SimpleKVs{cmn.ProviderAzure: fmt.Sprintf(
+		"AccountName=authnaccount;AccountKey=%s;BlobEndpoint=%s",
+		base64.StdEncoding.EncodeToString([]byte("wrongkey")), az.creds.endpoint)})
+	if _, err, errcode := azi.getbucketnames(ctx); err == nil || errcode != http.StatusForbidden {
+		t.Fatalf("expected authentication failure, got %v (%d)", err, errcode)
+	}
+}
+
+func TestAzureBuckets(t *testing.T) {
+	az, srv := newAzurite(t, azuriteAccount, azuriteKey)
+	defer srv.Close()
+	os.Setenv(azureConnStringEnv, az.connString())
+	defer os.Unsetenv(azureConnStringEnv)
+
+	var (
+		azi     = newAzureProvider(nil)
+		ctx     = context.Background()
+		objects = []string{"a/1", "a/2", "a/3", "b/1", "b/2", "c"}
+	)
+	az.containers["bucket1"] = make(map[string]*azuriteBlob)
+	az.containers["bucket2"] = make(map[string]*azuriteBlob)
+	for _, name := range objects {
+		az.containers["bucket1"][name] = &azuriteBlob{data: []byte(name), etag: "\"0x1\"", mtime: time.Now()}
+	}
+
+	buckets, err, _ := azi.getbucketnames(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(buckets) != 2 || buckets[0] != "bucket1" || buckets[1] != "bucket2" {
+		t.Fatalf("expected [bucket1 bucket2], got %v", buckets)
+	}
+
+	props, err, _ := azi.headbucket(ctx, "bucket1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if props[cmn.HeaderCloudProvider] != cmn.ProviderAzure {
+		t.Errorf("expected provider %q, got %q", cmn.ProviderAzure, props[cmn.HeaderCloudProvider])
+	}
+	if _, err, errcode := azi.headbucket(ctx, "nonexistent"); err != cmn.ErrorCloudBucketDoesNotExist ||
+		errcode != http.StatusNotFound {
+		t.Errorf("expected %v (404), got %v (%d)", cmn.ErrorCloudBucketDoesNotExist, err, errcode)
+	}
+
+	// list page by page
+	var (
+		listed []string
+		msg    = &cmn.SelectMsg{PageSize: 4, Props: cmn.GetPropsSize + "," + cmn.GetPropsChecksum}
+	)
+	for {
+		jsbytes, err, _ := azi.listbucket(ctx, "bucket1", msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		list := &cmn.BucketList{}
+		if err := jsoniter.Unmarshal(jsbytes, list); err != nil {
+			t.Fatal(err)
+		}
+		if len(list.Entries) > msg.PageSize {
+			t.Fatalf("page size %d exceeded: %d entries", msg.PageSize, len(list.Entries))
+		}
+		for _, entry := range list.Entries {
+			sum := md5.Sum([]byte(entry.Name))
+			if entry.Size != int64(len(entry.Name)) || entry.Checksum != fmt.Sprintf("%x", sum) {
+				t.Errorf("%s: unexpected size %d or checksum %s", entry.Name, entry.Size, entry.Checksum)
+			}
+			listed = append(listed, entry.Name)
+		}
+		if list.PageMarker == "" {
+			break
+		}
+		msg.PageMarker = list.PageMarker
+	}
+	if strings.Join(listed, " ") != strings.Join(objects, " ") {
+		t.Errorf("expected %v, got %v", objects, listed)
+	}
+
+	// prefix
+	jsbytes, err, _ := azi.listbucket(ctx, "bucket1", &cmn.SelectMsg{Prefix: "b/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := &cmn.BucketList{}
+	jsoniter.Unmarshal(jsbytes, list)
+	if len(list.Entries) != 2 || list.Entries[0].Name != "b/1" || list.Entries[1].Name != "b/2" {
+		t.Errorf("expected [b/1 b/2], got %d entries", len(list.Entries))
+	}
+	if _, err, errcode := azi.listbucket(ctx, "nonexistent", &cmn.SelectMsg{}); errcode != http.StatusNotFound {
+		t.Errorf("expected 404, got %v (%d)", err, errcode)
+	}
+}
+
+func TestAzureObjects(t *testing.T) {
+	az, srv := newAzurite(t, azuriteAccount, azuriteKey)
+	defer srv.Close()
+	os.Setenv(azureConnStringEnv, az.connString())
+	defer os.Unsetenv(azureConnStringEnv)
+	az.containers["bucket"] = make(map[string]*azuriteBlob)
+
+	gmem2 = &memsys.Mem2{Name: "azuremem"}
+	if err := gmem2.Init(true); err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "azure-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var (
+		azi     = newAzureProvider(nil)
+		ctx     = context.Background()
+		data    = []byte("some object content")
+		objname = "dir/object name"
+		srcFQN  = filepath.Join(dir, "src")
+		lom     = &cluster.LOM{Bucket: "bucket", Objname: objname}
+	)
+	if err := ioutil.WriteFile(srcFQN, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(srcFQN)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lom.SetCksum(cmn.NewCksum(cmn.ChecksumXXHash, "0123456789abcdef"))
+	lom.SetCustomMD(cmn.SimpleKVs{"source": "test"})
+	version, err, _ := azi.putobj(ctx, file, lom)
+	file.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version == "" {
+		t.Error("expected non-empty version")
+	}
+
+	objmeta, err, _ := azi.headobject(ctx, lom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if objmeta[cmn.HeaderObjSize] != strconv.Itoa(len(data)) || objmeta[cmn.HeaderObjVersion] != version ||
+		objmeta[cmn.HeaderObjCustomPrefix+"source"] != "test" {
+		t.Errorf("unexpected object metadata %v", objmeta)
+	}
+
+	// cold GET
+	getLOM := &cluster.LOM{Bucket: "bucket", Objname: objname,
+		BckProps: &cmn.BucketProps{Cksum: cmn.CksumConf{Type: cmn.ChecksumXXHash, ValidateColdGet: true}}}
+	workFQN := filepath.Join(dir, "work")
+	if err, _ := azi.getobj(ctx, workFQN, getLOM); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := ioutil.ReadFile(workFQN); err != nil || string(b) != string(data) {
+		t.Fatalf("unexpected content %q, err: %v", b, err)
+	}
+	if getLOM.Version() != version || getLOM.Size() != int64(len(data)) || getLOM.CustomMD()["source"] != "test" {
+		t.Errorf("unexpected %s: version %q, size %d, custom %v", objname, getLOM.Version(), getLOM.Size(),
+			getLOM.CustomMD())
+	}
+
+	if err, _ := azi.deleteobj(ctx, lom); err != nil {
+		t.Fatal(err)
+	}
+	if _, err, errcode := azi.headobject(ctx, lom); err == nil || errcode != http.StatusNotFound {
+		t.Errorf("expected 404 after delete, got %v (%d)", err, errcode)
+	}
+	if err, errcode := azi.deleteobj(ctx, lom); err == nil || errcode != http.StatusNotFound {
+		t.Errorf("expected 404 deleting nonexistent object, got %v (%d)", err, errcode)
+	}
+}
@@ -0,0 +1,252 @@
+// Package ais provides core functionality for the AIStore object storage.
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package ais
+
+import (
+	"context"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/NVIDIA/aistore/3rdparty/glog"
+	"github.com/NVIDIA/aistore/cluster"
+	"github.com/NVIDIA/aistore/cmn"
+	"github.com/NVIDIA/aistore/stats"
+)
+
+//
+// Requests to a Cloud provider go through cloudReqs that (see cmn.CloudReqConf):
+// - limits the rate of requests per bucket with a token bucket - requests wait
+//   for their turn rather than fail;
+// - retries the requests that fail with one of the configured HTTP status codes
+//   (e.g., 503 SlowDown) with exponential backoff and jitter.
+// The rate is limited by each target on its own: targets do not coordinate.
+// Cloud SDKs that retry on their own are configured not to when cloudReqs
+// retries (see aws.go); the GCP client library cannot be configured so - hence,
+// cloud_requests.providers.gcp.max_retries defaults to 0.
+//
+
+// when to clean up the limiters of the buckets that are no longer in use
+const cloudLimitersSweep = 1024
+
+type (
+	cloudReqs struct {
+		t        *targetrunner
+		cloud    cloudif
+		provider string
+		mtx      sync.Mutex
+		limiters map[string]*cloudLimiter // by bucket
+	}
+	cloudLimiter struct {
+		mtx    sync.Mutex
+		tokens float64
+		last   time.Time
+	}
+)
+
+var (
+	_ cloudif = &cloudReqs{}
+)
+
+func newCloudReqs(t *targetrunner, provider string, cloud cloudif) *cloudReqs {
+	return &cloudReqs{t: t, cloud: cloud, provider: provider, limiters: make(map[string]*cloudLimiter)}
+}
+
+// reserves a token and returns the time to wait for it
+func (l *cloudLimiter) reserve(rate float64, burst int, now time.Time) time.Duration {
+	if burst == 0 {
+		burst = int(math.Ceil(rate))
+	}
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if l.last.IsZero() {
+		l.tokens = float64(burst)
+	} else {
+		l.tokens = math.Min(float64(burst), l.tokens+now.Sub(l.last).Seconds()*rate)
+	}
+	l.last = now
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / rate * float64(time.Second))
+}
+
+// returns the token reserved by the request that did not wait for its turn
+func (l *cloudLimiter) cancel() {
+	l.mtx.Lock()
+	l.tokens++
+	l.mtx.Unlock()
+}
+
+// true if the limiter has refilled to the burst and is thus no different from a new one
+func (l *cloudLimiter) idle(rate float64, burst int, now time.Time) bool {
+	if burst == 0 {
+		burst = int(math.Ceil(rate))
+	}
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	return l.tokens+now.Sub(l.last).Seconds()*rate >= float64(burst)
+}
+
+// exponential, with jitter: half of the delay is random
+func cloudBackoff(conf *cmn.CloudReqConf, retries int) time.Duration {
+	delay := conf.MaxBackoff
+	if retries < 32 {
+		delay = cmn.MinDuration(conf.Backoff<<uint(retries), conf.MaxBackoff)
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+func cloudRetriable(conf *cmn.CloudReqConf, errcode int) bool {
+	for _, status := range conf.RetryOnStatus {
+		if status == errcode {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *cloudReqs) wait(ctx context.Context, bucket string, conf *cmn.CloudReqConf) error {
+	if conf.RateLimit <= 0 {
+		return nil
+	}
+	now := time.Now()
+	c.mtx.Lock()
+	l, ok := c.limiters[bucket]
+	if !ok {
+		if len(c.limiters) >= cloudLimitersSweep {
+			c.sweepLocked(conf, now)
+		}
+		l = &cloudLimiter{}
+		c.limiters[bucket] = l
+	}
+	c.mtx.Unlock()
+	delay := l.reserve(conf.RateLimit, conf.Burst, now)
+	if delay == 0 {
+		return nil
+	}
+	c.t.statsif.Add(stats.CloudThrottleCount, 1)
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.cancel()
+		return ctx.Err()
+	}
+}
+
+func (c *cloudReqs) sweepLocked(conf *cmn.CloudReqConf, now time.Time) {
+	for bucket, l := range c.limiters {
+		if l.idle(conf.RateLimit, conf.Burst, now) {
+			delete(c.limiters, bucket)
+		}
+	}
+}
+
+// executes the request (rate-limited) and retries it if need be
+func (c *cloudReqs) do(ctx context.Context, method, bucket, objname string, req func() (error, int)) (err error,
+	errcode int) {
+	conf := cmn.GCO.Get().CloudReq.Provider(c.provider)
+	for retries := 0; ; retries++ {
+		if err = c.wait(ctx, bucket, conf); err != nil {
+			return err, http.StatusRequestTimeout
+		}
+		if err, errcode = req(); err == nil || !cloudRetriable(conf, errcode) {
+			return
+		}
+		if errcode == http.StatusTooManyRequests || errcode == http.StatusServiceUnavailable {
+			c.t.statsif.Add(stats.ErrCloudThrottleCount, 1)
+		}
+		if retries >= conf.MaxRetries {
+			return
+		}
+		delay := cloudBackoff(conf, retries)
+		glog.Warningf("%s %s/%s failed (status %d), retrying in %v, err: %v", method, bucket, objname, errcode, delay, err)
+		c.t.statsif.Add(stats.CloudRetryCount, 1)
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
+
+//==================
+//
+// bucket operations
+//
+//==================
+func (c *cloudReqs) listbucket(ctx context.Context, bucket string, msg *cmn.SelectMsg) (jsbytes []byte, err error,
+	errcode int) {
+	err, errcode = c.do(ctx, "list", bucket, "", func() (err error, errcode int) {
+		jsbytes, err, errcode = c.cloud.listbucket(ctx, bucket, msg)
+		return
+	})
+	return
+}
+
+func (c *cloudReqs) headbucket(ctx context.Context, bucket string) (bucketprops cmn.SimpleKVs, err error,
+	errcode int) {
+	err, errcode = c.do(ctx, http.MethodHead, bucket, "", func() (err error, errcode int) {
+		bucketprops, err, errcode = c.cloud.headbucket(ctx, bucket)
+		return
+	})
+	return
+}
+
+func (c *cloudReqs) getbucketnames(ctx context.Context) (buckets []string, err error, errcode int) {
+	err, errcode = c.do(ctx, "list buckets", "", "", func() (err error, errcode int) {
+		buckets, err, errcode = c.cloud.getbucketnames(ctx)
+		return
+	})
+	return
+}
+
+//=============================
+//
+// object meta and data operations
+//
+//=============================
+func (c *cloudReqs) headobject(ctx context.Context, lom *cluster.LOM) (objmeta cmn.SimpleKVs, err error,
+	errcode int) {
+	err, errcode = c.do(ctx, http.MethodHead, lom.Bucket, lom.Objname, func() (err error, errcode int) {
+		objmeta, err, errcode = c.cloud.headobject(ctx, lom)
+		return
+	})
+	return
+}
+
+func (c *cloudReqs) getobj(ctx context.Context, fqn string, lom *cluster.LOM) (err error, errcode int) {
+	return c.do(ctx, http.MethodGet, lom.Bucket, lom.Objname, func() (error, int) {
+		return c.cloud.getobj(ctx, fqn, lom)
+	})
+}
+
+func (c *cloudReqs) putobj(ctx context.Context, file *os.File, lom *cluster.LOM) (version string, err error,
+	errcode int) {
+	err, errcode = c.do(ctx, http.MethodPut, lom.Bucket, lom.Objname, func() (err error, errcode int) {
+		if _, err = file.Seek(0, io.SeekStart); err != nil { // rewind prior to (re)sending
+			return err, http.StatusInternalServerError
+		}
+		version, err, errcode = c.cloud.putobj(ctx, file, lom)
+		return
+	})
+	return
+}
+
+func (c *cloudReqs) deleteobj(ctx context.Context, lom *cluster.LOM) (err error, errcode int) {
+	return c.do(ctx, http.MethodDelete, lom.Bucket, lom.Objname, func() (error, int) {
+		return c.cloud.deleteobj(ctx, lom)
+	})
+}
@@ -0,0 +1,179 @@
+// Package ais provides core functionality for the AIStore object storage.
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package ais
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/NVIDIA/aistore/cluster"
+	"github.com/NVIDIA/aistore/cmn"
+	"github.com/NVIDIA/aistore/stats"
+)
+
+// fails the first `failures` GETs with the given status
+type slowDownCloud struct {
+	emptyCloud
+	failures, calls int
+	status          int
+}
+
+func (m *slowDownCloud) getobj(ctx context.Context, fqn string, lom *cluster.LOM) (err error, errcode int) {
+	m.calls++
+	if m.calls <= m.failures {
+		return errors.New("SlowDown"), m.status
+	}
+	return nil, 0
+}
+
+func TestCloudLimiter(t *testing.T) {
+	var (
+		l   = &cloudLimiter{}
+		now = time.Now()
+	)
+	// burst of 2 at 10 requests per second
+	for i := 0; i < 2; i++ {
+		if d := l.reserve(10, 2, now); d != 0 {
+			t.Fatalf("request %d: expected no delay, got %v", i, d)
+		}
+	}
+	if d := l.reserve(10, 2, now); d != 100*time.Millisecond {
+		t.Fatalf("expected 100ms delay, got %v", d)
+	}
+	if d := l.reserve(10, 2, now); d != 200*time.Millisecond {
+		t.Fatalf("expected 200ms delay, got %v", d)
+	}
+	// one second later: the 2 reserved tokens are paid off, the bucket holds the burst (2) at most
+	now = now.Add(time.Second)
+	for i := 0; i < 2; i++ {
+		if d := l.reserve(10, 2, now); d != 0 {
+			t.Fatalf("request %d: expected no delay, got %v", i, d)
+		}
+	}
+	if d := l.reserve(10, 2, now); d == 0 {
+		t.Fatalf("expected delay once the burst is used up")
+	}
+}
+
+func TestCloudLimiterCancel(t *testing.T) {
+	var (
+		l   = &cloudLimiter{}
+		now = time.Now()
+	)
+	l.reserve(10, 1, now)
+	if d := l.reserve(10, 1, now); d != 100*time.Millisecond {
+		t.Fatalf("expected 100ms delay, got %v", d)
+	}
+	// the cancelled request does not delay the next one
+	l.cancel()
+	if d := l.reserve(10, 1, now); d != 100*time.Millisecond {
+		t.Fatalf("expected 100ms delay after cancel, got %v", d)
+	}
+	if l.idle(10, 1, now.Add(100*time.Millisecond)) {
+		t.Fatalf("expected busy limiter")
+	}
+	if !l.idle(10, 1, now.Add(200*time.Millisecond)) {
+		t.Fatalf("expected idle limiter")
+	}
+}
+
+func TestCloudLimitersSweep(t *testing.T) {
+	var (
+		c    = newCloudReqs(nil, cmn.ProviderAmazon, &emptyCloud{})
+		conf = &cmn.CloudReqConf{RateLimit: 1000, Burst: 1}
+		ctx  = context.Background()
+	)
+	for i := 0; i < cloudLimitersSweep; i++ {
+		if err := c.wait(ctx, fmt.Sprintf("bucket%d", i), conf); err != nil {
+			t.Fatal(err)
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	if err := c.wait(ctx, "new", conf); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.limiters) != 1 {
+		t.Fatalf("expected idle limiters to be removed, got %d limiters", len(c.limiters))
+	}
+}
+
+func TestCloudReqConfProviders(t *testing.T) {
+	gcp := &cmn.CloudReqConf{MaxRetries: 0, BackoffStr: "1s", MaxBackoffStr: "1m"}
+	conf := &cmn.CloudReqConf{MaxRetries: 5, BackoffStr: "1s", MaxBackoffStr: "1m",
+		Providers: map[string]*cmn.CloudReqConf{cmn.ProviderGoogle: gcp}}
+	if err := conf.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if conf.Provider(cmn.ProviderGoogle) != gcp || conf.Provider(cmn.ProviderAmazon) != conf {
+		t.Fatalf("unexpected per-provider config")
+	}
+	conf.Providers[cmn.CloudBs] = gcp
+	if err := conf.Validate(); err == nil {
+		t.Fatalf("expected invalid provider %q to fail validation", cmn.CloudBs)
+	}
+}
+
+func TestCloudBackoff(t *testing.T) {
+	conf := &cmn.CloudReqConf{Backoff: time.Second, MaxBackoff: 10 * time.Second}
+	tests := []struct {
+		retries  int
+		min, max time.Duration
+	}{
+		{0, 500 * time.Millisecond, time.Second},
+		{2, 2 * time.Second, 4 * time.Second},
+		{4, 5 * time.Second, 10 * time.Second},
+		{100, 5 * time.Second, 10 * time.Second},
+	}
+	for _, test := range tests {
+		for i := 0; i < 10; i++ {
+			if d := cloudBackoff(conf, test.retries); d < test.min || d > test.max {
+				t.Errorf("retries %d: expected backoff in [%v, %v], got %v", test.retries, test.min, test.max, d)
+			}
+		}
+	}
+}
+
+func TestCloudRetry(t *testing.T) {
+	oldConf := cmn.GCO.Get().CloudReq
+	config := cmn.GCO.BeginUpdate()
+	config.CloudReq = cmn.CloudReqConf{MaxRetries: 2, Backoff: time.Millisecond, MaxBackoff: time.Millisecond,
+		RetryOnStatus: []int{http.StatusServiceUnavailable}}
+	cmn.GCO.CommitUpdate(config)
+	defer func() {
+		config := cmn.GCO.BeginUpdate()
+		config.CloudReq = oldConf
+		cmn.GCO.CommitUpdate(config)
+	}()
+
+	var (
+		tgt   = &targetrunner{}
+		mock  = &tierStatsMock{vals: make(map[string]int64)}
+		lom   = &cluster.LOM{Bucket: "b", Objname: "o"}
+		tests = []struct {
+			failures, status, calls int
+			ok                      bool
+		}{
+			{2, http.StatusServiceUnavailable, 3, true},  // retried twice
+			{3, http.StatusServiceUnavailable, 3, false}, // out of retries
+			{1, http.StatusNotFound, 1, false},           // not retriable
+		}
+	)
+	tgt.statsif = mock
+	for _, test := range tests {
+		cloud := &slowDownCloud{failures: test.failures, status: test.status}
+		err, _ := newCloudReqs(tgt, cmn.ProviderAmazon, cloud).getobj(context.Background(), "", lom)
+		if (err == nil) != test.ok || cloud.calls != test.calls {
+			t.Errorf("%d x %d: expected (%t, %d calls), got (%v, %d calls)",
+				test.failures, test.status, test.ok, test.calls, err, cloud.calls)
+		}
+	}
+	if mock.vals[stats.CloudRetryCount] != 4 || mock.vals[stats.ErrCloudThrottleCount] != 5 {
+		t.Errorf("expected 4 retries and 5 throttled requests, got %v", mock.vals)
+	}
+}
@@ -338,11 +338,11 @@ func getstorstatsrunner() *stats.Trunner {
 	return rr
 }
 
-func getcloudif() cloudif {
+func getcloudif(bucket, bckProvider string) cloudif {
 	r := ctx.rg.runmap[cmn.Target]
 	rr, ok := r.(*targetrunner)
 	cmn.Assert(ok)
-	return rr.cloudif
+	return rr.cloud(bucket, bckProvider)
 }
 
 func getmetasyncer() *metasyncer {
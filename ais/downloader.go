@@ -499,7 +499,7 @@ func (p *proxyrunner) respondWithID(w http.ResponseWriter, r *http.Request, id s
 // Handles the case when given `bucket` is a cloud bucket and it is not present in BMD's CBmap.
 // Checks if the bucket exists in cloud and registers it at the primary proxy.
 func (p *proxyrunner) handleUnknownCB(bucket string) error {
-	existsInCloud, err := p.doesCloudBucketExist(bucket)
+	existsInCloud, _, err := p.doesCloudBucketExist(bucket, "")
 	if err != nil {
 		return fmt.Errorf("error checking if bucket exists in cloud: %v", err)
 	}
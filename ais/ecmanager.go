@@ -132,6 +132,17 @@ func (mgr *ecManager) newRespondXact(bucket string) *ec.XactRespond {
 		mgr.t.smapowner, mgr.t.si, bucket, mgr.reqBundle, mgr.respBundle)
 }
 
+func (mgr *ecManager) newScrubXact(bucket string) *ec.XactScrub {
+	return ec.NewScrubXact(mgr.t, mgr.t.smapowner, mgr.t.si, bucket, mgr.t.rtnamemap,
+		func() *ec.XactGet { return mgr.restoreBckGetXact(bucket) },
+		func() *ec.XactPut { return mgr.restoreBckPutXact(bucket) })
+}
+
+func (mgr *ecManager) newEncodeXact(bucket string) *ec.XactEncode {
+	return ec.NewEncodeXact(mgr.t, mgr.t.smapowner, mgr.t.si, bucket, mgr.t.rtnamemap,
+		func() *ec.XactPut { return mgr.restoreBckPutXact(bucket) })
+}
+
 func (mgr *ecManager) restoreBckGetXact(bckName string) *ec.XactGet {
 	xact := mgr.getBckXacts(bckName).Get()
 	if xact == nil || xact.Finished() {
@@ -335,6 +346,18 @@ func (mgr *ecManager) enableBck(bckName string) {
 	mgr.restoreBckPutXact(bckName).EnableRequests()
 }
 
+// abortEncodeBck aborts encoding the bucket's objects with the EC configuration
+// that has changed; the primary proxy starts encoding them anew (see
+// proxyrunner.reEncodeEC)
+func (mgr *ecManager) abortEncodeBck(bckName string) {
+	mgr.t.xactions.abortBucketXact(cmn.ActECEncode, bckName)
+}
+
+func ecConfEqual(a, b *cmn.ECConf) bool {
+	return a.DataSlices == b.DataSlices && a.ParitySlices == b.ParitySlices && a.ObjSizeLimit == b.ObjSizeLimit &&
+		a.Code == b.Code && a.LocalGroups == b.LocalGroups
+}
+
 func (mgr *ecManager) BucketsMDChanged() {
 	newBckMD := mgr.bowner.get()
 	oldBckMD := mgr.bckMD
@@ -361,6 +384,8 @@ func (mgr *ecManager) BucketsMDChanged() {
 				mgr.enableBck(bckName)
 			} else if oldBck.EC.Enabled && !newBck.EC.Enabled {
 				mgr.disableBck(bckName)
+			} else if oldBck.EC.Enabled && newBck.EC.Enabled && !ecConfEqual(&oldBck.EC, &newBck.EC) {
+				mgr.abortEncodeBck(bckName)
 			}
 		}
 	}
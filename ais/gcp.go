@@ -24,6 +24,7 @@ import (
 	"github.com/NVIDIA/aistore/cluster"
 	"github.com/NVIDIA/aistore/cmn"
 	jsoniter "github.com/json-iterator/go"
+	"google.golang.org/api/googleapi"
 	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
 )
@@ -175,13 +176,17 @@ func gcpErrorToAISError(gcpError error) (error, int) {
 	if gcpError == storage.ErrBucketNotExist {
 		return cmn.ErrorCloudBucketDoesNotExist, http.StatusNotFound
 	}
+	// keep the status (e.g., 429 or 503) for the request to be retried (see cloudreqs.go)
+	if apiErr, ok := gcpError.(*googleapi.Error); ok && apiErr.Code >= http.StatusBadRequest {
+		return gcpError, apiErr.Code
+	}
 
 	return gcpError, http.StatusBadRequest
 }
 
 func handleObjectError(objErr error, bucket *storage.BucketHandle, gctx context.Context) (error, int) {
 	if objErr != storage.ErrObjectNotExist {
-		return objErr, http.StatusBadRequest
+		return gcpErrorToAISError(objErr)
 	}
 
 	// Object does not exist, but in gcp it doesn't mean that the bucket existed. Check if the buckets exists
@@ -334,6 +339,9 @@ func (gcpimpl *gcpimpl) headobject(ct context.Context, lom *cluster.LOM) (objmet
 	}
 	objmeta[cmn.HeaderCloudProvider] = cmn.ProviderGoogle
 	objmeta[cmn.HeaderObjVersion] = fmt.Sprintf("%d", attrs.Generation)
+	for k, v := range gcpCustomMD(attrs.Metadata) {
+		objmeta[cmn.HeaderObjCustomPrefix+k] = v
+	}
 	if glog.FastV(4, glog.SmoduleAIS) {
 		glog.Infof("HEAD %s", lom)
 	}
@@ -365,14 +373,9 @@ func (gcpimpl *gcpimpl) getobj(ct context.Context, workFQN string, lom *cluster.
 		return
 	}
 	// hashtype and hash could be empty for legacy objects.
-	bckProvider, _ := cmn.BckProviderFromStr(cmn.CloudBs)
-	lom, errstr := cluster.LOM{T: gcpimpl.t, Bucket: lom.Bucket, Objname: lom.Objname, BucketProvider: bckProvider}.Init()
-	if errstr != "" {
-		err = errors.New(errstr)
-		return
-	}
 	lom.SetCksum(cksum)
 	lom.SetVersion(strconv.FormatInt(attrs.Generation, 10))
+	lom.SetCustomMD(gcpCustomMD(attrs.Metadata))
 	roi := &recvObjInfo{
 		t:            gcpimpl.t,
 		cold:         true,
@@ -383,7 +386,6 @@ func (gcpimpl *gcpimpl) getobj(ct context.Context, workFQN string, lom *cluster.
 	}
 
 	if err = roi.writeToFile(); err != nil {
-		errstr = err.Error()
 		return
 	}
 	if glog.FastV(4, glog.SmoduleAIS) {
@@ -398,7 +400,10 @@ func (gcpimpl *gcpimpl) putobj(ct context.Context, file *os.File, lom *cluster.L
 		return
 	}
 
-	md := make(cmn.SimpleKVs)
+	md := make(cmn.SimpleKVs, len(lom.CustomMD())+2)
+	for k, v := range lom.CustomMD() {
+		md[k] = v
+	}
 	md[gcpChecksumType], md[gcpChecksumVal] = lom.Cksum().Get()
 
 	gcpObj := gcpclient.Bucket(lom.Bucket).Object(lom.Objname)
@@ -442,3 +447,17 @@ func (gcpimpl *gcpimpl) deleteobj(ct context.Context, lom *cluster.LOM) (err err
 	}
 	return
 }
+
+// returns user-defined metadata, i.e. the object's metadata minus the keys used internally
+func gcpCustomMD(md map[string]string) (customMD cmn.SimpleKVs) {
+	for k, v := range md {
+		if strings.EqualFold(k, gcpChecksumType) || strings.EqualFold(k, gcpChecksumVal) {
+			continue
+		}
+		if customMD == nil {
+			customMD = make(cmn.SimpleKVs, len(md))
+		}
+		customMD[strings.ToLower(k)] = v
+	}
+	return
+}
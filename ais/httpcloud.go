@@ -0,0 +1,192 @@
+// Package ais provides core functionality for the AIStore object storage.
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package ais
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/NVIDIA/aistore/3rdparty/glog"
+	"github.com/NVIDIA/aistore/cluster"
+	"github.com/NVIDIA/aistore/cmn"
+	jsoniter "github.com/json-iterator/go"
+)
+
+//
+// Read-only HTTP(S) origin: each Cloud bucket maps to a base URL (see
+// cmn.HTTPOriginConf) and an object - to the URL's path relative to the base,
+// so that GET http://aistore/v1/objects/bucket/a/b.tar cold-GETs base-url/a/b.tar.
+// The origin's ETag (or, if not provided, Last-Modified) is the object's version.
+// Plain web servers cannot be listed: listing the bucket returns an empty list.
+//
+
+type (
+	httpimpl struct {
+		t      *targetrunner
+		client *http.Client
+	}
+)
+
+var (
+	_ cloudif = &httpimpl{}
+)
+
+func newHTTPProvider(t *targetrunner) *httpimpl {
+	return &httpimpl{t: t, client: cmn.NewClient(cmn.ClientArgs{IdleConnsPerHost: 100})}
+}
+
+// returns the URL of the object at the origin
+func (hi *httpimpl) objURL(bucket, objname string) (string, error) {
+	baseURL, ok := cmn.GCO.Get().HTTPOrigin.Buckets[bucket]
+	if !ok {
+		return "", cmn.ErrorCloudBucketDoesNotExist
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return "", err
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + objname
+	return u.String(), nil
+}
+
+func (hi *httpimpl) do(ct context.Context, method string, lom *cluster.LOM) (resp *http.Response, err error, errcode int) {
+	objURL, err := hi.objURL(lom.Bucket, lom.Objname)
+	if err != nil {
+		return nil, err, http.StatusNotFound
+	}
+	req, err := http.NewRequest(method, objURL, nil)
+	if err != nil {
+		return nil, err, http.StatusInternalServerError
+	}
+	if resp, err = hi.client.Do(req.WithContext(ct)); err != nil {
+		return nil, err, http.StatusBadGateway
+	}
+	if resp.StatusCode != http.StatusOK {
+		ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s %s: %s", method, objURL, resp.Status), resp.StatusCode
+	}
+	return
+}
+
+func httpVersion(hdr http.Header) string {
+	if etag := hdr.Get("ETag"); etag != "" {
+		return strings.Trim(strings.TrimPrefix(etag, "W/"), "\"")
+	}
+	return hdr.Get("Last-Modified")
+}
+
+func httpReadOnly(bucket string) (error, int) {
+	return fmt.Errorf("%s bucket %s is read-only", cmn.ProviderHTTP, bucket), http.StatusMethodNotAllowed
+}
+
+//==================
+//
+// bucket operations
+//
+//==================
+func (hi *httpimpl) listbucket(ct context.Context, bucket string, msg *cmn.SelectMsg) (jsbytes []byte, err error, errcode int) {
+	if _, ok := cmn.GCO.Get().HTTPOrigin.Buckets[bucket]; !ok {
+		return nil, cmn.ErrorCloudBucketDoesNotExist, http.StatusNotFound
+	}
+	jsbytes, err = jsoniter.Marshal(cmn.BucketList{Entries: []*cmn.BucketEntry{}})
+	cmn.AssertNoErr(err)
+	return
+}
+
+func (hi *httpimpl) headbucket(ct context.Context, bucket string) (bucketprops cmn.SimpleKVs, err error, errcode int) {
+	if _, ok := cmn.GCO.Get().HTTPOrigin.Buckets[bucket]; !ok {
+		return nil, cmn.ErrorCloudBucketDoesNotExist, http.StatusNotFound
+	}
+	bucketprops = make(cmn.SimpleKVs)
+	bucketprops[cmn.HeaderCloudProvider] = cmn.ProviderHTTP
+	bucketprops[cmn.HeaderBucketVerEnabled] = "true"
+	return
+}
+
+func (hi *httpimpl) getbucketnames(ct context.Context) (buckets []string, err error, errcode int) {
+	origins := cmn.GCO.Get().HTTPOrigin.Buckets
+	buckets = make([]string, 0, len(origins))
+	for bucket := range origins {
+		buckets = append(buckets, bucket)
+	}
+	sort.Strings(buckets)
+	return
+}
+
+//============
+//
+// object meta
+//
+//============
+func (hi *httpimpl) headobject(ct context.Context, lom *cluster.LOM) (objmeta cmn.SimpleKVs, err error, errcode int) {
+	resp, err, errcode := hi.do(ct, http.MethodHead, lom)
+	if err != nil {
+		return
+	}
+	resp.Body.Close()
+	objmeta = make(cmn.SimpleKVs)
+	objmeta[cmn.HeaderCloudProvider] = cmn.ProviderHTTP
+	if version := httpVersion(resp.Header); version != "" {
+		objmeta[cmn.HeaderObjVersion] = version
+	}
+	if size := resp.Header.Get("Content-Length"); size != "" {
+		objmeta[cmn.HeaderObjSize] = size
+	}
+	if glog.FastV(4, glog.SmoduleAIS) {
+		glog.Infof("HEAD %s", lom)
+	}
+	return
+}
+
+//=======================
+//
+// object data operations
+//
+//=======================
+func (hi *httpimpl) getobj(ctx context.Context, workFQN string, lom *cluster.LOM) (err error, errcode int) {
+	var cksumToCheck cmn.Cksummer
+	resp, err, errcode := hi.do(ctx, http.MethodGet, lom)
+	if err != nil {
+		return
+	}
+	if b, errDec := base64.StdEncoding.DecodeString(resp.Header.Get("Content-MD5")); errDec == nil && len(b) > 0 {
+		cksumToCheck = cmn.NewCksum(cmn.ChecksumMD5, hex.EncodeToString(b))
+	}
+	lom.SetCksum(nil)
+	lom.SetVersion(httpVersion(resp.Header))
+	roi := &recvObjInfo{
+		t:            hi.t,
+		lom:          lom,
+		r:            resp.Body,
+		cksumToCheck: cksumToCheck,
+		workFQN:      workFQN,
+		cold:         true,
+	}
+	if err = roi.writeToFile(); err != nil {
+		return
+	}
+	if glog.FastV(4, glog.SmoduleAIS) {
+		glog.Infof("GET %s", lom)
+	}
+	return
+}
+
+func (hi *httpimpl) putobj(ct context.Context, file *os.File, lom *cluster.LOM) (version string, err error, errcode int) {
+	err, errcode = httpReadOnly(lom.Bucket)
+	return
+}
+
+func (hi *httpimpl) deleteobj(ct context.Context, lom *cluster.LOM) (err error, errcode int) {
+	return httpReadOnly(lom.Bucket)
+}
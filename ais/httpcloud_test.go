@@ -0,0 +1,112 @@
+// Package ais provides core functionality for the AIStore object storage.
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package ais
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NVIDIA/aistore/cluster"
+	"github.com/NVIDIA/aistore/cmn"
+	"github.com/NVIDIA/aistore/memsys"
+)
+
+func TestHTTPOrigin(t *testing.T) {
+	var (
+		content = map[string]string{"/data/a/b.txt": "some content", "/data/no-etag": "other content"}
+		mtime   = time.Date(2019, 7, 1, 0, 0, 0, 0, time.UTC)
+		ctx     = context.Background()
+		hi      = newHTTPProvider(nil)
+	)
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := content[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Path == "/data/a/b.txt" {
+			w.Header().Set("ETag", "\"v1\"")
+		}
+		http.ServeContent(w, r, "", mtime, strings.NewReader(data))
+	}))
+	defer origin.Close()
+
+	config := cmn.GCO.BeginUpdate()
+	config.HTTPOrigin.Buckets = cmn.SimpleKVs{"web": origin.URL + "/data/"}
+	cmn.GCO.CommitUpdate(config)
+	defer func() {
+		config := cmn.GCO.BeginUpdate()
+		config.HTTPOrigin.Buckets = nil
+		cmn.GCO.CommitUpdate(config)
+	}()
+
+	// buckets
+	if buckets, _, _ := hi.getbucketnames(ctx); len(buckets) != 1 || buckets[0] != "web" {
+		t.Fatalf("expected [web], got %v", buckets)
+	}
+	if props, err, _ := hi.headbucket(ctx, "web"); err != nil || props[cmn.HeaderCloudProvider] != cmn.ProviderHTTP {
+		t.Fatalf("unexpected bucket props %v, err: %v", props, err)
+	}
+	if _, err, errcode := hi.headbucket(ctx, "nonexistent"); err == nil || errcode != http.StatusNotFound {
+		t.Fatalf("expected 404, got %v (%d)", err, errcode)
+	}
+
+	// HEAD: ETag or Last-Modified as the version
+	objmeta, err, _ := hi.headobject(ctx, &cluster.LOM{Bucket: "web", Objname: "a/b.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if objmeta[cmn.HeaderObjVersion] != "v1" || objmeta[cmn.HeaderObjSize] != "12" {
+		t.Errorf("unexpected object metadata %v", objmeta)
+	}
+	objmeta, err, _ = hi.headobject(ctx, &cluster.LOM{Bucket: "web", Objname: "no-etag"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if objmeta[cmn.HeaderObjVersion] != mtime.Format(http.TimeFormat) {
+		t.Errorf("expected Last-Modified version, got %v", objmeta)
+	}
+	if _, err, errcode := hi.headobject(ctx, &cluster.LOM{Bucket: "web", Objname: "nonexistent"}); err == nil ||
+		errcode != http.StatusNotFound {
+		t.Errorf("expected 404, got %v (%d)", err, errcode)
+	}
+
+	// cold GET
+	gmem2 = &memsys.Mem2{Name: "httpmem"}
+	if err := gmem2.Init(true); err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "http-origin-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	var (
+		workFQN = filepath.Join(dir, "work")
+		lom     = &cluster.LOM{Bucket: "web", Objname: "a/b.txt",
+			BckProps: &cmn.BucketProps{Cksum: cmn.CksumConf{Type: cmn.ChecksumXXHash}}}
+	)
+	if err, _ := hi.getobj(ctx, workFQN, lom); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := ioutil.ReadFile(workFQN); err != nil || string(b) != content["/data/a/b.txt"] {
+		t.Fatalf("unexpected content %q, err: %v", b, err)
+	}
+	if lom.Version() != "v1" || lom.Size() != 12 {
+		t.Errorf("unexpected version %q or size %d", lom.Version(), lom.Size())
+	}
+
+	// read-only
+	if err, errcode := hi.deleteobj(ctx, lom); err == nil || errcode != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405, got %v (%d)", err, errcode)
+	}
+}
@@ -26,6 +26,7 @@ import (
 
 	"github.com/NVIDIA/aistore/3rdparty/glog"
 	"github.com/NVIDIA/aistore/3rdparty/golang/mux"
+	"github.com/NVIDIA/aistore/ais/s3compat"
 	"github.com/NVIDIA/aistore/cluster"
 	"github.com/NVIDIA/aistore/cmn"
 	"github.com/NVIDIA/aistore/stats"
@@ -51,6 +52,7 @@ type (
 		err     error
 		errstr  string
 		status  int
+		header  http.Header // response headers
 	}
 
 	// reqArgs specifies http request that we want to send
@@ -441,6 +443,10 @@ func (h *httprunner) initSI(daemonType string) {
 	}
 
 	h.si = newSnode(daemonID, config.Net.HTTP.Proto, daemonType, publicAddr, intraControlAddr, intraDataAddr)
+	// optional failure domain (rack, zone, etc.) - EC spreads slices across domains
+	if daemonType == cmn.Target {
+		h.si.FailureDomain = os.Getenv("AIS_FAILURE_DOMAIN")
+	}
 }
 
 func (h *httprunner) run() error {
@@ -560,7 +566,7 @@ func (h *httprunner) call(args callArgs) callResult {
 
 	if err != nil {
 		errstr = fmt.Sprintf("Unexpected failure to create http request %s %s, err: %v", args.req.method, url, err)
-		return callResult{args.si, outjson, err, errstr, status}
+		return callResult{args.si, outjson, err, errstr, status, nil}
 	}
 
 	copyHeaders(args.req.header, &request.Header)
@@ -584,11 +590,11 @@ func (h *httprunner) call(args callArgs) callResult {
 		if response != nil && response.StatusCode > 0 {
 			errstr = fmt.Sprintf("Failed to http-call %s (%s %s): status %s, err %v", sid, args.req.method, url, response.Status, err)
 			status = response.StatusCode
-			return callResult{args.si, outjson, err, errstr, status}
+			return callResult{args.si, outjson, err, errstr, status, nil}
 		}
 
 		errstr = fmt.Sprintf("Failed to http-call %s (%s %s): err %v", sid, args.req.method, url, err)
-		return callResult{args.si, outjson, err, errstr, status}
+		return callResult{args.si, outjson, err, errstr, status, nil}
 	}
 
 	if outjson, err = ioutil.ReadAll(response.Body); err != nil {
@@ -601,7 +607,7 @@ func (h *httprunner) call(args callArgs) callResult {
 		}
 
 		response.Body.Close()
-		return callResult{args.si, outjson, err, errstr, status}
+		return callResult{args.si, outjson, err, errstr, status, nil}
 	}
 	response.Body.Close()
 
@@ -610,14 +616,14 @@ func (h *httprunner) call(args callArgs) callResult {
 		err = fmt.Errorf("%s", outjson)
 		errstr = err.Error()
 		status = response.StatusCode
-		return callResult{args.si, outjson, err, errstr, status}
+		return callResult{args.si, outjson, err, errstr, status, response.Header}
 	}
 
 	if sid != "unknown" {
 		h.keepalive.heardFrom(sid, false /* reset */)
 	}
 
-	return callResult{args.si, outjson, err, errstr, response.StatusCode}
+	return callResult{args.si, outjson, err, errstr, response.StatusCode, response.Header}
 }
 
 //
@@ -832,7 +838,16 @@ func (h *httprunner) httpdaeget(w http.ResponseWriter, r *http.Request) {
 //=================
 
 func (h *httprunner) invalmsghdlr(w http.ResponseWriter, r *http.Request, msg string, errCode ...int) {
-	cmn.InvalidHandlerDetailed(w, r, msg, errCode...)
+	if r.URL.Query().Get(cmn.URLParamS3) == "true" {
+		status := http.StatusBadRequest
+		if len(errCode) > 0 && errCode[0] >= http.StatusBadRequest {
+			status = errCode[0]
+		}
+		glog.Errorf("s3: %s %s: %s (%d)", r.Method, r.URL.Path, msg, status)
+		s3compat.WriteErr(w, r, s3compat.ErrCode(status, true), msg, status)
+	} else {
+		cmn.InvalidHandlerDetailed(w, r, msg, errCode...)
+	}
 	h.statsif.AddErrorHTTP(r.Method, 1)
 }
 
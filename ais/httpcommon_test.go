@@ -0,0 +1,39 @@
+// Package ais provides core functionality for the AIStore object storage.
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package ais
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/NVIDIA/aistore/cmn"
+)
+
+func TestInvalMsgHandlerS3(t *testing.T) {
+	h := &httprunner{statsif: &tierStatsMock{vals: make(map[string]int64)}}
+	tests := []struct {
+		query string
+		xml   bool
+	}{
+		{"", false},
+		{"?" + cmn.URLParamS3 + "=true", true},
+	}
+	for _, test := range tests {
+		var (
+			w = httptest.NewRecorder()
+			r = httptest.NewRequest(http.MethodGet, "/v1/objects/bucket/obj"+test.query, nil)
+		)
+		h.invalmsghdlr(w, r, "bucket/obj does not exist", http.StatusNotFound)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("%q: expected status %d, got %d", test.query, http.StatusNotFound, w.Code)
+		}
+		isXML := strings.Contains(w.Body.String(), "<Code>NoSuchKey</Code>")
+		if isXML != test.xml || (w.Header().Get("Content-Type") == "application/xml") != test.xml {
+			t.Errorf("%q: expected xml %t, got %q", test.query, test.xml, w.Body.String())
+		}
+	}
+}
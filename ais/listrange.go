@@ -43,7 +43,7 @@ type filesWithDeadline struct {
 type listf func(ct context.Context, objects []string, bucket, bckProvider string, deadline time.Duration, done chan struct{}) error
 
 func getCloudBucketPage(ct context.Context, bucket string, msg *cmn.SelectMsg) (bucketList *cmn.BucketList, err error) {
-	jsbytes, err, errcode := getcloudif().listbucket(ct, bucket, msg)
+	jsbytes, err, errcode := getcloudif(bucket, "").listbucket(ct, bucket, msg)
 	if err != nil {
 		return nil, fmt.Errorf("error listing cloud bucket %s: %d(%v)", bucket, errcode, err)
 	}
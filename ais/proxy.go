@@ -84,6 +84,7 @@ type proxyrunner struct {
 	authn      *authManager
 	startedUp  atomic.Bool
 	metasyncer *metasyncer
+	listCache  *listCache
 	rproxy     struct {
 		sync.Mutex
 		cloud *httputil.ReverseProxy            // unmodified GET requests => storage.googleapis.com
@@ -121,6 +122,7 @@ func (p *proxyrunner) Run() error {
 		revokedTokens: make(map[string]bool),
 		version:       1,
 	}
+	p.listCache = newListCache()
 
 	if config.Net.HTTP.RevProxy == cmn.RevProxyCloud {
 		p.rproxy.cloud = &httputil.ReverseProxy{
@@ -163,6 +165,7 @@ func (p *proxyrunner) Run() error {
 		networkHandler{r: "/", h: cmn.InvalidHandler, net: []string{cmn.NetworkIntraControl, cmn.NetworkIntraData}},
 	}
 	p.registerNetworkHandlers(networkHandlers)
+	p.registerPublicNetHandler("/"+cmn.S3, p.s3Handler)
 
 	glog.Infof("%s: [public net] listening on: %s", p.si.Name(), p.si.PublicNet.DirectURL)
 	if p.si.PublicNet.DirectURL != p.si.IntraControlNet.DirectURL {
@@ -445,6 +448,9 @@ func (p *proxyrunner) httpobjput(w http.ResponseWriter, r *http.Request) {
 		bmd := p.bmdowner.get()
 		glog.Infof("%s %s/%s => %s", r.Method, bmd.Bstring(bucket, bckIsLocal), objname, si)
 	}
+	if !bckIsLocal {
+		p.listCache.invalidate(bucket)
+	}
 	redirectURL := p.redirectURL(r, si.PublicNet.DirectURL, started)
 	http.Redirect(w, r, redirectURL, http.StatusTemporaryRedirect)
 
@@ -496,6 +502,7 @@ func (p *proxyrunner) httpbckdelete(w http.ResponseWriter, r *http.Request) {
 			p.invalmsghdlr(w, r, err.Error())
 			return
 		}
+		p.listCache.invalidate(bucket)
 
 		msgInt := p.newActionMsgInternal(&msg, nil, bucketmd)
 		jsonbytes, err := jsoniter.Marshal(msgInt)
@@ -530,6 +537,8 @@ func (p *proxyrunner) httpbckdelete(w http.ResponseWriter, r *http.Request) {
 				p.invalmsghdlr(w, r, fmt.Sprintf("Bucket %s appears to be local (not cloud)", bucket))
 				return
 			}
+		} else if !bckIsLocal {
+			p.listCache.invalidate(bucket)
 		}
 		p.listRangeHandler(w, r, &msg, http.MethodDelete, bckProvider)
 	default:
@@ -560,6 +569,9 @@ func (p *proxyrunner) httpobjdelete(w http.ResponseWriter, r *http.Request) {
 		bmd := p.bmdowner.get()
 		glog.Infof("%s %s/%s => %s", r.Method, bmd.Bstring(bucket, bckIsLocal), objname, si)
 	}
+	if !bckIsLocal {
+		p.listCache.invalidate(bucket)
+	}
 	redirectURL := p.redirectURL(r, si.PublicNet.DirectURL, started)
 	http.Redirect(w, r, redirectURL, http.StatusTemporaryRedirect)
 
@@ -834,6 +846,14 @@ func (p *proxyrunner) httpbckpost(w http.ResponseWriter, r *http.Request) {
 		p.listBucketAndCollectStats(w, r, bucket, bckProvider, msg, started, false /* fast listing */)
 	case cmn.ActMakeNCopies:
 		p.makeNCopies(w, r, bucket, &msg, config, bckIsLocal)
+	case cmn.ActGetBatch:
+		p.getBatch(w, r, bucket, bckProvider, &msg)
+	case cmn.ActCopyBucket:
+		p.copyBucket(w, r, bucket, bckProvider, &msg, config)
+	case cmn.ActSyncBucket:
+		p.syncBucket(w, r, bucket, bckProvider, &msg, config, bckIsLocal)
+	case cmn.ActECScrub, cmn.ActECEncode:
+		p.bucketEC(w, r, bucket, bckProvider, &msg, config, bckIsLocal)
 	default:
 		s := fmt.Sprintf("Unexpected cmn.ActionMsg <- JSON [%v]", msg)
 		p.invalmsghdlr(w, r, s)
@@ -902,18 +922,22 @@ func (p *proxyrunner) httpobjpost(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		return
 	}
-	// We only support actions for local buckets
 	bucket := apitems[0]
-	if _, ok := p.validateBucket(w, r, bucket, cmn.LocalBs); !ok {
-		return
-	}
 	if cmn.ReadJSON(w, r, &msg) != nil {
 		return
 	}
 	switch msg.Action {
 	case cmn.ActRename:
+		// We only support renaming in local buckets
+		if _, ok := p.validateBucket(w, r, bucket, cmn.LocalBs); !ok {
+			return
+		}
 		p.objRename(w, r)
 		return
+	case cmn.ActInitMultipart, cmn.ActCompleteMultipart, cmn.ActAbortMultipart, cmn.ActConcat, cmn.ActSetCustomMD,
+		cmn.ActCopyObject:
+		p.objAction(w, r, &msg)
+		return
 	default:
 		s := fmt.Sprintf("Unexpected cmn.ActionMsg <- JSON [%v]", msg)
 		p.invalmsghdlr(w, r, s)
@@ -930,7 +954,8 @@ func (p *proxyrunner) httpbckhead(w http.ResponseWriter, r *http.Request) {
 	}
 	bucket := apitems[0]
 	bckProvider := r.URL.Query().Get(cmn.URLParamBckProvider)
-	if _, ok := p.validateBucket(w, r, bucket, bckProvider); !ok {
+	bckIsLocal, ok := p.validateBucket(w, r, bucket, bckProvider)
+	if !ok {
 		return
 	}
 
@@ -943,10 +968,52 @@ func (p *proxyrunner) httpbckhead(w http.ResponseWriter, r *http.Request) {
 	if glog.V(3) {
 		glog.Infof("%s %s => %s", r.Method, bucket, si)
 	}
+	// buckets with quotas: include the cluster-wide usage
+	if props, ok := p.bmdowner.get().Get(bucket, bckIsLocal); ok && props.Quota.Enabled() {
+		usage, err := p.bucketUsage(bucket, bckProvider, smap)
+		if err != nil {
+			p.invalmsghdlr(w, r, err.Error())
+			return
+		}
+		hdr := w.Header()
+		hdr.Set(cmn.HeaderBucketUsedSize, strconv.FormatInt(usage.Size, 10))
+		hdr.Set(cmn.HeaderBucketUsedObjs, strconv.FormatInt(usage.Objs, 10))
+		p.reverseDP(w, r, si)
+		return
+	}
 	redirectURL := p.redirectURL(r, si.PublicNet.DirectURL, started)
 	http.Redirect(w, r, redirectURL, http.StatusTemporaryRedirect)
 }
 
+// aggregates the bucket's usage reported by all targets
+func (p *proxyrunner) bucketUsage(bucket, bckProvider string, smap *smapX) (usage cmn.BucketUsage, err error) {
+	query := url.Values{}
+	query.Add(cmn.URLParamWhat, cmn.GetWhatBucketUsage)
+	query.Add(cmn.URLParamBckProvider, bckProvider)
+	results := p.broadcastTo(
+		cmn.URLPath(cmn.Version, cmn.Buckets, bucket),
+		query,
+		http.MethodGet,
+		nil, // body
+		smap,
+		cmn.GCO.Get().Timeout.Default,
+		cmn.NetworkIntraControl,
+		cluster.Targets,
+	)
+	for res := range results {
+		if res.err != nil {
+			return usage, fmt.Errorf("failed to get bucket %s usage from %s: %v", bucket, res.si, res.errstr)
+		}
+		tusage := cmn.BucketUsage{}
+		if err = jsoniter.Unmarshal(res.outjson, &tusage); err != nil {
+			return
+		}
+		usage.Size += tusage.Size
+		usage.Objs += tusage.Objs
+	}
+	return
+}
+
 func (p *proxyrunner) updateBucketProps(bucket string, bckIsLocal bool, nvs cmn.SimpleKVs) (errRet error) {
 	const errFmt = "invalid %s value %q: %v"
 	p.bmdowner.Lock()
@@ -957,7 +1024,7 @@ func (p *proxyrunner) updateBucketProps(bucket string, bckIsLocal bool, nvs cmn.
 	if !exists {
 		cmn.Assert(!bckIsLocal)
 
-		existsInCloud, err := p.doesCloudBucketExist(bucket)
+		existsInCloud, provider, err := p.doesCloudBucketExist(bucket, "")
 		if err != nil {
 			p.bmdowner.Unlock()
 			return err
@@ -968,8 +1035,10 @@ func (p *proxyrunner) updateBucketProps(bucket string, bckIsLocal bool, nvs cmn.
 		}
 
 		bprops = cmn.DefaultBucketProps()
+		bprops.CloudProvider = provider
 		clone.add(bucket, false /* bucket is local */, bprops)
 	}
+	oldEC := bprops.EC
 
 	// HTTP headers display property names title-cased so that LRULowWM becomes Lrulowwm, etc.
 	// - make sure to lowercase therefore
@@ -978,13 +1047,6 @@ func (p *proxyrunner) updateBucketProps(bucket string, bckIsLocal bool, nvs cmn.
 		if glog.FastV(4, glog.SmoduleAIS) {
 			glog.Infof("Updating bucket %s property %s => %s", bucket, name, value)
 		}
-		// Disable ability to set EC properties once enabled
-		if !bprops.EC.Updatable(name) {
-			errRet = errors.New("cannot change EC configuration after it is enabled")
-			p.bmdowner.Unlock()
-			return
-		}
-
 		switch name {
 		case cmn.HeaderBucketECEnabled:
 			if v, err := strconv.ParseBool(value); err == nil {
@@ -1012,6 +1074,14 @@ func (p *proxyrunner) updateBucketProps(bucket string, bckIsLocal bool, nvs cmn.
 			} else {
 				errRet = fmt.Errorf(errFmt, name, value, err)
 			}
+		case cmn.HeaderBucketECCode:
+			bprops.EC.Code = value
+		case cmn.HeaderBucketECLocalGroups:
+			if v, err := cmn.ParseIntRanged(value, 10, 32, 0, 32); err == nil {
+				bprops.EC.LocalGroups = int(v)
+			} else {
+				errRet = fmt.Errorf(errFmt, name, value, err)
+			}
 		case cmn.HeaderBucketECMinSize:
 			if v, err := strconv.ParseInt(value, 10, 64); err == nil {
 				bprops.EC.ObjSizeLimit = v
@@ -1042,6 +1112,55 @@ func (p *proxyrunner) updateBucketProps(bucket string, bckIsLocal bool, nvs cmn.
 			} else {
 				errRet = fmt.Errorf(errFmt, name, value, err)
 			}
+		case cmn.HeaderBucketVerMaxVersions:
+			if v, err := cmn.ParseIntRanged(value, 10, 32, 0, cmn.MaxObjVersions); err == nil {
+				ownBucketVersioning(bprops, config)
+				bprops.Versioning.MaxVersions = int(v)
+			} else {
+				errRet = fmt.Errorf(errFmt, name, value, err)
+			}
+		case cmn.HeaderBucketVerRetention:
+			if d, err := time.ParseDuration(value); value == "" || (err == nil && d > 0) {
+				ownBucketVersioning(bprops, config)
+				bprops.Versioning.RetentionStr = value
+			} else {
+				errRet = fmt.Errorf(errFmt, name, value, "expecting positive duration")
+			}
+		case cmn.HeaderBucketQuotaSoftSize, cmn.HeaderBucketQuotaHardSize:
+			if v, err := cmn.S2B(value); err == nil && v >= 0 {
+				if name == cmn.HeaderBucketQuotaSoftSize {
+					bprops.Quota.SoftSize = v
+				} else {
+					bprops.Quota.HardSize = v
+				}
+			} else {
+				errRet = fmt.Errorf(errFmt, name, value, "expecting non-negative size")
+			}
+		case cmn.HeaderBucketQuotaSoftObjs, cmn.HeaderBucketQuotaHardObjs:
+			if v, err := strconv.ParseInt(value, 10, 64); err == nil && v >= 0 {
+				if name == cmn.HeaderBucketQuotaSoftObjs {
+					bprops.Quota.SoftObjs = v
+				} else {
+					bprops.Quota.HardObjs = v
+				}
+			} else {
+				errRet = fmt.Errorf(errFmt, name, value, "expecting non-negative integer")
+			}
+		case cmn.HeaderBucketWriteBack:
+			if v, err := strconv.ParseBool(value); err == nil {
+				bprops.WriteBack.Enabled = v
+				errRet = bprops.WriteBack.ValidateAsProps(&cmn.ValidationArgs{BckIsLocal: bckIsLocal})
+			} else {
+				errRet = fmt.Errorf(errFmt, name, value, err)
+			}
+		case cmn.HeaderBucketEncryption:
+			if v, err := strconv.ParseBool(value); err == nil {
+				bprops.Encryption.Enabled = v
+			} else {
+				errRet = fmt.Errorf(errFmt, name, value, err)
+			}
+		case cmn.HeaderBucketEncryptionKey:
+			bprops.Encryption.KeyID = value
 		default:
 			errRet = fmt.Errorf("changing property %s is not supported", name)
 		}
@@ -1051,6 +1170,25 @@ func (p *proxyrunner) updateBucketProps(bucket string, bckIsLocal bool, nvs cmn.
 			return
 		}
 	}
+	// soft vs hard quota: validate once all quotas are updated
+	if errRet = bprops.Quota.ValidateAsProps(&cmn.ValidationArgs{BckIsLocal: bckIsLocal}); errRet != nil {
+		p.bmdowner.Unlock()
+		return
+	}
+	// ditto: encryption and its key
+	if errRet = bprops.Encryption.ValidateAsProps(&cmn.ValidationArgs{BckIsLocal: bckIsLocal}); errRet != nil {
+		p.bmdowner.Unlock()
+		return
+	}
+	// EC enabled or reconfigured: make sure there are enough targets and failure domains
+	if bprops.EC.Enabled {
+		smap := p.smapowner.Get()
+		args := &cmn.ValidationArgs{BckIsLocal: bckIsLocal, TargetCnt: smap.CountTargets(), Domains: smap.DomainSizes()}
+		if errRet = bprops.EC.ValidateAsProps(args); errRet != nil {
+			p.bmdowner.Unlock()
+			return
+		}
+	}
 
 	clone.set(bucket, bckIsLocal, bprops)
 	if e := p.savebmdconf(clone, config); e != "" {
@@ -1060,9 +1198,18 @@ func (p *proxyrunner) updateBucketProps(bucket string, bckIsLocal bool, nvs cmn.
 	p.bmdowner.Unlock()
 	msgInt := p.newActionMsgInternalStr(cmn.ActSetProps, nil, clone)
 	p.metasyncer.sync(true, revspair{clone, msgInt})
+	p.reEncodeEC(bucket, &oldEC, &bprops.EC, config)
 	return
 }
 
+// the bucket stops inheriting global versioning config once any of its versioning props is set
+func ownBucketVersioning(bprops *cmn.BucketProps, config *cmn.Config) {
+	if bprops.Versioning.Type == cmn.PropInherit {
+		bprops.Versioning = config.Ver
+		bprops.Versioning.Type = cmn.PropOwn
+	}
+}
+
 // PUT /v1/buckets/bucket-name
 // PUT /v1/buckets/bucket-name/setprops
 func (p *proxyrunner) httpbckput(w http.ResponseWriter, r *http.Request) {
@@ -1130,7 +1277,7 @@ func (p *proxyrunner) httpbckput(w http.ResponseWriter, r *http.Request) {
 	if !exists {
 		cmn.Assert(!bckIsLocal)
 
-		existsInCloud, err := p.doesCloudBucketExist(bucket)
+		existsInCloud, provider, err := p.doesCloudBucketExist(bucket, nprops.CloudProvider)
 		if err != nil {
 			p.bmdowner.Unlock()
 			p.invalmsghdlr(w, r, err.Error())
@@ -1143,26 +1290,21 @@ func (p *proxyrunner) httpbckput(w http.ResponseWriter, r *http.Request) {
 		}
 
 		bprops = cmn.DefaultBucketProps()
+		bprops.CloudProvider = provider
 		clone.add(bucket, false /* bucket is local */, bprops)
 	}
 
+	oldEC := bprops.EC
 	switch msg.Action {
 	case cmn.ActSetProps:
-		targetCnt := p.smapowner.Get().CountTargets()
-		if err := nprops.Validate(bckIsLocal, targetCnt, p.urlOutsideCluster); err != nil {
-			p.bmdowner.Unlock()
-			p.invalmsghdlr(w, r, err.Error(), http.StatusBadRequest)
-			return
+		// Cloud bucket stays with its provider unless specified otherwise
+		if !bckIsLocal && nprops.CloudProvider == "" {
+			nprops.CloudProvider = bprops.CloudProvider
 		}
-
-		// forbid changing EC properties if EC is already enabled (for now)
-		if bprops.EC.Enabled && nprops.EC.Enabled &&
-			(bprops.EC.ParitySlices != nprops.EC.ParitySlices ||
-				bprops.EC.DataSlices != nprops.EC.DataSlices ||
-				bprops.EC.ObjSizeLimit != nprops.EC.ObjSizeLimit) {
+		smap := p.smapowner.Get()
+		if err := nprops.Validate(bckIsLocal, smap.CountTargets(), smap.DomainSizes(), p.urlOutsideCluster); err != nil {
 			p.bmdowner.Unlock()
-			p.invalmsghdlr(w, r, "Cannot change EC configuration after it is enabled",
-				http.StatusBadRequest)
+			p.invalmsghdlr(w, r, err.Error(), http.StatusBadRequest)
 			return
 		}
 
@@ -1174,7 +1316,11 @@ func (p *proxyrunner) httpbckput(w http.ResponseWriter, r *http.Request) {
 				http.StatusBadRequest)
 			return
 		}
+		provider := bprops.CloudProvider
 		bprops = cmn.DefaultBucketProps()
+		if !bckIsLocal {
+			bprops.CloudProvider = provider
+		}
 	}
 	clone.set(bucket, bckIsLocal, bprops)
 	if e := p.savebmdconf(clone, config); e != "" {
@@ -1185,6 +1331,7 @@ func (p *proxyrunner) httpbckput(w http.ResponseWriter, r *http.Request) {
 
 	msgInt := p.newActionMsgInternal(&msg, nil, clone)
 	p.metasyncer.sync(true, revspair{clone, msgInt})
+	p.reEncodeEC(bucket, &oldEC, &bprops.EC, config)
 }
 
 // HEAD /v1/objects/bucket-name/object-name
@@ -1372,6 +1519,182 @@ func (p *proxyrunner) makeNCopies(w http.ResponseWriter, r *http.Request, bucket
 	}
 }
 
+func (p *proxyrunner) copyBucket(w http.ResponseWriter, r *http.Request, bucket, bckProvider string,
+	actionMsg *cmn.ActionMsg, cfg *cmn.Config) {
+	cpyMsg := &cmn.CopyBucketMsg{}
+	b, err := jsoniter.Marshal(actionMsg.Value)
+	if err == nil {
+		err = jsoniter.Unmarshal(b, cpyMsg)
+	}
+	if err != nil {
+		p.invalmsghdlr(w, r, fmt.Sprintf("unable to parse %s request: %v", actionMsg.Action, actionMsg.Value))
+		return
+	}
+	if _, err = p.bmdowner.get().ValidateBucket(cpyMsg.Bucket, cpyMsg.BckProvider); err == nil {
+		if cpyMsg.Bucket == bucket {
+			err = errors.New("source and destination buckets must be different")
+		} else {
+			_, err = newCopyFilter(cpyMsg)
+		}
+	}
+	if err != nil {
+		p.invalmsghdlr(w, r, err.Error())
+		return
+	}
+	smap := p.smapowner.get()
+	msgInt := p.newActionMsgInternal(actionMsg, smap, p.bmdowner.get())
+	jsbytes, err := jsoniter.Marshal(msgInt)
+	cmn.AssertNoErr(err)
+	results := p.broadcastTo(
+		cmn.URLPath(cmn.Version, cmn.Buckets, bucket),
+		url.Values{cmn.URLParamBckProvider: []string{bckProvider}},
+		http.MethodPost,
+		jsbytes,
+		smap,
+		cfg.Timeout.CplaneOperation,
+		cmn.NetworkIntraControl,
+		cluster.Targets,
+	)
+	for res := range results {
+		if res.err != nil {
+			s := fmt.Sprintf("Failed to copy bucket %s => %s: %s, err: %v(%d)",
+				bucket, cpyMsg.Bucket, res.si.Name(), res.err, res.status)
+			if res.errstr != "" {
+				glog.Errorln(res.errstr)
+			}
+			p.invalmsghdlr(w, r, s)
+			return
+		}
+	}
+	p.listCache.invalidate(cpyMsg.Bucket)
+	glog.Infof("copying bucket %s => %s", bucket, cpyMsg.Bucket)
+}
+
+// starts the sync xaction on all targets or, in dry run, returns the merged report
+func (p *proxyrunner) syncBucket(w http.ResponseWriter, r *http.Request, bucket, bckProvider string,
+	actionMsg *cmn.ActionMsg, cfg *cmn.Config, bckIsLocal bool) {
+	syncMsg := &cmn.SyncBucketMsg{}
+	b, err := jsoniter.Marshal(actionMsg.Value)
+	if err == nil {
+		err = jsoniter.Unmarshal(b, syncMsg)
+	}
+	if err != nil {
+		p.invalmsghdlr(w, r, fmt.Sprintf("unable to parse %s request: %v", actionMsg.Action, actionMsg.Value))
+		return
+	}
+	if bckIsLocal {
+		p.invalmsghdlr(w, r, fmt.Sprintf("%s: %s is not a Cloud bucket", actionMsg.Action, bucket))
+		return
+	}
+	smap := p.smapowner.get()
+	msgInt := p.newActionMsgInternal(actionMsg, smap, p.bmdowner.get())
+	jsbytes, err := jsoniter.Marshal(msgInt)
+	cmn.AssertNoErr(err)
+	timeout := cfg.Timeout.CplaneOperation
+	if syncMsg.DryRun {
+		timeout = cfg.Timeout.DefaultLong // lists the entire bucket
+	}
+	results := p.broadcastTo(
+		cmn.URLPath(cmn.Version, cmn.Buckets, bucket),
+		url.Values{cmn.URLParamBckProvider: []string{bckProvider}},
+		http.MethodPost,
+		jsbytes,
+		smap,
+		timeout,
+		cmn.NetworkIntraControl,
+		cluster.Targets,
+	)
+	report := cmn.SyncBucketReport{New: []string{}, Changed: []string{}, Deleted: []string{}}
+	for res := range results {
+		if res.err != nil {
+			s := fmt.Sprintf("Failed to synchronize bucket %s: %s, err: %v(%d)", bucket, res.si.Name(), res.err, res.status)
+			if res.errstr != "" {
+				glog.Errorln(res.errstr)
+			}
+			p.invalmsghdlr(w, r, s)
+			return
+		}
+		if !syncMsg.DryRun {
+			continue
+		}
+		tgtReport := cmn.SyncBucketReport{}
+		if err := jsoniter.Unmarshal(res.outjson, &tgtReport); err != nil {
+			p.invalmsghdlr(w, r, fmt.Sprintf("Failed to unmarshal %s report, err: %v", res.si.Name(), err))
+			return
+		}
+		report.New = append(report.New, tgtReport.New...)
+		report.Changed = append(report.Changed, tgtReport.Changed...)
+		report.Deleted = append(report.Deleted, tgtReport.Deleted...)
+	}
+	if !syncMsg.DryRun {
+		glog.Infof("synchronizing bucket %s", bucket)
+		return
+	}
+	sort.Strings(report.New)
+	sort.Strings(report.Changed)
+	sort.Strings(report.Deleted)
+	jsbytes, err = jsoniter.Marshal(&report)
+	cmn.AssertNoErr(err)
+	p.writeJSON(w, r, jsbytes, "syncbck")
+}
+
+// broadcasts EC bucket action (scrub, encode) to all targets
+func (p *proxyrunner) bucketEC(w http.ResponseWriter, r *http.Request, bucket, bckProvider string,
+	actionMsg *cmn.ActionMsg, cfg *cmn.Config, bckIsLocal bool) {
+	bmd := p.bmdowner.get()
+	props, ok := bmd.Get(bucket, true)
+	if !bckIsLocal || !ok || !props.EC.Enabled {
+		p.invalmsghdlr(w, r, fmt.Sprintf("%s: erasure coding is not enabled for bucket %s", actionMsg.Action, bucket))
+		return
+	}
+	if err := p.broadcastBucketEC(bucket, bckProvider, actionMsg, cfg); err != nil {
+		p.invalmsghdlr(w, r, err.Error())
+		return
+	}
+	glog.Infof("%s bucket %s", actionMsg.Action, bucket)
+}
+
+func (p *proxyrunner) broadcastBucketEC(bucket, bckProvider string, actionMsg *cmn.ActionMsg, cfg *cmn.Config) error {
+	smap := p.smapowner.get()
+	msgInt := p.newActionMsgInternal(actionMsg, smap, p.bmdowner.get())
+	jsbytes, err := jsoniter.Marshal(msgInt)
+	cmn.AssertNoErr(err)
+	results := p.broadcastTo(
+		cmn.URLPath(cmn.Version, cmn.Buckets, bucket),
+		url.Values{cmn.URLParamBckProvider: []string{bckProvider}},
+		http.MethodPost,
+		jsbytes,
+		smap,
+		cfg.Timeout.CplaneOperation,
+		cmn.NetworkIntraControl,
+		cluster.Targets,
+	)
+	for res := range results {
+		if res.err != nil {
+			if res.errstr != "" {
+				glog.Errorln(res.errstr)
+			}
+			return fmt.Errorf("failed to %s bucket %s: %s, err: %v(%d)", actionMsg.Action, bucket, res.si.Name(),
+				res.err, res.status)
+		}
+	}
+	return nil
+}
+
+// re-encodes the bucket's objects once its EC configuration has changed (and the
+// targets have received the new one) - the objects encoded with the old one
+// get encoded anew
+func (p *proxyrunner) reEncodeEC(bucket string, oldEC, newEC *cmn.ECConf, cfg *cmn.Config) {
+	if !oldEC.Enabled || !newEC.Enabled || ecConfEqual(oldEC, newEC) {
+		return
+	}
+	if err := p.broadcastBucketEC(bucket, cmn.LocalBs, &cmn.ActionMsg{Action: cmn.ActECEncode}, cfg); err != nil {
+		glog.Errorf("%s: failed to re-encode bucket %s, err: %v", p.si, bucket, err)
+		return
+	}
+	glog.Infof("%s: EC configuration of bucket %s changed - re-encoding", p.si, bucket)
+}
+
 func (p *proxyrunner) getbucketnames(w http.ResponseWriter, r *http.Request, bckProvider string) {
 	bucketmd := p.bmdowner.get()
 	bckProviderStr := "?" + cmn.URLParamBckProvider + "=" + bckProvider
@@ -1474,7 +1797,9 @@ func (p *proxyrunner) targetListBucket(r *http.Request, bucket, bckProvider stri
 	}, res.err
 }
 
-func (p *proxyrunner) doesCloudBucketExist(bucket string) (bool, error) {
+// doesCloudBucketExist checks the bucket with the specified (or, if empty, the default)
+// Cloud provider and returns the provider that has it
+func (p *proxyrunner) doesCloudBucketExist(bucket, bckProvider string) (exists bool, provider string, err error) {
 	var si *cluster.Snode
 	// Use random map iteration order to choose a random target to ask
 	smap := p.smapowner.get()
@@ -1488,6 +1813,7 @@ func (p *proxyrunner) doesCloudBucketExist(bucket string) (bool, error) {
 			method: http.MethodHead,
 			base:   si.URL(cmn.NetworkIntraData),
 			path:   cmn.URLPath(cmn.Version, cmn.Buckets, bucket),
+			query:  url.Values{cmn.URLParamBckProvider: []string{bckProvider}},
 		},
 		timeout: defaultTimeout,
 	}
@@ -1495,10 +1821,13 @@ func (p *proxyrunner) doesCloudBucketExist(bucket string) (bool, error) {
 	resp := p.call(args)
 
 	if resp.err != nil && resp.status != http.StatusNotFound {
-		return false, fmt.Errorf("failed to check if bucket exists contacting %s: %v", si.URL(cmn.NetworkIntraData), resp.err)
+		err = fmt.Errorf("failed to check if bucket exists contacting %s: %v", si.URL(cmn.NetworkIntraData), resp.err)
+		return
 	}
-
-	return resp.status != http.StatusNotFound, nil
+	if resp.status == http.StatusNotFound {
+		return
+	}
+	return true, resp.header.Get(cmn.HeaderCloudProvider), nil
 }
 
 // Receives and aggregates info on locally cached objects and merges the two lists
@@ -1748,20 +2077,42 @@ func (p *proxyrunner) getCloudBucketObjects(r *http.Request, bucket, bckProvider
 		glog.Warningf("Page size(%d) for cloud bucket %s exceeds the limit(%d)", msg.PageSize, bucket, maxPageSize)
 	}
 
-	// first, get the cloud object list from a random target
-	smap := p.smapowner.get()
-	for _, si := range smap.Tmap {
-		resp, err = p.targetListBucket(r, bucket, bckProvider, si, &msg, cachedObjects)
-		if err != nil {
-			return
+	// first, get the cloud object list: from the cache or from a random target
+	var (
+		outjson []byte
+		userID  string
+		config  = cmn.GCO.Get()
+		ttl     = config.Proxy.ListCacheTTL
+	)
+	if config.Auth.Enabled {
+		// the listing is done with the user's credentials - not to be shared with other users
+		if auth, err := p.validateToken(r); err == nil {
+			userID = auth.userID
+		} else {
+			msg.BypassCache = true
 		}
-		break
 	}
-
-	if resp.outjson == nil || len(resp.outjson) == 0 {
-		return
+	key := listCacheKey(bckProvider, userID, &msg)
+	if !msg.BypassCache {
+		outjson = p.listCache.get(bucket, key, ttl)
+	}
+	if outjson == nil {
+		started := time.Now()
+		smap := p.smapowner.get()
+		for _, si := range smap.Tmap {
+			resp, err = p.targetListBucket(r, bucket, bckProvider, si, &msg, cachedObjects)
+			if err != nil {
+				return
+			}
+			break
+		}
+		if resp == nil || len(resp.outjson) == 0 {
+			return
+		}
+		outjson = resp.outjson
+		p.listCache.put(bucket, key, outjson, started, ttl)
 	}
-	if err = jsoniter.Unmarshal(resp.outjson, &allentries); err != nil {
+	if err = jsoniter.Unmarshal(outjson, &allentries); err != nil {
 		return
 	}
 	if len(allentries.Entries) == 0 {
@@ -1846,6 +2197,41 @@ func (p *proxyrunner) objRename(w http.ResponseWriter, r *http.Request) {
 	p.statsif.Add(stats.RenameCount, 1)
 }
 
+// redirects per-object actions (multipart upload, concat, etc.) to the target that owns the object
+func (p *proxyrunner) objAction(w http.ResponseWriter, r *http.Request, msg *cmn.ActionMsg) {
+	started := time.Now()
+	apitems, err := p.checkRESTItems(w, r, 2, false, cmn.Version, cmn.Objects)
+	if err != nil {
+		return
+	}
+	bucket, objname := apitems[0], apitems[1]
+	bckProvider := r.URL.Query().Get(cmn.URLParamBckProvider)
+	bckIsLocal, ok := p.validateBucket(w, r, bucket, bckProvider)
+	if !ok {
+		return
+	}
+	si, errstr := hrwTarget(bucket, objname, p.smapowner.get())
+	if errstr != "" {
+		p.invalmsghdlr(w, r, errstr)
+		return
+	}
+	if glog.FastV(4, glog.SmoduleAIS) {
+		bmd := p.bmdowner.get()
+		glog.Infof("%s %s/%s => %s", msg.Action, bmd.Bstring(bucket, bckIsLocal), objname, si)
+	}
+	if msg.Action == cmn.ActCopyObject {
+		cpyMsg := &cmn.CopyObjectMsg{}
+		if b, err := jsoniter.Marshal(msg.Value); err == nil && jsoniter.Unmarshal(b, cpyMsg) == nil {
+			p.listCache.invalidate(cpyMsg.Bucket)
+		}
+	} else if !bckIsLocal {
+		p.listCache.invalidate(bucket)
+	}
+	// NOTE: 307 to preserve the original JSON payload (see objRename)
+	redirectURL := p.redirectURL(r, si.PublicNet.DirectURL, started)
+	http.Redirect(w, r, redirectURL, http.StatusTemporaryRedirect)
+}
+
 func (p *proxyrunner) listRangeHandler(w http.ResponseWriter, r *http.Request, actionMsg *cmn.ActionMsg, method, bckProvider string) {
 	var (
 		err   error
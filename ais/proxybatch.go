@@ -0,0 +1,262 @@
+// Package ais provides core functionality for the AIStore object storage.
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package ais
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NVIDIA/aistore/3rdparty/glog"
+	"github.com/NVIDIA/aistore/cluster"
+	"github.com/NVIDIA/aistore/cmn"
+	jsoniter "github.com/json-iterator/go"
+)
+
+//
+// Batched GET: the proxy groups the requested objects by their (HRW) targets,
+// requests all the groups in parallel - each target streams back a TAR of the
+// objects it stores - and merges the per-target archives into a single TAR
+// (or tar.gz) that preserves the requested order of objects.
+//
+
+const maxBatchObjects = 100000 // max number of objects in a single batched GET
+
+// batchPart is the portion of a batched GET served by a single target
+type batchPart struct {
+	si       *cluster.Snode
+	objnames []string
+	resp     *http.Response
+	tr       *tar.Reader
+	hdr      *tar.Header // next entry (already read from the stream)
+	eof      bool
+	err      error
+	status   int
+}
+
+// POST { action: getbatch } /v1/buckets/bucket-name
+func (p *proxyrunner) getBatch(w http.ResponseWriter, r *http.Request, bucket, bckProvider string, msg *cmn.ActionMsg) {
+	var (
+		started  = time.Now()
+		batchMsg = &cmn.GetBatchMsg{}
+		parts    = make(map[string]*batchPart)
+		gzw      *gzip.Writer
+		tw       *tar.Writer
+		written  int64
+	)
+	b, err := jsoniter.Marshal(msg.Value)
+	if err == nil {
+		err = jsoniter.Unmarshal(b, batchMsg)
+	}
+	if err != nil {
+		p.invalmsghdlr(w, r, fmt.Sprintf("unable to parse %s request: %v", msg.Action, msg.Value))
+		return
+	}
+	if batchMsg.Format != "" && batchMsg.Format != cmn.BatchFormatTar && batchMsg.Format != cmn.BatchFormatTgz {
+		p.invalmsghdlr(w, r, fmt.Sprintf("invalid %s format %q", msg.Action, batchMsg.Format))
+		return
+	}
+	objnames, err := batchObjnames(batchMsg)
+	if err != nil {
+		p.invalmsghdlr(w, r, err.Error())
+		return
+	}
+
+	// group by target; order[i] is the part that serves objnames[i]
+	smap := p.smapowner.get()
+	order := make([]*batchPart, len(objnames))
+	for i, objname := range objnames {
+		si, errstr := hrwTarget(bucket, objname, smap)
+		if errstr != "" {
+			p.invalmsghdlr(w, r, errstr)
+			return
+		}
+		part, ok := parts[si.DaemonID]
+		if !ok {
+			part = &batchPart{si: si}
+			parts[si.DaemonID] = part
+		}
+		part.objnames = append(part.objnames, objname)
+		order[i] = part
+	}
+	defer func() {
+		for _, part := range parts {
+			if part.resp != nil {
+				part.resp.Body.Close()
+			}
+		}
+	}()
+	wg := &sync.WaitGroup{}
+	for _, part := range parts {
+		wg.Add(1)
+		go func(part *batchPart) {
+			p.getBatchPart(r, bucket, bckProvider, part, batchMsg.SkipMissing)
+			wg.Done()
+		}(part)
+	}
+	wg.Wait()
+	for _, part := range parts {
+		if part.err != nil {
+			p.invalmsghdlr(w, r, part.err.Error(), part.status)
+			return
+		}
+	}
+
+	// merge
+	if batchMsg.Format == cmn.BatchFormatTgz {
+		w.Header().Set("Content-Type", "application/gzip")
+		gzw = gzip.NewWriter(w)
+		tw = tar.NewWriter(gzw)
+	} else {
+		w.Header().Set("Content-Type", "application/x-tar")
+		tw = tar.NewWriter(w)
+	}
+	for i, objname := range objnames {
+		n, err := order[i].copyNext(tw, objname)
+		if err != nil {
+			p.abortBatch(bucket, len(objnames), err)
+		}
+		written += n
+	}
+	for _, part := range parts {
+		if !part.done() {
+			p.abortBatch(bucket, len(objnames), fmt.Errorf("%s: unexpected archive entry", part.si))
+		}
+	}
+	err = tw.Close()
+	if err == nil && gzw != nil {
+		err = gzw.Close()
+	}
+	if err != nil {
+		p.abortBatch(bucket, len(objnames), err)
+	}
+	if glog.FastV(4, glog.SmoduleAIS) {
+		glog.Infof("GET batch: %s, %d objects from %d targets (%s), %d µs", bucket, len(objnames), len(parts),
+			cmn.B2S(written, 1), int64(time.Since(started)/time.Microsecond))
+	}
+}
+
+// the response status is already sent: abort the connection so that the
+// client would not mistake a truncated archive for a complete one
+func (p *proxyrunner) abortBatch(bucket string, cnt int, err error) {
+	glog.Errorf("%s: failed to GET batch of %d objects from %s, err: %v", p.si, cnt, bucket, err)
+	panic(http.ErrAbortHandler)
+}
+
+// requests the part from its target; the response is streamed later on
+func (p *proxyrunner) getBatchPart(r *http.Request, bucket, bckProvider string, part *batchPart, skipMissing bool) {
+	var (
+		bmd     = p.bmdowner.get()
+		msg     = &cmn.ActionMsg{Action: cmn.ActGetBatch, Value: &cmn.GetBatchMsg{Objnames: part.objnames, SkipMissing: skipMissing}}
+		query   = url.Values{}
+		body, _ = jsoniter.Marshal(p.newActionMsgInternal(msg, nil, bmd))
+	)
+	part.status = http.StatusInternalServerError
+	query.Add(cmn.URLParamBckProvider, bckProvider)
+	query.Add(cmn.URLParamBMDVersion, bmd.vstr)
+	reqURL := part.si.URL(cmn.NetworkPublic) + cmn.URLPath(cmn.Version, cmn.Buckets, bucket) + "?" + query.Encode()
+	req, err := http.NewRequest(http.MethodPost, reqURL, bytes.NewReader(body))
+	if err != nil {
+		part.err = err
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+	resp, err := p.httpclientLongTimeout.Do(req)
+	if err != nil {
+		p.keepalive.onerr(err, 0)
+		part.err = fmt.Errorf("failed to GET batch from %s, err: %v", part.si, err)
+		return
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		b, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		part.err = fmt.Errorf("failed to GET batch from %s: %s", part.si, strings.TrimSpace(string(b)))
+		part.status = resp.StatusCode
+		return
+	}
+	part.resp = resp
+	part.tr = tar.NewReader(resp.Body)
+}
+
+// copies the named object from the target's stream into the archive; does
+// nothing if the target has omitted the object (missing and skip_missing)
+func (part *batchPart) copyNext(tw *tar.Writer, objname string) (n int64, err error) {
+	if part.hdr == nil && !part.eof {
+		if part.hdr, err = part.tr.Next(); err != nil {
+			if err != io.EOF {
+				return
+			}
+			part.eof, err = true, nil
+		}
+	}
+	if part.hdr == nil || part.hdr.Name != objname {
+		return
+	}
+	if err = tw.WriteHeader(part.hdr); err == nil {
+		n, err = io.Copy(tw, part.tr)
+	}
+	part.hdr = nil
+	return
+}
+
+// returns true if the target's stream has been consumed in its entirety
+func (part *batchPart) done() bool {
+	if part.hdr != nil {
+		return false
+	}
+	if !part.eof {
+		_, err := part.tr.Next()
+		part.eof = err == io.EOF
+	}
+	return part.eof
+}
+
+// returns the names of the objects to read: either as specified or by expanding the template
+func batchObjnames(batchMsg *cmn.GetBatchMsg) (objnames []string, err error) {
+	switch {
+	case len(batchMsg.Objnames) > 0 && batchMsg.Template != "":
+		return nil, errors.New("object names and template are mutually exclusive")
+	case len(batchMsg.Objnames) > 0:
+		objnames = batchMsg.Objnames
+	case batchMsg.Template != "":
+		var pt cmn.ParsedTemplate
+		if pt, err = cmn.ParseBashTemplate(batchMsg.Template); err != nil {
+			if pt, err = cmn.ParseAtTemplate(batchMsg.Template); err != nil {
+				return nil, fmt.Errorf("invalid template %q: %v", batchMsg.Template, err)
+			}
+		}
+		if cnt := pt.Count(); cnt > maxBatchObjects {
+			return nil, fmt.Errorf("template %q: too many objects (%d > %d)", batchMsg.Template, cnt, maxBatchObjects)
+		}
+		objnames = make([]string, 0, pt.Count())
+		it := pt.Iter()
+		for name, hasNext := it(); hasNext; name, hasNext = it() {
+			objnames = append(objnames, name)
+		}
+	default:
+		return nil, errors.New("object names or template must be specified")
+	}
+	if len(objnames) > maxBatchObjects {
+		return nil, fmt.Errorf("too many objects (%d > %d)", len(objnames), maxBatchObjects)
+	}
+	for _, objname := range objnames {
+		if objname == "" {
+			return nil, errors.New("object name cannot be empty")
+		}
+	}
+	return
+}
@@ -0,0 +1,171 @@
+// Package ais provides core functionality for the AIStore object storage.
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package ais
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NVIDIA/aistore/cmn"
+)
+
+//
+// Proxy-side cache of Cloud bucket listings (see cmn.ProxyConf.ListCacheTTL).
+// Listing a large Cloud bucket page by page takes a long time, so each proxy
+// keeps the pages it lists for up to the configured TTL. The pages are keyed
+// by bucket, by the user (with authentication enabled the listing is done with
+// the user's Cloud credentials), and by the listing options (prefix, page
+// marker, page size, ...). PUT, DELETE and other modifications of the Cloud
+// bucket that go through the proxy drop all cached pages of the bucket.
+// Invalidation is local: the proxies do not notify each other, and so the
+// modifications through other proxies (or directly in the Cloud) become
+// visible once the TTL expires. Listing with cmn.SelectMsg.BypassCache always
+// goes to the Cloud.
+//
+
+const (
+	listCacheMaxSize = 64 * cmn.MiB
+	// no caching for a while after the bucket is modified: the modification
+	// (e.g., redirected PUT) may not be in the Cloud by the time of listing
+	listCacheHold = time.Minute
+	// when to clean up the record of modified buckets
+	listCacheSweep = 64
+)
+
+type (
+	listCache struct {
+		mtx         sync.Mutex
+		buckets     map[string]map[string]*listCacheEntry // bucket => listing options => page
+		invalidated map[string]time.Time                  // bucket => last modification
+		size        int64
+	}
+	listCacheEntry struct {
+		outjson []byte
+		cached  time.Time
+	}
+)
+
+func newListCache() *listCache {
+	return &listCache{
+		buckets:     make(map[string]map[string]*listCacheEntry),
+		invalidated: make(map[string]time.Time),
+	}
+}
+
+func listCacheKey(bckProvider, userID string, msg *cmn.SelectMsg) string {
+	return strings.Join([]string{bckProvider, userID, msg.Prefix, msg.PageMarker, strconv.Itoa(msg.PageSize),
+		msg.Props, msg.TimeFormat, strconv.FormatBool(msg.Fast)}, "|")
+}
+
+// returns the cached page, if any and not expired
+func (c *listCache) get(bucket, key string, ttl time.Duration) []byte {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if ttl <= 0 {
+		if c.size > 0 { // caching has been disabled
+			c.clear()
+		}
+		return nil
+	}
+	entry, ok := c.buckets[bucket][key]
+	if !ok {
+		return nil
+	}
+	if time.Since(entry.cached) > ttl {
+		c.del(bucket, key)
+		return nil
+	}
+	return entry.outjson
+}
+
+// caches the page listed at `started` unless the bucket has been modified since (or just before)
+func (c *listCache) put(bucket, key string, outjson []byte, started time.Time, ttl time.Duration) {
+	size := int64(len(outjson))
+	if ttl <= 0 || size == 0 || size > listCacheMaxSize/4 {
+		return
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if modified, ok := c.invalidated[bucket]; ok && started.Sub(modified) < listCacheHold {
+		return
+	}
+	c.del(bucket, key)
+	for c.size+size > listCacheMaxSize {
+		c.evict(ttl)
+	}
+	pages, ok := c.buckets[bucket]
+	if !ok {
+		pages = make(map[string]*listCacheEntry)
+		c.buckets[bucket] = pages
+	}
+	pages[key] = &listCacheEntry{outjson: outjson, cached: time.Now()}
+	c.size += size
+}
+
+// drops all cached pages of the bucket - in this proxy only
+func (c *listCache) invalidate(bucket string) {
+	now := time.Now()
+	c.mtx.Lock()
+	if pages, ok := c.buckets[bucket]; ok {
+		for _, entry := range pages {
+			c.size -= int64(len(entry.outjson))
+		}
+		delete(c.buckets, bucket)
+	}
+	if len(c.invalidated) >= listCacheSweep {
+		for b, modified := range c.invalidated {
+			if now.Sub(modified) >= listCacheHold {
+				delete(c.invalidated, b)
+			}
+		}
+	}
+	c.invalidated[bucket] = now
+	c.mtx.Unlock()
+}
+
+// removes expired pages and, if need be, the oldest one (under lock)
+func (c *listCache) evict(ttl time.Duration) {
+	var (
+		oldestBucket, oldestKey string
+		oldest                  time.Time
+		now                     = time.Now()
+	)
+	for bucket, pages := range c.buckets {
+		for key, entry := range pages {
+			if now.Sub(entry.cached) > ttl {
+				c.del(bucket, key)
+				continue
+			}
+			if oldest.IsZero() || entry.cached.Before(oldest) {
+				oldestBucket, oldestKey, oldest = bucket, key, entry.cached
+			}
+		}
+	}
+	if c.size > 0 && !oldest.IsZero() {
+		c.del(oldestBucket, oldestKey)
+	}
+}
+
+// (under lock)
+func (c *listCache) del(bucket, key string) {
+	pages := c.buckets[bucket]
+	entry, ok := pages[key]
+	if !ok {
+		return
+	}
+	c.size -= int64(len(entry.outjson))
+	delete(pages, key)
+	if len(pages) == 0 {
+		delete(c.buckets, bucket)
+	}
+}
+
+// (under lock)
+func (c *listCache) clear() {
+	c.buckets = make(map[string]map[string]*listCacheEntry)
+	c.size = 0
+}
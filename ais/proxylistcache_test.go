@@ -0,0 +1,88 @@
+// Package ais provides core functionality for the AIStore object storage.
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package ais
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NVIDIA/aistore/cmn"
+)
+
+func TestListCache(t *testing.T) {
+	var (
+		c       = newListCache()
+		ttl     = time.Minute
+		page    = []byte(`{"entries":[]}`)
+		key     = listCacheKey(cmn.CloudBs, "", &cmn.SelectMsg{Prefix: "a/", PageSize: 1000})
+		nextKey = listCacheKey(cmn.CloudBs, "", &cmn.SelectMsg{Prefix: "a/", PageSize: 1000, PageMarker: "a/z"})
+		userKey = listCacheKey(cmn.CloudBs, "user", &cmn.SelectMsg{Prefix: "a/", PageSize: 1000})
+	)
+	if key == nextKey {
+		t.Fatalf("expected different keys for different pages")
+	}
+	if key == userKey {
+		t.Fatalf("expected different keys for different users")
+	}
+	c.put("b", key, page, time.Now(), ttl)
+	if c.get("b", key, ttl) == nil {
+		t.Fatalf("expected cached page")
+	}
+	if c.get("b", nextKey, ttl) != nil || c.get("other", key, ttl) != nil {
+		t.Fatalf("expected no cached page")
+	}
+
+	// expired
+	c.buckets["b"][key].cached = time.Now().Add(-2 * ttl)
+	if c.get("b", key, ttl) != nil || c.size != 0 {
+		t.Fatalf("expected expired page to be removed, size %d", c.size)
+	}
+
+	// modified: dropped and not cached for a while, even if listed before the modification
+	started := time.Now()
+	c.put("b", key, page, started, ttl)
+	c.invalidate("b")
+	if c.get("b", key, ttl) != nil || c.size != 0 {
+		t.Fatalf("expected invalidated page to be removed, size %d", c.size)
+	}
+	c.put("b", key, page, started, ttl)
+	c.put("b", key, page, time.Now(), ttl)
+	if c.get("b", key, ttl) != nil {
+		t.Fatalf("expected no caching right after modification")
+	}
+	c.invalidated["b"] = time.Now().Add(-listCacheHold)
+	c.put("b", key, page, time.Now(), ttl)
+	if c.get("b", key, ttl) == nil {
+		t.Fatalf("expected cached page")
+	}
+
+	// disabled
+	if c.get("b", key, 0) != nil || c.size != 0 {
+		t.Fatalf("expected no caching when disabled, size %d", c.size)
+	}
+}
+
+func TestListCacheMaxSize(t *testing.T) {
+	var (
+		c    = newListCache()
+		ttl  = time.Minute
+		page = make([]byte, listCacheMaxSize/4)
+	)
+	keys := []string{"1", "2", "3", "4", "5"}
+	for _, key := range keys {
+		c.put("b", key, page, time.Now(), ttl)
+		time.Sleep(time.Millisecond)
+	}
+	if c.size > listCacheMaxSize {
+		t.Fatalf("cache size %d exceeds the limit %d", c.size, listCacheMaxSize)
+	}
+	if c.get("b", "1", ttl) != nil || c.get("b", "5", ttl) == nil {
+		t.Fatalf("expected the oldest page to be evicted")
+	}
+	c.put("b", "big", make([]byte, listCacheMaxSize), time.Now(), ttl)
+	if c.get("b", "big", ttl) != nil {
+		t.Fatalf("expected page larger than the limit not to be cached")
+	}
+}
@@ -0,0 +1,479 @@
+// Package ais provides core functionality for the AIStore object storage.
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package ais
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NVIDIA/aistore/3rdparty/glog"
+	"github.com/NVIDIA/aistore/ais/s3compat"
+	"github.com/NVIDIA/aistore/cluster"
+	"github.com/NVIDIA/aistore/cmn"
+	"github.com/NVIDIA/aistore/stats"
+	jsoniter "github.com/json-iterator/go"
+)
+
+//==========================================================================
+//
+// S3 compatibility API: /s3/[bucket-name[/object-name]]
+//
+// The handlers translate S3 requests into their native counterparts:
+// bucket-level requests are executed by the proxy itself while object-level
+// requests are redirected to (or reverse-proxied to, as per config) the HRW
+// target's /v1/objects. All errors are returned as S3 XML.
+//
+//==========================================================================
+
+// [METHOD] /s3
+func (p *proxyrunner) s3Handler(w http.ResponseWriter, r *http.Request) {
+	apitems := s3Items(r.URL.Path)
+	switch len(apitems) {
+	case 0:
+		if r.Method != http.MethodGet {
+			p.s3Err(w, r, s3compat.ErrMethodNotAllowed, "invalid method for /"+cmn.S3, http.StatusMethodNotAllowed)
+			return
+		}
+		p.s3ListBuckets(w, r)
+	case 1:
+		bucket := apitems[0]
+		switch r.Method {
+		case http.MethodGet:
+			p.s3ListObjects(w, r, bucket)
+		case http.MethodHead:
+			p.s3HeadBucket(w, r, bucket)
+		case http.MethodPut:
+			p.s3CreateBucket(w, r, bucket)
+		case http.MethodDelete:
+			p.s3DeleteBucket(w, r, bucket)
+		default:
+			p.s3Err(w, r, s3compat.ErrMethodNotAllowed, "invalid method for S3 bucket", http.StatusMethodNotAllowed)
+		}
+	default:
+		bucket, objname := apitems[0], apitems[1]
+		query := r.URL.Query()
+		_, initMultipart := query[s3compat.QparamUploads]
+		uploadID := query.Get(s3compat.QparamUploadID)
+		switch {
+		case r.Method == http.MethodPost && initMultipart:
+			p.s3InitMultipart(w, r, bucket, objname)
+		case r.Method == http.MethodPost && uploadID != "":
+			p.s3CompleteMultipart(w, r, bucket, objname, uploadID)
+		case r.Method == http.MethodDelete && uploadID != "":
+			p.s3AbortMultipart(w, r, bucket, objname, uploadID)
+		case r.Method == http.MethodGet, r.Method == http.MethodPut, r.Method == http.MethodDelete:
+			p.s3Object(w, r, bucket, objname)
+		case r.Method == http.MethodHead:
+			p.s3HeadObject(w, r, bucket, objname)
+		default:
+			p.s3Err(w, r, s3compat.ErrMethodNotAllowed, "invalid method for S3 object", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+// s3Items splits /s3/bucket-name/object/name into at most two items: bucket and object names
+func s3Items(path string) []string {
+	path = strings.TrimPrefix(path, "/"+cmn.S3)
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.SplitN(path, "/", 2)
+}
+
+func (p *proxyrunner) s3Err(w http.ResponseWriter, r *http.Request, code, msg string, status int) {
+	if glog.FastV(4, glog.SmoduleAIS) {
+		glog.Errorf("%s %s: %s (%d)", r.Method, r.URL.Path, msg, status)
+	}
+	s3compat.WriteErr(w, r, code, msg, status)
+	p.statsif.AddErrorHTTP(r.Method, 1)
+}
+
+func (p *proxyrunner) s3WriteXML(w http.ResponseWriter, r *http.Request, v interface{}) {
+	b, err := s3compat.Marshal(v)
+	if err != nil {
+		p.s3Err(w, r, s3compat.ErrInternalError, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	w.Write(b)
+}
+
+// validates bucket and returns true if it is an ais (local) bucket
+func (p *proxyrunner) s3ValidateBucket(w http.ResponseWriter, r *http.Request, bucket string) (bckIsLocal, ok bool) {
+	var err error
+	if p.smapowner.get().CountTargets() < 1 {
+		p.s3Err(w, r, s3compat.ErrServiceUnavailable, "No registered targets yet", http.StatusServiceUnavailable)
+		return
+	}
+	if bckIsLocal, err = p.bmdowner.get().ValidateBucket(bucket, ""); err != nil {
+		p.s3Err(w, r, s3compat.ErrNoSuchBucket, err.Error(), http.StatusNotFound)
+		return
+	}
+	return bckIsLocal, true
+}
+
+// GET /s3
+func (p *proxyrunner) s3ListBuckets(w http.ResponseWriter, r *http.Request) {
+	var (
+		si   *cluster.Snode
+		smap = p.smapowner.get()
+	)
+	for _, si = range smap.Tmap {
+		break
+	}
+	if si == nil {
+		p.s3Err(w, r, s3compat.ErrServiceUnavailable, "No registered targets yet", http.StatusServiceUnavailable)
+		return
+	}
+	args := callArgs{
+		si: si,
+		req: reqArgs{
+			method: http.MethodGet,
+			header: r.Header,
+			path:   cmn.URLPath(cmn.Version, cmn.Buckets, cmn.ListAll),
+		},
+		timeout: defaultTimeout,
+	}
+	res := p.call(args)
+	if res.err != nil {
+		p.s3Err(w, r, s3compat.ErrCode(res.status, false), res.errstr, cmn.Max(res.status, http.StatusBadRequest))
+		p.keepalive.onerr(res.err, res.status)
+		return
+	}
+	bucketNames := &cmn.BucketNames{}
+	if err := jsoniter.Unmarshal(res.outjson, bucketNames); err != nil {
+		p.s3Err(w, r, s3compat.ErrInternalError, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	names := append(bucketNames.Local, bucketNames.Cloud...)
+	sort.Strings(names)
+	result := s3compat.NewListAllMyBucketsResult()
+	for _, bucket := range names {
+		result.Add(bucket)
+	}
+	p.s3WriteXML(w, r, result)
+}
+
+// GET /s3/bucket-name (ListObjectsV2)
+func (p *proxyrunner) s3ListObjects(w http.ResponseWriter, r *http.Request, bucket string) {
+	started := time.Now()
+	bckIsLocal, ok := p.s3ValidateBucket(w, r, bucket)
+	if !ok {
+		return
+	}
+	var (
+		query  = r.URL.Query()
+		result = s3compat.NewListBucketResult(bucket)
+		msg    = cmn.SelectMsg{
+			Props:      cmn.GetPropsSize + ", " + cmn.GetPropsChecksum + ", " + cmn.GetPropsCtime,
+			TimeFormat: cmn.RFC3339,
+		}
+	)
+	result.Prefix = query.Get(s3compat.QparamPrefix)
+	result.Delimiter = query.Get(s3compat.QparamDelimiter)
+	result.StartAfter = query.Get(s3compat.QparamStartAfter)
+	result.ContinuationToken = query.Get(s3compat.QparamContinuationToken)
+	if s := query.Get(s3compat.QparamMaxKeys); s != "" {
+		maxKeys, err := strconv.Atoi(s)
+		if err != nil || maxKeys < 0 {
+			p.s3Err(w, r, s3compat.ErrInvalidArgument, fmt.Sprintf("invalid %s %q", s3compat.QparamMaxKeys, s),
+				http.StatusBadRequest)
+			return
+		}
+		if maxKeys < s3compat.DefaultMaxKeys {
+			result.MaxKeys = maxKeys
+		}
+	}
+	// max-keys=0: nothing to list, respond with an empty page
+	if result.MaxKeys == 0 {
+		p.s3WriteXML(w, r, result)
+		return
+	}
+	msg.Prefix = result.Prefix
+	msg.PageSize = result.MaxKeys
+	msg.PageMarker = result.StartAfter
+	if result.ContinuationToken != "" {
+		marker, err := s3compat.DecodeToken(result.ContinuationToken)
+		if err != nil {
+			p.s3Err(w, r, s3compat.ErrInvalidToken, err.Error(), http.StatusBadRequest)
+			return
+		}
+		msg.PageMarker = marker
+	}
+
+	bckProvider := cmn.LocalBs
+	if !bckIsLocal {
+		bckProvider = cmn.CloudBs
+	}
+	bckList, err := p.listBucket(r, bucket, bckProvider, msg)
+	if err != nil {
+		p.s3Err(w, r, s3compat.ErrInternalError, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	result.FromBucketList(bckList)
+	p.s3WriteXML(w, r, result)
+
+	delta := time.Since(started)
+	p.statsif.AddMany(
+		stats.NamedVal64{Name: stats.ListCount, Val: 1},
+		stats.NamedVal64{Name: stats.ListLatency, Val: int64(delta)},
+	)
+}
+
+// HEAD /s3/bucket-name
+func (p *proxyrunner) s3HeadBucket(w http.ResponseWriter, r *http.Request, bucket string) {
+	if _, ok := p.s3ValidateBucket(w, r, bucket); !ok {
+		return
+	}
+}
+
+// PUT /s3/bucket-name (creates local bucket)
+func (p *proxyrunner) s3CreateBucket(w http.ResponseWriter, r *http.Request, bucket string) {
+	msg := cmn.ActionMsg{Action: cmn.ActCreateLB}
+	if p.forwardCP(w, r, &msg, bucket, nil) {
+		return
+	}
+	if err := p.createBucket(&msg, bucket, true); err != nil {
+		status := http.StatusInternalServerError
+		if err == cmn.ErrorBucketAlreadyExists {
+			status = http.StatusConflict
+		}
+		p.s3Err(w, r, s3compat.ErrCode(status, false), err.Error(), status)
+	}
+}
+
+// DELETE /s3/bucket-name (destroys local bucket)
+func (p *proxyrunner) s3DeleteBucket(w http.ResponseWriter, r *http.Request, bucket string) {
+	msg := cmn.ActionMsg{Action: cmn.ActDestroyLB}
+	bckIsLocal, ok := p.s3ValidateBucket(w, r, bucket)
+	if !ok {
+		return
+	}
+	if !bckIsLocal {
+		p.s3Err(w, r, s3compat.ErrNotImplemented, fmt.Sprintf("cannot delete cloud bucket %s", bucket),
+			http.StatusNotImplemented)
+		return
+	}
+	if p.forwardCP(w, r, &msg, bucket, nil) {
+		return
+	}
+	if _, err := p.destroyBucket(&msg, bucket, true); err != nil {
+		p.s3Err(w, r, s3compat.ErrInternalError, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET, PUT, DELETE /s3/bucket-name/object-name
+func (p *proxyrunner) s3Object(w http.ResponseWriter, r *http.Request, bucket, objname string) {
+	started := time.Now()
+	bckIsLocal, ok := p.s3ValidateBucket(w, r, bucket)
+	if !ok {
+		return
+	}
+	si, errstr := hrwTarget(bucket, objname, p.smapowner.get())
+	if errstr != "" {
+		p.s3Err(w, r, s3compat.ErrInternalError, errstr, http.StatusInternalServerError)
+		return
+	}
+	query := p.s3TargetQuery(bckIsLocal, started)
+	if uploadID := r.URL.Query().Get(s3compat.QparamUploadID); uploadID != "" && r.Method == http.MethodPut {
+		query.Set(cmn.URLParamUploadID, uploadID)
+		query.Set(cmn.URLParamPartNum, r.URL.Query().Get(s3compat.QparamPartNumber))
+	}
+	if rng := r.Header.Get("Range"); rng != "" && r.Method == http.MethodGet {
+		offset, length, err := s3compat.ParseRange(rng, -1)
+		if err == s3compat.ErrRangeNeedsSize {
+			var (
+				hdr    http.Header
+				status int
+			)
+			if hdr, status, err = p.s3HeadTarget(r, si, bucket, objname, query); err != nil {
+				p.s3Err(w, r, s3compat.ErrCode(status, true), err.Error(), status)
+				return
+			}
+			size, _ := strconv.ParseInt(hdr.Get(cmn.HeaderObjSize), 10, 64)
+			offset, length, err = s3compat.ParseRange(rng, size)
+		}
+		if err != nil {
+			p.s3Err(w, r, s3compat.ErrInvalidRange, err.Error(), http.StatusRequestedRangeNotSatisfiable)
+			return
+		}
+		query.Set(cmn.URLParamOffset, strconv.FormatInt(offset, 10))
+		query.Set(cmn.URLParamLength, strconv.FormatInt(length, 10))
+	}
+	if glog.FastV(4, glog.SmoduleAIS) {
+		bmd := p.bmdowner.get()
+		glog.Infof("s3: %s %s/%s => %s", r.Method, bmd.Bstring(bucket, bckIsLocal), objname, si)
+	}
+	if !bckIsLocal && (r.Method == http.MethodPut || r.Method == http.MethodDelete) {
+		p.listCache.invalidate(bucket)
+	}
+	// the target responds to the client directly: have it report errors in S3 format
+	query.Set(cmn.URLParamS3, "true")
+	r.URL.Path = cmn.URLPath(cmn.Version, cmn.Objects, bucket, objname)
+	r.URL.RawQuery = query.Encode()
+	if cmn.GCO.Get().Net.HTTP.RevProxy == cmn.RevProxyTarget {
+		p.reverseDP(w, r, si)
+	} else {
+		redirectURL := si.URL(cmn.NetworkPublic) + r.URL.Path + "?" + r.URL.RawQuery
+		http.Redirect(w, r, redirectURL, http.StatusTemporaryRedirect)
+	}
+	switch r.Method {
+	case http.MethodGet:
+		p.statsif.Add(stats.GetCount, 1)
+	case http.MethodPut:
+		p.statsif.Add(stats.PutCount, 1)
+	case http.MethodDelete:
+		p.statsif.Add(stats.DeleteCount, 1)
+	}
+}
+
+// HEAD /s3/bucket-name/object-name
+func (p *proxyrunner) s3HeadObject(w http.ResponseWriter, r *http.Request, bucket, objname string) {
+	started := time.Now()
+	bckIsLocal, ok := p.s3ValidateBucket(w, r, bucket)
+	if !ok {
+		return
+	}
+	si, errstr := hrwTarget(bucket, objname, p.smapowner.get())
+	if errstr != "" {
+		p.s3Err(w, r, s3compat.ErrInternalError, errstr, http.StatusInternalServerError)
+		return
+	}
+	hdr, status, err := p.s3HeadTarget(r, si, bucket, objname, p.s3TargetQuery(bckIsLocal, started))
+	if err != nil {
+		p.s3Err(w, r, s3compat.ErrCode(status, true), err.Error(), status)
+		return
+	}
+	wh := w.Header()
+	for k, v := range hdr {
+		wh[k] = v
+	}
+	if size := hdr.Get(cmn.HeaderObjSize); size != "" {
+		wh.Set("Content-Length", size)
+	}
+	if cksum := hdr.Get(cmn.HeaderObjCksumVal); cksum != "" {
+		wh.Set("ETag", s3compat.ETag(cksum))
+	}
+}
+
+// query parameters that the target expects to see in a request redirected by the proxy
+func (p *proxyrunner) s3TargetQuery(bckIsLocal bool, started time.Time) url.Values {
+	query := url.Values{}
+	if !bckIsLocal {
+		query.Add(cmn.URLParamBckProvider, cmn.CloudBs)
+	}
+	query.Add(cmn.URLParamProxyID, p.si.DaemonID)
+	query.Add(cmn.URLParamBMDVersion, p.bmdowner.get().vstr)
+	query.Add(cmn.URLParamUnixTime, strconv.FormatInt(started.UnixNano(), 10))
+	return query
+}
+
+// executes HEAD object on the target and returns the resulting (native) object metadata
+func (p *proxyrunner) s3HeadTarget(r *http.Request, si *cluster.Snode, bucket, objname string,
+	query url.Values) (http.Header, int, error) {
+	u := si.URL(cmn.NetworkPublic) + cmn.URLPath(cmn.Version, cmn.Objects, bucket, objname) + "?" + query.Encode()
+	req, err := http.NewRequest(http.MethodHead, u, nil)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	copyHeaders(r.Header, &req.Header)
+	resp, err := p.httpclient.Do(req)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, resp.StatusCode, fmt.Errorf("%s/%s: %s", bucket, objname, resp.Status)
+	}
+	return resp.Header, resp.StatusCode, nil
+}
+
+// POST /s3/bucket-name/object-name?uploads
+func (p *proxyrunner) s3InitMultipart(w http.ResponseWriter, r *http.Request, bucket, objname string) {
+	res, ok := p.s3Multipart(w, r, bucket, objname, &cmn.ActionMsg{Action: cmn.ActInitMultipart})
+	if !ok {
+		return
+	}
+	mpuMsg := &cmn.MultipartMsg{}
+	if err := jsoniter.Unmarshal(res.outjson, mpuMsg); err != nil {
+		p.s3Err(w, r, s3compat.ErrInternalError, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	p.s3WriteXML(w, r, s3compat.NewInitiateMultipartUploadResult(bucket, objname, mpuMsg.UploadID))
+}
+
+// POST /s3/bucket-name/object-name?uploadId=...
+func (p *proxyrunner) s3CompleteMultipart(w http.ResponseWriter, r *http.Request, bucket, objname, uploadID string) {
+	complete := &s3compat.CompleteMultipartUpload{}
+	if err := xml.NewDecoder(r.Body).Decode(complete); err != nil {
+		p.s3Err(w, r, s3compat.ErrInvalidArgument, fmt.Sprintf("failed to parse request: %v", err),
+			http.StatusBadRequest)
+		return
+	}
+	msg := &cmn.ActionMsg{
+		Action: cmn.ActCompleteMultipart,
+		Value:  &cmn.MultipartMsg{UploadID: uploadID, Parts: complete.ToMultipartParts()},
+	}
+	res, ok := p.s3Multipart(w, r, bucket, objname, msg)
+	if !ok {
+		return
+	}
+	p.listCache.invalidate(bucket)
+	// same as HEAD: the checksum of the resulting object (none if the bucket is configured without checksums)
+	p.s3WriteXML(w, r, s3compat.NewCompleteMultipartUploadResult(bucket, objname, res.header.Get(cmn.HeaderObjCksumVal)))
+}
+
+// DELETE /s3/bucket-name/object-name?uploadId=...
+func (p *proxyrunner) s3AbortMultipart(w http.ResponseWriter, r *http.Request, bucket, objname, uploadID string) {
+	msg := &cmn.ActionMsg{Action: cmn.ActAbortMultipart, Value: &cmn.MultipartMsg{UploadID: uploadID}}
+	if _, ok := p.s3Multipart(w, r, bucket, objname, msg); !ok {
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// executes multipart action on the HRW target
+func (p *proxyrunner) s3Multipart(w http.ResponseWriter, r *http.Request, bucket, objname string,
+	msg *cmn.ActionMsg) (res callResult, ok bool) {
+	started := time.Now()
+	bckIsLocal, ok := p.s3ValidateBucket(w, r, bucket)
+	if !ok {
+		return
+	}
+	ok = false
+	si, errstr := hrwTarget(bucket, objname, p.smapowner.get())
+	if errstr != "" {
+		p.s3Err(w, r, s3compat.ErrInternalError, errstr, http.StatusInternalServerError)
+		return
+	}
+	body, err := jsoniter.Marshal(msg)
+	cmn.AssertNoErr(err)
+	args := callArgs{
+		si: si,
+		req: reqArgs{
+			method: http.MethodPost,
+			base:   si.URL(cmn.NetworkPublic),
+			path:   cmn.URLPath(cmn.Version, cmn.Objects, bucket, objname),
+			query:  p.s3TargetQuery(bckIsLocal, started),
+			body:   body,
+		},
+		timeout: longTimeout,
+	}
+	if res = p.call(args); res.err != nil {
+		status := cmn.Max(res.status, http.StatusBadRequest)
+		p.s3Err(w, r, s3compat.ErrCode(status, true), res.errstr, status)
+		return
+	}
+	return res, true
+}
@@ -5,6 +5,7 @@
 package ais
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -22,6 +23,8 @@ import (
 	"github.com/NVIDIA/aistore/3rdparty/glog"
 	"github.com/NVIDIA/aistore/cluster"
 	"github.com/NVIDIA/aistore/cmn"
+	"github.com/NVIDIA/aistore/ec"
+	"github.com/NVIDIA/aistore/encryption"
 	"github.com/NVIDIA/aistore/filter"
 	"github.com/NVIDIA/aistore/fs"
 	"github.com/NVIDIA/aistore/memsys"
@@ -37,6 +40,11 @@ const (
 
 const NeighborRebalanceStartDelay = 10 * time.Second
 
+// older versions of the objects (see cluster/lom_ver.go) are sent by global
+// rebalance along with the objects; Opaque of a version's header is the prefix
+// followed by JSON-encoded rebVersion
+const rebVerOpaque = "ver\x00"
+
 type (
 	rebJoggerBase struct {
 		m            *rebManager
@@ -50,11 +58,21 @@ type (
 		rebJoggerBase
 		smap *smapX // cluster.Smap?
 	}
+	// walks EC metafiles and re-encodes the objects whose slices and
+	// replicas are now to be placed on different targets
+	ecRebJogger struct {
+		rebJoggerBase
+	}
 	localRebJogger struct {
 		rebJoggerBase
 		slab *memsys.Slab2
 		buf  []byte
 	}
+	rebVersion struct {
+		Version string `json:"version"`
+		Meta    []byte `json:"meta"`  // as stored (see cluster.VersionMeta), rekeyed by the receiver
+		Mtime   int64  `json:"mtime"` // the time the version was superseded
+	}
 	rebManager struct {
 		t           *targetrunner
 		streams     *transport.StreamBundle
@@ -85,8 +103,13 @@ func (reb *rebManager) recvRebalanceObj(w http.ResponseWriter, hdr transport.Hea
 		glog.Error(errstr)
 		return
 	}
+	if bytes.HasPrefix(hdr.Opaque, []byte(rebVerOpaque)) {
+		reb.recvRebalanceVer(lom, hdr, objReader)
+		return
+	}
 	lom.SetAtimeUnix(hdr.ObjAttrs.Atime)
 	lom.SetVersion(hdr.ObjAttrs.Version)
+	lom.SetCustomMD(hdr.ObjAttrs.CustomMD)
 	roi := &recvObjInfo{
 		t:            reb.t,
 		lom:          lom,
@@ -108,6 +131,66 @@ func (reb *rebManager) recvRebalanceObj(w http.ResponseWriter, hdr transport.Hea
 	reb.t.statsif.AddMany(stats.NamedVal64{stats.RxCount, 1}, stats.NamedVal64{stats.RxSize, hdr.ObjAttrs.Size})
 }
 
+// stores the received older version of the object
+func (reb *rebManager) recvRebalanceVer(lom *cluster.LOM, hdr transport.Header, objReader io.Reader) {
+	var (
+		file *os.File
+		ver  = &rebVersion{}
+		err  = jsoniter.Unmarshal(hdr.Opaque[len(rebVerOpaque):], ver)
+	)
+	if err != nil {
+		glog.Errorf("%s: invalid version header, err: %v", lom, err)
+		return
+	}
+	workFQN := lom.GenFQN(fs.WorkfileType, fs.WorkfileRebalance)
+	if file, err = cmn.CreateFile(workFQN); err != nil {
+		reb.t.fshc(err, workFQN)
+		glog.Errorf("%s: failed to create %s, err: %v", lom, workFQN, err)
+		return
+	}
+	// received decrypted - encrypt anew, as per the current bucket properties
+	var (
+		md     []byte
+		writer io.Writer = file
+		encw   *encryption.Writer
+		keyID  = lom.EncryptKeyID()
+	)
+	if keyID != "" {
+		var key []byte
+		if key, err = encryption.Key(keyID); err == nil {
+			encw, err = encryption.NewWriter(file, key)
+			writer = encw
+		}
+	}
+	if err == nil {
+		buf, slab := gmem2.AllocFromSlab2(hdr.ObjAttrs.Size)
+		_, err = io.CopyBuffer(writer, objReader, buf)
+		slab.Free(buf)
+	}
+	if err == nil && encw != nil {
+		err = encw.Close()
+	}
+	if errClose := file.Close(); err == nil {
+		err = errClose
+	}
+	if err == nil {
+		md, err = cluster.RekeyVersionMeta(ver.Meta, hdr.ObjAttrs.Size, keyID)
+	}
+	if err == nil {
+		reb.t.rtnamemap.Lock(lom.Uname(), true)
+		err = lom.SaveVersion(workFQN, ver.Version, md, time.Unix(0, ver.Mtime))
+		reb.t.rtnamemap.Unlock(lom.Uname(), true)
+	}
+	if err != nil {
+		os.Remove(workFQN)
+		glog.Errorf("%s: failed to receive version %s, err: %v", lom, ver.Version, err)
+		return
+	}
+	if glog.FastV(4, glog.SmoduleAIS) {
+		glog.Infof("Rebalance %s version %s", lom, ver.Version)
+	}
+}
+
 //
 // GLOBAL REBALANCE
 //
@@ -142,7 +225,7 @@ func (rj *globalRebJogger) rebalanceObjCallback(hdr transport.Header, r io.ReadC
 // the walking callback is executed by the LRU xaction
 func (rj *globalRebJogger) walk(fqn string, fi os.FileInfo, inerr error) (err error) {
 	var (
-		file                  *cmn.FileHandle
+		file                  cluster.ObjReader
 		lom                   *cluster.LOM
 		si                    *cluster.Snode
 		errstr                string
@@ -179,6 +262,10 @@ func (rj *globalRebJogger) walk(fqn string, fi os.FileInfo, inerr error) (err er
 	if si.DaemonID == rj.m.t.si.DaemonID {
 		return nil
 	}
+	// EC replicas are not rebalanced - the main target re-creates them (see ecRebJogger)
+	if lom.BckIsLocal && lom.BckProps != nil && lom.BckProps.EC.Enabled && ec.IsReplica(lom, rj.m.t.si.DaemonID) {
+		return nil
+	}
 
 	// Skip objects that were already sent via GFN (due to probabilistic filtering
 	// false-positives, albeit rare, are still possible)
@@ -188,6 +275,14 @@ func (rj *globalRebJogger) walk(fqn string, fi os.FileInfo, inerr error) (err er
 		return nil
 	}
 
+	// the new owner does not know about the pending write-back - upload now
+	if !lom.BckIsLocal && lom.WriteBackPending() {
+		if err = rj.flushWriteBack(lom); err != nil {
+			glog.Errorf("%s: not rebalanced, write-back failed: %v", lom, err)
+			return nil
+		}
+	}
+
 	// LOCK & rebalance
 	if glog.FastV(4, glog.SmoduleAIS) {
 		glog.Infof("%s %s => %s", lom, rj.m.t.si.Name(), si.Name())
@@ -198,11 +293,16 @@ func (rj *globalRebJogger) walk(fqn string, fi os.FileInfo, inerr error) (err er
 	if errstr != "" || !lom.Exists() || lom.IsCopy() {
 		goto rerr
 	}
+	if !lom.BckIsLocal && lom.WriteBackPending() { // overwritten in the meantime
+		rj.m.t.rtnamemap.Unlock(uname, false)
+		glog.Warningf("%s: not rebalanced, write-back pending", lom)
+		return nil
+	}
 	if cksum, errstr = lom.CksumComputeIfMissing(); errstr != "" {
 		goto rerr
 	}
 	cksumType, cksumValue = cksum.Get()
-	if file, err = cmn.NewFileHandle(lom.FQN); err != nil {
+	if file, err = lom.Open(lom.FQN); err != nil { // sent decrypted (see lom_enc.go)
 		goto rerr
 	}
 	hdr = transport.Header{
@@ -211,11 +311,12 @@ func (rj *globalRebJogger) walk(fqn string, fi os.FileInfo, inerr error) (err er
 		IsLocal: lom.BckIsLocal,
 		Opaque:  []byte(si.DaemonID),
 		ObjAttrs: transport.ObjectAttrs{
-			Size:       fi.Size(),
+			Size:       lom.Size(),
 			Atime:      lom.Atime().UnixNano(),
 			CksumType:  cksumType,
 			CksumValue: cksumValue,
 			Version:    lom.Version(),
+			CustomMD:   lom.CustomMD(),
 		},
 	}
 
@@ -224,6 +325,9 @@ func (rj *globalRebJogger) walk(fqn string, fi os.FileInfo, inerr error) (err er
 		rj.wg.Done()
 		goto rerr
 	}
+	if lom.BckIsLocal && lom.VerConf().KeepsHistory() {
+		rj.sendVersions(lom, si)
+	}
 	return nil
 rerr:
 	rj.m.t.rtnamemap.Unlock(uname, false)
@@ -238,6 +342,113 @@ rerr:
 	return
 }
 
+// uploads the pending object under the exclusive lock - the upload updates
+// the object's metadata
+func (rj *globalRebJogger) flushWriteBack(lom *cluster.LOM) (err error) {
+	rj.m.t.rtnamemap.Lock(lom.Uname(), true)
+	defer rj.m.t.rtnamemap.Unlock(lom.Uname(), true)
+	if _, errstr := lom.Load(false); errstr != "" {
+		return errors.New(errstr)
+	}
+	return rj.m.t.writeBack.flushLocked(context.Background(), lom)
+}
+
+// sends the older versions of the object to its new target; as with the
+// objects themselves, the local versions are not removed
+func (rj *globalRebJogger) sendVersions(lom *cluster.LOM, si *cluster.Snode) {
+	vers, err := lom.OlderVersions()
+	if err != nil {
+		glog.Errorf("%s: failed to list versions, err: %v", lom, err)
+		return
+	}
+	cb := func(hdr transport.Header, r io.ReadCloser, err error) {
+		if err != nil {
+			glog.Errorf("failed to send version of %s/%s, err: %v", hdr.Bucket, hdr.Objname, err)
+		}
+		rj.wg.Done()
+	}
+	for _, v := range vers {
+		fqn := lom.VerFQN(v.Version)
+		md, err := cluster.VersionMeta(fqn)
+		if err != nil {
+			continue
+		}
+		file, err := lom.OpenVersion(fqn) // sent decrypted (see lom_enc.go)
+		if err != nil {
+			glog.Errorf("%s: failed to open version %s, err: %v", lom, v.Version, err)
+			continue
+		}
+		opaque, err := jsoniter.Marshal(&rebVersion{Version: v.Version, Meta: md, Mtime: v.Mtime.UnixNano()})
+		cmn.AssertNoErr(err)
+		hdr := transport.Header{
+			Bucket:   lom.Bucket,
+			Objname:  lom.Objname,
+			IsLocal:  lom.BckIsLocal,
+			Opaque:   append([]byte(rebVerOpaque), opaque...),
+			ObjAttrs: transport.ObjectAttrs{Size: v.Size, Version: v.Version},
+		}
+		rj.wg.Add(1)
+		if err := rj.m.t.rebManager.streams.SendV(hdr, file, cb, si); err != nil {
+			rj.wg.Done()
+			glog.Errorf("%s: failed to send version %s, err: %v", lom, v.Version, err)
+		}
+	}
+}
+
+func (rj *ecRebJogger) jog() {
+	if err := filepath.Walk(rj.mpath, rj.walk); err != nil {
+		if rj.xreb.Aborted() {
+			glog.Infof("Aborting %s traversal", rj.mpath)
+		} else {
+			glog.Errorf("Failed to traverse %s, err: %v", rj.mpath, err)
+		}
+	}
+
+	rj.xreb.confirmCh <- struct{}{}
+	rj.wg.Done()
+}
+
+func (rj *ecRebJogger) walk(fqn string, fi os.FileInfo, inerr error) error {
+	if rj.xreb.Aborted() {
+		return fmt.Errorf("%s: aborted, path %s", rj.xreb, rj.mpath)
+	}
+	if inerr != nil {
+		if errstr := cmn.PathWalkErr(inerr); errstr != "" {
+			glog.Error(errstr)
+			return inerr
+		}
+		return nil
+	}
+	if fi.Mode().IsDir() {
+		return nil
+	}
+	metaLOM, errstr := cluster.LOM{T: rj.m.t, FQN: fqn}.Init()
+	if errstr != "" {
+		if glog.FastV(4, glog.SmoduleAIS) {
+			glog.Infof("%s, err %s - skipping...", metaLOM, errstr)
+		}
+		return nil
+	}
+	// skip the directory if the bucket is not erasure coded
+	if metaLOM.BckProps == nil || !metaLOM.BckProps.EC.Enabled {
+		return filepath.SkipDir
+	}
+	lom, errstr := cluster.LOM{T: rj.m.t, Bucket: metaLOM.Bucket, Objname: metaLOM.Objname}.Init()
+	if errstr != "" {
+		return nil
+	}
+	reencoded, err := ECM.restoreBckPutXact(lom.Bucket).Rebalance(fqn, lom, rj.m.t.rtnamemap)
+	if err != nil {
+		glog.Errorf("Failed to rebalance EC object %s, err: %v", lom, err)
+		return nil
+	}
+	if reencoded {
+		rj.objectsMoved.Inc()
+		rj.bytesMoved.Add(lom.Size())
+	}
+	return nil
+}
+
 //
 // LOCAL REBALANCE
 //
@@ -335,11 +546,28 @@ func (rj *localRebJogger) walk(fqn string, fileInfo os.FileInfo, err error) erro
 	}
 	dst.Load(true)
 	rj.m.t.rtnamemap.Unlock(lom.Uname(), false)
+	if lom.BckIsLocal {
+		rj.moveVersions(lom)
+	}
 	rj.objectsMoved.Inc()
 	rj.bytesMoved.Add(fileInfo.Size())
 	return nil
 }
 
+// moves the older versions of the object along with it (see cluster/lom_ver.go)
+func (rj *localRebJogger) moveVersions(lom *cluster.LOM) {
+	hlom, errstr := cluster.LOM{T: rj.m.t, FQN: lom.HrwFQN}.Init()
+	if errstr != "" {
+		glog.Error(errstr)
+		return
+	}
+	rj.m.t.rtnamemap.Lock(lom.Uname(), true)
+	if errstr = lom.MoveVersions(hlom, rj.buf); errstr != "" {
+		glog.Error(errstr)
+	}
+	rj.m.t.rtnamemap.Unlock(lom.Uname(), true)
+}
+
 // pingTarget pings target to check if it is running. After DestRetryTime it
 // assumes that target is dead. Returns true if target is healthy and running,
 // false otherwise.
@@ -515,7 +743,7 @@ func (reb *rebManager) runGlobalReb(smap *smapX, newTargetID string) {
 	// abort in-progress xaction if exists and if its Smap version is lower
 	// start new xaction unless the one for the current version is already in progress
 	availablePaths, _ := fs.Mountpaths.Get()
-	runnerCnt := len(availablePaths) * 2
+	runnerCnt := len(availablePaths) * 3 // cloud, local and EC metadata
 	xreb := reb.t.xactions.renewGlobalReb(ver, runnerCnt)
 	if xreb == nil {
 		return
@@ -544,7 +772,7 @@ func (reb *rebManager) runGlobalReb(smap *smapX, newTargetID string) {
 	wg = &sync.WaitGroup{}
 
 	joggers := make([]*globalRebJogger, 0, runnerCnt)
-	// TODO: currently supporting a single content-type: Object
+	ecJoggers := make([]*ecRebJogger, 0, len(availablePaths))
 	for _, mpathInfo := range availablePaths {
 		mpathC := mpathInfo.MakePath(fs.ObjectType, false /*cloud*/)
 		rc := &globalRebJogger{rebJoggerBase: rebJoggerBase{m: reb, mpath: mpathC, xreb: xreb, wg: wg}, smap: smap}
@@ -557,6 +785,12 @@ func (reb *rebManager) runGlobalReb(smap *smapX, newTargetID string) {
 		wg.Add(1)
 		joggers = append(joggers, rl)
 		go rl.jog()
+
+		mpathE := mpathInfo.MakePath(ec.MetaType, true /*is local*/)
+		re := &ecRebJogger{rebJoggerBase: rebJoggerBase{m: reb, mpath: mpathE, xreb: xreb, wg: wg}}
+		wg.Add(1)
+		ecJoggers = append(ecJoggers, re)
+		go re.jog()
 	}
 	wg.Wait()
 
@@ -568,6 +802,10 @@ func (reb *rebManager) runGlobalReb(smap *smapX, newTargetID string) {
 			totalObjectsMoved += jogger.objectsMoved.Load()
 			totalBytesMoved += jogger.bytesMoved.Load()
 		}
+		for _, jogger := range ecJoggers {
+			totalObjectsMoved += jogger.objectsMoved.Load()
+			totalBytesMoved += jogger.bytesMoved.Load()
+		}
 		if !xreb.Aborted() {
 			if err := os.Remove(pmarker); err != nil {
 				glog.Errorf("failed to remove in-progress mark %s, err: %v", pmarker, err)
@@ -582,6 +820,7 @@ func (reb *rebManager) runGlobalReb(smap *smapX, newTargetID string) {
 		glog.Infof("rebalance %s(self)", reb.t.si.Name())
 		reb.pollRebalancingDone(smap) // until the cluster is fully rebalanced - see t.httpobjget
 	}
+	reb.t.quotas.invalidate() // objects have migrated
 	xreb.EndTime(time.Now())
 }
 
@@ -0,0 +1,400 @@
+// Package ais provides core functionality for the AIStore object storage.
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package ais
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NVIDIA/aistore/3rdparty/glog"
+	"github.com/NVIDIA/aistore/cluster"
+	"github.com/NVIDIA/aistore/cmn"
+	jsoniter "github.com/json-iterator/go"
+)
+
+//
+// Another AIStore cluster as the Cloud: Cloud buckets of this cluster are the
+// buckets (local or Cloud) of the remote cluster addressed by its proxy URL (see
+// cmn.RemoteAISConf). Requests are sent to the remote primary; the remote cluster
+// map is fetched from the configured URL and from the known remote proxies -
+// periodically and whenever the (presumably, former) primary is unreachable.
+// The user's token, if any, is forwarded to the remote cluster as is.
+//
+
+const (
+	remaisSmapRefresh = time.Minute // max age of the remote cluster map
+	remaisMaxRedirect = 10
+)
+
+type (
+	remaisimpl struct {
+		t      *targetrunner
+		client *http.Client
+		mtx    sync.Mutex
+		smap   *cluster.Smap // remote cluster map
+		synced time.Time     // when the remote cluster map was fetched
+	}
+)
+
+var (
+	_ cloudif = &remaisimpl{}
+)
+
+func newRemAISProvider(t *targetrunner) *remaisimpl {
+	client := cmn.NewClient(cmn.ClientArgs{IdleConnsPerHost: 100})
+	// remote proxies redirect object requests to remote targets: keep the token
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= remaisMaxRedirect {
+			return fmt.Errorf("stopped after %d redirects", remaisMaxRedirect)
+		}
+		if auth := via[0].Header.Get("Authorization"); auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		return nil
+	}
+	return &remaisimpl{t: t, client: client}
+}
+
+//======
+//
+// remote cluster map
+//
+//======
+
+// returns the public URL of the remote primary
+func (r *remaisimpl) primaryURL(refresh bool) (string, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if refresh || r.smap == nil || time.Since(r.synced) > remaisSmapRefresh {
+		if err := r.refreshSmap(); err != nil {
+			if r.smap == nil {
+				return "", err
+			}
+			glog.Errorf("failed to refresh %s cluster map, err: %v", cmn.ProviderRemoteAIS, err)
+		}
+	}
+	return r.smap.ProxySI.PublicNet.DirectURL, nil
+}
+
+// fetches the cluster map from the configured URL and from all known remote
+// proxies and adopts the newest one; must be called under lock
+func (r *remaisimpl) refreshSmap() (err error) {
+	var (
+		newest *cluster.Smap
+		urls   = []string{cmn.GCO.Get().RemoteAIS.URL}
+	)
+	if r.smap != nil {
+		for _, si := range r.smap.Pmap {
+			urls = append(urls, si.PublicNet.DirectURL)
+		}
+	}
+	for _, u := range urls {
+		if u == "" {
+			continue
+		}
+		smap, errget := r.getSmap(u)
+		if errget != nil {
+			err = errget
+			continue
+		}
+		if newest == nil || smap.Version > newest.Version {
+			newest = smap
+		}
+	}
+	if newest == nil {
+		if err == nil {
+			err = errors.New("remote cluster URL is not configured")
+		}
+		return
+	}
+	if r.smap != nil && r.smap.ProxySI.DaemonID != newest.ProxySI.DaemonID {
+		glog.Infof("%s: primary changed %s => %s (cluster map v%d)", cmn.ProviderRemoteAIS,
+			r.smap.ProxySI.DaemonID, newest.ProxySI.DaemonID, newest.Version)
+	}
+	r.smap, r.synced = newest, time.Now()
+	return nil
+}
+
+func (r *remaisimpl) getSmap(proxyURL string) (*cluster.Smap, error) {
+	query := url.Values{cmn.URLParamWhat: []string{cmn.GetWhatSmap}}
+	geturl := proxyURL + cmn.URLPath(cmn.Version, cmn.Daemon) + "?" + query.Encode()
+	ctx, cancel := context.WithTimeout(context.Background(), cmn.GCO.Get().Timeout.Default)
+	defer cancel()
+	req, err := http.NewRequest(http.MethodGet, geturl, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", geturl, resp.Status)
+	}
+	smap := &cluster.Smap{}
+	if err = jsoniter.NewDecoder(resp.Body).Decode(smap); err != nil {
+		return nil, fmt.Errorf("GET %s: failed to decode cluster map, err: %v", geturl, err)
+	}
+	if smap.ProxySI == nil {
+		return nil, fmt.Errorf("GET %s: cluster map without primary", geturl)
+	}
+	return smap, nil
+}
+
+//======
+//
+// requests
+//
+//======
+
+// sends the request to the remote primary (retrying once upon connection failure -
+// with the refreshed cluster map); on success, the caller must close the response body
+func (r *remaisimpl) do(ct context.Context, method, path string, hdr http.Header,
+	open func() (io.ReadCloser, error), size int64) (resp *http.Response, err error, errcode int) {
+	for retry := false; ; retry = true {
+		var (
+			primary string
+			body    io.ReadCloser
+			req     *http.Request
+		)
+		if primary, err = r.primaryURL(retry); err != nil {
+			return nil, err, http.StatusBadGateway
+		}
+		if open != nil {
+			if body, err = open(); err != nil {
+				return nil, err, http.StatusInternalServerError
+			}
+		}
+		if req, err = http.NewRequest(method, primary+path, body); err != nil {
+			return nil, err, http.StatusInternalServerError
+		}
+		if open != nil {
+			req.GetBody = open // to follow the remote proxy's redirect
+			req.ContentLength = size
+		}
+		for k, v := range hdr {
+			req.Header[k] = v
+		}
+		if token := getStringFromContext(ct, ctxUserToken); token != "" {
+			req.Header.Set("Authorization", tokenStart+" "+token)
+		}
+		if resp, err = r.client.Do(req.WithContext(ct)); err == nil {
+			break
+		}
+		if retry || ct.Err() != nil {
+			return nil, err, http.StatusBadGateway
+		}
+		glog.Warningf("%s %s failed, err: %v - refreshing %s cluster map", method, primary+path, err,
+			cmn.ProviderRemoteAIS)
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		b, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		msg := strings.TrimSpace(string(b))
+		if msg == "" {
+			msg = resp.Status
+		}
+		return nil, fmt.Errorf("%s: %s", cmn.ProviderRemoteAIS, msg), resp.StatusCode
+	}
+	return
+}
+
+//==================
+//
+// bucket operations
+//
+//==================
+func (r *remaisimpl) listbucket(ct context.Context, bucket string, msg *cmn.SelectMsg) (jsbytes []byte, err error, errcode int) {
+	if glog.FastV(4, glog.SmoduleAIS) {
+		glog.Infof("listbucket %s", bucket)
+	}
+	body, err := jsoniter.Marshal(cmn.ActionMsg{Action: cmn.ActListObjects, Value: msg})
+	cmn.AssertNoErr(err)
+	open := func() (io.ReadCloser, error) { return ioutil.NopCloser(bytes.NewReader(body)), nil }
+	hdr := http.Header{"Content-Type": []string{"application/json"}}
+	resp, err, errcode := r.do(ct, http.MethodPost, cmn.URLPath(cmn.Version, cmn.Buckets, bucket), hdr, open,
+		int64(len(body)))
+	if err != nil {
+		if errcode == http.StatusNotFound {
+			err = cmn.ErrorCloudBucketDoesNotExist
+		}
+		return
+	}
+	defer resp.Body.Close()
+	// the remote cluster's list is returned as is
+	if jsbytes, err = ioutil.ReadAll(resp.Body); err != nil {
+		errcode = http.StatusBadGateway
+	}
+	return
+}
+
+func (r *remaisimpl) headbucket(ct context.Context, bucket string) (bucketprops cmn.SimpleKVs, err error, errcode int) {
+	if glog.FastV(4, glog.SmoduleAIS) {
+		glog.Infof("headbucket %s", bucket)
+	}
+	resp, err, errcode := r.do(ct, http.MethodHead, cmn.URLPath(cmn.Version, cmn.Buckets, bucket), nil, nil, 0)
+	if err != nil {
+		if errcode == http.StatusNotFound {
+			err = cmn.ErrorCloudBucketDoesNotExist
+		}
+		return
+	}
+	resp.Body.Close()
+	bucketprops = make(cmn.SimpleKVs)
+	bucketprops[cmn.HeaderCloudProvider] = cmn.ProviderRemoteAIS
+	verEnabled := resp.Header.Get(cmn.HeaderBucketVerEnabled)
+	if verEnabled == "" {
+		verEnabled = "false"
+	}
+	bucketprops[cmn.HeaderBucketVerEnabled] = verEnabled
+	return
+}
+
+// returns the names of all - local and Cloud - buckets of the remote cluster
+func (r *remaisimpl) getbucketnames(ct context.Context) (buckets []string, err error, errcode int) {
+	resp, err, errcode := r.do(ct, http.MethodGet, cmn.URLPath(cmn.Version, cmn.Buckets, cmn.ListAll), nil, nil, 0)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	names := &cmn.BucketNames{}
+	if err = jsoniter.NewDecoder(resp.Body).Decode(names); err != nil {
+		return nil, err, http.StatusBadGateway
+	}
+	buckets = make([]string, 0, len(names.Local)+len(names.Cloud))
+	buckets = append(buckets, names.Local...)
+	for _, bucket := range names.Cloud {
+		if !cmn.StringInSlice(bucket, names.Local) {
+			buckets = append(buckets, bucket)
+		}
+	}
+	return
+}
+
+//============
+//
+// object meta
+//
+//============
+func (r *remaisimpl) headobject(ct context.Context, lom *cluster.LOM) (objmeta cmn.SimpleKVs, err error, errcode int) {
+	path := cmn.URLPath(cmn.Version, cmn.Objects, lom.Bucket, lom.Objname)
+	resp, err, errcode := r.do(ct, http.MethodHead, path, nil, nil, 0)
+	if err != nil {
+		return
+	}
+	resp.Body.Close()
+	objmeta = make(cmn.SimpleKVs)
+	objmeta[cmn.HeaderCloudProvider] = cmn.ProviderRemoteAIS
+	if version := resp.Header.Get(cmn.HeaderObjVersion); version != "" {
+		objmeta[cmn.HeaderObjVersion] = version
+	}
+	if size := resp.Header.Get(cmn.HeaderObjSize); size != "" {
+		objmeta[cmn.HeaderObjSize] = size
+	}
+	customMD, _ := cmn.CustomMDFromHeader(resp.Header)
+	for k, v := range customMD {
+		objmeta[cmn.HeaderObjCustomPrefix+k] = v
+	}
+	if glog.FastV(4, glog.SmoduleAIS) {
+		glog.Infof("HEAD %s", lom)
+	}
+	return
+}
+
+//=======================
+//
+// object data operations
+//
+//=======================
+func (r *remaisimpl) getobj(ctx context.Context, workFQN string, lom *cluster.LOM) (err error, errcode int) {
+	path := cmn.URLPath(cmn.Version, cmn.Objects, lom.Bucket, lom.Objname)
+	resp, err, errcode := r.do(ctx, http.MethodGet, path, nil, nil, 0)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	var (
+		cksum       = cmn.NewCksum(resp.Header.Get(cmn.HeaderObjCksumType), resp.Header.Get(cmn.HeaderObjCksumVal))
+		customMD, _ = cmn.CustomMDFromHeader(resp.Header)
+	)
+	lom.SetCksum(nil)
+	lom.SetVersion(resp.Header.Get(cmn.HeaderObjVersion))
+	lom.SetCustomMD(customMD)
+	roi := &recvObjInfo{
+		t:       r.t,
+		lom:     lom,
+		r:       resp.Body,
+		workFQN: workFQN,
+		cold:    true,
+	}
+	if err = roi.writeToFile(); err != nil {
+		return
+	}
+	// both clusters compute the same (xxhash) checksum
+	if cksum != nil && lom.CksumConf().ValidateColdGet && lom.Cksum() != nil {
+		if cksumType, _ := cksum.Get(); cksumType == cmn.ChecksumXXHash && !cmn.EqCksum(cksum, lom.Cksum()) {
+			os.Remove(workFQN)
+			return fmt.Errorf("%s: bad checksum - expected %s, got %s", lom, cksum, lom.Cksum()),
+				http.StatusInternalServerError
+		}
+	}
+	if glog.FastV(4, glog.SmoduleAIS) {
+		glog.Infof("GET %s", lom)
+	}
+	return
+}
+
+func (r *remaisimpl) putobj(ct context.Context, file *os.File, lom *cluster.LOM) (version string, err error, errcode int) {
+	finfo, err := file.Stat()
+	if err != nil {
+		return "", err, http.StatusInternalServerError
+	}
+	var (
+		fqn  = file.Name()
+		path = cmn.URLPath(cmn.Version, cmn.Objects, lom.Bucket, lom.Objname)
+		hdr  = make(http.Header, len(lom.CustomMD())+2)
+		open = func() (io.ReadCloser, error) { return os.Open(fqn) }
+	)
+	if cksum := lom.Cksum(); cksum != nil {
+		cksumType, cksumValue := cksum.Get()
+		hdr.Set(cmn.HeaderObjCksumType, cksumType)
+		hdr.Set(cmn.HeaderObjCksumVal, cksumValue)
+	}
+	cmn.CustomMDToHeader(lom.CustomMD(), hdr)
+	resp, err, errcode := r.do(ct, http.MethodPut, path, hdr, open, finfo.Size())
+	if err != nil {
+		return
+	}
+	resp.Body.Close()
+	version = resp.Header.Get(cmn.HeaderObjVersion)
+	if glog.FastV(4, glog.SmoduleAIS) {
+		glog.Infof("PUT %s (%s)", lom, cmn.B2S(finfo.Size(), 1))
+	}
+	return
+}
+
+func (r *remaisimpl) deleteobj(ct context.Context, lom *cluster.LOM) (err error, errcode int) {
+	path := cmn.URLPath(cmn.Version, cmn.Objects, lom.Bucket, lom.Objname)
+	resp, err, errcode := r.do(ct, http.MethodDelete, path, nil, nil, 0)
+	if err != nil {
+		return
+	}
+	resp.Body.Close()
+	if glog.FastV(4, glog.SmoduleAIS) {
+		glog.Infof("DELETE %s", lom)
+	}
+	return
+}
@@ -0,0 +1,257 @@
+// Package ais provides core functionality for the AIStore object storage.
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package ais
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NVIDIA/aistore/cluster"
+	"github.com/NVIDIA/aistore/cmn"
+	"github.com/NVIDIA/aistore/memsys"
+)
+
+// remote cluster stand-in: two proxies and one target; the proxies serve the
+// cluster map and bucket requests and redirect GET and PUT of objects to the target
+type remoteCluster struct {
+	mtx     sync.Mutex
+	smap    *cluster.Smap
+	objects map[string][]byte // bucket/objname => content
+	token   string            // expected token
+	proxies [2]*httptest.Server
+	target  *httptest.Server
+}
+
+func newRemoteCluster(token string) *remoteCluster {
+	rc := &remoteCluster{objects: make(map[string][]byte), token: token}
+	rc.target = httptest.NewServer(http.HandlerFunc(rc.targetHandler))
+	rc.smap = &cluster.Smap{Pmap: make(cluster.NodeMap), Tmap: make(cluster.NodeMap), Version: 1}
+	for i := range rc.proxies {
+		rc.proxies[i] = httptest.NewServer(http.HandlerFunc(rc.proxyHandler))
+		si := &cluster.Snode{DaemonID: "p" + strconv.Itoa(i+1)}
+		si.PublicNet.DirectURL = rc.proxies[i].URL
+		rc.smap.Pmap[si.DaemonID] = si
+	}
+	rc.smap.ProxySI = rc.smap.Pmap["p1"]
+	return rc
+}
+
+func (rc *remoteCluster) close() {
+	rc.proxies[0].Close()
+	rc.proxies[1].Close()
+	rc.target.Close()
+}
+
+// primary p1 goes down, p2 takes over
+func (rc *remoteCluster) failover() {
+	rc.mtx.Lock()
+	rc.smap = &cluster.Smap{Pmap: rc.smap.Pmap, Tmap: rc.smap.Tmap, Version: rc.smap.Version + 1,
+		ProxySI: rc.smap.Pmap["p2"]}
+	rc.mtx.Unlock()
+	rc.proxies[0].CloseClientConnections()
+	rc.proxies[0].Close()
+}
+
+func (rc *remoteCluster) authorized(w http.ResponseWriter, r *http.Request) bool {
+	if rc.token != "" && r.Header.Get("Authorization") != tokenStart+" "+rc.token {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+func (rc *remoteCluster) proxyHandler(w http.ResponseWriter, r *http.Request) {
+	rc.mtx.Lock()
+	defer rc.mtx.Unlock()
+	items := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/v1/"), "/", 3)
+	if items[0] == cmn.Daemon {
+		json.NewEncoder(w).Encode(rc.smap)
+		return
+	}
+	if !rc.authorized(w, r) {
+		return
+	}
+	bucket := items[1]
+	if items[0] == cmn.Buckets {
+		switch {
+		case bucket == cmn.ListAll:
+			json.NewEncoder(w).Encode(cmn.BucketNames{Local: []string{"remote"}, Cloud: []string{"remote", "s3"}})
+		case bucket != "remote":
+			http.Error(w, "bucket does not exist", http.StatusNotFound)
+		case r.Method == http.MethodHead:
+			w.Header().Set(cmn.HeaderBucketVerEnabled, "true")
+		default:
+			list := cmn.BucketList{Entries: []*cmn.BucketEntry{}}
+			for name := range rc.objects {
+				list.Entries = append(list.Entries, &cmn.BucketEntry{Name: strings.TrimPrefix(name, "remote/")})
+			}
+			json.NewEncoder(w).Encode(list)
+		}
+		return
+	}
+	name := bucket + "/" + items[2]
+	switch r.Method {
+	case http.MethodGet, http.MethodPut:
+		http.Redirect(w, r, rc.target.URL+r.URL.Path, http.StatusTemporaryRedirect)
+	case http.MethodHead:
+		data, ok := rc.objects[name]
+		if !ok {
+			http.Error(w, "object does not exist", http.StatusNotFound)
+			return
+		}
+		w.Header().Set(cmn.HeaderObjVersion, "1")
+		w.Header().Set(cmn.HeaderObjSize, strconv.Itoa(len(data)))
+		w.Header().Set(cmn.HeaderObjCustomPrefix+"source", "remote")
+	case http.MethodDelete:
+		delete(rc.objects, name)
+	}
+}
+
+func (rc *remoteCluster) targetHandler(w http.ResponseWriter, r *http.Request) {
+	if !rc.authorized(w, r) {
+		return
+	}
+	rc.mtx.Lock()
+	defer rc.mtx.Unlock()
+	name := strings.TrimPrefix(r.URL.Path, "/v1/objects/")
+	switch r.Method {
+	case http.MethodPut:
+		data, _ := ioutil.ReadAll(r.Body)
+		rc.objects[name] = data
+	case http.MethodGet:
+		data, ok := rc.objects[name]
+		if !ok {
+			http.Error(w, "object does not exist", http.StatusNotFound)
+			return
+		}
+		w.Header().Set(cmn.HeaderObjVersion, "1")
+		w.Header().Set(cmn.HeaderObjCustomPrefix+"source", "remote")
+		w.Write(data)
+	}
+}
+
+func TestRemoteAIS(t *testing.T) {
+	const token = "remote-token"
+	var (
+		rc   = newRemoteCluster(token)
+		r    = newRemAISProvider(nil)
+		data = []byte("content")
+		ctx  = context.WithValue(context.Background(), ctxUserToken, token)
+	)
+	defer rc.close()
+	config := cmn.GCO.BeginUpdate()
+	config.RemoteAIS.URL = rc.proxies[0].URL
+	config.Timeout.Default = 10 * time.Second
+	cmn.GCO.CommitUpdate(config)
+	defer func() {
+		config := cmn.GCO.BeginUpdate()
+		config.RemoteAIS.URL = ""
+		cmn.GCO.CommitUpdate(config)
+	}()
+
+	gmem2 = &memsys.Mem2{Name: "remaismem"}
+	if err := gmem2.Init(true); err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "remais-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	srcFQN := filepath.Join(dir, "src")
+	if err := ioutil.WriteFile(srcFQN, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// buckets
+	buckets, err, _ := r.getbucketnames(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(buckets, ",") != "remote,s3" {
+		t.Errorf("expected [remote s3], got %v", buckets)
+	}
+	props, err, _ := r.headbucket(ctx, "remote")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if props[cmn.HeaderCloudProvider] != cmn.ProviderRemoteAIS || props[cmn.HeaderBucketVerEnabled] != "true" {
+		t.Errorf("unexpected bucket props %v", props)
+	}
+	if _, err, _ := r.headbucket(ctx, "nonexistent"); err != cmn.ErrorCloudBucketDoesNotExist {
+		t.Errorf("expected %v, got %v", cmn.ErrorCloudBucketDoesNotExist, err)
+	}
+	if _, err, errcode := r.headbucket(context.Background(), "remote"); errcode != http.StatusUnauthorized {
+		t.Errorf("expected 401 without token, got %v (%d)", err, errcode)
+	}
+
+	// PUT (redirected to the remote target)
+	file, err := os.Open(srcFQN)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lom := &cluster.LOM{Bucket: "remote", Objname: "dir/obj"}
+	_, err, _ = r.putobj(ctx, file, lom)
+	file.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(rc.objects["remote/dir/obj"]) != string(data) {
+		t.Fatalf("object was not written through: %q", rc.objects["remote/dir/obj"])
+	}
+
+	// remote primary changes
+	rc.failover()
+
+	jsbytes, err, _ := r.listbucket(ctx, "remote", &cmn.SelectMsg{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(jsbytes), "dir/obj") {
+		t.Errorf("expected dir/obj in the list, got %s", jsbytes)
+	}
+	if r.smap.ProxySI.DaemonID != "p2" {
+		t.Errorf("expected the new primary p2, got %s", r.smap.ProxySI.DaemonID)
+	}
+
+	// HEAD and cold GET
+	objmeta, err, _ := r.headobject(ctx, lom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if objmeta[cmn.HeaderObjVersion] != "1" || objmeta[cmn.HeaderObjCustomPrefix+"source"] != "remote" {
+		t.Errorf("unexpected object metadata %v", objmeta)
+	}
+	workFQN := filepath.Join(dir, "work")
+	getLOM := &cluster.LOM{Bucket: "remote", Objname: "dir/obj",
+		BckProps: &cmn.BucketProps{Cksum: cmn.CksumConf{Type: cmn.ChecksumXXHash}}}
+	if err, _ := r.getobj(ctx, workFQN, getLOM); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := ioutil.ReadFile(workFQN); err != nil || string(b) != string(data) {
+		t.Fatalf("unexpected content %q, err: %v", b, err)
+	}
+	if getLOM.Version() != "1" || getLOM.CustomMD()["source"] != "remote" {
+		t.Errorf("unexpected version %q or custom metadata %v", getLOM.Version(), getLOM.CustomMD())
+	}
+
+	// DELETE
+	if err, _ := r.deleteobj(ctx, lom); err != nil {
+		t.Fatal(err)
+	}
+	if _, err, errcode := r.headobject(ctx, lom); errcode != http.StatusNotFound {
+		t.Errorf("expected 404 after delete, got %v (%d)", err, errcode)
+	}
+}
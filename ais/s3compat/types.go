@@ -0,0 +1,197 @@
+// Package s3compat provides Amazon S3 compatibility layer
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package s3compat
+
+import (
+	"encoding/xml"
+	"strings"
+
+	"github.com/NVIDIA/aistore/cmn"
+)
+
+const (
+	// s3 namespace of the XML documents
+	s3Namespace = "http://s3.amazonaws.com/doc/2006-03-01/"
+
+	// AIS does not track bucket owners - S3 clients want _some_ owner though
+	ownerID   = "aistore"
+	ownerName = "aistore"
+
+	// the only storage class
+	storageClass = "STANDARD"
+
+	// AIS does not track bucket creation time either
+	creationTime = "1970-01-01T00:00:00.000Z"
+
+	// default and maximum number of keys per ListObjectsV2 page (as per S3 spec)
+	DefaultMaxKeys = 1000
+)
+
+// ListObjectsV2 query parameters
+const (
+	QparamListType          = "list-type"
+	QparamPrefix            = "prefix"
+	QparamDelimiter         = "delimiter"
+	QparamMaxKeys           = "max-keys"
+	QparamStartAfter        = "start-after"
+	QparamContinuationToken = "continuation-token"
+
+	// multipart upload
+	QparamUploads    = "uploads"
+	QparamUploadID   = "uploadId"
+	QparamPartNumber = "partNumber"
+)
+
+type (
+	// GET / (ListBuckets) response
+	ListAllMyBucketsResult struct {
+		XMLName xml.Name    `xml:"ListAllMyBucketsResult"`
+		Ns      string      `xml:"xmlns,attr"`
+		Owner   BucketOwner `xml:"Owner"`
+		Buckets []*Bucket   `xml:"Buckets>Bucket"`
+	}
+	BucketOwner struct {
+		ID          string `xml:"ID"`
+		DisplayName string `xml:"DisplayName"`
+	}
+	Bucket struct {
+		Name         string `xml:"Name"`
+		CreationDate string `xml:"CreationDate"`
+	}
+
+	// GET /bucket?list-type=2 (ListObjectsV2) response
+	ListBucketResult struct {
+		XMLName               xml.Name        `xml:"ListBucketResult"`
+		Ns                    string          `xml:"xmlns,attr"`
+		Name                  string          `xml:"Name"`
+		Prefix                string          `xml:"Prefix"`
+		Delimiter             string          `xml:"Delimiter,omitempty"`
+		StartAfter            string          `xml:"StartAfter,omitempty"`
+		ContinuationToken     string          `xml:"ContinuationToken,omitempty"`
+		NextContinuationToken string          `xml:"NextContinuationToken,omitempty"`
+		KeyCount              int             `xml:"KeyCount"`
+		MaxKeys               int             `xml:"MaxKeys"`
+		IsTruncated           bool            `xml:"IsTruncated"`
+		Contents              []*ObjInfo      `xml:"Contents"`
+		CommonPrefixes        []*CommonPrefix `xml:"CommonPrefixes,omitempty"`
+	}
+	ObjInfo struct {
+		Key          string `xml:"Key"`
+		LastModified string `xml:"LastModified"`
+		ETag         string `xml:"ETag"`
+		Size         int64  `xml:"Size"`
+		StorageClass string `xml:"StorageClass"`
+	}
+	CommonPrefix struct {
+		Prefix string `xml:"Prefix"`
+	}
+
+	// POST /bucket/object?uploads (CreateMultipartUpload) response
+	InitiateMultipartUploadResult struct {
+		XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+		Ns       string   `xml:"xmlns,attr"`
+		Bucket   string   `xml:"Bucket"`
+		Key      string   `xml:"Key"`
+		UploadID string   `xml:"UploadId"`
+	}
+
+	// POST /bucket/object?uploadId=... (CompleteMultipartUpload) request and response
+	CompleteMultipartUpload struct {
+		XMLName xml.Name    `xml:"CompleteMultipartUpload"`
+		Parts   []*PartInfo `xml:"Part"`
+	}
+	PartInfo struct {
+		PartNumber int    `xml:"PartNumber"`
+		ETag       string `xml:"ETag"`
+	}
+	CompleteMultipartUploadResult struct {
+		XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
+		Ns      string   `xml:"xmlns,attr"`
+		Bucket  string   `xml:"Bucket"`
+		Key     string   `xml:"Key"`
+		ETag    string   `xml:"ETag"`
+	}
+)
+
+func NewListAllMyBucketsResult() *ListAllMyBucketsResult {
+	return &ListAllMyBucketsResult{
+		Ns:      s3Namespace,
+		Owner:   BucketOwner{ID: ownerID, DisplayName: ownerName},
+		Buckets: make([]*Bucket, 0),
+	}
+}
+
+func (r *ListAllMyBucketsResult) Add(bucket string) {
+	r.Buckets = append(r.Buckets, &Bucket{Name: bucket, CreationDate: creationTime})
+}
+
+func NewListBucketResult(bucket string) *ListBucketResult {
+	return &ListBucketResult{
+		Ns:       s3Namespace,
+		Name:     bucket,
+		MaxKeys:  DefaultMaxKeys,
+		Contents: make([]*ObjInfo, 0),
+	}
+}
+
+// FromBucketList fills in the result with the entries of a single page
+// produced by the native list-bucket. With a non-empty delimiter, all names
+// that contain the delimiter past the prefix are folded into common prefixes.
+func (r *ListBucketResult) FromBucketList(bckList *cmn.BucketList) {
+	seen := make(map[string]struct{})
+	for _, entry := range bckList.Entries {
+		if entry.Status != cmn.ObjStatusOK {
+			continue
+		}
+		if r.Delimiter != "" {
+			rest := strings.TrimPrefix(entry.Name, r.Prefix)
+			if idx := strings.Index(rest, r.Delimiter); idx >= 0 {
+				cp := r.Prefix + rest[:idx+len(r.Delimiter)]
+				if _, ok := seen[cp]; !ok {
+					seen[cp] = struct{}{}
+					r.CommonPrefixes = append(r.CommonPrefixes, &CommonPrefix{Prefix: cp})
+				}
+				continue
+			}
+		}
+		r.Contents = append(r.Contents, &ObjInfo{
+			Key:          entry.Name,
+			LastModified: entry.Ctime,
+			ETag:         ETag(entry.Checksum),
+			Size:         entry.Size,
+			StorageClass: storageClass,
+		})
+	}
+	r.KeyCount = len(r.Contents) + len(r.CommonPrefixes)
+	if bckList.PageMarker != "" {
+		r.IsTruncated = true
+		r.NextContinuationToken = EncodeToken(bckList.PageMarker)
+	}
+}
+
+func NewInitiateMultipartUploadResult(bucket, objname, uploadID string) *InitiateMultipartUploadResult {
+	return &InitiateMultipartUploadResult{Ns: s3Namespace, Bucket: bucket, Key: objname, UploadID: uploadID}
+}
+
+func NewCompleteMultipartUploadResult(bucket, objname, cksum string) *CompleteMultipartUploadResult {
+	return &CompleteMultipartUploadResult{Ns: s3Namespace, Bucket: bucket, Key: objname, ETag: ETag(cksum)}
+}
+
+// ToMultipartParts converts S3 list of parts into its native counterpart
+func (c *CompleteMultipartUpload) ToMultipartParts() []cmn.MultipartPart {
+	parts := make([]cmn.MultipartPart, 0, len(c.Parts))
+	for _, p := range c.Parts {
+		parts = append(parts, cmn.MultipartPart{PartNum: p.PartNumber, Cksum: strings.Trim(p.ETag, "\"")})
+	}
+	return parts
+}
+
+// ETag returns object checksum formatted as S3 entity tag (quoted)
+func ETag(cksum string) string {
+	if cksum == "" {
+		return ""
+	}
+	return "\"" + cksum + "\""
+}
@@ -0,0 +1,186 @@
+// Package s3compat provides Amazon S3 compatibility layer
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package s3compat
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// S3 error codes (subset)
+const (
+	ErrAccessDenied        = "AccessDenied"
+	ErrNoSuchBucket        = "NoSuchBucket"
+	ErrNoSuchKey           = "NoSuchKey"
+	ErrBucketAlreadyExists = "BucketAlreadyOwnedByYou"
+	ErrInvalidArgument     = "InvalidArgument"
+	ErrInvalidRange        = "InvalidRange"
+	ErrInvalidToken        = "InvalidToken"
+	ErrMethodNotAllowed    = "MethodNotAllowed"
+	ErrNotImplemented      = "NotImplemented"
+	ErrOperationAborted    = "OperationAborted"
+	ErrInternalError       = "InternalError"
+	ErrServiceUnavailable  = "ServiceUnavailable"
+)
+
+// ErrRangeNeedsSize is returned by ParseRange when the range cannot
+// be resolved without knowing the object size
+var ErrRangeNeedsSize = errors.New("range requires object size")
+
+// Error is S3 error response
+type Error struct {
+	XMLName  xml.Name `xml:"Error"`
+	Code     string   `xml:"Code"`
+	Message  string   `xml:"Message"`
+	Resource string   `xml:"Resource"`
+}
+
+// WriteErr writes S3 XML error with a given http status
+func WriteErr(w http.ResponseWriter, r *http.Request, code, msg string, status int) {
+	e := &Error{Code: code, Message: msg, Resource: r.URL.Path}
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	if r.Method == http.MethodHead {
+		return // HEAD response must not have a body
+	}
+	w.Write([]byte(xml.Header))
+	xml.NewEncoder(w).Encode(e)
+}
+
+// ErrCode maps http status (as returned by the native API) to S3 error code
+func ErrCode(status int, isObject bool) string {
+	switch status {
+	case http.StatusNotFound:
+		if isObject {
+			return ErrNoSuchKey
+		}
+		return ErrNoSuchBucket
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrAccessDenied
+	case http.StatusConflict:
+		if isObject {
+			return ErrOperationAborted
+		}
+		return ErrBucketAlreadyExists
+	case http.StatusMethodNotAllowed:
+		return ErrMethodNotAllowed
+	case http.StatusRequestedRangeNotSatisfiable:
+		return ErrInvalidRange
+	case http.StatusServiceUnavailable:
+		return ErrServiceUnavailable
+	default:
+		if status >= http.StatusInternalServerError {
+			return ErrInternalError
+		}
+		return ErrInvalidArgument
+	}
+}
+
+// Marshal returns XML document, including the standard header
+func Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	if err := xml.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// S3 continuation tokens are opaque to the client. AIS uses the name
+// of the last object in the page (PageMarker) encoded as base64
+func EncodeToken(pageMarker string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(pageMarker))
+}
+
+func DecodeToken(token string) (string, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return "", fmt.Errorf("invalid continuation token %q", token)
+	}
+	return string(b), nil
+}
+
+// ParseRange parses the value of HTTP Range header (single range only)
+// and returns offset and length, as per URLParamOffset and URLParamLength.
+// Object size is required to resolve suffix ("bytes=-N") and open-ended
+// ("bytes=N-") ranges; size < 0 means unknown.
+func ParseRange(hdr string, size int64) (offset, length int64, err error) {
+	const prefix = "bytes="
+	if !strings.HasPrefix(hdr, prefix) {
+		err = fmt.Errorf("invalid range %q", hdr)
+		return
+	}
+	spec := strings.TrimSpace(hdr[len(prefix):])
+	if strings.Contains(spec, ",") {
+		err = errors.New("multiple ranges are not supported")
+		return
+	}
+	dash := strings.IndexByte(spec, '-')
+	if dash < 0 {
+		err = fmt.Errorf("invalid range %q", hdr)
+		return
+	}
+	startStr, endStr := strings.TrimSpace(spec[:dash]), strings.TrimSpace(spec[dash+1:])
+	switch {
+	case startStr == "" && endStr == "":
+		err = fmt.Errorf("invalid range %q", hdr)
+		return
+	case startStr == "": // suffix: last N bytes
+		var n int64
+		if n, err = strconv.ParseInt(endStr, 10, 64); err != nil || n <= 0 {
+			err = fmt.Errorf("invalid range %q", hdr)
+			return
+		}
+		if size < 0 {
+			err = ErrRangeNeedsSize
+			return
+		}
+		if n > size {
+			n = size
+		}
+		offset, length = size-n, n
+	default:
+		if offset, err = strconv.ParseInt(startStr, 10, 64); err != nil || offset < 0 {
+			err = fmt.Errorf("invalid range %q", hdr)
+			return
+		}
+		end := int64(-1)
+		if endStr != "" {
+			if end, err = strconv.ParseInt(endStr, 10, 64); err != nil || end < offset {
+				err = fmt.Errorf("invalid range %q", hdr)
+				return
+			}
+		}
+		if size >= 0 {
+			if offset >= size {
+				err = fmt.Errorf("range %q not satisfiable (size %d)", hdr, size)
+				return
+			}
+			if end < 0 || end >= size {
+				end = size - 1
+			}
+		} else if end < 0 {
+			err = ErrRangeNeedsSize
+			return
+		}
+		length = end - offset + 1
+	}
+	if length <= 0 {
+		err = fmt.Errorf("range %q not satisfiable (size %d)", hdr, size)
+	}
+	return
+}
+
+// ContentRange returns the value of HTTP Content-Range header for the byte
+// range of the given offset and length in an object of the given size
+func ContentRange(offset, length, size int64) string {
+	return fmt.Sprintf("bytes %d-%d/%d", offset, offset+length-1, size)
+}
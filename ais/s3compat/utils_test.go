@@ -0,0 +1,161 @@
+// Package s3compat provides Amazon S3 compatibility layer
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package s3compat
+
+import (
+	"encoding/xml"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/NVIDIA/aistore/cmn"
+)
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		hdr            string
+		size           int64
+		offset, length int64
+		err            bool
+	}{
+		{"bytes=0-9", 100, 0, 10, false},
+		{"bytes=10-", 100, 10, 90, false},
+		{"bytes=-10", 100, 90, 10, false},
+		{"bytes=-200", 100, 0, 100, false},
+		{"bytes=90-200", 100, 90, 10, false},
+		{"bytes=0-9", -1, 0, 10, false},
+		{"bytes=100-", 100, 0, 0, true},
+		{"bytes=9-0", 100, 0, 0, true},
+		{"bytes=0-1,5-6", 100, 0, 0, true},
+		{"items=0-9", 100, 0, 0, true},
+		{"bytes=-", 100, 0, 0, true},
+	}
+	for _, test := range tests {
+		offset, length, err := ParseRange(test.hdr, test.size)
+		if test.err {
+			if err == nil {
+				t.Errorf("%q: expected error", test.hdr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", test.hdr, err)
+			continue
+		}
+		if offset != test.offset || length != test.length {
+			t.Errorf("%q: expected [%d, %d], got [%d, %d]", test.hdr, test.offset, test.length, offset, length)
+		}
+	}
+}
+
+func TestParseRangeNeedsSize(t *testing.T) {
+	for _, hdr := range []string{"bytes=-10", "bytes=10-"} {
+		if _, _, err := ParseRange(hdr, -1); err != ErrRangeNeedsSize {
+			t.Errorf("%q: expected %v, got %v", hdr, ErrRangeNeedsSize, err)
+		}
+	}
+}
+
+func TestContentRange(t *testing.T) {
+	if s := ContentRange(10, 90, 100); s != "bytes 10-99/100" {
+		t.Errorf("expected %q, got %q", "bytes 10-99/100", s)
+	}
+	if s := ContentRange(0, 1, 1); s != "bytes 0-0/1" {
+		t.Errorf("expected %q, got %q", "bytes 0-0/1", s)
+	}
+}
+
+func TestContinuationToken(t *testing.T) {
+	for _, marker := range []string{"a", "dir/subdir/obj-0001", "with spaces & symbols?"} {
+		token := EncodeToken(marker)
+		decoded, err := DecodeToken(token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decoded != marker {
+			t.Errorf("expected %q, got %q", marker, decoded)
+		}
+	}
+	if _, err := DecodeToken("%%%"); err == nil {
+		t.Error("expected error on invalid token")
+	}
+}
+
+func TestListBucketResult(t *testing.T) {
+	bckList := &cmn.BucketList{
+		Entries: []*cmn.BucketEntry{
+			{Name: "a/1", Size: 1, Checksum: "c1"},
+			{Name: "a/2", Size: 2},
+			{Name: "b/1", Size: 3},
+			{Name: "c", Size: 4, Checksum: "c4"},
+			{Name: "d", Size: 5, Status: cmn.ObjStatusMoved},
+		},
+		PageMarker: "d",
+	}
+	result := NewListBucketResult("bck")
+	result.Delimiter = "/"
+	result.FromBucketList(bckList)
+
+	if len(result.Contents) != 1 || result.Contents[0].Key != "c" || result.Contents[0].ETag != `"c4"` {
+		t.Errorf("unexpected contents: %+v", result.Contents)
+	}
+	if len(result.CommonPrefixes) != 2 || result.CommonPrefixes[0].Prefix != "a/" || result.CommonPrefixes[1].Prefix != "b/" {
+		t.Errorf("unexpected common prefixes: %+v", result.CommonPrefixes)
+	}
+	if result.KeyCount != 3 {
+		t.Errorf("expected key count 3, got %d", result.KeyCount)
+	}
+	if !result.IsTruncated || result.NextContinuationToken != EncodeToken("d") {
+		t.Errorf("expected truncated result with continuation token")
+	}
+
+	b, err := Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), "<ListBucketResult xmlns=") {
+		t.Errorf("unexpected xml: %s", b)
+	}
+}
+
+func TestCompleteMultipartUpload(t *testing.T) {
+	body := `<CompleteMultipartUpload>
+  <Part><PartNumber>1</PartNumber><ETag>"aaa"</ETag></Part>
+  <Part><PartNumber>2</PartNumber><ETag>bbb</ETag></Part>
+</CompleteMultipartUpload>`
+	complete := &CompleteMultipartUpload{}
+	if err := xml.Unmarshal([]byte(body), complete); err != nil {
+		t.Fatal(err)
+	}
+	parts := complete.ToMultipartParts()
+	if len(parts) != 2 {
+		t.Fatalf("expected 2 parts, got %d", len(parts))
+	}
+	if parts[0].PartNum != 1 || parts[0].Cksum != "aaa" || parts[1].PartNum != 2 || parts[1].Cksum != "bbb" {
+		t.Errorf("unexpected parts: %+v", parts)
+	}
+}
+
+func TestErrCode(t *testing.T) {
+	tests := []struct {
+		status   int
+		isObject bool
+		code     string
+	}{
+		{http.StatusNotFound, true, ErrNoSuchKey},
+		{http.StatusNotFound, false, ErrNoSuchBucket},
+		{http.StatusConflict, true, ErrOperationAborted},
+		{http.StatusConflict, false, ErrBucketAlreadyExists},
+		{http.StatusForbidden, true, ErrAccessDenied},
+		{http.StatusUnauthorized, false, ErrAccessDenied},
+		{http.StatusBadRequest, true, ErrInvalidArgument},
+		{http.StatusInsufficientStorage, true, ErrInternalError},
+	}
+	for _, test := range tests {
+		if code := ErrCode(test.status, test.isObject); code != test.code {
+			t.Errorf("%d (object: %t): expected %s, got %s", test.status, test.isObject, test.code, code)
+		}
+	}
+}
@@ -24,7 +24,8 @@ cat > $CONFFILE <<EOL
 	},
 	"periodic": {
 		"stats_time":        "10s",
-		"retry_sync_time":   "2s"
+		"retry_sync_time":   "2s",
+		"lifecycle_time":    "1h"
 	},
 	"timeout": {
 		"default_timeout":	"10s",
@@ -40,7 +41,8 @@ cat > $CONFFILE <<EOL
 		"non_electable": ${NON_ELECTABLE:-false},
 		"primary_url":   "${PROXYURL}",
 		"original_url":  "${PROXYURL}",
-		"discovery_url": "${DISCOVERYURL}"
+		"discovery_url": "${DISCOVERYURL}",
+		"list_cache_ttl": "0s"
 	},
 	"lru": {
 		"lowwm":		75,
@@ -130,6 +132,34 @@ cat > $CONFFILE <<EOL
 	"distributed_sort": {
 		"duplicated_records": "warn",
 		"missing_shards":     "abort"
+	},
+	"http_origin": {
+		"buckets": {}
+	},
+	"remote_ais": {
+		"url": "${REMOTE_AIS_URL}"
+	},
+	"cloud_requests": {
+		"rate_limit":		0,
+		"burst":		0,
+		"max_retries":		5,
+		"backoff":		"1s",
+		"max_backoff":		"1m",
+		"retry_on_status":	[429, 500, 502, 503, 504],
+		"providers": {
+			"gcp": {
+				"rate_limit":		0,
+				"burst":		0,
+				"max_retries":		0,
+				"backoff":		"1s",
+				"max_backoff":		"1m",
+				"retry_on_status":	[429, 500, 502, 503, 504]
+			}
+		}
+	},
+	"key_provider": {
+		"provider":	"",
+		"key_file":	""
 	}
 }
 EOL
@@ -124,20 +124,23 @@ fi
 TEST_FSPATH_COUNT=$testfspathcnt
 
 # If not specified, CLDPROVIDER it will be empty and build
-# will not include neither AWS nor GCP. As long as CLDPROVIDER
-# is not equal to `aws` nor `gcp` it will be assumed to be empty.
+# will not include neither AWS nor GCP nor Azure. As long as CLDPROVIDER
+# is not equal to `aws`, `gcp` or `azure` it will be assumed to be empty.
 CLDPROVIDER=""
 
 echo Select Cloud Provider:
 echo  1: Amazon Cloud
 echo  2: Google Cloud
 echo  3: None
+echo  4: Microsoft Azure
 echo Enter your choice:
 read cldprovider
 if [ $cldprovider -eq 1 ]; then
 	CLDPROVIDER="aws"
 elif [ $cldprovider -eq 2 ]; then
 	CLDPROVIDER="gcp"
+elif [ $cldprovider -eq 4 ]; then
+	CLDPROVIDER="azure"
 fi
 
 mkdir -p $CONFDIR
@@ -26,13 +26,16 @@ import (
 
 	"github.com/NVIDIA/aistore/3rdparty/atomic"
 	"github.com/NVIDIA/aistore/3rdparty/glog"
+	"github.com/NVIDIA/aistore/ais/s3compat"
 	"github.com/NVIDIA/aistore/cluster"
 	"github.com/NVIDIA/aistore/cmn"
 	"github.com/NVIDIA/aistore/dsort"
 	"github.com/NVIDIA/aistore/ec"
+	"github.com/NVIDIA/aistore/encryption"
 	"github.com/NVIDIA/aistore/filter"
 	"github.com/NVIDIA/aistore/fs"
 	"github.com/NVIDIA/aistore/ios"
+	"github.com/NVIDIA/aistore/lifecycle"
 	"github.com/NVIDIA/aistore/lru"
 	"github.com/NVIDIA/aistore/memsys"
 	"github.com/NVIDIA/aistore/mirror"
@@ -80,6 +83,7 @@ type (
 		needVersion  bool
 		needStatus   bool
 		needCopies   bool
+		needCustomMD bool
 	}
 	uxprocess struct {
 		starttime time.Time
@@ -119,6 +123,10 @@ type (
 		migrated bool
 		// Determines if the recv is cold recv: either from another cluster or cloud.
 		cold bool
+		// Set when the object is mirrored synchronously (see write-back).
+		mirrored bool
+		// If set, checked under the object's lock prior to replacing the object.
+		precond func() (errstr string, errCode int)
 	}
 
 	// The state that may influence GET logic when mountpath is added/enabled
@@ -141,7 +149,7 @@ type (
 
 	targetrunner struct {
 		httprunner
-		cloudif        cloudif // multi-cloud backend
+		clouds         map[string]cloudif // Cloud backends by provider (see config.CloudProvider)
 		uxprocess      *uxprocess
 		rtnamemap      *rtnamemap
 		prefetchQueue  chan filesWithDeadline
@@ -152,12 +160,18 @@ type (
 		xputlrep       *mirror.XactPutLRepl
 		ecmanager      *ecManager
 		rebManager     *rebManager
+		copyManager    *copyManager
+		quotas         *quotaTracker
+		writeBack      *writeBack
+		tiers          *tierTracker
 		capUsed        capUsed
 		gfn            struct {
 			local  localGFN
 			global globalGFN
 		}
 		regstate regstate // the state of being registered with the primary (can be en/disabled via API)
+		mpu      *mpuRegistry
+		appends  *appendRegistry
 	}
 )
 
@@ -250,6 +264,18 @@ func (t *targetrunner) Run() error {
 	if err := fs.CSM.RegisterFileType(fs.WorkfileType, &fs.WorkfileContentResolver{}); err != nil {
 		cmn.ExitLogf("%s", err)
 	}
+	if err := fs.CSM.RegisterFileType(archIndexType, &archIndexSpec{}); err != nil {
+		cmn.ExitLogf("%s", err)
+	}
+	if err := fs.CSM.RegisterFileType(fs.VersionType, &fs.VersionContentResolver{}); err != nil {
+		cmn.ExitLogf("%s", err)
+	}
+	if err := fs.CSM.RegisterFileType(fs.WriteBackType, &fs.WriteBackContentResolver{}); err != nil {
+		cmn.ExitLogf("%s", err)
+	}
+	if err := encryption.Init(&config.KeyProvider); err != nil {
+		cmn.ExitLogf("%s", err)
+	}
 
 	if err := fs.Mountpaths.CreateBucketDir(cmn.LocalBs); err != nil {
 		cmn.ExitLogf("%s", err)
@@ -259,13 +285,10 @@ func (t *targetrunner) Run() error {
 	}
 	t.detectMpathChanges()
 
-	// cloud provider (empty stubs that may get populated via build tags)
-	if config.CloudProvider == cmn.ProviderAmazon {
-		t.cloudif = newAWSProvider(t)
-	} else if config.CloudProvider == cmn.ProviderGoogle {
-		t.cloudif = newGCPProvider(t)
-	} else {
-		t.cloudif = newEmptyCloud() // mock
+	// cloud providers (empty stubs that may get populated via build tags)
+	t.clouds = make(map[string]cloudif, 2)
+	for _, provider := range cmn.ParseCloudProviders(config.CloudProvider) {
+		t.clouds[provider] = newCloudReqs(t, provider, t.newCloudProvider(provider))
 	}
 
 	// prefetch
@@ -306,6 +329,17 @@ func (t *targetrunner) Run() error {
 	if err := t.setupRebalanceManager(); err != nil {
 		cmn.ExitLogf("%s", err)
 	}
+	if err := t.setupCopyManager(); err != nil {
+		cmn.ExitLogf("%s", err)
+	}
+	t.quotas = newQuotaTracker(t)
+	t.writeBack = newWriteBack(t)
+	go t.writeBack.run()
+	t.appends = newAppendRegistry(t)
+	go t.appends.run()
+	t.mpu = newMpuRegistry()
+	go t.mpu.run()
+	t.tiers = newTierTracker(t)
 
 	pid := int64(os.Getpid())
 	t.uxprocess = &uxprocess{time.Now(), strconv.FormatInt(pid, 16), pid}
@@ -380,6 +414,9 @@ func (t *targetrunner) registerStats() {
 	t.statsif.Register(stats.PrefetchCount, stats.KindCounter)
 	t.statsif.Register(stats.PrefetchSize, stats.KindCounter)
 	t.statsif.Register(stats.VerChangeCount, stats.KindCounter)
+	t.statsif.Register(stats.LcDeleteCount, stats.KindCounter)
+	t.statsif.Register(stats.LcEvictCount, stats.KindCounter)
+	t.statsif.Register(stats.LcTransitCount, stats.KindCounter)
 	t.statsif.Register(stats.VerChangeSize, stats.KindCounter)
 	t.statsif.Register(stats.ErrCksumCount, stats.KindCounter)
 	t.statsif.Register(stats.ErrCksumSize, stats.KindCounter)
@@ -397,6 +434,21 @@ func (t *targetrunner) registerStats() {
 	// download
 	t.statsif.Register(stats.DownloadSize, stats.KindCounter)
 	t.statsif.Register(stats.DownloadLatency, stats.KindLatency)
+	// write-back
+	t.statsif.Register(stats.WriteBackCount, stats.KindCounter)
+	t.statsif.Register(stats.WriteBackSize, stats.KindCounter)
+	t.statsif.Register(stats.ErrWriteBackCount, stats.KindCounter)
+	t.statsif.Register(stats.WriteBackBacklogCount, stats.KindSpecial)
+	t.statsif.Register(stats.WriteBackBacklogSize, stats.KindSpecial)
+	// multi-tiering
+	t.statsif.Register(stats.TierHitCount, stats.KindCounter)
+	t.statsif.Register(stats.TierMissCount, stats.KindCounter)
+	t.statsif.Register(stats.TierPutCount, stats.KindCounter)
+	t.statsif.Register(stats.TierFallbackCount, stats.KindCounter)
+	// Cloud requests
+	t.statsif.Register(stats.CloudThrottleCount, stats.KindCounter)
+	t.statsif.Register(stats.CloudRetryCount, stats.KindCounter)
+	t.statsif.Register(stats.ErrCloudThrottleCount, stats.KindCounter)
 }
 
 // stop gracefully
@@ -407,6 +459,15 @@ func (t *targetrunner) Stop(err error) {
 		t.unregister() // ignore errors
 	}
 
+	if t.writeBack != nil {
+		t.writeBack.stop()
+	}
+	if t.appends != nil {
+		t.appends.stop()
+	}
+	if t.mpu != nil {
+		t.mpu.stop()
+	}
 	t.httprunner.stop(err)
 	if sleep {
 		time.Sleep(time.Second)
@@ -467,12 +528,47 @@ func (t *targetrunner) RunLRU() {
 		GetFSStats:          ios.GetFSStats,
 	}
 	lru.InitAndRun(&ini) // blocking
+	t.quotas.invalidate()
 
 	xlru.EndTime(time.Now())
 }
 
+// gets periodically triggered by the stats runner (see stats/target_stats.go)
+// and then runs in a goroutine
+func (t *targetrunner) RunLifecycle() {
+	if !lifecycle.Buckets(&t.bmdowner.get().BMD) {
+		return
+	}
+	if t.IsRebalancing() {
+		glog.Infoln("Warning: rebalancing (local or global) is in progress, skipping lifecycle run")
+		return
+	}
+	xlc := t.xactions.renewLifecycle()
+	if xlc == nil {
+		return
+	}
+	ini := lifecycle.InitLifecycle{
+		Xlc:        xlc,
+		Statsif:    t.statsif,
+		T:          t,
+		Delete:     func(lom *cluster.LOM) error { return t.lcDelete(lom, false) },
+		Evict:      func(lom *cluster.LOM) error { return t.lcDelete(lom, true) },
+		Transition: t.lcTransition,
+	}
+	lifecycle.InitAndRun(&ini) // blocking
+
+	xlc.EndTime(time.Now())
+}
+
 func (t *targetrunner) PrefetchQueueLen() int { return len(t.prefetchQueue) }
 
+func (t *targetrunner) WriteBackBacklog() (cnt, size int64) {
+	if t.writeBack == nil { // not started yet
+		return
+	}
+	return t.writeBack.backlog()
+}
+
 func (t *targetrunner) Prefetch() {
 	xpre := t.xactions.renewPrefetch()
 
@@ -578,10 +674,53 @@ func (t *targetrunner) httpbckget(w http.ResponseWriter, r *http.Request) {
 		}
 		return
 	}
+	if r.URL.Query().Get(cmn.URLParamWhat) == cmn.GetWhatBucketUsage {
+		if bckIsLocal, ok := t.validateBucket(w, r, bucket, bckProvider); ok {
+			t.getBucketUsage(w, r, bucket, bckIsLocal)
+		}
+		return
+	}
 	s := fmt.Sprintf("Invalid route /buckets/%s", bucket)
 	t.invalmsghdlr(w, r, s)
 }
 
+func (t *targetrunner) newCloudProvider(provider string) cloudif {
+	switch provider {
+	case cmn.ProviderAmazon:
+		return newAWSProvider(t)
+	case cmn.ProviderGoogle:
+		return newGCPProvider(t)
+	case cmn.ProviderAzure:
+		return newAzureProvider(t)
+	case cmn.ProviderHTTP:
+		return newHTTPProvider(t)
+	case cmn.ProviderRemoteAIS:
+		return newRemAISProvider(t)
+	default:
+		return newEmptyCloud() // mock
+	}
+}
+
+// cloud returns the Cloud backend of the bucket: the provider from the bucket's props
+// or, if not set, the one specified by the request (bckProvider) - or the default one
+func (t *targetrunner) cloud(bucket, bckProvider string) cloudif {
+	providers := cmn.ParseCloudProviders(cmn.GCO.Get().CloudProvider)
+	if len(providers) == 0 {
+		return newEmptyCloud()
+	}
+	provider := providers[0]
+	if props, ok := t.bmdowner.get().Get(bucket, false); ok && props.CloudProvider != "" &&
+		props.CloudProvider != cmn.ProviderAIS {
+		provider = props.CloudProvider
+	} else if cmn.StringInSlice(bckProvider, providers) {
+		provider = bckProvider
+	}
+	if cloud, ok := t.clouds[provider]; ok {
+		return cloud
+	}
+	return newEmptyCloud()
+}
+
 // verifyProxyRedirection returns if the http request was redirected from a proxy
 func (t *targetrunner) verifyProxyRedirection(w http.ResponseWriter, r *http.Request, action string) bool {
 	query := r.URL.Query()
@@ -634,11 +773,29 @@ func (t *targetrunner) httpobjget(w http.ResponseWriter, r *http.Request) {
 		t.invalmsghdlr(w, r, errstr)
 		return
 	}
+	archPath := query.Get(cmn.URLParamArchPath)
+	if archPath != "" && rangeLen != 0 {
+		t.invalmsghdlr(w, r, fmt.Sprintf("%s and %s are mutually exclusive", cmn.URLParamArchPath, cmn.URLParamLength))
+		return
+	}
+	version := query.Get(cmn.URLParamVersion)
+	if version != "" && (archPath != "" || rangeLen != 0) {
+		t.invalmsghdlr(w, r, fmt.Sprintf("%s cannot be combined with %s or %s",
+			cmn.URLParamVersion, cmn.URLParamArchPath, cmn.URLParamLength))
+		return
+	}
 	lom, errstr = cluster.LOM{T: t, Bucket: bucket, Objname: objname, BucketProvider: bckProvider}.Init(config)
 	if errstr != "" {
 		t.invalmsghdlr(w, r, errstr)
 		return
 	}
+	if query.Get(cmn.URLParamWhat) == cmn.GetWhatObjVersions {
+		t.objGetVersions(w, r, lom)
+		return
+	}
+	if version != "" && t.objGetOlderVersion(w, r, lom, version, started) {
+		return
+	}
 
 	// 2. under lock: lom init, restore from cluster
 	t.rtnamemap.Lock(lom.Uname(), false)
@@ -717,7 +874,14 @@ do:
 
 	// 4. get locally and stream back
 get:
-	retry, errstr := t.objGetComplete(w, r, lom, started, rangeOff, rangeLen, coldGet)
+	if archPath != "" {
+		if errstr, errcode = t.objGetArchMember(w, lom, archPath, started); errstr != "" {
+			t.invalmsghdlr(w, r, errstr, errcode)
+		}
+		t.rtnamemap.Unlock(lom.Uname(), false)
+		return
+	}
+	retry, errstr, errcode := t.objGetComplete(w, r, lom, started, rangeOff, rangeLen, coldGet)
 	if retry && !retried {
 		glog.Warningf("GET %s: uncaching and retrying...", lom)
 		retried = true
@@ -725,7 +889,7 @@ get:
 		goto do
 	}
 	if errstr != "" {
-		t.invalmsghdlr(w, r, errstr)
+		t.invalmsghdlr(w, r, errstr, errcode)
 	}
 	t.rtnamemap.Unlock(lom.Uname(), false)
 }
@@ -794,6 +958,12 @@ func (t *targetrunner) restoreObjLBNeigh(lom *cluster.LOM, r *http.Request) (err
 	} else if ecErr != ec.ErrorECDisabled {
 		errstr = fmt.Sprintf("Failed to restore object %s/%s: %v", lom.Bucket, lom.Objname, ecErr)
 	}
+	// read from the next tier, if configured
+	if restored, err := t.restoreFromNextTier(t.contextWithAuth(r.Header), lom); restored {
+		return "", 0
+	} else if err != nil && errstr == "" {
+		errstr = err.Error()
+	}
 
 	s := fmt.Sprintf("GET local: %s(%s) %s", lom, lom.FQN, cmn.DoesNotExist)
 	if errstr != "" {
@@ -811,9 +981,9 @@ func (t *targetrunner) restoreObjLBNeigh(lom *cluster.LOM, r *http.Request) (err
 // 4. read local, write http (note: coldGet() keeps the read lock if successful)
 //
 func (t *targetrunner) objGetComplete(w http.ResponseWriter, r *http.Request, lom *cluster.LOM, started time.Time,
-	rangeOff, rangeLen int64, coldGet bool) (retry bool, errstr string) {
+	rangeOff, rangeLen int64, coldGet bool) (retry bool, errstr string, errcode int) {
 	var (
-		file            *os.File
+		file            cluster.ObjReader
 		sgl             *memsys.SGL
 		slab            *memsys.Slab2
 		buf             []byte
@@ -848,6 +1018,7 @@ func (t *targetrunner) objGetComplete(w http.ResponseWriter, r *http.Request, lo
 		hdr.Add(cmn.HeaderObjVersion, lom.Version())
 	}
 	hdr.Add(cmn.HeaderObjSize, strconv.FormatInt(lom.Size(), 10))
+	cmn.CustomMDToHeader(lom.CustomMD(), hdr)
 
 	timeInt := lom.Atime().UnixNano()
 	if lom.Atime().IsZero() {
@@ -855,6 +1026,20 @@ func (t *targetrunner) objGetComplete(w http.ResponseWriter, r *http.Request, lo
 	}
 	hdr.Add(cmn.HeaderObjAtime, strconv.FormatInt(timeInt, 10))
 
+	// S3 range GET: partial content, with the range clamped to the object size
+	isS3 := r.URL.Query().Get(cmn.URLParamS3) == "true" && !dryRun.disk
+	if isS3 {
+		if rangeLen > 0 {
+			if rangeOff >= lom.Size() {
+				errstr = fmt.Sprintf("%s: range offset %d is beyond the object size %d", lom, rangeOff, lom.Size())
+				errcode = http.StatusRequestedRangeNotSatisfiable
+				return
+			}
+			rangeLen = cmn.MinI64(rangeLen, lom.Size()-rangeOff)
+		}
+		s3GetHeaders(hdr, lom, rangeOff, rangeLen)
+	}
+
 	// loopback if disk IO is disabled
 	if dryRun.disk {
 		rd := newDryReader(dryRun.size)
@@ -875,7 +1060,7 @@ func (t *targetrunner) objGetComplete(w http.ResponseWriter, r *http.Request, lo
 		return
 	}
 	fqn := lom.LoadBalanceGET() // coldGet => len(CopyFQN) == 0
-	file, err = os.Open(fqn)
+	file, err = lom.Open(fqn)   // decrypts, if need be
 	if err != nil {
 		if os.IsNotExist(err) {
 			errstr = err.Error()
@@ -906,6 +1091,9 @@ func (t *targetrunner) objGetComplete(w http.ResponseWriter, r *http.Request, lo
 		}
 	}
 
+	if isS3 && rangeLen > 0 {
+		w.WriteHeader(http.StatusPartialContent)
+	}
 	written, err = io.CopyBuffer(w, reader, buf)
 	if err != nil {
 		errstr = fmt.Sprintf("Failed to GET %s, err: %v", fqn, err)
@@ -943,6 +1131,24 @@ func (t *targetrunner) objGetComplete(w http.ResponseWriter, r *http.Request, lo
 	return
 }
 
+// s3GetHeaders sets the standard headers that S3 clients expect in GET object response
+func s3GetHeaders(hdr http.Header, lom *cluster.LOM, rangeOff, rangeLen int64) {
+	if lom.Cksum() != nil {
+		_, cksumValue := lom.Cksum().Get()
+		hdr.Set("ETag", s3compat.ETag(cksumValue))
+	}
+	if finfo, err := os.Stat(lom.FQN); err == nil {
+		hdr.Set("Last-Modified", finfo.ModTime().UTC().Format(http.TimeFormat))
+	}
+	hdr.Set("Accept-Ranges", "bytes")
+	if rangeLen > 0 {
+		hdr.Set("Content-Range", s3compat.ContentRange(rangeOff, rangeLen, lom.Size()))
+		hdr.Set("Content-Length", strconv.FormatInt(rangeLen, 10))
+	} else {
+		hdr.Set("Content-Length", strconv.FormatInt(lom.Size(), 10))
+	}
+}
+
 func (t *targetrunner) rangeCksum(r io.ReaderAt, fqn string, offset, length int64, buf []byte) (
 	cksumValue string, sgl *memsys.SGL, rangeReader io.ReadSeeker, errstr string) {
 	var (
@@ -1020,9 +1226,28 @@ func (t *targetrunner) httpobjput(w http.ResponseWriter, r *http.Request) {
 		t.invalmsghdlr(w, r, errstr)
 		return
 	}
+	// appends (the delta) and multipart upload parts count against the quota as well
+	if errstr, errCode := t.quotas.checkPut(lom, r.ContentLength); errstr != "" {
+		t.invalmsghdlr(w, r, errstr, errCode)
+		return
+	}
+	if uploadID := query.Get(cmn.URLParamUploadID); uploadID != "" {
+		t.putPart(w, r, lom, uploadID)
+		return
+	}
+	if appendTy := query.Get(cmn.URLParamAppendType); appendTy != "" {
+		t.appendObject(w, r, lom, appendTy)
+		return
+	}
 	if lom.BckIsLocal && lom.VerConf().Enabled {
 		lom.Load(true) // need to know the current version if versionig enabled
 	}
+	customMD, err := cmn.CustomMDFromHeader(r.Header)
+	if err != nil {
+		t.invalmsghdlr(w, r, fmt.Sprintf("%s: %v", lom, err))
+		return
+	}
+	lom.SetCustomMD(customMD)
 	if err, errCode := t.doPut(r, lom); err != nil {
 		t.invalmsghdlr(w, r, err.Error(), errCode)
 	}
@@ -1065,8 +1290,13 @@ func (t *targetrunner) httpbckdelete(w http.ResponseWriter, r *http.Request) {
 
 	switch msgInt.Action {
 	case cmn.ActEvictCB:
+		if err := t.writeBack.flushBucket(bucket); err != nil {
+			t.invalmsghdlr(w, r, fmt.Sprintf("Failed to evict bucket %s: %v", bucket, err))
+			return
+		}
 		cluster.EvictCache(bucket)
 		fs.Mountpaths.EvictCloudBucket(bucket) // validation handled in proxy.go
+		t.quotas.drop(bucket, false)
 	case cmn.ActDelete, cmn.ActEvictObjects:
 		if len(b) > 0 { // must be a List/Range request
 			err := t.listRangeOperation(r, apitems, bckProvider, &msgInt)
@@ -1122,6 +1352,10 @@ func (t *targetrunner) httpobjdelete(w http.ResponseWriter, r *http.Request) {
 		t.invalmsghdlr(w, r, errstr)
 		return
 	}
+	if version := r.URL.Query().Get(cmn.URLParamVersion); version != "" {
+		t.objDeleteVersion(w, r, lom, version)
+		return
+	}
 	err = t.objDelete(t.contextWithAuth(r.Header), lom, evict)
 	if err != nil {
 		s := fmt.Sprintf("Error deleting %s: %v", lom.StringEx(), err)
@@ -1217,6 +1451,16 @@ func (t *targetrunner) httpbckpost(w http.ResponseWriter, r *http.Request) {
 		t.xactions.abortBucketXact(cmn.ActPutCopies, bucket)
 		bckIsLocal := t.bmdowner.get().IsLocal(bucket)
 		t.xactions.renewBckMakeNCopies(bucket, t, copies, bckIsLocal)
+	case cmn.ActGetBatch:
+		t.getBatch(w, r, bucket, bckProvider, &msgInt)
+	case cmn.ActCopyBucket:
+		t.copyBucket(w, r, bucket, bckProvider, bckIsLocal, &msgInt)
+	case cmn.ActSyncBucket:
+		t.syncBucket(w, r, bucket, bckProvider, bckIsLocal, &msgInt)
+	case cmn.ActECScrub:
+		t.scrubEC(w, r, bucket, bckIsLocal, &msgInt)
+	case cmn.ActECEncode:
+		t.encodeEC(w, r, bucket, bckIsLocal, &msgInt)
 	default:
 		t.invalmsghdlr(w, r, "Unexpected action "+msgInt.Action)
 	}
@@ -1231,6 +1475,36 @@ func (t *targetrunner) httpobjpost(w http.ResponseWriter, r *http.Request) {
 	switch msg.Action {
 	case cmn.ActRename:
 		t.renameObject(w, r, msg)
+	case cmn.ActInitMultipart, cmn.ActCompleteMultipart, cmn.ActAbortMultipart, cmn.ActConcat, cmn.ActSetCustomMD,
+		cmn.ActCopyObject:
+		apitems, err := t.checkRESTItems(w, r, 2, false, cmn.Version, cmn.Objects)
+		if err != nil {
+			return
+		}
+		bucket, objname := apitems[0], apitems[1]
+		bckProvider := r.URL.Query().Get(cmn.URLParamBckProvider)
+		if _, ok := t.validateBucket(w, r, bucket, bckProvider); !ok {
+			return
+		}
+		lom, errstr := cluster.LOM{T: t, Bucket: bucket, Objname: objname, BucketProvider: bckProvider}.Init()
+		if errstr != "" {
+			t.invalmsghdlr(w, r, errstr)
+			return
+		}
+		switch msg.Action {
+		case cmn.ActInitMultipart:
+			t.initMultipart(w, r, lom)
+		case cmn.ActCompleteMultipart:
+			t.completeMultipart(w, r, lom, &msg)
+		case cmn.ActConcat:
+			t.concatObjects(w, r, lom, &msg)
+		case cmn.ActSetCustomMD:
+			t.setCustomMD(w, r, lom, &msg)
+		case cmn.ActCopyObject:
+			t.copyObject(w, r, lom, &msg)
+		default:
+			t.abortMultipart(w, r, lom, &msg)
+		}
 	default:
 		t.invalmsghdlr(w, r, "Unexpected action "+msg.Action)
 	}
@@ -1261,7 +1535,7 @@ func (t *targetrunner) httpbckhead(w http.ResponseWriter, r *http.Request) {
 	}
 	config := cmn.GCO.Get()
 	if !bckIsLocal {
-		bucketProps, err, errCode = getcloudif().headbucket(t.contextWithAuth(r.Header), bucket)
+		bucketProps, err, errCode = getcloudif(bucket, bckProvider).headbucket(t.contextWithAuth(r.Header), bucket)
 		if err != nil {
 			errMsg := fmt.Sprintf("the bucket %s either %s or is not accessible, err: %v", bucket, cmn.DoesNotExist, err)
 			t.invalmsghdlr(w, r, errMsg, errCode)
@@ -1301,6 +1575,8 @@ func (t *targetrunner) httpbckhead(w http.ResponseWriter, r *http.Request) {
 
 	hdr.Add(cmn.HeaderBucketVerEnabled, strconv.FormatBool(verConf.Enabled))
 	hdr.Add(cmn.HeaderBucketVerValidateWarm, strconv.FormatBool(verConf.ValidateWarmGet))
+	hdr.Add(cmn.HeaderBucketVerMaxVersions, strconv.Itoa(verConf.MaxVersions))
+	hdr.Add(cmn.HeaderBucketVerRetention, verConf.RetentionStr)
 
 	hdr.Add(cmn.HeaderBucketLRULowWM, strconv.FormatInt(props.LRU.LowWM, 10))
 	hdr.Add(cmn.HeaderBucketLRUHighWM, strconv.FormatInt(props.LRU.HighWM, 10))
@@ -1316,10 +1592,20 @@ func (t *targetrunner) httpbckhead(w http.ResponseWriter, r *http.Request) {
 	}
 	hdr.Add(cmn.HeaderRebalanceEnabled, strconv.FormatBool(props.Rebalance.Enabled))
 
+	hdr.Add(cmn.HeaderBucketQuotaSoftSize, strconv.FormatInt(props.Quota.SoftSize, 10))
+	hdr.Add(cmn.HeaderBucketQuotaHardSize, strconv.FormatInt(props.Quota.HardSize, 10))
+	hdr.Add(cmn.HeaderBucketQuotaSoftObjs, strconv.FormatInt(props.Quota.SoftObjs, 10))
+	hdr.Add(cmn.HeaderBucketQuotaHardObjs, strconv.FormatInt(props.Quota.HardObjs, 10))
+	hdr.Add(cmn.HeaderBucketWriteBack, strconv.FormatBool(props.WriteBack.Enabled))
+	hdr.Add(cmn.HeaderBucketEncryption, strconv.FormatBool(props.Encryption.Enabled))
+	hdr.Add(cmn.HeaderBucketEncryptionKey, props.Encryption.KeyID)
+
 	hdr.Add(cmn.HeaderBucketECEnabled, strconv.FormatBool(props.EC.Enabled))
 	hdr.Add(cmn.HeaderBucketECMinSize, strconv.FormatUint(uint64(props.EC.ObjSizeLimit), 10))
 	hdr.Add(cmn.HeaderBucketECData, strconv.FormatUint(uint64(props.EC.DataSlices), 10))
 	hdr.Add(cmn.HeaderBucketECParity, strconv.FormatUint(uint64(props.EC.ParitySlices), 10))
+	hdr.Add(cmn.HeaderBucketECCode, props.EC.Code)
+	hdr.Add(cmn.HeaderBucketECLocalGroups, strconv.Itoa(props.EC.LocalGroups))
 }
 
 // HEAD /v1/objects/bucket-name/object-name
@@ -1365,11 +1651,14 @@ func (t *targetrunner) httpobjhead(w http.ResponseWriter, r *http.Request) {
 		objmeta = make(cmn.SimpleKVs)
 		objmeta[cmn.HeaderObjSize] = strconv.FormatInt(lom.Size(), 10)
 		objmeta[cmn.HeaderObjVersion] = lom.Version()
+		for k, v := range lom.CustomMD() {
+			objmeta[cmn.HeaderObjCustomPrefix+k] = v
+		}
 		if glog.FastV(4, glog.SmoduleAIS) {
 			glog.Infof("%s(%s), ver=%s", lom, cmn.B2S(lom.Size(), 1), lom.Version())
 		}
 	} else {
-		objmeta, err, errcode = getcloudif().headobject(t.contextWithAuth(r.Header), lom)
+		objmeta, err, errcode = getcloudif(lom.Bucket, lom.BucketProvider).headobject(t.contextWithAuth(r.Header), lom)
 		if err != nil {
 			errMsg := fmt.Sprintf("%s: failed to head metadata, err: %v", lom, err)
 			t.invalmsghdlr(w, r, errMsg, errcode)
@@ -1466,7 +1755,7 @@ func (renctx *renamectx) walkf(fqn string, osfi os.FileInfo, err error) error {
 // should be called only if the local copy exists
 func (t *targetrunner) checkCloudVersion(ct context.Context, lom *cluster.LOM) (vchanged bool, errstr string, errcode int) {
 	var objmeta cmn.SimpleKVs
-	objmeta, err, errcode := t.cloudif.headobject(ct, lom)
+	objmeta, err, errcode := t.cloud(lom.Bucket, lom.BucketProvider).headobject(ct, lom)
 	if err != nil {
 		errstr = fmt.Sprintf("%s: failed to head metadata, err: %v", lom, err)
 		return
@@ -1530,6 +1819,9 @@ func (t *targetrunner) getFromNeighbor(r *http.Request, lom *cluster.LOM, smap *
 	lom.SetCksum(cksum)
 	lom.SetVersion(version)
 	lom.SetAtimeUnix(atime)
+	if customMD, err := cmn.CustomMDFromHeader(response.Header); err == nil {
+		lom.SetCustomMD(customMD)
+	}
 	roi := &recvObjInfo{
 		t:        t,
 		lom:      lom,
@@ -1569,7 +1861,7 @@ func (t *targetrunner) GetCold(ct context.Context, lom *cluster.LOM, prefetch bo
 		t.rtnamemap.Lock(lom.Uname(), true) // one cold-GET at a time
 	}
 	workFQN := lom.GenFQN(fs.WorkfileType, fs.WorkfileColdget)
-	if err, errcode = getcloudif().getobj(ct, workFQN, lom); err != nil {
+	if err, errcode = t.getColdUpstream(ct, workFQN, lom); err != nil {
 		errstr = fmt.Sprintf("%s: GET failed, err: %v", lom, err)
 		t.rtnamemap.Unlock(lom.Uname(), true)
 		return
@@ -1831,13 +2123,31 @@ func (t *targetrunner) getbucketnames(w http.ResponseWriter, r *http.Request, bc
 		}
 	}
 
-	buckets, err, errcode := getcloudif().getbucketnames(t.contextWithAuth(r.Header))
-	if err != nil {
-		errMsg := fmt.Sprintf("failed to list all buckets, err: %v", err)
-		t.invalmsghdlr(w, r, errMsg, errcode)
-		return
+	// Cloud buckets of all configured providers, grouped by provider
+	var (
+		ct   = t.contextWithAuth(r.Header)
+		seen = make(map[string]struct{}, 64)
+	)
+	bucketNames.CloudByProvider = make(map[string][]string, len(t.clouds))
+	for _, provider := range cmn.ParseCloudProviders(cmn.GCO.Get().CloudProvider) {
+		cloud, ok := t.clouds[provider]
+		if !ok {
+			continue
+		}
+		buckets, err, errcode := cloud.getbucketnames(ct)
+		if err != nil {
+			errMsg := fmt.Sprintf("failed to list all %s buckets, err: %v", provider, err)
+			t.invalmsghdlr(w, r, errMsg, errcode)
+			return
+		}
+		bucketNames.CloudByProvider[provider] = buckets
+		for _, bucket := range buckets {
+			if _, ok := seen[bucket]; !ok {
+				seen[bucket] = struct{}{}
+				bucketNames.Cloud = append(bucketNames.Cloud, bucket)
+			}
+		}
 	}
-	bucketNames.Cloud = buckets
 
 	jsbytes, err := jsoniter.Marshal(bucketNames)
 	cmn.AssertNoErr(err)
@@ -1908,7 +2218,7 @@ func (t *targetrunner) listbucket(w http.ResponseWriter, r *http.Request, bucket
 		jsbytes, errstr = t.listCachedObjects(bucket, &msg, false /* local */)
 	} else {
 		tag = "cloud"
-		jsbytes, err, errcode = getcloudif().listbucket(t.contextWithAuth(r.Header), bucket, &msg)
+		jsbytes, err, errcode = getcloudif(bucket, query.Get(cmn.URLParamBckProvider)).listbucket(t.contextWithAuth(r.Header), bucket, &msg)
 		if err != nil {
 			errstr = fmt.Sprintf("error listing cloud bucket %s: %d(%v)", bucket, errcode, err)
 		}
@@ -1948,6 +2258,7 @@ func (t *targetrunner) newFileWalk(bucket string, msg *cmn.SelectMsg) *allfinfos
 		needVersion:  strings.Contains(msg.Props, cmn.GetPropsVersion),
 		needStatus:   strings.Contains(msg.Props, cmn.GetPropsStatus),
 		needCopies:   strings.Contains(msg.Props, cmn.GetPropsCopies),
+		needCustomMD: strings.Contains(msg.Props, cmn.GetPropsCustomMD),
 	}
 
 	if msg.PageSize != 0 {
@@ -2023,7 +2334,13 @@ func (ci *allfinfos) lsObject(lom *cluster.LOM, osfi os.FileInfo, objStatus stri
 	if ci.needCopies {
 		fileInfo.Copies = int16(lom.NumCopies())
 	}
+	if ci.needCustomMD {
+		fileInfo.CustomMD = lom.CustomMD()
+	}
 	fileInfo.Size = osfi.Size()
+	if lom.Encrypted() {
+		fileInfo.Size = lom.Size() // not including the encryption overhead
+	}
 	ci.files = append(ci.files, fileInfo)
 	ci.lastFilePath = lom.FQN
 	return nil
@@ -2195,34 +2512,50 @@ func (roi *recvObjInfo) commit() (errstr string, errCode int) {
 	if err := roi.t.ecmanager.EncodeObject(roi.lom); err != nil && err != ec.ErrorECDisabled {
 		errstr = err.Error()
 	}
-	roi.t.putMirror(roi.lom)
+	if !roi.mirrored {
+		roi.t.putMirror(roi.lom)
+	}
 	return
 }
 
 func (roi *recvObjInfo) tryCommit() (errstr string, errCode int) {
 	var (
-		ver string
-		lom = roi.lom
+		ver       string
+		lom       = roi.lom
+		writeBack = !lom.BckIsLocal && !roi.migrated && lom.WriteBackConf().Enabled
 	)
-	if !lom.BckIsLocal && !roi.migrated {
+	if writeBack {
+		// acknowledged prior to uploading - the object must survive a crash
+		if err := fsyncFile(roi.workFQN); err != nil {
+			errstr = fmt.Sprintf("failed to sync %s err: %v", roi.workFQN, err)
+			return
+		}
+	} else if !roi.migrated && (!lom.BckIsLocal || lom.BckProps.NextTierURL != "") {
 		file, err := os.Open(roi.workFQN)
 		if err != nil {
 			errstr = fmt.Sprintf("failed to open %s err: %v", roi.workFQN, err)
 			return
 		}
 		cmn.Assert(lom.Cksum() != nil)
-		ver, err, errCode = getcloudif().putobj(roi.ctx, file, lom)
+		ver, err, errCode = roi.t.putUpstream(roi.ctx, file, lom)
 		file.Close()
 		if err != nil {
 			errstr = fmt.Sprintf("%s: PUT failed, err: %v", lom, err)
 			return
 		}
-		lom.SetVersion(ver)
+		if !lom.BckIsLocal {
+			lom.SetVersion(ver)
+		}
 	}
 
 	roi.t.rtnamemap.Lock(lom.Uname(), true)
 	defer roi.t.rtnamemap.Unlock(lom.Uname(), true)
 
+	if roi.precond != nil {
+		if errstr, errCode = roi.precond(); errstr != "" {
+			return
+		}
+	}
 	if lom.BckIsLocal && lom.VerConf().Enabled {
 		if ver, errstr = lom.IncObjectVersion(); errstr != "" {
 			return
@@ -2233,7 +2566,16 @@ func (roi *recvObjInfo) tryCommit() (errstr string, errCode int) {
 	if errstr = lom.DelAllCopies(); errstr != "" {
 		return
 	}
-	if err := cmn.MvFile(roi.workFQN, lom.FQN); err != nil {
+	var prevSize, prevObjs int64
+	if finfo, err := os.Stat(lom.FQN); err == nil {
+		prevSize, prevObjs = finfo.Size(), 1
+	}
+	replace := func() error { return cmn.MvFile(roi.workFQN, lom.FQN) }
+	if !roi.migrated {
+		if errstr = lom.ArchiveVersion(replace); errstr != "" {
+			return
+		}
+	} else if err := replace(); err != nil {
 		errstr = fmt.Sprintf("MvFile failed => %s: %v", lom, err)
 		return
 	}
@@ -2242,6 +2584,24 @@ func (roi *recvObjInfo) tryCommit() (errstr string, errCode int) {
 		glog.Errorf("failed to persist %s: %s", lom, errstr)
 	}
 	lom.ReCache()
+	roi.t.quotas.update(lom, lom.DiskSize()-prevSize, 1-prevObjs)
+	if writeBack {
+		if err := roi.t.writeBack.enqueue(lom); err != nil {
+			errstr = fmt.Sprintf("%s: failed to schedule write-back, err: %v", lom, err)
+			return
+		}
+		// not yet in the Cloud - mirror prior to acknowledging, if configured
+		if mirrConf := lom.MirrorConf(); mirrConf.Enabled && fs.Mountpaths.NumAvail() >= int(mirrConf.Copies) {
+			buf, slab := gmem2.AllocFromSlab2(lom.Size())
+			err := mirror.MakeCopies(lom, int(mirrConf.Copies), buf)
+			slab.Free(buf)
+			if err != nil {
+				errstr = fmt.Sprintf("%s: failed to mirror, err: %v", lom, err)
+				return
+			}
+			roi.mirrored = true
+		}
+	}
 	return
 }
 
@@ -2277,14 +2637,22 @@ func (t *targetrunner) putMirror(lom *cluster.LOM) {
 }
 
 func (t *targetrunner) objDelete(ct context.Context, lom *cluster.LOM, evict bool) error {
+	t.rtnamemap.Lock(lom.Uname(), true)
+	defer t.rtnamemap.Unlock(lom.Uname(), true)
+	if lom.BckIsLocal && !evict {
+		if err, _ := t.deleteUpstream(ct, lom); err != nil {
+			return err
+		}
+	}
+	return t.objDeleteLocked(ct, lom, evict)
+}
+
+// must be called under the object's exclusive lock
+func (t *targetrunner) objDeleteLocked(ct context.Context, lom *cluster.LOM, evict bool) error {
 	var (
 		cloudErr error
 		errRet   error
 	)
-
-	t.rtnamemap.Lock(lom.Uname(), true)
-	defer t.rtnamemap.Unlock(lom.Uname(), true)
-
 	delFromCloud := !lom.BckIsLocal && !evict
 	if _, errstr := lom.Load(false); errstr != "" {
 		return errors.New(errstr)
@@ -2292,16 +2660,29 @@ func (t *targetrunner) objDelete(ct context.Context, lom *cluster.LOM, evict boo
 	delFromAIS := lom.Exists()
 
 	if delFromCloud {
-		if err, _ := getcloudif().deleteobj(ct, lom); err != nil {
+		pending := t.writeBack.cancelLocked(lom)
+		err, errCode := t.deleteUpstream(ct, lom)
+		// not found in the Cloud is expected when the object has never been uploaded
+		if err != nil && !(pending && errCode == http.StatusNotFound) {
 			cloudErr = fmt.Errorf("%s: DELETE failed, err: %v", lom, err)
 			t.statsif.Add(stats.DeleteCount, 1)
 		}
+	} else if evict && delFromAIS {
+		if err := t.writeBack.flushLocked(ct, lom); err != nil {
+			return fmt.Errorf("%s: failed to write back prior to eviction, err: %v", lom, err)
+		}
 	}
 	if delFromAIS {
 		// Don't persis meta as object will be soon removed anyway
 		if errs := lom.DelAllCopies(); errs != "" {
 			glog.Errorf("%s: %s", lom, errs)
 		}
+		delArchIndex(lom)
+		if lom.BckIsLocal {
+			if errs := lom.DelAllVersions(); errs != "" {
+				glog.Errorln(errs)
+			}
+		}
 		errRet = os.Remove(lom.FQN)
 		if errRet != nil {
 			if !os.IsNotExist(errRet) {
@@ -2310,6 +2691,8 @@ func (t *targetrunner) objDelete(ct context.Context, lom *cluster.LOM, evict boo
 				}
 				return errRet
 			}
+		} else {
+			t.quotas.update(lom, -lom.DiskSize(), -1)
 		}
 		if evict {
 			cmn.Assert(!lom.BckIsLocal)
@@ -2324,6 +2707,73 @@ func (t *targetrunner) objDelete(ct context.Context, lom *cluster.LOM, evict boo
 	return errRet
 }
 
+// POST { ActSetCustomMD } /v1/objects/bucket-name/object-name
+// Merges the specified key/value pairs into the object's user-defined metadata;
+// keys with empty values are removed
+func (t *targetrunner) setCustomMD(w http.ResponseWriter, r *http.Request, lom *cluster.LOM, msg *cmn.ActionMsg) {
+	var updates cmn.SimpleKVs
+	b, err := jsoniter.Marshal(msg.Value)
+	if err == nil {
+		err = jsoniter.Unmarshal(b, &updates)
+	}
+	if err != nil {
+		t.invalmsghdlr(w, r, fmt.Sprintf("unable to parse %s request: %v", msg.Action, msg.Value))
+		return
+	}
+
+	t.rtnamemap.Lock(lom.Uname(), true)
+	if _, errstr := lom.Load(false); errstr != "" {
+		t.rtnamemap.Unlock(lom.Uname(), true)
+		t.invalmsghdlr(w, r, errstr)
+		return
+	}
+	if !lom.Exists() {
+		t.rtnamemap.Unlock(lom.Uname(), true)
+		t.invalmsghdlr(w, r, fmt.Sprintf("%s %s", lom, cmn.DoesNotExist), http.StatusNotFound)
+		return
+	}
+	customMD := make(cmn.SimpleKVs, len(lom.CustomMD())+len(updates))
+	for k, v := range lom.CustomMD() {
+		customMD[k] = v
+	}
+	for k, v := range updates {
+		k = strings.ToLower(k)
+		if v == "" {
+			delete(customMD, k)
+		} else {
+			customMD[k] = v
+		}
+	}
+	if err = cmn.ValidateCustomMD(customMD); err != nil {
+		t.rtnamemap.Unlock(lom.Uname(), true)
+		t.invalmsghdlr(w, r, fmt.Sprintf("%s: %v", lom, err))
+		return
+	}
+	lom.SetCustomMD(customMD)
+	if err = lom.Persist(); err == nil {
+		for _, cpyFQN := range lom.CopyFQN() {
+			copyLOM := lom.Clone(cpyFQN)
+			copyLOM.SetCopyFQN([]string{lom.FQN})
+			if err = copyLOM.Persist(); err != nil {
+				break
+			}
+		}
+	}
+	lom.ReCache()
+	t.rtnamemap.Unlock(lom.Uname(), true)
+	if err != nil {
+		t.invalmsghdlr(w, r, fmt.Sprintf("%s: failed to persist metadata, err: %v", lom, err))
+		return
+	}
+	// re-encode to update the metadata stored with the slices and replicas
+	if err = t.ecmanager.EncodeObject(lom); err != nil && err != ec.ErrorECDisabled {
+		glog.Errorf("%s: failed to re-encode, err: %v", lom, err)
+	}
+	if glog.FastV(4, glog.SmoduleAIS) {
+		glog.Infof("%s: custom metadata %v", lom, customMD)
+	}
+}
+
 func (t *targetrunner) renameObject(w http.ResponseWriter, r *http.Request, msg cmn.ActionMsg) {
 	apitems, err := t.checkRESTItems(w, r, 2, false, cmn.Version, cmn.Objects)
 	if err != nil {
@@ -2349,7 +2799,7 @@ func (t *targetrunner) renameBucketObject(contentType, bucketFrom, objnameFrom,
 	objnameTo string) (errstr string) {
 	var (
 		fi                    os.FileInfo
-		file                  *cmn.FileHandle
+		file                  cluster.ObjReader
 		si                    *cluster.Snode
 		newFQN                string
 		cksumType, cksumValue string
@@ -2393,11 +2843,6 @@ func (t *targetrunner) renameBucketObject(contentType, bucketFrom, objnameFrom,
 	}
 
 	// TODO: fill and send should be general function in `rebManager`: from, to, object
-	if file, err = cmn.NewFileHandle(fqn); err != nil {
-		return fmt.Sprintf("failed to open %s, err: %v", fqn, err)
-	}
-	defer file.Close()
-
 	lom, errstr := cluster.LOM{T: t, FQN: fqn}.Init()
 	if errstr != "" {
 		return errstr
@@ -2405,6 +2850,10 @@ func (t *targetrunner) renameBucketObject(contentType, bucketFrom, objnameFrom,
 	if _, errstr := lom.Load(false); errstr != "" {
 		return errstr
 	}
+	if file, err = lom.Open(fqn); err != nil { // sent decrypted
+		return fmt.Sprintf("failed to open %s, err: %v", fqn, err)
+	}
+	defer file.Close()
 	if lom.Cksum() != nil {
 		cksumType, cksumValue = lom.Cksum().Get()
 	}
@@ -2414,11 +2863,12 @@ func (t *targetrunner) renameBucketObject(contentType, bucketFrom, objnameFrom,
 		IsLocal: lom.BckIsLocal,
 		Opaque:  []byte(t.si.DaemonID),
 		ObjAttrs: transport.ObjectAttrs{
-			Size:       fi.Size(),
+			Size:       lom.Size(),
 			Atime:      lom.Atime().UnixNano(),
 			CksumType:  cksumType,
 			CksumValue: cksumValue,
 			Version:    lom.Version(),
+			CustomMD:   lom.CustomMD(),
 		},
 	}
 	wg := &sync.WaitGroup{}
@@ -2493,9 +2943,12 @@ func (roi *recvObjInfo) writeToFile() (err error) {
 		}
 	}()
 
-	// receive and checksum
+	// receive, checksum, and encrypt if need be (the checksum is computed over plaintext)
 	var (
 		written int64
+		writer  io.Writer = file
+		encw    *encryption.Writer
+		keyID   = roi.lom.EncryptKeyID()
 
 		checkCksumType      string
 		expectedCksum       cmn.Cksummer
@@ -2546,9 +2999,24 @@ func (roi *recvObjInfo) writeToFile() (err error) {
 		}
 	}
 
-	if written, err = cmn.ReceiveAndChecksum(file, reader, buf, hashes...); err != nil {
+	if keyID != "" {
+		var key []byte
+		if key, err = encryption.Key(keyID); err != nil {
+			return fmt.Errorf("%s: failed to get encryption key, err: %v", roi.lom, err)
+		}
+		if encw, err = encryption.NewWriter(file, key); err != nil {
+			return
+		}
+		writer = encw
+	}
+	if written, err = cmn.ReceiveAndChecksum(writer, reader, buf, hashes...); err != nil {
 		return
 	}
+	if encw != nil {
+		if err = encw.Close(); err != nil {
+			return
+		}
+	}
 
 	if checkHash != nil {
 		computedCksum := cmn.NewCksum(checkCksumType, cmn.HashToStr(checkHash))
@@ -2560,6 +3028,7 @@ func (roi *recvObjInfo) writeToFile() (err error) {
 		}
 	}
 	roi.lom.SetSize(written)
+	roi.lom.SetKeyID(keyID)
 	if saveHash != nil {
 		roi.lom.SetCksum(cmn.NewCksum(cmn.ChecksumXXHash, cmn.HashToStr(saveHash)))
 	}
@@ -0,0 +1,162 @@
+// Package ais provides core functionality for the AIStore object storage.
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package ais
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/NVIDIA/aistore/cluster"
+	"github.com/NVIDIA/aistore/cmn"
+	"github.com/NVIDIA/aistore/fs"
+	"github.com/NVIDIA/aistore/memsys"
+)
+
+func TestTargetCloudProviders(t *testing.T) {
+	oldProvider := cmn.GCO.Get().CloudProvider
+	config := cmn.GCO.BeginUpdate()
+	config.CloudProvider = "aws, http"
+	cmn.GCO.CommitUpdate(config)
+	defer func() {
+		config := cmn.GCO.BeginUpdate()
+		config.CloudProvider = oldProvider
+		cmn.GCO.CommitUpdate(config)
+	}()
+
+	tgt := &targetrunner{clouds: make(map[string]cloudif)}
+	for _, provider := range cmn.ParseCloudProviders(cmn.GCO.Get().CloudProvider) {
+		tgt.clouds[provider] = tgt.newCloudProvider(provider)
+	}
+	bmd := newBucketMD()
+	bmd.add("s3", false, &cmn.BucketProps{CloudProvider: cmn.ProviderAmazon})
+	bmd.add("web", false, &cmn.BucketProps{CloudProvider: cmn.ProviderHTTP})
+	bmd.add("nopin", false, &cmn.BucketProps{})
+	tgt.bmdowner = newBmdowner()
+	tgt.bmdowner.put(bmd)
+
+	tests := []struct {
+		bucket, bckProvider, expected string
+	}{
+		{"s3", "", cmn.ProviderAmazon},
+		{"web", "", cmn.ProviderHTTP},
+		{"web", cmn.ProviderAmazon, cmn.ProviderHTTP}, // bucket's own provider wins
+		{"nopin", "", cmn.ProviderAmazon},             // default
+		{"unknown", "", cmn.ProviderAmazon},
+		{"unknown", cmn.ProviderHTTP, cmn.ProviderHTTP},
+		{"unknown", cmn.CloudBs, cmn.ProviderAmazon},
+		{"unknown", cmn.ProviderGoogle, cmn.ProviderAmazon}, // not configured
+	}
+	for _, test := range tests {
+		if tgt.cloud(test.bucket, test.bckProvider) != tgt.clouds[test.expected] {
+			t.Errorf("bucket %s (provider %q): expected %s backend", test.bucket, test.bckProvider, test.expected)
+		}
+	}
+
+	config = cmn.GCO.BeginUpdate()
+	config.CloudProvider = ""
+	cmn.GCO.CommitUpdate(config)
+	if _, ok := tgt.cloud("s3", "").(*emptyCloud); !ok {
+		t.Errorf("expected empty Cloud when no providers are configured")
+	}
+}
+
+func TestObjGetCompleteS3(t *testing.T) {
+	dir, err := ioutil.TempDir("", "objget-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldMountpaths := fs.Mountpaths
+	defer func() {
+		os.RemoveAll(dir)
+		fs.Mountpaths = oldMountpaths
+	}()
+	fs.Mountpaths = fs.NewMountedFS()
+	if err := fs.Mountpaths.Add(dir); err != nil {
+		t.Fatal(err)
+	}
+	_ = fs.CSM.RegisterFileType(fs.ObjectType, &fs.ObjectContentResolver{})
+	gmem2 = &memsys.Mem2{Name: "objgetmem"}
+	if err := gmem2.Init(true); err != nil {
+		t.Fatal(err)
+	}
+
+	tgt := &targetrunner{bmdowner: newBmdowner()}
+	tgt.statsif = &tierStatsMock{vals: make(map[string]int64)}
+	bmd := newBucketMD()
+	bmd.add("s3", true, &cmn.BucketProps{Cksum: cmn.CksumConf{Type: cmn.ChecksumNone}})
+	tgt.bmdowner.put(bmd)
+
+	data := []byte("0123456789")
+	lom, errstr := cluster.LOM{T: tgt, Bucket: "s3", Objname: "obj", BucketProvider: cmn.LocalBs}.Init()
+	if errstr != "" {
+		t.Fatal(errstr)
+	}
+	if err := cmn.CreateDir(filepath.Dir(lom.FQN)); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(lom.FQN, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	lom.SetSize(int64(len(data)))
+	lom.SetCksum(cmn.NewCksum(cmn.ChecksumXXHash, "abcdef"))
+	if err := lom.Persist(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		query            string
+		offset, length   int64
+		status, errcode  int
+		body, contentRng string
+	}{
+		{"", 0, 0, http.StatusOK, 0, "0123456789", ""},
+		{"", 2, 3, http.StatusOK, 0, "234", ""}, // native API: no partial content
+		{"?" + cmn.URLParamS3 + "=true", 0, 0, http.StatusOK, 0, "0123456789", ""},
+		{"?" + cmn.URLParamS3 + "=true", 2, 3, http.StatusPartialContent, 0, "234", "bytes 2-4/10"},
+		{"?" + cmn.URLParamS3 + "=true", 8, 5, http.StatusPartialContent, 0, "89", "bytes 8-9/10"},
+		{"?" + cmn.URLParamS3 + "=true", 10, 5, 0, http.StatusRequestedRangeNotSatisfiable, "", ""},
+	}
+	for _, test := range tests {
+		var (
+			w = httptest.NewRecorder()
+			r = httptest.NewRequest(http.MethodGet, "/v1/objects/s3/obj"+test.query, nil)
+		)
+		_, errstr, errcode := tgt.objGetComplete(w, r, lom, time.Now(), test.offset, test.length, false)
+		if test.errcode != 0 {
+			if errstr == "" || errcode != test.errcode {
+				t.Errorf("%q [%d, %d]: expected error %d, got %q (%d)", test.query, test.offset, test.length,
+					test.errcode, errstr, errcode)
+			}
+			continue
+		}
+		if errstr != "" {
+			t.Errorf("%q [%d, %d]: unexpected error: %s", test.query, test.offset, test.length, errstr)
+			continue
+		}
+		hdr := w.Header()
+		if w.Code != test.status || w.Body.String() != test.body || hdr.Get("Content-Range") != test.contentRng {
+			t.Errorf("%q [%d, %d]: expected %d %q (%q), got %d %q (%q)", test.query, test.offset, test.length,
+				test.status, test.body, test.contentRng, w.Code, w.Body.String(), hdr.Get("Content-Range"))
+		}
+		if test.query == "" {
+			if hdr.Get("ETag") != "" || hdr.Get("Last-Modified") != "" {
+				t.Errorf("%q: unexpected S3 headers %v", test.query, hdr)
+			}
+			continue
+		}
+		if hdr.Get("ETag") != "\"abcdef\"" || hdr.Get("Last-Modified") == "" || hdr.Get("Accept-Ranges") != "bytes" {
+			t.Errorf("%q: missing S3 headers %v", test.query, hdr)
+		}
+		if hdr.Get("Content-Length") != strconv.Itoa(len(test.body)) {
+			t.Errorf("%q: expected Content-Length %d, got %q", test.query, len(test.body), hdr.Get("Content-Length"))
+		}
+	}
+}
@@ -631,3 +631,260 @@ func countObjects(objectList *cmn.BucketList) (total, copies2, copies3, cached i
 	}
 	return
 }
+
+func TestCopyBucket(t *testing.T) {
+	const (
+		numObjs = 50
+		objSize = 4 * cmn.KiB
+	)
+	var (
+		srcBucket  = t.Name() + "Src"
+		dstBucket  = t.Name() + "Dst"
+		proxyURL   = getPrimaryURL(t, proxyURLReadOnly)
+		baseParams = tutils.DefaultBaseAPIParams(t)
+		expected   = make(map[string]struct{}, numObjs)
+	)
+	tutils.CreateFreshLocalBucket(t, proxyURL, srcBucket)
+	defer tutils.DestroyLocalBucket(t, proxyURL, srcBucket)
+	tutils.CreateFreshLocalBucket(t, proxyURL, dstBucket)
+	defer tutils.DestroyLocalBucket(t, proxyURL, dstBucket)
+
+	for i := 0; i < numObjs; i++ {
+		dir := "skip"
+		if i%2 == 0 {
+			dir = "copy"
+		}
+		objName := fmt.Sprintf("%s/obj-%02d", dir, i)
+		err := api.PutObject(api.PutObjectArgs{
+			BaseParams:     baseParams,
+			Bucket:         srcBucket,
+			BucketProvider: cmn.LocalBs,
+			Object:         objName,
+			Reader:         tutils.NewBytesReader(make([]byte, objSize)),
+		})
+		tassert.CheckFatal(t, err)
+		if dir == "copy" {
+			expected[objName] = struct{}{}
+		}
+	}
+
+	// same bucket is invalid
+	err := api.CopyBucket(baseParams, srcBucket, cmn.LocalBs, &cmn.CopyBucketMsg{Bucket: srcBucket})
+	if err == nil {
+		t.Fatalf("copying bucket %s into itself must fail", srcBucket)
+	}
+
+	tutils.Logf("Copying %s => %s (prefix %q)\n", srcBucket, dstBucket, "copy/")
+	err = api.CopyBucket(baseParams, srcBucket, cmn.LocalBs, &cmn.CopyBucketMsg{Bucket: dstBucket, Prefix: "copy/"})
+	tassert.CheckFatal(t, err)
+	waitForBucketXactionToComplete(t, cmn.ActCopyBucket, srcBucket, baseParams, 60*time.Second)
+
+	// transport streams complete asynchronously
+	var (
+		bckList *cmn.BucketList
+		ok      bool
+	)
+	for i := 0; i < 10 && !ok; i++ {
+		bckList, err = api.ListBucket(baseParams, dstBucket, &cmn.SelectMsg{}, 0)
+		tassert.CheckFatal(t, err)
+		ok = len(bckList.Entries) == len(expected)
+		if !ok {
+			time.Sleep(time.Second)
+		}
+	}
+	if !ok {
+		t.Fatalf("expected %d objects in %s, got %d", len(expected), dstBucket, len(bckList.Entries))
+	}
+	for _, entry := range bckList.Entries {
+		if _, ok := expected[entry.Name]; !ok {
+			t.Errorf("unexpected object %s in %s", entry.Name, dstBucket)
+		}
+	}
+
+	// single object, renamed
+	err = api.CopyObject(baseParams, srcBucket, cmn.LocalBs, "skip/obj-01",
+		&cmn.CopyObjectMsg{Bucket: dstBucket, Objname: "renamed"})
+	tassert.CheckFatal(t, err)
+	for i := 0; i < 10; i++ {
+		if _, err = api.HeadObject(baseParams, dstBucket, cmn.LocalBs, "renamed"); err == nil {
+			break
+		}
+		time.Sleep(time.Second)
+	}
+	tassert.CheckFatal(t, err)
+}
+
+func TestCopyCloudBucket(t *testing.T) {
+	var (
+		dstBucket  = t.Name() + "Dst"
+		proxyURL   = getPrimaryURL(t, proxyURLReadOnly)
+		baseParams = tutils.DefaultBaseAPIParams(t)
+	)
+	if !isCloudBucket(t, baseParams.URL, clibucket) {
+		t.Skipf("%s requires a cloud bucket", t.Name())
+	}
+	objectList, err := api.ListBucket(baseParams, clibucket, &cmn.SelectMsg{Prefix: prefix}, 0)
+	tassert.CheckFatal(t, err)
+	if len(objectList.Entries) == 0 {
+		t.Skipf("%s: no objects with prefix %q in the Cloud bucket %s", t.Name(), prefix, clibucket)
+	}
+	tutils.CreateFreshLocalBucket(t, proxyURL, dstBucket)
+	defer tutils.DestroyLocalBucket(t, proxyURL, dstBucket)
+
+	// nothing cached: the objects get fetched from the Cloud
+	query := make(url.Values)
+	query.Add(cmn.URLParamBckProvider, cmn.CloudBs)
+	err = api.EvictCloudBucket(baseParams, clibucket, query)
+	tassert.CheckFatal(t, err)
+
+	err = api.CopyBucket(baseParams, clibucket, cmn.CloudBs, &cmn.CopyBucketMsg{Bucket: dstBucket, Prefix: prefix})
+	tassert.CheckFatal(t, err)
+	waitForBucketXactionToComplete(t, cmn.ActCopyBucket, clibucket, baseParams, 2*time.Minute)
+
+	var (
+		bckList *cmn.BucketList
+		ok      bool
+	)
+	for i := 0; i < 10 && !ok; i++ {
+		bckList, err = api.ListBucket(baseParams, dstBucket, &cmn.SelectMsg{}, 0)
+		tassert.CheckFatal(t, err)
+		ok = len(bckList.Entries) == len(objectList.Entries)
+		if !ok {
+			time.Sleep(time.Second)
+		}
+	}
+	if !ok {
+		t.Fatalf("expected %d objects in %s, got %d", len(objectList.Entries), dstBucket, len(bckList.Entries))
+	}
+}
+
+func waitForBucketXactionToComplete(t *testing.T, kind, bucket string, baseParams *api.BaseParams, timeout time.Duration) {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		time.Sleep(time.Second)
+		allDetails, err := api.GetXactionResponse(baseParams, kind, cmn.ActXactStats, bucket)
+		tassert.CheckFatal(t, err)
+		done := true
+		for tid := range allDetails {
+			for _, detail := range allDetails[tid] {
+				if detail.Status() == cmn.XactionStatusInProgress {
+					done = false
+				}
+			}
+		}
+		if done {
+			return
+		}
+	}
+	t.Fatalf("timed-out waiting for %s (bucket %s) to finish", kind, bucket)
+}
+
+func TestSyncBucket(t *testing.T) {
+	baseParams := tutils.DefaultBaseAPIParams(t)
+	if !isCloudBucket(t, baseParams.URL, clibucket) {
+		t.Skipf("%s requires a cloud bucket", t.Name())
+	}
+
+	// evict
+	query := make(url.Values)
+	query.Add(cmn.URLParamBckProvider, cmn.CloudBs)
+	err := api.EvictCloudBucket(baseParams, clibucket, query)
+	tassert.CheckFatal(t, err)
+
+	// dry run - everything is new
+	report, err := api.SyncBucket(baseParams, clibucket, cmn.CloudBs,
+		&cmn.SyncBucketMsg{Prefix: prefix, EvictDeleted: true, DryRun: true})
+	tassert.CheckFatal(t, err)
+	if len(report.New) == 0 {
+		t.Skipf("%s: no objects with prefix %q in the Cloud bucket %s", t.Name(), prefix, clibucket)
+	}
+	if len(report.Changed) != 0 || len(report.Deleted) != 0 {
+		t.Fatalf("expecting only new objects after eviction, got %+v", report)
+	}
+
+	// sync
+	_, err = api.SyncBucket(baseParams, clibucket, cmn.CloudBs, &cmn.SyncBucketMsg{Prefix: prefix})
+	tassert.CheckFatal(t, err)
+	waitForBucketXactionToComplete(t, cmn.ActSyncBucket, clibucket, baseParams, 2*time.Minute)
+
+	msg := &cmn.SelectMsg{Prefix: prefix}
+	query = make(url.Values)
+	query.Set(cmn.URLParamCached, "true")
+	objectList, err := api.ListBucket(baseParams, clibucket, msg, 0, query)
+	tassert.CheckFatal(t, err)
+	if len(objectList.Entries) != len(report.New) {
+		t.Errorf("expecting %d cached objects, got %d", len(report.New), len(objectList.Entries))
+	}
+
+	// nothing to do
+	report, err = api.SyncBucket(baseParams, clibucket, cmn.CloudBs,
+		&cmn.SyncBucketMsg{Prefix: prefix, EvictDeleted: true, DryRun: true})
+	tassert.CheckFatal(t, err)
+	if len(report.New) != 0 || len(report.Changed) != 0 || len(report.Deleted) != 0 {
+		t.Errorf("expecting the bucket in sync, got %+v", report)
+	}
+}
+
+func TestBucketQuota(t *testing.T) {
+	const objSize = cmn.KiB
+	var (
+		bucket      = TestLocalBucketName
+		proxyURL    = getPrimaryURL(t, proxyURLReadOnly)
+		baseParams  = tutils.DefaultBaseAPIParams(t)
+		numTargets  = len(getClusterMap(t, proxyURL).Tmap)
+		hardObjs    = 2 * numTargets
+		put, stored int
+	)
+	tutils.CreateFreshLocalBucket(t, proxyURL, bucket)
+	defer tutils.DestroyLocalBucket(t, proxyURL, bucket)
+
+	err := api.SetBucketProps(baseParams, bucket, cmn.SimpleKVs{
+		cmn.HeaderBucketQuotaHardObjs: strconv.Itoa(hardObjs),
+		cmn.HeaderBucketQuotaSoftSize: "1MiB",
+	})
+	tassert.CheckFatal(t, err)
+	props, err := api.HeadBucket(baseParams, bucket)
+	tassert.CheckFatal(t, err)
+	if props.Quota.HardObjs != int64(hardObjs) || props.Quota.SoftSize != cmn.MiB {
+		t.Fatalf("unexpected quota %+v", props.Quota)
+	}
+
+	putObjs := func(cnt int) (errs int) {
+		for i := 0; i < cnt; i, put = i+1, put+1 {
+			err = api.PutObject(api.PutObjectArgs{
+				BaseParams:     baseParams,
+				Bucket:         bucket,
+				BucketProvider: cmn.LocalBs,
+				Object:         fmt.Sprintf("quota/obj%d", put),
+				Reader:         tutils.NewBytesReader(make([]byte, objSize)),
+			})
+			if err == nil {
+				stored++
+			} else if httpErr, ok := err.(*cmn.HTTPError); !ok || httpErr.Status != http.StatusForbidden {
+				t.Fatalf("expected %d, got %v", http.StatusForbidden, err)
+			} else {
+				errs++
+			}
+		}
+		return
+	}
+
+	// the quota is enforced against the cluster-wide usage that each target
+	// refreshes in the background - fill the bucket, and give the targets time
+	// to learn about each other's usage
+	if errs := putObjs(hardObjs); errs != 0 {
+		t.Fatalf("quota (%d objects) enforced prematurely: %d PUTs failed", hardObjs, errs)
+	}
+	time.Sleep(11 * time.Second)
+	putObjs(2 * numTargets)
+	time.Sleep(2 * time.Second)
+	if errs := putObjs(2 * numTargets); errs != 2*numTargets {
+		t.Fatalf("quota (%d objects) not enforced: stored %d out of %d", hardObjs, stored, put)
+	}
+
+	usage, err := api.GetBucketUsage(baseParams, bucket)
+	tassert.CheckFatal(t, err)
+	if usage.Objs != int64(stored) || usage.Size != int64(stored*objSize) {
+		t.Errorf("expected usage: %d objects (%d bytes), got %+v", stored, stored*objSize, *usage)
+	}
+}
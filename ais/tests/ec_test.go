@@ -11,6 +11,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"testing"
@@ -575,7 +576,11 @@ func TestECChange(t *testing.T) {
 	bucketProps.EC.Enabled = true
 	bucketProps.EC.ObjSizeLimit = 300000
 	err = api.SetBucketPropsMsg(baseParams, bucket, bucketProps)
-	tassert.Errorf(t, err != nil, "Modifiying EC properties must fail")
+	tassert.Errorf(t, err == nil, "Modifiying EC properties failed: %v", err)
+
+	tutils.Logln("Trying to set too many slices when EC is enabled")
+	err = api.SetBucketProps(baseParams, bucket, cmn.SimpleKVs{cmn.HeaderBucketECParity: "25"})
+	tassert.Errorf(t, err != nil, "Setting the number of slices must fail in case of the number of targets fewer than the number of slices")
 
 	tutils.Logln("Resetting bucket properties")
 	err = api.ResetBucketProps(baseParams, bucket)
@@ -871,6 +876,251 @@ func TestECChecksum(t *testing.T) {
 	tassert.Fatalf(t, err != nil, "Object should not be restored when checksums are wrong")
 }
 
+// Creates 2 EC files, then removes a slice (along with its metafile) of the
+// first one and corrupts a slice of the second one. Checks that the scrub
+// re-encodes both objects, so that all their slices are in place again
+func TestECScrub(t *testing.T) {
+	const (
+		objPatt = "obj-scrub-%04d"
+	)
+
+	if testing.Short() {
+		t.Skip(skipping)
+	}
+
+	if tutils.DockerRunning() {
+		t.Skip(fmt.Sprintf("test %q requires Xattributes to be set, doesn't work with docker", t.Name()))
+	}
+
+	var (
+		bucket   = TestLocalBucketName
+		proxyURL = getPrimaryURL(t, proxyURLReadOnly)
+		tMock    = cluster.NewTargetMock(cluster.NewBaseBownerMock(TestLocalBucketName))
+	)
+
+	smap := getClusterMap(t, proxyURL)
+	tassert.CheckFatal(t, ecSliceNumInit(t, smap))
+
+	fullPath := fmt.Sprintf("local/%s/%s", bucket, ecTestDir)
+	seed := time.Now().UnixNano()
+	baseParams := tutils.BaseAPIParams(proxyURL)
+
+	newLocalBckWithProps(t, bucket, defaultECBckProps(), seed, 0, baseParams)
+	defer tutils.DestroyLocalBucket(t, proxyURL, bucket)
+
+	objName1 := fmt.Sprintf(objPatt, 1)
+	foundParts1, _ := createECFile(t, bucket, objName1, fullPath, baseParams)
+	objName2 := fmt.Sprintf(objPatt, 2)
+	foundParts2, _ := createECFile(t, bucket, objName2, fullPath, baseParams)
+
+	for k := range foundParts1 {
+		if strings.Contains(k, ecSliceDir) {
+			metaPath := strings.Replace(k, ecSliceDir, ecMetaDir, 1)
+			tutils.Logf("Removing slice %s and its metafile\n", k)
+			tassert.CheckFatal(t, os.Remove(k))
+			tassert.CheckFatal(t, os.Remove(metaPath))
+			break
+		}
+	}
+	for k := range foundParts2 {
+		if strings.Contains(k, ecSliceDir) {
+			tutils.Logf("Corrupting slice %s\n", k)
+			err := tutils.SetXattrCksm(k, cmn.NewCksum(cmn.ChecksumXXHash, "01234"), tMock)
+			tassert.CheckFatal(t, err)
+			break
+		}
+	}
+
+	tassert.CheckFatal(t, api.ScrubBucketEC(baseParams, bucket))
+	waitForBucketXactionToComplete(t, cmn.ActECScrub, bucket, baseParams, ECPutTimeOut)
+
+	totalCnt := 2 + (ecDataSliceCnt+ecParitySliceCnt)*2
+	objSize := int64(ecMinBigSize * 2)
+	sliceSize := ec.SliceSize(objSize, ecDataSliceCnt)
+	for _, objName := range []string{objName1, objName2} {
+		foundParts, mainObjPath := waitForECFinishes(t, totalCnt, objSize, sliceSize, true, objName, bucket)
+		tassert.Fatalf(t, mainObjPath != "", "Full copy %s was not found", mainObjPath)
+		ecCheckSlices(t, foundParts, fullPath+objName, objSize, sliceSize, totalCnt)
+		for k, md := range foundParts {
+			if strings.Contains(k, ecSliceDir) {
+				tassert.Errorf(t, md.hash != "01234", "Slice %s is still corrupted", k)
+			}
+		}
+		_, err := api.GetObject(baseParams, bucket, ecTestDir+objName)
+		tassert.CheckFatal(t, err)
+	}
+}
+
+// Puts objects to the bucket with EC disabled, then enables EC and encodes
+// the bucket. Checks that all objects get their slices. Then decreases the
+// number of parity slices and checks that the objects are re-encoded - and
+// the extra slices are gone
+func TestECEncodeBucket(t *testing.T) {
+	const (
+		objPatt  = "obj-encode-%04d"
+		numFiles = 5
+	)
+
+	if testing.Short() {
+		t.Skip(skipping)
+	}
+
+	var (
+		bucket   = TestLocalBucketName
+		proxyURL = getPrimaryURL(t, proxyURLReadOnly)
+	)
+
+	smap := getClusterMap(t, proxyURL)
+	tassert.CheckFatal(t, ecSliceNumInit(t, smap))
+
+	fullPath := fmt.Sprintf("local/%s/%s", bucket, ecTestDir)
+	seed := time.Now().UnixNano()
+	baseParams := tutils.BaseAPIParams(proxyURL)
+
+	bckProps := defaultECBckProps()
+	bckProps.EC.Enabled = false
+	newLocalBckWithProps(t, bucket, bckProps, seed, 0, baseParams)
+	defer tutils.DestroyLocalBucket(t, proxyURL, bucket)
+
+	objSize := int64(ecMinBigSize * 2)
+	for i := 0; i < numFiles; i++ {
+		putRandomFile(t, baseParams, bucket, ecTestDir+fmt.Sprintf(objPatt, i), int(objSize))
+	}
+
+	checkSlices := func(dataCnt, parityCnt int) {
+		totalCnt := 2 + (dataCnt+parityCnt)*2
+		sliceSize := ec.SliceSize(objSize, dataCnt)
+		for i := 0; i < numFiles; i++ {
+			objName := fmt.Sprintf(objPatt, i)
+			foundParts, mainObjPath := waitForECFinishes(t, totalCnt, objSize, sliceSize, true, objName, bucket)
+			tassert.Fatalf(t, mainObjPath != "", "Full copy %s was not found", mainObjPath)
+			ecCheckSlices(t, foundParts, fullPath+objName, objSize, sliceSize, totalCnt)
+		}
+	}
+
+	tutils.Logln("Enabling EC and encoding the bucket")
+	err := api.SetBucketProps(baseParams, bucket, cmn.SimpleKVs{cmn.HeaderBucketECEnabled: "true"})
+	tassert.CheckFatal(t, err)
+	tassert.CheckFatal(t, api.EncodeBucketEC(baseParams, bucket))
+	waitForBucketXactionToComplete(t, cmn.ActECEncode, bucket, baseParams, ECPutTimeOut)
+	checkSlices(ecDataSliceCnt, ecParitySliceCnt)
+
+	if ecParitySliceCnt < 2 {
+		return
+	}
+	tutils.Logf("Changing the number of parity slices to %d\n", ecParitySliceCnt-1)
+	err = api.SetBucketProps(baseParams, bucket, cmn.SimpleKVs{cmn.HeaderBucketECParity: strconv.Itoa(ecParitySliceCnt - 1)})
+	tassert.CheckFatal(t, err)
+	waitForBucketXactionToComplete(t, cmn.ActECEncode, bucket, baseParams, ECPutTimeOut)
+	checkSlices(ecDataSliceCnt, ecParitySliceCnt-1)
+}
+
+func TestECLRC(t *testing.T) {
+	const (
+		objPatt  = "obj-lrc-%04d"
+		numFiles = 25
+		semaCnt  = 8
+	)
+	if testing.Short() {
+		t.Skip(skipping)
+	}
+
+	var (
+		bucket   = TestLocalBucketName
+		proxyURL = getPrimaryURL(t, proxyURLReadOnly)
+		sema     = make(chan struct{}, semaCnt)
+	)
+
+	smap := getClusterMap(t, proxyURL)
+	tassert.CheckFatal(t, ecSliceNumInit(t, smap))
+	// LRC requires at least one global parity slice in addition to the local one
+	if ecParitySliceCnt < 2 {
+		ecParitySliceCnt = 2
+	}
+	if len(smap.Tmap) < ecDataSliceCnt+ecParitySliceCnt+1 {
+		t.Skipf("%s requires at least %d targets", t.Name(), ecDataSliceCnt+ecParitySliceCnt+1)
+	}
+
+	fullPath := fmt.Sprintf("local/%s/%s", bucket, ecTestDir)
+	seed := time.Now().UnixNano()
+	rnd := rand.New(rand.NewSource(seed))
+	baseParams := tutils.BaseAPIParams(proxyURL)
+
+	bckProps := defaultECBckProps()
+	bckProps.EC.Code = cmn.ECCodeLRC
+	bckProps.EC.LocalGroups = 1
+	newLocalBckWithProps(t, bucket, bckProps, seed, 0, baseParams)
+	defer tutils.DestroyLocalBucket(t, proxyURL, bucket)
+
+	p, err := api.HeadBucket(baseParams, bucket)
+	tassert.CheckFatal(t, err)
+	tassert.Fatalf(t, p.EC.Code == cmn.ECCodeLRC && p.EC.LocalGroups == 1,
+		"Invalid EC code of bucket %s: %q, %d local groups", bucket, p.EC.Code, p.EC.LocalGroups)
+
+	wg := sync.WaitGroup{}
+	wg.Add(numFiles)
+	for i := 0; i < numFiles; i++ {
+		objName := fmt.Sprintf(objPatt, i)
+		go func(i int) {
+			createDamageRestoreECFile(t, baseParams, bucket, objName, i, sema, rnd, fullPath)
+			wg.Done()
+		}(i)
+	}
+	wg.Wait()
+	assertBucketSize(t, baseParams, bucket, numFiles)
+
+	// every parity slice being local is not allowed
+	err = api.SetBucketProps(baseParams, bucket, cmn.SimpleKVs{cmn.HeaderBucketECLocalGroups: strconv.Itoa(ecParitySliceCnt)})
+	tassert.Errorf(t, err != nil, "Setting %d local groups for %d parity slices must fail", ecParitySliceCnt, ecParitySliceCnt)
+}
+
+func TestECRebalance(t *testing.T) {
+	const (
+		objPatt  = "obj-reb-%04d"
+		numFiles = 20
+	)
+
+	if testing.Short() {
+		t.Skip(skipping)
+	}
+
+	var (
+		bucket   = TestLocalBucketName
+		proxyURL = getPrimaryURL(t, proxyURLReadOnly)
+	)
+
+	smap := getClusterMap(t, proxyURL)
+	tassert.CheckFatal(t, ecSliceNumInit(t, smap))
+
+	fullPath := fmt.Sprintf("local/%s/%s", bucket, ecTestDir)
+	seed := time.Now().UnixNano()
+	baseParams := tutils.BaseAPIParams(proxyURL)
+
+	newLocalBckWithProps(t, bucket, defaultECBckProps(), seed, 0, baseParams)
+	defer tutils.DestroyLocalBucket(t, proxyURL, bucket)
+
+	objSize := int64(ecMinBigSize * 2)
+	totalCnt := 2 + (ecDataSliceCnt+ecParitySliceCnt)*2
+	sliceSize := ec.SliceSize(objSize, ecDataSliceCnt)
+	for i := 0; i < numFiles; i++ {
+		objName := fmt.Sprintf(objPatt, i)
+		putRandomFile(t, baseParams, bucket, ecTestDir+objName, int(objSize))
+		foundParts, mainObjPath := waitForECFinishes(t, totalCnt, objSize, sliceSize, true, objName, bucket)
+		tassert.Fatalf(t, mainObjPath != "", "Full copy %s was not found", mainObjPath)
+		ecCheckSlices(t, foundParts, fullPath+objName, objSize, sliceSize, totalCnt)
+	}
+
+	// the placement of slices changes twice: the objects must remain readable
+	var removedTarget *cluster.Snode
+	smap, removedTarget = tutils.RemoveTarget(t, proxyURL, smap)
+	waitForRebalanceToComplete(t, baseParams)
+	objectsExist(t, baseParams, bucket, objPatt, numFiles)
+
+	smap = tutils.RestoreTarget(t, proxyURL, smap, removedTarget)
+	waitForRebalanceToComplete(t, baseParams)
+	objectsExist(t, baseParams, bucket, objPatt, numFiles)
+}
+
 func TestECEnabledDisabledEnabled(t *testing.T) {
 	const (
 		objPatt  = "obj-rest-%04d"
@@ -5,7 +5,9 @@
 package ais_test
 
 import (
+	"bytes"
 	"fmt"
+	"net/url"
 	"path"
 	"strconv"
 	"sync"
@@ -467,3 +469,78 @@ func TestObjPropsVersionDisabled(t *testing.T) {
 
 	propsMainTest(t, false)
 }
+
+func TestObjPropsVersionHistory(t *testing.T) {
+	const (
+		objName = "versioned-obj"
+		numPuts = 4
+	)
+	var (
+		bucket     = TestLocalBucketName
+		proxyURL   = getPrimaryURL(t, proxyURLReadOnly)
+		baseParams = tutils.DefaultBaseAPIParams(t)
+	)
+	tutils.CreateFreshLocalBucket(t, proxyURL, bucket)
+	defer tutils.DestroyLocalBucket(t, proxyURL, bucket)
+
+	props, err := api.HeadBucket(baseParams, bucket)
+	tassert.CheckFatal(t, err)
+	props.Versioning = cmn.VersionConf{Type: cmn.PropOwn, Enabled: true, MaxVersions: numPuts}
+	err = api.SetBucketPropsMsg(baseParams, bucket, *props)
+	tassert.CheckFatal(t, err)
+
+	content := func(i int) []byte { return []byte(fmt.Sprintf("content of version %d", i)) }
+	for i := 1; i <= numPuts; i++ {
+		err = api.PutObject(api.PutObjectArgs{
+			BaseParams:     baseParams,
+			Bucket:         bucket,
+			BucketProvider: cmn.LocalBs,
+			Object:         objName,
+			Reader:         tutils.NewBytesReader(content(i)),
+		})
+		tassert.CheckFatal(t, err)
+	}
+
+	vers, err := api.GetObjectVersions(baseParams, bucket, cmn.LocalBs, objName)
+	tassert.CheckFatal(t, err)
+	if len(vers) != numPuts {
+		t.Fatalf("expected %d versions, got %d: %+v", numPuts, len(vers), vers)
+	}
+	for i, ver := range vers {
+		if ver.Version != strconv.Itoa(i+1) || ver.Current != (i == numPuts-1) {
+			t.Errorf("unexpected version #%d: %+v", i, ver)
+		}
+	}
+
+	// read an older version
+	w := bytes.NewBuffer(nil)
+	query := url.Values{cmn.URLParamVersion: []string{"2"}}
+	_, err = api.GetObject(baseParams, bucket, objName, api.GetObjectInput{Writer: w, Query: query})
+	tassert.CheckFatal(t, err)
+	if !bytes.Equal(w.Bytes(), content(2)) {
+		t.Errorf("version 2: expected %q, got %q", content(2), w.Bytes())
+	}
+
+	// delete an older version; the current one cannot be deleted this way
+	err = api.DeleteObjectVersion(baseParams, bucket, objName, cmn.LocalBs, "1")
+	tassert.CheckFatal(t, err)
+	if err = api.DeleteObjectVersion(baseParams, bucket, objName, cmn.LocalBs, strconv.Itoa(numPuts)); err == nil {
+		t.Errorf("deleting the current version must fail")
+	}
+	query = url.Values{cmn.URLParamVersion: []string{"1"}}
+	if _, err = api.GetObject(baseParams, bucket, objName, api.GetObjectInput{Query: query}); err == nil {
+		t.Errorf("version 1 must not exist")
+	}
+	vers, err = api.GetObjectVersions(baseParams, bucket, cmn.LocalBs, objName)
+	tassert.CheckFatal(t, err)
+	if len(vers) != numPuts-1 {
+		t.Errorf("expected %d versions, got %d: %+v", numPuts-1, len(vers), vers)
+	}
+
+	// deleting the object removes its history as well
+	err = api.DeleteObject(baseParams, bucket, objName, cmn.LocalBs)
+	tassert.CheckFatal(t, err)
+	if _, err = api.GetObjectVersions(baseParams, bucket, cmn.LocalBs, objName); err == nil {
+		t.Errorf("%s/%s: versions must be gone along with the object", bucket, objName)
+	}
+}
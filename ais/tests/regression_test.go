@@ -5,16 +5,20 @@
 package ais_test
 
 import (
+	"archive/tar"
 	"bytes"
+	"compress/gzip"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"math/rand"
 	"net/http"
+	"net/url"
 	"os"
 	"path"
 	"path/filepath"
+	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
@@ -1420,3 +1424,382 @@ func selectErr(errCh chan error, verb string, t *testing.T, errisfatal bool) {
 	default:
 	}
 }
+
+func TestMultipartUpload(t *testing.T) {
+	const (
+		numParts = 4
+		partSize = 64 * cmn.KiB
+		objName  = "multipart/obj"
+	)
+	var (
+		bucket     = t.Name()
+		proxyURL   = getPrimaryURL(t, proxyURLReadOnly)
+		baseParams = tutils.DefaultBaseAPIParams(t)
+		parts      = make([][]byte, numParts)
+		mpuParts   = make([]cmn.MultipartPart, numParts)
+	)
+	tutils.CreateFreshLocalBucket(t, proxyURL, bucket)
+	defer tutils.DestroyLocalBucket(t, proxyURL, bucket)
+
+	uploadID, err := api.InitMultipartUpload(baseParams, bucket, cmn.LocalBs, objName)
+	tassert.CheckFatal(t, err)
+
+	// upload the parts in reverse order
+	for i := numParts - 1; i >= 0; i-- {
+		parts[i] = make([]byte, partSize)
+		rand.Read(parts[i])
+		args := api.PutObjectArgs{
+			BaseParams:     baseParams,
+			Bucket:         bucket,
+			BucketProvider: cmn.LocalBs,
+			Object:         objName,
+			Reader:         tutils.NewBytesReader(parts[i]),
+		}
+		cksum, err := api.PutObjectPart(args, uploadID, i+1)
+		tassert.CheckFatal(t, err)
+		mpuParts[i] = cmn.MultipartPart{PartNum: i + 1, Cksum: cksum}
+	}
+	err = api.CompleteMultipartUpload(baseParams, bucket, cmn.LocalBs, objName, uploadID, mpuParts)
+	tassert.CheckFatal(t, err)
+
+	buf := &bytes.Buffer{}
+	_, err = api.GetObjectWithValidation(baseParams, bucket, objName, api.GetObjectInput{Writer: buf})
+	tassert.CheckFatal(t, err)
+	if !bytes.Equal(buf.Bytes(), bytes.Join(parts, nil)) {
+		t.Fatalf("%s/%s: content does not match the uploaded parts", bucket, objName)
+	}
+
+	// the upload is gone once completed
+	err = api.AbortMultipartUpload(baseParams, bucket, cmn.LocalBs, objName, uploadID)
+	if err == nil {
+		t.Fatalf("expected abort of completed upload %q to fail", uploadID)
+	}
+
+	// abort, then try to complete
+	uploadID, err = api.InitMultipartUpload(baseParams, bucket, cmn.LocalBs, objName+".aborted")
+	tassert.CheckFatal(t, err)
+	args := api.PutObjectArgs{
+		BaseParams:     baseParams,
+		Bucket:         bucket,
+		BucketProvider: cmn.LocalBs,
+		Object:         objName + ".aborted",
+		Reader:         tutils.NewBytesReader(parts[0]),
+	}
+	_, err = api.PutObjectPart(args, uploadID, 1)
+	tassert.CheckFatal(t, err)
+	err = api.AbortMultipartUpload(baseParams, bucket, cmn.LocalBs, objName+".aborted", uploadID)
+	tassert.CheckFatal(t, err)
+	err = api.CompleteMultipartUpload(baseParams, bucket, cmn.LocalBs, objName+".aborted", uploadID,
+		[]cmn.MultipartPart{{PartNum: 1}})
+	if err == nil {
+		t.Fatalf("expected complete of aborted upload %q to fail", uploadID)
+	}
+}
+
+func TestAppendAndConcat(t *testing.T) {
+	const (
+		numChunks = 3
+		chunkSize = 32 * cmn.KiB
+		objName   = "append/obj"
+	)
+	var (
+		bucket     = t.Name()
+		proxyURL   = getPrimaryURL(t, proxyURLReadOnly)
+		baseParams = tutils.DefaultBaseAPIParams(t)
+		chunks     = make([][]byte, numChunks)
+		handle     string
+	)
+	tutils.CreateFreshLocalBucket(t, proxyURL, bucket)
+	defer tutils.DestroyLocalBucket(t, proxyURL, bucket)
+
+	for i := 0; i < numChunks; i++ {
+		chunks[i] = make([]byte, chunkSize)
+		rand.Read(chunks[i])
+		args := api.PutObjectArgs{
+			BaseParams:     baseParams,
+			Bucket:         bucket,
+			BucketProvider: cmn.LocalBs,
+			Object:         objName,
+			Reader:         tutils.NewBytesReader(chunks[i]),
+		}
+		var err error
+		handle, err = api.AppendObject(args, handle)
+		tassert.CheckFatal(t, err)
+		if handle == "" {
+			t.Fatalf("%s/%s: append returned empty handle", bucket, objName)
+		}
+	}
+	// not visible until flushed
+	if _, err := api.HeadObject(baseParams, bucket, cmn.LocalBs, objName); err == nil {
+		t.Fatalf("%s/%s: appended object must not be visible before flush", bucket, objName)
+	}
+	err := api.FlushObject(baseParams, bucket, cmn.LocalBs, objName, handle)
+	tassert.CheckFatal(t, err)
+
+	buf := &bytes.Buffer{}
+	_, err = api.GetObjectWithValidation(baseParams, bucket, objName, api.GetObjectInput{Writer: buf})
+	tassert.CheckFatal(t, err)
+	if !bytes.Equal(buf.Bytes(), bytes.Join(chunks, nil)) {
+		t.Fatalf("%s/%s: content does not match the appended data", bucket, objName)
+	}
+
+	// the handle is gone once flushed
+	if err = api.FlushObject(baseParams, bucket, cmn.LocalBs, objName, handle); err == nil {
+		t.Fatalf("expected flush of flushed handle %q to fail", handle)
+	}
+
+	// the object is overwritten while being appended to: flush must fail
+	args := api.PutObjectArgs{
+		BaseParams:     baseParams,
+		Bucket:         bucket,
+		BucketProvider: cmn.LocalBs,
+		Object:         objName,
+		Reader:         tutils.NewBytesReader(chunks[0]),
+	}
+	handle, err = api.AppendObject(args, "")
+	tassert.CheckFatal(t, err)
+	args.Reader = tutils.NewBytesReader(chunks[1])
+	err = api.PutObject(args)
+	tassert.CheckFatal(t, err)
+	if err = api.FlushObject(baseParams, bucket, cmn.LocalBs, objName, handle); err == nil {
+		t.Fatalf("expected flush of the overwritten object to fail")
+	}
+
+	// concat: put the chunks as separate objects, then concatenate them in reverse order
+	objnames := make([]string, 0, numChunks)
+	for i := numChunks - 1; i >= 0; i-- {
+		name := fmt.Sprintf("concat/src-%d", i)
+		err = api.PutObject(api.PutObjectArgs{
+			BaseParams:     baseParams,
+			Bucket:         bucket,
+			BucketProvider: cmn.LocalBs,
+			Object:         name,
+			Reader:         tutils.NewBytesReader(chunks[i]),
+		})
+		tassert.CheckFatal(t, err)
+		objnames = append(objnames, name)
+	}
+	err = api.ConcatObjects(baseParams, bucket, cmn.LocalBs, "concat/dst", objnames)
+	tassert.CheckFatal(t, err)
+
+	buf.Reset()
+	_, err = api.GetObjectWithValidation(baseParams, bucket, "concat/dst", api.GetObjectInput{Writer: buf})
+	tassert.CheckFatal(t, err)
+	expected := make([]byte, 0, numChunks*chunkSize)
+	for i := numChunks - 1; i >= 0; i-- {
+		expected = append(expected, chunks[i]...)
+	}
+	if !bytes.Equal(buf.Bytes(), expected) {
+		t.Fatalf("%s/concat/dst: content does not match the concatenated objects", bucket)
+	}
+
+	// missing source object
+	err = api.ConcatObjects(baseParams, bucket, cmn.LocalBs, "concat/bad", append(objnames, "does-not-exist"))
+	if err == nil {
+		t.Fatalf("expected concat with a missing source object to fail")
+	}
+}
+
+func TestObjectCustomMD(t *testing.T) {
+	const objName = "custommd/obj"
+	var (
+		bucket     = t.Name()
+		proxyURL   = getPrimaryURL(t, proxyURLReadOnly)
+		baseParams = tutils.DefaultBaseAPIParams(t)
+		customMD   = cmn.SimpleKVs{"owner": "ais", "project": "custom-md"}
+	)
+	tutils.CreateFreshLocalBucket(t, proxyURL, bucket)
+	defer tutils.DestroyLocalBucket(t, proxyURL, bucket)
+
+	reader, err := tutils.NewRandReader(cmn.KiB, false /* withHash */)
+	tassert.CheckFatal(t, err)
+	err = api.PutObject(api.PutObjectArgs{
+		BaseParams:     baseParams,
+		Bucket:         bucket,
+		BucketProvider: cmn.LocalBs,
+		Object:         objName,
+		Reader:         reader,
+		CustomMD:       customMD,
+	})
+	tassert.CheckFatal(t, err)
+
+	props, err := api.HeadObject(baseParams, bucket, cmn.LocalBs, objName)
+	tassert.CheckFatal(t, err)
+	if !reflect.DeepEqual(props.CustomMD, customMD) {
+		t.Fatalf("%s/%s: expected custom metadata %v, got %v", bucket, objName, customMD, props.CustomMD)
+	}
+
+	// update: overwrite one key, remove another, add a new one
+	err = api.SetObjectCustomMD(baseParams, bucket, cmn.LocalBs, objName,
+		cmn.SimpleKVs{"owner": "nvidia", "project": "", "stage": "done"})
+	tassert.CheckFatal(t, err)
+	expected := cmn.SimpleKVs{"owner": "nvidia", "stage": "done"}
+
+	props, err = api.HeadObject(baseParams, bucket, cmn.LocalBs, objName)
+	tassert.CheckFatal(t, err)
+	if !reflect.DeepEqual(props.CustomMD, expected) {
+		t.Fatalf("%s/%s: expected custom metadata %v, got %v", bucket, objName, expected, props.CustomMD)
+	}
+
+	msg := &cmn.SelectMsg{Props: cmn.GetPropsSize + "," + cmn.GetPropsCustomMD}
+	objList, err := api.ListBucket(baseParams, bucket, msg, 0)
+	tassert.CheckFatal(t, err)
+	if len(objList.Entries) != 1 {
+		t.Fatalf("expected 1 object in %s, got %d", bucket, len(objList.Entries))
+	}
+	if !reflect.DeepEqual(objList.Entries[0].CustomMD, expected) {
+		t.Fatalf("%s/%s: expected listed custom metadata %v, got %v", bucket, objName, expected, objList.Entries[0].CustomMD)
+	}
+}
+
+func TestGetObjectsBatch(t *testing.T) {
+	const (
+		numObjs = 20
+		objSize = 4 * cmn.KiB
+	)
+	var (
+		bucket     = t.Name()
+		proxyURL   = getPrimaryURL(t, proxyURLReadOnly)
+		baseParams = tutils.DefaultBaseAPIParams(t)
+		content    = make(map[string][]byte, numObjs)
+		objnames   = make([]string, 0, numObjs)
+	)
+	tutils.CreateFreshLocalBucket(t, proxyURL, bucket)
+	defer tutils.DestroyLocalBucket(t, proxyURL, bucket)
+
+	for i := 0; i < numObjs; i++ {
+		objName := fmt.Sprintf("batch/obj-%02d", i)
+		b := make([]byte, objSize)
+		rand.Read(b)
+		err := api.PutObject(api.PutObjectArgs{
+			BaseParams:     baseParams,
+			Bucket:         bucket,
+			BucketProvider: cmn.LocalBs,
+			Object:         objName,
+			Reader:         tutils.NewBytesReader(b),
+		})
+		tassert.CheckFatal(t, err)
+		content[objName] = b
+		objnames = append(objnames, objName)
+	}
+
+	// reads the archive and checks that it contains the expected objects, in order
+	checkArchive := func(buf *bytes.Buffer, gzipped bool, expected []string) {
+		var r io.Reader = buf
+		if gzipped {
+			gzr, err := gzip.NewReader(buf)
+			tassert.CheckFatal(t, err)
+			defer gzr.Close()
+			r = gzr
+		}
+		tr := tar.NewReader(r)
+		for _, objName := range expected {
+			hdr, err := tr.Next()
+			tassert.CheckFatal(t, err)
+			if hdr.Name != objName {
+				t.Fatalf("expected %s, got %s", objName, hdr.Name)
+			}
+			b, err := ioutil.ReadAll(tr)
+			tassert.CheckFatal(t, err)
+			if !bytes.Equal(b, content[objName]) {
+				t.Fatalf("%s/%s: content mismatch", bucket, objName)
+			}
+		}
+		if _, err := tr.Next(); err != io.EOF {
+			t.Fatalf("expected exactly %d objects in the archive, err: %v", len(expected), err)
+		}
+	}
+
+	// reverse order, by names
+	reversed := make([]string, 0, numObjs)
+	for i := numObjs - 1; i >= 0; i-- {
+		reversed = append(reversed, objnames[i])
+	}
+	buf := &bytes.Buffer{}
+	_, err := api.GetObjectsBatch(baseParams, bucket, cmn.LocalBs, &cmn.GetBatchMsg{Objnames: reversed}, buf)
+	tassert.CheckFatal(t, err)
+	checkArchive(buf, false, reversed)
+
+	// template, tar.gz
+	buf.Reset()
+	msg := &cmn.GetBatchMsg{Template: fmt.Sprintf("batch/obj-{00..%02d}", numObjs-1), Format: cmn.BatchFormatTgz}
+	_, err = api.GetObjectsBatch(baseParams, bucket, cmn.LocalBs, msg, buf)
+	tassert.CheckFatal(t, err)
+	checkArchive(buf, true, objnames)
+
+	// missing object: fail unless skipped
+	withMissing := append([]string{"batch/does-not-exist"}, objnames[:3]...)
+	buf.Reset()
+	if _, err = api.GetObjectsBatch(baseParams, bucket, cmn.LocalBs, &cmn.GetBatchMsg{Objnames: withMissing}, buf); err == nil {
+		t.Fatalf("expected batch with a missing object to fail")
+	}
+	buf.Reset()
+	msg = &cmn.GetBatchMsg{Objnames: withMissing, SkipMissing: true}
+	_, err = api.GetObjectsBatch(baseParams, bucket, cmn.LocalBs, msg, buf)
+	tassert.CheckFatal(t, err)
+	checkArchive(buf, false, objnames[:3])
+}
+
+func TestGetArchiveMember(t *testing.T) {
+	var (
+		bucket     = t.Name()
+		proxyURL   = getPrimaryURL(t, proxyURLReadOnly)
+		baseParams = tutils.DefaultBaseAPIParams(t)
+		members    = map[string][]byte{
+			"samples/000.jpg": bytes.Repeat([]byte{'a'}, 1000),
+			"samples/000.cls": []byte("7"),
+			"samples/001.jpg": bytes.Repeat([]byte{'b'}, 20*cmn.KiB),
+		}
+	)
+	tutils.CreateFreshLocalBucket(t, proxyURL, bucket)
+	defer tutils.DestroyLocalBucket(t, proxyURL, bucket)
+
+	for _, shard := range []string{"shard.tar", "shard.tar.gz"} {
+		var (
+			buf = &bytes.Buffer{}
+			w   io.Writer
+			gzw *gzip.Writer
+		)
+		w = buf
+		if strings.HasSuffix(shard, ".gz") {
+			gzw = gzip.NewWriter(buf)
+			w = gzw
+		}
+		tw := tar.NewWriter(w)
+		for name, content := range members {
+			err := tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: name, Size: int64(len(content)), Mode: 0644})
+			tassert.CheckFatal(t, err)
+			_, err = tw.Write(content)
+			tassert.CheckFatal(t, err)
+		}
+		tassert.CheckFatal(t, tw.Close())
+		if gzw != nil {
+			tassert.CheckFatal(t, gzw.Close())
+		}
+		err := api.PutObject(api.PutObjectArgs{
+			BaseParams:     baseParams,
+			Bucket:         bucket,
+			BucketProvider: cmn.LocalBs,
+			Object:         shard,
+			Reader:         tutils.NewBytesReader(buf.Bytes()),
+		})
+		tassert.CheckFatal(t, err)
+
+		// twice: the second time around uncompressed tarball is read via index
+		for i := 0; i < 2; i++ {
+			for name, content := range members {
+				buf.Reset()
+				query := url.Values{cmn.URLParamArchPath: []string{name}}
+				_, err = api.GetObject(baseParams, bucket, shard, api.GetObjectInput{Writer: buf, Query: query})
+				tassert.CheckFatal(t, err)
+				if !bytes.Equal(buf.Bytes(), content) {
+					t.Fatalf("%s/%s[%s]: content mismatch", bucket, shard, name)
+				}
+			}
+		}
+		query := url.Values{cmn.URLParamArchPath: []string{"samples/does-not-exist"}}
+		if _, err = api.GetObject(baseParams, bucket, shard, api.GetObjectInput{Query: query}); err == nil {
+			t.Fatalf("%s/%s: expected GET of a missing member to fail", bucket, shard)
+		}
+	}
+}
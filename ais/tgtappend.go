@@ -0,0 +1,562 @@
+// Package ais provides core functionality for the AIStore object storage.
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package ais
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/NVIDIA/aistore/3rdparty/glog"
+	"github.com/NVIDIA/aistore/cluster"
+	"github.com/NVIDIA/aistore/cmn"
+	"github.com/NVIDIA/aistore/encryption"
+	"github.com/NVIDIA/aistore/fs"
+	jsoniter "github.com/json-iterator/go"
+)
+
+//
+// Append: the first append to an object creates a workfile (initialized with
+// the current content of the object, if any) and returns a handle. Subsequent
+// appends that carry the handle are serialized via the name locker and go to
+// the end of the workfile. Flush commits the workfile exactly like a regular
+// PUT - that is, atomically and with the object's checksum and version
+// recomputed. Appended (but not yet flushed) content is not visible to readers.
+// Since the workfile starts out as a copy of the object, flush fails if the
+// object has been modified by other means in the meantime. Handles that are
+// not used for appendHandleTTL expire, and their workfiles are removed.
+//
+// The workfile holds plaintext: the content of an encrypted object is decrypted
+// when the workfile is created, and flush encrypts the workfile as per the
+// current bucket properties (see cmn.EncryptionConf).
+//
+// Concat: builds a new object out of an ordered list of existing objects in the
+// same bucket. Source objects are read from their respective (HRW) targets.
+//
+
+const (
+	appendHandleTTL   = time.Hour
+	appendSweepPeriod = 5 * time.Minute
+)
+
+type (
+	appendHandle struct {
+		uname    string // object's unique name (see cluster.Bo2Uname)
+		workFQN  string
+		size     int64
+		customMD cmn.SimpleKVs // user-defined metadata of the object being appended to
+		atime    time.Time     // last used
+		base     appendBase    // the object the workfile started out as a copy of
+	}
+	appendBase struct {
+		exists  bool
+		size    int64
+		version string
+		cksum   cmn.Cksummer
+	}
+	appendRegistry struct {
+		sync.Mutex
+		t       *targetrunner
+		handles map[string]*appendHandle // handle => appendHandle
+		stopCh  chan struct{}
+	}
+)
+
+func newAppendRegistry(t *targetrunner) *appendRegistry {
+	return &appendRegistry{
+		t:       t,
+		handles: make(map[string]*appendHandle, 16),
+		stopCh:  make(chan struct{}),
+	}
+}
+
+func (reg *appendRegistry) run() {
+	ticker := time.NewTicker(appendSweepPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			reg.sweep(time.Now().Add(-appendHandleTTL))
+		case <-reg.stopCh:
+			return
+		}
+	}
+}
+
+func (reg *appendRegistry) stop() { close(reg.stopCh) }
+
+func (reg *appendRegistry) add(handle string, ah *appendHandle) {
+	reg.Lock()
+	ah.atime = time.Now()
+	reg.handles[handle] = ah
+	reg.Unlock()
+}
+
+func (reg *appendRegistry) get(handle, uname string) (*appendHandle, error) {
+	reg.Lock()
+	defer reg.Unlock()
+	ah, ok := reg.handles[handle]
+	if !ok || ah.uname != uname {
+		return nil, fmt.Errorf("append handle %q (%s) %s", handle, uname, cmn.DoesNotExist)
+	}
+	ah.atime = time.Now()
+	return ah, nil
+}
+
+// returns false if the handle has already been removed (e.g., expired)
+func (reg *appendRegistry) del(handle string) bool {
+	reg.Lock()
+	_, ok := reg.handles[handle]
+	delete(reg.handles, handle)
+	reg.Unlock()
+	return ok
+}
+
+// removes the handles not used since `unused` along with their workfiles
+func (reg *appendRegistry) sweep(unused time.Time) {
+	expired := make([]*appendHandle, 0, 4)
+	reg.Lock()
+	for handle, ah := range reg.handles {
+		if ah.atime.Before(unused) {
+			delete(reg.handles, handle)
+			expired = append(expired, ah)
+		}
+	}
+	reg.Unlock()
+	for _, ah := range expired {
+		// wait for the append in progress, if any
+		reg.t.rtnamemap.Lock(ah.workFQN, true)
+		if err := os.Remove(ah.workFQN); err != nil && !os.IsNotExist(err) {
+			glog.Errorf("failed to remove %s, err: %v", ah.workFQN, err)
+		}
+		reg.t.rtnamemap.Unlock(ah.workFQN, true)
+		glog.Infof("%s: append handle expired (%s appended)", ah.uname, cmn.B2S(ah.size, 1))
+	}
+}
+
+func newAppendBase(lom *cluster.LOM) appendBase {
+	if !lom.Exists() {
+		return appendBase{}
+	}
+	return appendBase{exists: true, size: lom.Size(), version: lom.Version(), cksum: lom.Cksum()}
+}
+
+func (b *appendBase) equal(other *appendBase) bool {
+	if b.exists != other.exists || b.size != other.size || b.version != other.version {
+		return false
+	}
+	// a missing checksum may get computed and stored in the meantime (e.g., by GET)
+	return b.cksum == nil || other.cksum == nil || cmn.EqCksum(b.cksum, other.cksum)
+}
+
+// PUT /v1/objects/bucket-name/object-name?appendty=append|flush[&handle=...]
+func (t *targetrunner) appendObject(w http.ResponseWriter, r *http.Request, lom *cluster.LOM, appendTy string) {
+	var (
+		ah     *appendHandle
+		err    error
+		handle = r.URL.Query().Get(cmn.URLParamHandle)
+	)
+	if !lom.BckIsLocal {
+		t.invalmsghdlr(w, r, fmt.Sprintf("%s: append is supported only for local buckets", lom))
+		return
+	}
+	switch appendTy {
+	case cmn.AppendOp:
+		if handle == "" {
+			handle, ah, err = t.newAppendHandle(lom)
+			if err != nil {
+				t.invalmsghdlr(w, r, err.Error())
+				return
+			}
+		} else if ah, err = t.appends.get(handle, lom.Uname()); err != nil {
+			t.invalmsghdlr(w, r, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err = t.appendData(ah, r.Body); err != nil {
+			t.invalmsghdlr(w, r, fmt.Sprintf("%s: %v", lom, err))
+			return
+		}
+		w.Header().Set(cmn.HeaderAppendHandle, handle)
+	case cmn.FlushOp:
+		if handle == "" {
+			t.invalmsghdlr(w, r, fmt.Sprintf("%s: append handle is not specified", lom))
+			return
+		}
+		if ah, err = t.appends.get(handle, lom.Uname()); err != nil {
+			t.invalmsghdlr(w, r, err.Error(), http.StatusNotFound)
+			return
+		}
+		customMD, err := cmn.CustomMDFromHeader(r.Header)
+		if err != nil {
+			t.invalmsghdlr(w, r, fmt.Sprintf("%s: %v", lom, err))
+			return
+		}
+		if errstr, errCode := t.flushAppend(r, lom, handle, ah, customMD); errstr != "" {
+			t.invalmsghdlr(w, r, errstr, errCode)
+			return
+		}
+		if cksum := lom.Cksum(); cksum != nil {
+			ty, val := cksum.Get()
+			w.Header().Set(cmn.HeaderObjCksumType, ty)
+			w.Header().Set(cmn.HeaderObjCksumVal, val)
+		}
+	default:
+		t.invalmsghdlr(w, r, fmt.Sprintf("invalid append type %q (expecting %q or %q)",
+			appendTy, cmn.AppendOp, cmn.FlushOp))
+	}
+}
+
+// creates a new append handle along with its workfile; the workfile starts
+// out as a copy of the current object, if the latter exists
+func (t *targetrunner) newAppendHandle(lom *cluster.LOM) (handle string, ah *appendHandle, err error) {
+	if handle, err = cmn.GenUUID(); err != nil {
+		return
+	}
+	ah = &appendHandle{uname: lom.Uname(), workFQN: lom.GenFQN(fs.WorkfileType, fs.WorkfileAppend)}
+
+	t.rtnamemap.Lock(lom.Uname(), false)
+	if _, errstr := lom.Load(true); errstr != "" {
+		t.rtnamemap.Unlock(lom.Uname(), false)
+		return "", nil, errors.New(errstr)
+	}
+	if lom.Exists() {
+		var file cluster.ObjReader
+		buf, slab := gmem2.AllocFromSlab2(0)
+		if file, err = lom.Open(lom.FQN); err == nil { // decrypted, if need be
+			_, err = cmn.SaveReader(ah.workFQN, file, buf, false)
+			file.Close()
+		}
+		slab.Free(buf)
+		ah.size = lom.Size()
+		ah.customMD = lom.CustomMD()
+		ah.base = newAppendBase(lom)
+	} else {
+		var file *os.File
+		if file, err = cmn.CreateFile(ah.workFQN); err == nil {
+			err = file.Close()
+		}
+	}
+	t.rtnamemap.Unlock(lom.Uname(), false)
+
+	if err != nil {
+		t.fshc(err, ah.workFQN)
+		os.Remove(ah.workFQN)
+		return "", nil, err
+	}
+	t.appends.add(handle, ah)
+	return
+}
+
+// appends data to the handle's workfile; concurrent appenders are serialized
+// by locking the workfile's name, and a failed append is rolled back
+func (t *targetrunner) appendData(ah *appendHandle, reader io.ReadCloser) (err error) {
+	var (
+		file    *os.File
+		written int64
+	)
+	defer reader.Close()
+	t.rtnamemap.Lock(ah.workFQN, true)
+	defer t.rtnamemap.Unlock(ah.workFQN, true)
+
+	if file, err = os.OpenFile(ah.workFQN, os.O_WRONLY|os.O_APPEND, 0644); err != nil {
+		return
+	}
+	buf, slab := gmem2.AllocFromSlab2(0)
+	written, err = io.CopyBuffer(file, reader, buf)
+	slab.Free(buf)
+	if err != nil {
+		if errTrunc := file.Truncate(ah.size); errTrunc != nil {
+			glog.Errorf("Nested (%v): failed to truncate %s, err: %v", err, ah.workFQN, errTrunc)
+		}
+		file.Close()
+		t.fshc(err, ah.workFQN)
+		return
+	}
+	if err = file.Close(); err != nil {
+		return
+	}
+	ah.size += written
+	return
+}
+
+// commits the appended content as the new version of the object
+func (t *targetrunner) flushAppend(r *http.Request, lom *cluster.LOM, handle string, ah *appendHandle,
+	customMD cmn.SimpleKVs) (errstr string, errCode int) {
+	// wait for the appends in progress, if any, and keep out the new ones
+	t.rtnamemap.Lock(ah.workFQN, true)
+	defer t.rtnamemap.Unlock(ah.workFQN, true)
+	if !t.appends.del(handle) {
+		return fmt.Sprintf("append handle %q (%s) %s", handle, lom, cmn.DoesNotExist), http.StatusNotFound
+	}
+
+	if lom.VerConf().Enabled {
+		lom.Load(true) // need to know the current version if versioning enabled
+	}
+	lom.SetSize(ah.size)
+	lom.SetCksum(nil)
+	lom.SetCustomMD(ah.customMD)
+	if customMD != nil {
+		lom.SetCustomMD(customMD) // overrides the one of the original object
+	}
+	if ckConf := lom.CksumConf(); ckConf.Type != cmn.ChecksumNone {
+		cmn.AssertMsg(ckConf.Type == cmn.ChecksumXXHash, ckConf.Type)
+		file, err := os.Open(ah.workFQN)
+		if err != nil {
+			return fmt.Sprintf("%s: failed to open %s, err: %v", lom, ah.workFQN, err), http.StatusInternalServerError
+		}
+		buf, slab := gmem2.AllocFromSlab2(ah.size)
+		val, errstr := cmn.ComputeXXHash(file, buf)
+		slab.Free(buf)
+		file.Close()
+		if errstr != "" {
+			os.Remove(ah.workFQN)
+			return fmt.Sprintf("%s: %s", lom, errstr), http.StatusInternalServerError
+		}
+		cksum := cmn.NewCksum(cmn.ChecksumXXHash, val)
+		if expected := cmn.NewCksum(r.Header.Get(cmn.HeaderObjCksumType),
+			r.Header.Get(cmn.HeaderObjCksumVal)); expected != nil && !cmn.EqCksum(expected, cksum) {
+			os.Remove(ah.workFQN)
+			return fmt.Sprintf("%s: bad checksum - expected %s, got: %s",
+				lom, expected.String(), cksum.String()), http.StatusBadRequest
+		}
+		lom.SetCksum(cksum)
+	}
+	workFQN := ah.workFQN
+	lom.SetKeyID(lom.EncryptKeyID())
+	if lom.Encrypted() {
+		workFQN = lom.GenFQN(fs.WorkfileType, fs.WorkfileAppend)
+		err := encryptFile(ah.workFQN, workFQN, lom.KeyID())
+		os.Remove(ah.workFQN)
+		if err != nil {
+			os.Remove(workFQN)
+			return fmt.Sprintf("%s: failed to encrypt, err: %v", lom, err), http.StatusInternalServerError
+		}
+	}
+	roi := &recvObjInfo{
+		t:       t,
+		lom:     lom,
+		workFQN: workFQN,
+		ctx:     t.contextWithAuth(r.Header),
+		precond: func() (string, int) { return t.checkAppendBase(lom, ah) },
+	}
+	if errstr, errCode = roi.commit(); errstr != "" {
+		return
+	}
+	if glog.FastV(4, glog.SmoduleAIS) {
+		glog.Infof("%s: flushed append handle %q (%s)", lom, handle, cmn.B2S(ah.size, 1))
+	}
+	return
+}
+
+// the object must be the one the handle's workfile started out as a copy of;
+// must be called under the object's exclusive lock
+func (t *targetrunner) checkAppendBase(lom *cluster.LOM, ah *appendHandle) (errstr string, errCode int) {
+	cur, errstr := cluster.LOM{T: t, Bucket: lom.Bucket, Objname: lom.Objname, BucketProvider: lom.BucketProvider}.Init()
+	if errstr == "" {
+		_, errstr = cur.Load(true)
+	}
+	if errstr != "" {
+		return errstr, http.StatusInternalServerError
+	}
+	if base := newAppendBase(cur); !base.equal(&ah.base) {
+		return fmt.Sprintf("%s: the object has been modified since append handle was created", lom), http.StatusConflict
+	}
+	return
+}
+
+// POST { ActConcat } /v1/objects/bucket-name/object-name
+func (t *targetrunner) concatObjects(w http.ResponseWriter, r *http.Request, lom *cluster.LOM, msg *cmn.ActionMsg) {
+	started := time.Now()
+	concatMsg := &cmn.ConcatMsg{}
+	b, err := jsoniter.Marshal(msg.Value)
+	if err == nil {
+		err = jsoniter.Unmarshal(b, concatMsg)
+	}
+	if err != nil {
+		t.invalmsghdlr(w, r, fmt.Sprintf("unable to parse %s request: %v", msg.Action, msg.Value))
+		return
+	}
+	if len(concatMsg.Objnames) == 0 {
+		t.invalmsghdlr(w, r, fmt.Sprintf("%s: no objects to concatenate", lom))
+		return
+	}
+	for _, objname := range concatMsg.Objnames {
+		if objname == "" {
+			t.invalmsghdlr(w, r, fmt.Sprintf("%s: object name cannot be empty", lom))
+			return
+		}
+	}
+	if lom.BckIsLocal && lom.VerConf().Enabled {
+		lom.Load(true) // need to know the current version if versioning enabled
+	}
+	customMD, err := cmn.CustomMDFromHeader(r.Header)
+	if err != nil {
+		t.invalmsghdlr(w, r, fmt.Sprintf("%s: %v", lom, err))
+		return
+	}
+	lom.SetCustomMD(customMD)
+	reader := &concatReader{t: t, lom: lom, objnames: concatMsg.Objnames, auth: r.Header.Get("Authorization")}
+	roi := &recvObjInfo{
+		t:   t,
+		lom: lom,
+		r:   reader,
+		ctx: t.contextWithAuth(r.Header),
+	}
+	roi.init()
+	if err, errCode := roi.recv(); err != nil {
+		t.invalmsghdlr(w, r, err.Error(), errCode)
+		return
+	}
+	if cksum := lom.Cksum(); cksum != nil {
+		ty, val := cksum.Get()
+		w.Header().Set(cmn.HeaderObjCksumType, ty)
+		w.Header().Set(cmn.HeaderObjCksumVal, val)
+	}
+	glog.Infof("%s: concatenated %d objects (%s) in %v", lom, len(concatMsg.Objnames),
+		cmn.B2S(lom.Size(), 1), time.Since(started))
+}
+
+// concatReader reads the source objects back-to-back, opening each one only
+// when the previous one is fully read
+type concatReader struct {
+	t        *targetrunner
+	lom      *cluster.LOM // destination
+	objnames []string
+	auth     string // authorization header to forward to other targets
+	idx      int
+	cur      io.ReadCloser
+}
+
+func (cr *concatReader) Read(b []byte) (n int, err error) {
+	for {
+		if cr.cur == nil {
+			if cr.idx >= len(cr.objnames) {
+				return 0, io.EOF
+			}
+			if cr.cur, err = cr.open(cr.objnames[cr.idx]); err != nil {
+				return 0, err
+			}
+			cr.idx++
+		}
+		n, err = cr.cur.Read(b)
+		if err == io.EOF {
+			cr.cur.Close()
+			cr.cur = nil
+			err = nil
+			if n == 0 {
+				continue
+			}
+		}
+		return
+	}
+}
+
+func (cr *concatReader) Close() (err error) {
+	if cr.cur != nil {
+		err = cr.cur.Close()
+		cr.cur = nil
+	}
+	return
+}
+
+// opens the source object: locally, if this target owns it and has it,
+// otherwise via GET from the owner target (which also takes care of cold GET)
+func (cr *concatReader) open(objname string) (io.ReadCloser, error) {
+	var (
+		t      = cr.t
+		bucket = cr.lom.Bucket
+	)
+	si, errstr := hrwTarget(bucket, objname, t.smapowner.get())
+	if errstr != "" {
+		return nil, errors.New(errstr)
+	}
+	if si.DaemonID == t.si.DaemonID {
+		lom, errstr := cluster.LOM{T: t, Bucket: bucket, Objname: objname, BucketProvider: cr.lom.BucketProvider}.Init()
+		if errstr != "" {
+			return nil, errors.New(errstr)
+		}
+		t.rtnamemap.Lock(lom.Uname(), false)
+		if _, errstr = lom.Load(true); errstr != "" {
+			t.rtnamemap.Unlock(lom.Uname(), false)
+			return nil, errors.New(errstr)
+		}
+		if lom.Exists() {
+			file, err := lom.Open(lom.LoadBalanceGET())
+			if err != nil {
+				t.rtnamemap.Unlock(lom.Uname(), false)
+				return nil, err
+			}
+			return &lockedFile{ObjReader: file, t: t, uname: lom.Uname()}, nil
+		}
+		t.rtnamemap.Unlock(lom.Uname(), false)
+	}
+	query := url.Values{}
+	query.Add(cmn.URLParamBckProvider, cr.lom.BucketProvider)
+	geturl := si.PublicNet.DirectURL + cmn.URLPath(cmn.Version, cmn.Objects, bucket, objname) + "?" + query.Encode()
+	req, err := http.NewRequest(http.MethodGet, geturl, nil)
+	if err != nil {
+		return nil, err
+	}
+	if cr.auth != "" {
+		req.Header.Set("Authorization", cr.auth)
+	}
+	resp, err := t.httpclientLongTimeout.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to GET %s/%s from %s, err: %v", bucket, objname, si, err)
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to GET %s/%s from %s, status: %d", bucket, objname, si, resp.StatusCode)
+	}
+	return resp.Body, nil
+}
+
+// lockedFile holds the object's read lock until closed
+type lockedFile struct {
+	cluster.ObjReader
+	t     *targetrunner
+	uname string
+}
+
+func (lf *lockedFile) Close() error {
+	err := lf.ObjReader.Close()
+	lf.t.rtnamemap.Unlock(lf.uname, false)
+	return err
+}
+
+// encrypts the (plaintext) file with the given key
+func encryptFile(srcFQN, dstFQN, keyID string) (err error) {
+	var (
+		src, dst *os.File
+		w        *encryption.Writer
+		key      []byte
+	)
+	if key, err = encryption.Key(keyID); err != nil {
+		return
+	}
+	if src, err = os.Open(srcFQN); err != nil {
+		return
+	}
+	defer src.Close()
+	if dst, err = cmn.CreateFile(dstFQN); err != nil {
+		return
+	}
+	buf, slab := gmem2.AllocFromSlab2(0)
+	defer slab.Free(buf)
+	if w, err = encryption.NewWriter(dst, key); err == nil {
+		if _, err = io.CopyBuffer(w, src, buf); err == nil {
+			err = w.Close()
+		}
+	}
+	if errClose := dst.Close(); err == nil {
+		err = errClose
+	}
+	return
+}
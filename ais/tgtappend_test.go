@@ -0,0 +1,75 @@
+// Package ais provides core functionality for the AIStore object storage.
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package ais
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NVIDIA/aistore/cmn"
+)
+
+func TestAppendSweep(t *testing.T) {
+	dir, err := ioutil.TempDir("", "append-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	tgt := &targetrunner{rtnamemap: newrtnamemap()}
+	reg := newAppendRegistry(tgt)
+
+	handles := []string{"idle", "active"}
+	for _, handle := range handles {
+		workFQN := filepath.Join(dir, handle)
+		if err := ioutil.WriteFile(workFQN, []byte(handle), 0644); err != nil {
+			t.Fatal(err)
+		}
+		reg.add(handle, &appendHandle{uname: "b/" + handle, workFQN: workFQN})
+	}
+	reg.handles["idle"].atime = time.Now().Add(-2 * appendHandleTTL)
+
+	reg.sweep(time.Now().Add(-appendHandleTTL))
+	if _, err := reg.get("idle", "b/idle"); err == nil {
+		t.Errorf("expected the idle handle to expire")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "idle")); !os.IsNotExist(err) {
+		t.Errorf("expected the workfile of the idle handle to be removed, err: %v", err)
+	}
+	if _, err := reg.get("active", "b/active"); err != nil {
+		t.Error(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "active")); err != nil {
+		t.Error(err)
+	}
+	if reg.del("idle") || !reg.del("active") {
+		t.Errorf("expected only the active handle to be deleted")
+	}
+}
+
+func TestAppendBase(t *testing.T) {
+	var (
+		cksum = cmn.NewCksum(cmn.ChecksumXXHash, "01234567")
+		base  = appendBase{exists: true, size: 10, version: "1", cksum: cksum}
+	)
+	tests := []struct {
+		other appendBase
+		equal bool
+	}{
+		{base, true},
+		{appendBase{exists: true, size: 10, version: "1"}, true}, // checksum computed in the meantime
+		{appendBase{}, false},
+		{appendBase{exists: true, size: 11, version: "1", cksum: cksum}, false},
+		{appendBase{exists: true, size: 10, version: "2", cksum: cksum}, false},
+		{appendBase{exists: true, size: 10, version: "1", cksum: cmn.NewCksum(cmn.ChecksumXXHash, "76543210")}, false},
+	}
+	for i, tst := range tests {
+		if eq := base.equal(&tst.other); eq != tst.equal {
+			t.Errorf("#%d: expected %t, got %t", i, tst.equal, eq)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+// Package ais provides core functionality for the AIStore object storage.
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package ais
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/NVIDIA/aistore/3rdparty/glog"
+	"github.com/NVIDIA/aistore/cluster"
+	"github.com/NVIDIA/aistore/cmn"
+	"github.com/NVIDIA/aistore/dsort/extract"
+	"github.com/NVIDIA/aistore/fs"
+	"github.com/NVIDIA/aistore/stats"
+	jsoniter "github.com/json-iterator/go"
+)
+
+//
+// GET ?archpath=<member> reads a single file (member) of an archive object.
+// Archives are parsed with the same readers dsort uses. For uncompressed
+// tarballs, the first such GET also indexes all the members and stores the
+// index next to the object, so that the subsequent GETs read the member
+// directly from its offset. The index is valid as long as the object's size,
+// checksum and version do not change.
+//
+
+const archIndexType = "archidx" // content type: index of the tarball's members
+
+type (
+	archIndexSpec struct{}
+
+	archIndex struct {
+		Size    int64               `json:"size"`
+		Cksum   string              `json:"cksum"`
+		Version string              `json:"version,omitempty"`
+		Members extract.MemberIndex `json:"members"`
+	}
+)
+
+var _ fs.ContentResolver = &archIndexSpec{}
+
+func (*archIndexSpec) PermToMove() bool                        { return false }
+func (*archIndexSpec) PermToEvict() bool                       { return true }
+func (*archIndexSpec) PermToProcess() bool                     { return false }
+func (*archIndexSpec) GenUniqueFQN(base, prefix string) string { return base }
+func (*archIndexSpec) ParseUniqueFQN(base string) (orig string, old bool, ok bool) {
+	return base, false, true
+}
+
+// reads the member of the archive and streams it back; must be called under the object's read lock
+func (t *targetrunner) objGetArchMember(w http.ResponseWriter, lom *cluster.LOM, archPath string,
+	started time.Time) (errstr string, errcode int) {
+	var (
+		written int64
+		index   extract.MemberIndex
+		ext     = extract.ArchiveExt(lom.Objname)
+	)
+	if ext == "" {
+		return fmt.Sprintf("%s: not an archive (expecting one of: %s, %s, %s, %s)", lom,
+			extract.ExtTar, extract.ExtTgz, extract.ExtTarTgz, extract.ExtZip), http.StatusBadRequest
+	}
+	fqn := lom.LoadBalanceGET()
+	file, err := lom.Open(fqn)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			t.fshc(err, fqn)
+		}
+		return fmt.Sprintf("%s: err: %v", lom, err), http.StatusInternalServerError
+	}
+	defer file.Close()
+
+	if idx := t.loadArchIndex(lom); idx != nil {
+		loc, ok := idx.Members[archPath]
+		if !ok {
+			err = extract.ErrMemberNotFound
+		} else {
+			buf, slab := gmem2.AllocFromSlab2(cmn.MinI64(loc.Size, 8*cmn.MiB))
+			written, err = io.CopyBuffer(w, io.NewSectionReader(file, loc.Offset, loc.Size), buf)
+			slab.Free(buf)
+		}
+	} else {
+		written, index, err = extract.ReadMember(io.NewSectionReader(file, 0, lom.Size()), ext, archPath, w)
+		if index != nil {
+			t.storeArchIndex(lom, index)
+		}
+	}
+	if err != nil {
+		if err == extract.ErrMemberNotFound {
+			return fmt.Sprintf("%s: %q %s", lom, archPath, cmn.DoesNotExist), http.StatusNotFound
+		}
+		t.statsif.Add(stats.ErrGetCount, 1)
+		return fmt.Sprintf("%s: failed to read %q, err: %v", lom, archPath, err), http.StatusInternalServerError
+	}
+	delta := time.Since(started)
+	if glog.FastV(4, glog.SmoduleAIS) {
+		glog.Infof("GET: %s[%s] (%s), %d µs", lom, archPath, cmn.B2S(written, 1), int64(delta/time.Microsecond))
+	}
+	t.statsif.AddMany(
+		stats.NamedVal64{Name: stats.GetThroughput, Val: written},
+		stats.NamedVal64{Name: stats.GetLatency, Val: int64(delta)},
+		stats.NamedVal64{Name: stats.GetCount, Val: 1},
+	)
+	return
+}
+
+// returns the index if it exists and is up-to-date
+func (t *targetrunner) loadArchIndex(lom *cluster.LOM) *archIndex {
+	if lom.Cksum() == nil {
+		return nil
+	}
+	b, err := ioutil.ReadFile(lom.GenFQN(archIndexType, ""))
+	if err != nil {
+		return nil
+	}
+	idx := &archIndex{}
+	if err = jsoniter.Unmarshal(b, idx); err != nil {
+		glog.Warningf("%s: failed to load archive index, err: %v", lom, err)
+		return nil
+	}
+	if _, cksum := lom.Cksum().Get(); idx.Size != lom.Size() || idx.Cksum != cksum || idx.Version != lom.Version() {
+		return nil
+	}
+	return idx
+}
+
+// best effort: an index that fails to store gets rebuilt next time
+func (t *targetrunner) storeArchIndex(lom *cluster.LOM, members extract.MemberIndex) {
+	if lom.Cksum() == nil {
+		return
+	}
+	_, cksum := lom.Cksum().Get()
+	idx := &archIndex{Size: lom.Size(), Cksum: cksum, Version: lom.Version(), Members: members}
+	b, err := jsoniter.Marshal(idx)
+	if err != nil {
+		glog.Errorf("%s: failed to marshal archive index, err: %v", lom, err)
+		return
+	}
+	workFQN := lom.GenFQN(fs.WorkfileType, fs.WorkfileArchIndex)
+	file, err := cmn.CreateFile(workFQN)
+	if err == nil {
+		_, err = file.Write(b)
+		if errClose := file.Close(); err == nil {
+			err = errClose
+		}
+	}
+	if err == nil {
+		err = cmn.MvFile(workFQN, lom.GenFQN(archIndexType, ""))
+	}
+	if err != nil {
+		os.Remove(workFQN)
+		glog.Errorf("%s: failed to store archive index, err: %v", lom, err)
+	}
+}
+
+// removes the index (if any) of the object that is about to be deleted
+func delArchIndex(lom *cluster.LOM) {
+	if extract.ArchiveExt(lom.Objname) != extract.ExtTar {
+		return
+	}
+	if err := os.Remove(lom.GenFQN(archIndexType, "")); err != nil && !os.IsNotExist(err) {
+		glog.Warningf("%s: failed to remove archive index, err: %v", lom, err)
+	}
+}
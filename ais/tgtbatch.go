@@ -0,0 +1,148 @@
+// Package ais provides core functionality for the AIStore object storage.
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package ais
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/NVIDIA/aistore/3rdparty/glog"
+	"github.com/NVIDIA/aistore/cluster"
+	"github.com/NVIDIA/aistore/cmn"
+	"github.com/NVIDIA/aistore/stats"
+	jsoniter "github.com/json-iterator/go"
+)
+
+// POST { action: getbatch } /v1/buckets/bucket-name (from the proxy - see proxybatch.go)
+//
+// Streams back the requested objects as a TAR archive, in the requested order.
+// All the objects are looked up (and, if need be, restored or cold-GET-ed)
+// before anything is sent, so that a missing object fails the request with
+// a proper status - unless missing objects are to be skipped.
+func (t *targetrunner) getBatch(w http.ResponseWriter, r *http.Request, bucket, bckProvider string,
+	msgInt *actionMsgInternal) {
+	var (
+		started  = time.Now()
+		batchMsg = &cmn.GetBatchMsg{}
+		config   = cmn.GCO.Get()
+		ct       = t.contextWithAuth(r.Header)
+		written  int64
+	)
+	b, err := jsoniter.Marshal(msgInt.Value)
+	if err == nil {
+		err = jsoniter.Unmarshal(b, batchMsg)
+	}
+	if err != nil {
+		t.invalmsghdlr(w, r, fmt.Sprintf("unable to parse %s request: %v", msgInt.Action, msgInt.Value))
+		return
+	}
+	loms := make([]*cluster.LOM, 0, len(batchMsg.Objnames))
+	for _, objname := range batchMsg.Objnames {
+		lom, errstr := cluster.LOM{T: t, Bucket: bucket, Objname: objname, BucketProvider: bckProvider}.Init(config)
+		if errstr != "" {
+			t.invalmsghdlr(w, r, errstr)
+			return
+		}
+		if errstr, errcode := t.prepBatchObj(ct, r, lom); errstr != "" {
+			if errcode == http.StatusNotFound && batchMsg.SkipMissing {
+				continue
+			}
+			t.invalmsghdlr(w, r, errstr, errcode)
+			return
+		}
+		loms = append(loms, lom)
+	}
+
+	w.Header().Set("Content-Type", "application/x-tar")
+	tw := tar.NewWriter(w)
+	buf, slab := gmem2.AllocFromSlab2(0)
+	defer slab.Free(buf)
+	for _, lom := range loms {
+		n, err := t.writeBatchObj(tw, lom, buf)
+		if err != nil {
+			if os.IsNotExist(err) && batchMsg.SkipMissing { // removed in the meantime
+				continue
+			}
+			// the status is already sent - abort the connection to let the proxy know
+			glog.Errorf("%s: failed to GET batch (%s), err: %v", t.si, lom, err)
+			t.statsif.Add(stats.ErrGetCount, 1)
+			panic(http.ErrAbortHandler)
+		}
+		written += n
+	}
+	if err := tw.Close(); err != nil {
+		glog.Errorf("%s: failed to GET batch from %s, err: %v", t.si, bucket, err)
+		panic(http.ErrAbortHandler)
+	}
+	delta := time.Since(started)
+	if glog.FastV(4, glog.SmoduleAIS) {
+		glog.Infof("GET batch: %s, %d objects (%s), %d µs", bucket, len(loms), cmn.B2S(written, 1),
+			int64(delta/time.Microsecond))
+	}
+	t.statsif.AddMany(
+		stats.NamedVal64{Name: stats.GetThroughput, Val: written},
+		stats.NamedVal64{Name: stats.GetCount, Val: int64(len(loms))},
+	)
+}
+
+// makes sure the object is present locally, the same way GET does
+func (t *targetrunner) prepBatchObj(ct context.Context, r *http.Request, lom *cluster.LOM) (errstr string, errcode int) {
+	t.rtnamemap.Lock(lom.Uname(), false)
+	if _, errstr = lom.Load(true); errstr != "" {
+		t.rtnamemap.Unlock(lom.Uname(), false)
+		return
+	}
+	if lom.Exists() {
+		t.rtnamemap.Unlock(lom.Uname(), false)
+		return
+	}
+	if lom.BckIsLocal {
+		errstr, errcode = t.restoreObjLBNeigh(lom, r)
+		t.rtnamemap.Unlock(lom.Uname(), false)
+		return
+	}
+	t.rtnamemap.Unlock(lom.Uname(), false)
+	if errstr, errcode = t.GetCold(ct, lom, false); errstr != "" {
+		return
+	}
+	t.rtnamemap.Unlock(lom.Uname(), false) // GetCold keeps the read lock
+	t.putMirror(lom)
+	return
+}
+
+// writes the object into the archive under the object's read lock
+func (t *targetrunner) writeBatchObj(tw *tar.Writer, lom *cluster.LOM, buf []byte) (n int64, err error) {
+	t.rtnamemap.Lock(lom.Uname(), false)
+	defer t.rtnamemap.Unlock(lom.Uname(), false)
+	if _, errstr := lom.Load(true); errstr != "" {
+		return 0, errors.New(errstr)
+	}
+	if !lom.Exists() {
+		return 0, os.ErrNotExist
+	}
+	file, err := lom.Open(lom.LoadBalanceGET())
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	hdr := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     lom.Objname,
+		Size:     lom.Size(),
+		Mode:     0644,
+		ModTime:  lom.Atime(),
+	}
+	if err = tw.WriteHeader(hdr); err != nil {
+		return
+	}
+	n, err = io.CopyBuffer(tw, file, buf)
+	return
+}
@@ -0,0 +1,343 @@
+// Package ais provides core functionality for the AIStore object storage.
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package ais
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/NVIDIA/aistore/3rdparty/glog"
+	"github.com/NVIDIA/aistore/cluster"
+	"github.com/NVIDIA/aistore/cmn"
+	"github.com/NVIDIA/aistore/mirror"
+	"github.com/NVIDIA/aistore/stats"
+	"github.com/NVIDIA/aistore/transport"
+	jsoniter "github.com/json-iterator/go"
+)
+
+//
+// Copying objects into another bucket - local or Cloud, of the same or different
+// provider. Each object is sent to the target that owns (by HRW) its copy in
+// the destination bucket; the receiving target stores the copy the same way PUT
+// does - in particular, it PUTs the copy into the Cloud if the destination is a
+// Cloud bucket. Copying the entire bucket is done by the copy-bucket xaction
+// (see mirror.XactBckCopy) that runs on every target and traverses the objects
+// stored locally; when copying a Cloud bucket, each target first lists the bucket
+// and fetches (cold-GETs) the objects it owns but does not store.
+//
+
+const (
+	copyStreamName = "copy"
+
+	maxCopyTemplateObjects = 1000000 // max number of object names a template can expand to
+)
+
+type copyManager struct {
+	t       *targetrunner
+	streams *transport.StreamBundle
+}
+
+func (t *targetrunner) setupCopyManager() error {
+	network := cmn.NetworkIntraData
+	if !cmn.GCO.Get().Net.UseIntraData {
+		network = cmn.NetworkPublic
+	}
+	t.copyManager = &copyManager{t: t}
+	if _, err := transport.Register(network, copyStreamName, t.copyManager.recvObj); err != nil {
+		return err
+	}
+	sbArgs := transport.SBArgs{
+		Network: network,
+		Trname:  copyStreamName,
+	}
+	t.copyManager.streams = transport.NewStreamBundle(t.smapowner, t.si, transport.NewDefaultClient(), sbArgs)
+	return nil
+}
+
+// POST { action: copybck } /v1/buckets/bucket-name
+func (t *targetrunner) copyBucket(w http.ResponseWriter, r *http.Request, bucket, bckProvider string,
+	bckIsLocal bool, msgInt *actionMsgInternal) {
+	cpyMsg := &cmn.CopyBucketMsg{}
+	b, err := jsoniter.Marshal(msgInt.Value)
+	if err == nil {
+		err = jsoniter.Unmarshal(b, cpyMsg)
+	}
+	if err != nil {
+		t.invalmsghdlr(w, r, fmt.Sprintf("unable to parse %s request: %v", msgInt.Action, msgInt.Value))
+		return
+	}
+	dstLocal, err := t.bmdowner.get().ValidateBucket(cpyMsg.Bucket, cpyMsg.BckProvider)
+	if err == nil && cpyMsg.Bucket == bucket {
+		err = errors.New("source and destination buckets must be different")
+	}
+	if err != nil {
+		t.invalmsghdlr(w, r, err.Error())
+		return
+	}
+	match, err := newCopyFilter(cpyMsg)
+	if err != nil {
+		t.invalmsghdlr(w, r, err.Error())
+		return
+	}
+	send := func(lom *cluster.LOM, cb func(err error)) error {
+		return t.copyManager.send(lom, cpyMsg.Bucket, lom.Objname, dstLocal, cb)
+	}
+	var fetch mirror.CopyFetchFunc
+	if !bckIsLocal {
+		ct := t.contextWithAuth(r.Header)
+		fetch = func(x *mirror.XactBckCopy) (int64, error) {
+			return t.copyFetch(ct, x, bucket, bckProvider, cpyMsg.Prefix, match)
+		}
+	}
+	if x := t.xactions.renewBckCopy(bucket, cpyMsg.Bucket, t, bckIsLocal, match, fetch, send); x == nil {
+		t.invalmsghdlr(w, r, fmt.Sprintf("%s: bucket %s is already being copied", t.si, bucket), http.StatusConflict)
+	}
+}
+
+// fetches the objects of the Cloud bucket that this target owns and does not store
+func (t *targetrunner) copyFetch(ct context.Context, x *mirror.XactBckCopy, bucket, bckProvider, prefix string,
+	match func(objname string) bool) (failed int64, err error) {
+	diff, err := t.syncDiff(ct, x, bucket, bckProvider, &cmn.SyncBucketMsg{Prefix: prefix})
+	if err != nil {
+		return 0, err
+	}
+	for _, objname := range diff.report.New {
+		if x.Aborted() {
+			return failed, fmt.Errorf("%s aborted", x)
+		}
+		if match != nil && !match(objname) {
+			continue
+		}
+		lom, errstr := cluster.LOM{T: t, Bucket: bucket, Objname: objname, BucketProvider: bckProvider}.Init()
+		if errstr == "" {
+			errstr, _ = t.GetCold(ct, lom, true)
+		}
+		if errstr == "skip" { // being fetched by GET
+			continue
+		}
+		if errstr != "" {
+			glog.Errorf("%s: %s", x, errstr)
+			failed++
+			continue
+		}
+		t.statsif.AddMany(stats.NamedVal64{stats.PrefetchCount, 1}, stats.NamedVal64{stats.PrefetchSize, lom.Size()})
+	}
+	return
+}
+
+// POST { action: copyobj } /v1/objects/bucket-name/object-name
+func (t *targetrunner) copyObject(w http.ResponseWriter, r *http.Request, lom *cluster.LOM, msg *cmn.ActionMsg) {
+	cpyMsg := &cmn.CopyObjectMsg{}
+	b, err := jsoniter.Marshal(msg.Value)
+	if err == nil {
+		err = jsoniter.Unmarshal(b, cpyMsg)
+	}
+	if err != nil {
+		t.invalmsghdlr(w, r, fmt.Sprintf("unable to parse %s request: %v", msg.Action, msg.Value))
+		return
+	}
+	if cpyMsg.Objname == "" {
+		cpyMsg.Objname = lom.Objname
+	}
+	dstLocal, err := t.bmdowner.get().ValidateBucket(cpyMsg.Bucket, cpyMsg.BckProvider)
+	if err == nil && cpyMsg.Bucket == lom.Bucket {
+		err = errors.New("source and destination buckets must be different")
+	}
+	if err != nil {
+		t.invalmsghdlr(w, r, err.Error())
+		return
+	}
+	if errstr, errcode := t.prepBatchObj(t.contextWithAuth(r.Header), r, lom); errstr != "" {
+		t.invalmsghdlr(w, r, errstr, errcode)
+		return
+	}
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	cb := func(cbErr error) {
+		err = cbErr
+		wg.Done()
+	}
+	if err = t.copyManager.send(lom, cpyMsg.Bucket, cpyMsg.Objname, dstLocal, cb); err == nil {
+		wg.Wait()
+	}
+	if err != nil {
+		t.invalmsghdlr(w, r, fmt.Sprintf("failed to copy %s => %s/%s, err: %v", lom, cpyMsg.Bucket, cpyMsg.Objname, err))
+		return
+	}
+	if glog.FastV(4, glog.SmoduleAIS) {
+		glog.Infof("copied %s => %s/%s", lom, cpyMsg.Bucket, cpyMsg.Objname)
+	}
+}
+
+// sends the object to the target that owns (by HRW) its copy in the destination bucket;
+// the source object is read-locked until sent, the callback gets called upon completion
+// (unless an error is returned)
+func (cm *copyManager) send(lom *cluster.LOM, dstBucket, dstObjname string, dstLocal bool, cb func(err error)) error {
+	si, errstr := hrwTarget(dstBucket, dstObjname, cm.t.smapowner.get())
+	if errstr != "" {
+		return errors.New(errstr)
+	}
+	uname := lom.Uname()
+	cm.t.rtnamemap.Lock(uname, false)
+	file, cksum, err := cm.open(lom)
+	if err != nil {
+		cm.t.rtnamemap.Unlock(uname, false)
+		return err
+	}
+	var (
+		cksumType, cksumValue string
+		size                  = lom.Size()
+	)
+	if cksum != nil {
+		cksumType, cksumValue = cksum.Get()
+	}
+	// copy locally
+	if si.DaemonID == cm.t.si.DaemonID {
+		err = cm.recv(dstBucket, dstObjname, dstLocal, file, cksum, lom.CustomMD())
+		cm.t.rtnamemap.Unlock(uname, false)
+		cb(err)
+		return nil
+	}
+	hdr := transport.Header{
+		Bucket:  dstBucket,
+		Objname: dstObjname,
+		IsLocal: dstLocal,
+		Opaque:  []byte(cm.t.si.DaemonID),
+		ObjAttrs: transport.ObjectAttrs{
+			Size:       size,
+			Atime:      lom.Atime().UnixNano(),
+			CksumType:  cksumType,
+			CksumValue: cksumValue,
+			CustomMD:   lom.CustomMD(),
+		},
+	}
+	sent := func(hdr transport.Header, r io.ReadCloser, err error) {
+		cm.t.rtnamemap.Unlock(uname, false)
+		if err == nil {
+			cm.t.statsif.AddMany(stats.NamedVal64{Name: stats.TxCount, Val: 1},
+				stats.NamedVal64{Name: stats.TxSize, Val: size})
+		}
+		cb(err)
+	}
+	if err = cm.streams.SendV(hdr, file, sent, si); err != nil {
+		cm.t.rtnamemap.Unlock(uname, false)
+	}
+	return err
+}
+
+// opens the object for reading; must be called under the object's read lock
+func (cm *copyManager) open(lom *cluster.LOM) (file cluster.ObjReader, cksum cmn.Cksummer, err error) {
+	var errstr string
+	if _, errstr = lom.Load(true); errstr == "" && !lom.Exists() {
+		errstr = fmt.Sprintf("%s %s", lom, cmn.DoesNotExist)
+	}
+	if errstr == "" {
+		cksum, errstr = lom.CksumComputeIfMissing()
+	}
+	if errstr != "" {
+		return nil, nil, errors.New(errstr)
+	}
+	file, err = lom.Open(lom.FQN)
+	return
+}
+
+func (cm *copyManager) recvObj(w http.ResponseWriter, hdr transport.Header, objReader io.Reader, err error) {
+	if err != nil {
+		glog.Error(err)
+		return
+	}
+	cksum := cmn.NewCksum(hdr.ObjAttrs.CksumType, hdr.ObjAttrs.CksumValue)
+	if err = cm.recv(hdr.Bucket, hdr.Objname, hdr.IsLocal, ioutil.NopCloser(objReader), cksum,
+		hdr.ObjAttrs.CustomMD); err != nil {
+		glog.Errorf("failed to receive copy %s/%s from %s, err: %v", hdr.Bucket, hdr.Objname, hdr.Opaque, err)
+		io.Copy(ioutil.Discard, objReader) // drain the reader
+		return
+	}
+	cm.t.statsif.AddMany(stats.NamedVal64{Name: stats.RxCount, Val: 1},
+		stats.NamedVal64{Name: stats.RxSize, Val: hdr.ObjAttrs.Size})
+}
+
+// stores the copy the same way PUT does; the reader gets closed
+func (cm *copyManager) recv(bucket, objname string, bckIsLocal bool, r io.ReadCloser, cksum cmn.Cksummer,
+	customMD cmn.SimpleKVs) error {
+	lom, errstr := cluster.LOM{T: cm.t, Bucket: bucket, Objname: objname,
+		BucketProvider: cmn.BckProviderFromLocal(bckIsLocal)}.Init()
+	if errstr == "" {
+		_, errstr = lom.Load(true)
+	}
+	if errstr != "" {
+		r.Close()
+		return errors.New(errstr)
+	}
+	if cksum != nil && cmn.EqCksum(lom.Cksum(), cksum) {
+		r.Close() // already copied
+		return nil
+	}
+	lom.SetCustomMD(customMD)
+	roi := &recvObjInfo{
+		t:            cm.t,
+		lom:          lom,
+		r:            r,
+		cksumToCheck: cksum,
+		ctx:          context.Background(),
+	}
+	roi.init()
+	err, _ := roi.recv()
+	return err
+}
+
+// returns the function that selects the objects to copy (nil - all objects)
+func newCopyFilter(msg *cmn.CopyBucketMsg) (match func(objname string) bool, err error) {
+	var (
+		re    *regexp.Regexp
+		names map[string]struct{}
+	)
+	if msg.Regex != "" {
+		if re, err = regexp.Compile(msg.Regex); err != nil {
+			return nil, fmt.Errorf("invalid regex %q: %v", msg.Regex, err)
+		}
+	}
+	if msg.Template != "" {
+		var pt cmn.ParsedTemplate
+		if pt, err = cmn.ParseBashTemplate(msg.Template); err != nil {
+			if pt, err = cmn.ParseAtTemplate(msg.Template); err != nil {
+				return nil, fmt.Errorf("invalid template %q: %v", msg.Template, err)
+			}
+		}
+		if cnt := pt.Count(); cnt > maxCopyTemplateObjects {
+			return nil, fmt.Errorf("template %q: too many objects (%d > %d)", msg.Template, cnt, maxCopyTemplateObjects)
+		}
+		names = make(map[string]struct{}, pt.Count())
+		it := pt.Iter()
+		for name, hasNext := it(); hasNext; name, hasNext = it() {
+			names[name] = struct{}{}
+		}
+	}
+	if msg.Prefix == "" && re == nil && names == nil {
+		return nil, nil
+	}
+	match = func(objname string) bool {
+		if !strings.HasPrefix(objname, msg.Prefix) {
+			return false
+		}
+		if re != nil && !re.MatchString(objname) {
+			return false
+		}
+		if names != nil {
+			if _, ok := names[objname]; !ok {
+				return false
+			}
+		}
+		return true
+	}
+	return
+}
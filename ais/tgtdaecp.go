@@ -347,6 +347,8 @@ func (t *targetrunner) xactsStartRequest(kind, bucket string) error {
 		switch kind {
 		case cmn.ActLRU:
 			go t.RunLRU()
+		case cmn.ActLifecycle:
+			go t.RunLifecycle()
 		case cmn.ActLocalReb:
 			go t.rebManager.runLocalReb()
 		case cmn.ActGlobalReb:
@@ -457,13 +459,16 @@ func (t *targetrunner) httpdaedelete(w http.ResponseWriter, r *http.Request) {
 
 // Decrypts token and retreives userID from request header
 // Returns empty userID in case of token is invalid
-func (t *targetrunner) userFromRequest(header http.Header) (*authRec, error) {
-	token := ""
+func tokenFromHeader(header http.Header) (token string) {
 	tokenParts := strings.SplitN(header.Get("Authorization"), " ", 2)
 	if len(tokenParts) == 2 && tokenParts[0] == tokenStart {
 		token = tokenParts[1]
 	}
+	return
+}
 
+func (t *targetrunner) userFromRequest(header http.Header) (*authRec, error) {
+	token := tokenFromHeader(header)
 	if token == "" {
 		// no token in header = use default credentials
 		return nil, nil
@@ -485,7 +490,19 @@ func (t *targetrunner) contextWithAuth(header http.Header) context.Context {
 	ct := context.Background()
 	config := cmn.GCO.Get()
 
-	if config.Auth.CredDir == "" || !config.Auth.Enabled {
+	// multi-tiering: the tiers the request has been forwarded by (see tgttier.go)
+	if tpath := header.Get(cmn.HeaderTierPath); tpath != "" {
+		ct = context.WithValue(ct, ctxTierPath, tpath)
+	}
+
+	if !config.Auth.Enabled {
+		return ct
+	}
+	// forwarded as is to a remote AIS cluster (see remais.go)
+	if token := tokenFromHeader(header); token != "" {
+		ct = context.WithValue(ct, ctxUserToken, token)
+	}
+	if config.Auth.CredDir == "" {
 		return ct
 	}
 
@@ -637,6 +654,9 @@ func (t *targetrunner) receiveBucketMD(newbucketmd *bucketMD, msgInt *actionMsgI
 	t.xactions.abortBuckets(bucketsToDelete...)
 
 	fs.Mountpaths.CreateDestroyLocalBuckets("receive-bucketmd", false /*false=destroy*/, bucketsToDelete...)
+	for _, bucket := range bucketsToDelete {
+		t.quotas.drop(bucket, true)
+	}
 
 	// Create buckets that have been added
 	bucketsToCreate := make([]string, 0, len(newbucketmd.LBmap))
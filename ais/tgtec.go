@@ -0,0 +1,48 @@
+// Package ais provides core functionality for the AIStore object storage.
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package ais
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// POST { action: ecscrub } /v1/buckets/bucket-name
+func (t *targetrunner) scrubEC(w http.ResponseWriter, r *http.Request, bucket string, bckIsLocal bool,
+	msgInt *actionMsgInternal) {
+	if !t.checkBucketEC(w, r, bucket, bckIsLocal, msgInt) {
+		return
+	}
+	x := t.xactions.renewScrubEC(bucket)
+	if x == nil {
+		t.invalmsghdlr(w, r, fmt.Sprintf("%s: bucket %s is already being scrubbed", t.si, bucket), http.StatusConflict)
+		return
+	}
+	go x.Run()
+}
+
+// POST { action: ecencode } /v1/buckets/bucket-name
+func (t *targetrunner) encodeEC(w http.ResponseWriter, r *http.Request, bucket string, bckIsLocal bool,
+	msgInt *actionMsgInternal) {
+	if !t.checkBucketEC(w, r, bucket, bckIsLocal, msgInt) {
+		return
+	}
+	x := t.xactions.renewEncodeEC(bucket)
+	if x == nil {
+		t.invalmsghdlr(w, r, fmt.Sprintf("%s: bucket %s is already being encoded", t.si, bucket), http.StatusConflict)
+		return
+	}
+	go x.Run()
+}
+
+func (t *targetrunner) checkBucketEC(w http.ResponseWriter, r *http.Request, bucket string, bckIsLocal bool,
+	msgInt *actionMsgInternal) bool {
+	props, ok := t.bmdowner.get().Get(bucket, true)
+	if !bckIsLocal || !ok || !props.EC.Enabled {
+		t.invalmsghdlr(w, r, fmt.Sprintf("%s: erasure coding is not enabled for bucket %s", msgInt.Action, bucket))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,64 @@
+// Package ais provides core functionality for the AIStore object storage.
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package ais
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/NVIDIA/aistore/3rdparty/glog"
+	"github.com/NVIDIA/aistore/cluster"
+)
+
+//
+// Lifecycle actions (see lifecycle package): the lifecycle xaction selects
+// the objects, the target carries out the actions
+//
+
+// delete (or evict) the object along with its replicas, older versions, and EC slices
+func (t *targetrunner) lcDelete(lom *cluster.LOM, evict bool) error {
+	if err := t.objDelete(context.Background(), lom, evict); err != nil {
+		return err
+	}
+	t.ecmanager.CleanupObject(lom)
+	return nil
+}
+
+// transition the object to the next tier: PUT it into the same-name bucket of
+// the next-tier cluster and then remove it from this one (Cloud objects get evicted)
+func (t *targetrunner) lcTransition(lom *cluster.LOM) error {
+	bprops := lom.BckProps
+	if bprops == nil || bprops.NextTierURL == "" {
+		return fmt.Errorf("%s: next tier is not configured", lom)
+	}
+	t.rtnamemap.Lock(lom.Uname(), true)
+	defer t.rtnamemap.Unlock(lom.Uname(), true)
+
+	if _, errstr := lom.Load(false); errstr != "" {
+		return errors.New(errstr)
+	}
+	if !lom.Exists() {
+		return os.ErrNotExist
+	}
+	file, err := os.Open(lom.FQN)
+	if err != nil {
+		return err
+	}
+	_, err, _ = t.putNextTier(context.Background(), file, lom, bprops.NextTierURL, "")
+	file.Close()
+	if err != nil {
+		return err
+	}
+	if err := t.objDeleteLocked(context.Background(), lom, !lom.BckIsLocal); err != nil {
+		return err
+	}
+	t.ecmanager.CleanupObject(lom)
+	if glog.FastV(4, glog.SmoduleAIS) {
+		glog.Infof("%s => %s", lom, bprops.NextTierURL)
+	}
+	return nil
+}
@@ -0,0 +1,431 @@
+// Package ais provides core functionality for the AIStore object storage.
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package ais
+
+import (
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/NVIDIA/aistore/3rdparty/glog"
+	"github.com/NVIDIA/aistore/cluster"
+	"github.com/NVIDIA/aistore/cmn"
+	"github.com/NVIDIA/aistore/fs"
+	"github.com/NVIDIA/aistore/stats"
+	"github.com/OneOfOne/xxhash"
+	jsoniter "github.com/json-iterator/go"
+)
+
+//
+// Multipart upload: the parts are staged as workfiles on the target that
+// owns the object (as per HRW). Complete concatenates the specified parts, in
+// the specified order, into a regular PUT workfile that is then committed
+// exactly like any other PUT - atomically and with the checksum and version
+// recomputed. Uploads that get no parts for mpuUploadTTL are aborted, and
+// their parts are removed. Upload state is kept in memory: the parts left
+// behind by a restart are removed only by LRU (as old workfiles) - that is,
+// if LRU is enabled for the bucket, and when it runs.
+//
+
+const (
+	mpuUploadTTL   = 24 * time.Hour
+	mpuSweepPeriod = 10 * time.Minute
+)
+
+type (
+	mpuPart struct {
+		workFQN string
+		size    int64
+		cksum   cmn.Cksummer
+		quota   *bckUsage // the part's size is reserved against, if not nil
+	}
+	mpuUpload struct {
+		uname      string // object's unique name (see cluster.Bo2Uname)
+		parts      map[int]*mpuPart
+		completing bool      // set by complete/abort, no more parts are accepted
+		atime      time.Time // initiated or last part added
+	}
+	mpuRegistry struct {
+		sync.Mutex
+		uploads map[string]*mpuUpload // upload ID => upload
+		stopCh  chan struct{}
+	}
+)
+
+func newMpuRegistry() *mpuRegistry {
+	return &mpuRegistry{
+		uploads: make(map[string]*mpuUpload, 16),
+		stopCh:  make(chan struct{}),
+	}
+}
+
+func (reg *mpuRegistry) run() {
+	ticker := time.NewTicker(mpuSweepPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			reg.sweep(time.Now().Add(-mpuUploadTTL))
+		case <-reg.stopCh:
+			return
+		}
+	}
+}
+
+func (reg *mpuRegistry) stop() { close(reg.stopCh) }
+
+func (reg *mpuRegistry) init(uname string) (uploadID string, err error) {
+	if uploadID, err = cmn.GenUUID(); err != nil {
+		return
+	}
+	reg.Lock()
+	reg.uploads[uploadID] = &mpuUpload{uname: uname, parts: make(map[int]*mpuPart, 16), atime: time.Now()}
+	reg.Unlock()
+	return
+}
+
+// must be called under lock
+func (reg *mpuRegistry) get(uploadID, uname string) (*mpuUpload, error) {
+	upload, ok := reg.uploads[uploadID]
+	if !ok || upload.uname != uname {
+		return nil, fmt.Errorf("multipart upload %q (%s) %s", uploadID, uname, cmn.DoesNotExist)
+	}
+	if upload.completing {
+		return nil, fmt.Errorf("multipart upload %q (%s) is being completed or aborted", uploadID, uname)
+	}
+	return upload, nil
+}
+
+func (reg *mpuRegistry) addPart(uploadID, uname string, partNum int, part *mpuPart) error {
+	reg.Lock()
+	defer reg.Unlock()
+	upload, err := reg.get(uploadID, uname)
+	if err != nil {
+		return err
+	}
+	if prev, ok := upload.parts[partNum]; ok { // the part is being re-uploaded
+		prev.remove()
+	}
+	upload.parts[partNum] = part
+	upload.atime = time.Now()
+	return nil
+}
+
+// takes the upload out of service; the caller then either deletes it or
+// (e.g., upon failure to complete) puts it back into service via release
+func (reg *mpuRegistry) acquire(uploadID, uname string) (*mpuUpload, error) {
+	reg.Lock()
+	defer reg.Unlock()
+	upload, err := reg.get(uploadID, uname)
+	if err != nil {
+		return nil, err
+	}
+	upload.completing = true
+	return upload, nil
+}
+
+func (reg *mpuRegistry) release(upload *mpuUpload) {
+	reg.Lock()
+	upload.completing = false
+	reg.Unlock()
+}
+
+// removes the upload along with all its parts
+func (reg *mpuRegistry) del(uploadID string, upload *mpuUpload) {
+	reg.Lock()
+	delete(reg.uploads, uploadID)
+	reg.Unlock()
+	for _, part := range upload.parts {
+		part.remove()
+	}
+}
+
+// aborts the uploads that have had no new parts since `unused`, except those
+// being completed
+func (reg *mpuRegistry) sweep(unused time.Time) {
+	expired := make(map[string]*mpuUpload, 4)
+	reg.Lock()
+	for uploadID, upload := range reg.uploads {
+		if !upload.completing && upload.atime.Before(unused) {
+			delete(reg.uploads, uploadID)
+			expired[uploadID] = upload
+		}
+	}
+	reg.Unlock()
+	for uploadID, upload := range expired {
+		for _, part := range upload.parts {
+			part.remove()
+		}
+		glog.Infof("%s: multipart upload %q expired (%d parts)", upload.uname, uploadID, len(upload.parts))
+	}
+}
+
+// removes the part's workfile and releases its quota reservation
+func (part *mpuPart) remove() {
+	if err := os.Remove(part.workFQN); err != nil && !os.IsNotExist(err) {
+		glog.Errorf("failed to remove %s, err: %v", part.workFQN, err)
+	}
+	if part.quota != nil {
+		part.quota.reserved.Sub(part.size)
+		part.quota = nil
+	}
+}
+
+// POST { ActInitMultipart } /v1/objects/bucket-name/object-name
+func (t *targetrunner) initMultipart(w http.ResponseWriter, r *http.Request, lom *cluster.LOM) {
+	uploadID, err := t.mpu.init(lom.Uname())
+	if err != nil {
+		t.invalmsghdlr(w, r, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if glog.FastV(4, glog.SmoduleAIS) {
+		glog.Infof("%s: initiated multipart upload %q", lom, uploadID)
+	}
+	jsbytes, err := jsoniter.Marshal(&cmn.MultipartMsg{UploadID: uploadID})
+	cmn.AssertNoErr(err)
+	t.writeJSON(w, r, jsbytes, "initmultipart")
+}
+
+// PUT /v1/objects/bucket-name/object-name?uploadid=...&partnum=...
+func (t *targetrunner) putPart(w http.ResponseWriter, r *http.Request, lom *cluster.LOM, uploadID string) {
+	var (
+		started   = time.Now()
+		query     = r.URL.Query()
+		header    = r.Header
+		cksumType = header.Get(cmn.HeaderObjCksumType)
+		cksumVal  = header.Get(cmn.HeaderObjCksumVal)
+	)
+	partNum, err := strconv.Atoi(query.Get(cmn.URLParamPartNum))
+	if err != nil || partNum < 1 || partNum > cmn.MaxMultipartParts {
+		s := fmt.Sprintf("%s: invalid part number %q (expecting 1 to %d)",
+			lom, query.Get(cmn.URLParamPartNum), cmn.MaxMultipartParts)
+		t.invalmsghdlr(w, r, s)
+		return
+	}
+	workFQN := lom.GenFQN(fs.WorkfileType, fs.WorkfileMultipart)
+	part, err := t.recvPart(workFQN, r.Body, lom, cmn.NewCksum(cksumType, cksumVal))
+	if err != nil {
+		t.invalmsghdlr(w, r, err.Error())
+		return
+	}
+	part.quota = t.quotas.reserve(lom, part.size)
+	if err := t.mpu.addPart(uploadID, lom.Uname(), partNum, part); err != nil {
+		part.remove()
+		t.invalmsghdlr(w, r, err.Error(), http.StatusNotFound)
+		return
+	}
+	if part.cksum != nil {
+		ty, val := part.cksum.Get()
+		w.Header().Set(cmn.HeaderObjCksumType, ty)
+		w.Header().Set(cmn.HeaderObjCksumVal, val)
+		w.Header().Set("ETag", "\""+val+"\"")
+	}
+	if glog.FastV(4, glog.SmoduleAIS) {
+		glog.Infof("%s: upload %q part #%d (%s): %d µs", lom, uploadID, partNum, cmn.B2S(part.size, 1),
+			int64(time.Since(started)/time.Microsecond))
+	}
+}
+
+// receives a single part and validates its checksum, if provided by the sender
+func (t *targetrunner) recvPart(workFQN string, reader io.ReadCloser, lom *cluster.LOM,
+	expectedCksum cmn.Cksummer) (part *mpuPart, err error) {
+	var (
+		file    *os.File
+		written int64
+		hashes  []hash.Hash
+		ckConf  = lom.CksumConf()
+	)
+	defer reader.Close()
+	if file, err = cmn.CreateFile(workFQN); err != nil {
+		t.fshc(err, workFQN)
+		return nil, fmt.Errorf("failed to create %s, err: %v", workFQN, err)
+	}
+	xx := xxhash.New64()
+	if ckConf.Type != cmn.ChecksumNone {
+		cmn.AssertMsg(ckConf.Type == cmn.ChecksumXXHash, ckConf.Type)
+		hashes = []hash.Hash{xx}
+	}
+	buf, slab := gmem2.AllocFromSlab2(0)
+	written, err = cmn.ReceiveAndChecksum(file, reader, buf, hashes...)
+	slab.Free(buf)
+	if errClose := file.Close(); err == nil && errClose != nil {
+		err = errClose
+	}
+	if err != nil {
+		os.Remove(workFQN)
+		return nil, fmt.Errorf("failed to receive %s, err: %v", workFQN, err)
+	}
+	part = &mpuPart{workFQN: workFQN, size: written}
+	if len(hashes) > 0 {
+		part.cksum = cmn.NewCksum(cmn.ChecksumXXHash, cmn.HashToStr(xx))
+		if expectedCksum != nil && !cmn.EqCksum(expectedCksum, part.cksum) {
+			os.Remove(workFQN)
+			t.statsif.AddMany(
+				stats.NamedVal64{Name: stats.ErrCksumCount, Val: 1},
+				stats.NamedVal64{Name: stats.ErrCksumSize, Val: written},
+			)
+			return nil, fmt.Errorf("%s: bad checksum - expected %s, got: %s",
+				lom, expectedCksum.String(), part.cksum.String())
+		}
+	}
+	return
+}
+
+// POST { ActCompleteMultipart } /v1/objects/bucket-name/object-name
+func (t *targetrunner) completeMultipart(w http.ResponseWriter, r *http.Request, lom *cluster.LOM, msg *cmn.ActionMsg) {
+	mpuMsg, err := parseMultipartMsg(msg)
+	if err != nil {
+		t.invalmsghdlr(w, r, err.Error())
+		return
+	}
+	upload, err := t.mpu.acquire(mpuMsg.UploadID, lom.Uname())
+	if err != nil {
+		t.invalmsghdlr(w, r, err.Error(), http.StatusNotFound)
+		return
+	}
+	reader, err := upload.open(mpuMsg.Parts)
+	if err != nil {
+		t.mpu.release(upload)
+		t.invalmsghdlr(w, r, fmt.Sprintf("%s: %v", lom, err))
+		return
+	}
+	if lom.BckIsLocal && lom.VerConf().Enabled {
+		lom.Load(true) // need to know the current version if versioning enabled
+	}
+	customMD, err := cmn.CustomMDFromHeader(r.Header)
+	if err != nil {
+		t.mpu.release(upload)
+		reader.Close()
+		t.invalmsghdlr(w, r, fmt.Sprintf("%s: %v", lom, err))
+		return
+	}
+	lom.SetCustomMD(customMD)
+	roi := &recvObjInfo{
+		t:   t,
+		lom: lom,
+		r:   reader,
+		ctx: t.contextWithAuth(r.Header),
+	}
+	roi.init()
+	if err, errCode := roi.recv(); err != nil {
+		t.mpu.release(upload)
+		t.invalmsghdlr(w, r, err.Error(), errCode)
+		return
+	}
+	t.mpu.del(mpuMsg.UploadID, upload)
+	if cksum := lom.Cksum(); cksum != nil {
+		ty, val := cksum.Get()
+		w.Header().Set(cmn.HeaderObjCksumType, ty)
+		w.Header().Set(cmn.HeaderObjCksumVal, val)
+	}
+	glog.Infof("%s: completed multipart upload %q (%d parts, %s)", lom, mpuMsg.UploadID,
+		len(mpuMsg.Parts), cmn.B2S(lom.Size(), 1))
+}
+
+// POST { ActAbortMultipart } /v1/objects/bucket-name/object-name
+func (t *targetrunner) abortMultipart(w http.ResponseWriter, r *http.Request, lom *cluster.LOM, msg *cmn.ActionMsg) {
+	mpuMsg, err := parseMultipartMsg(msg)
+	if err != nil {
+		t.invalmsghdlr(w, r, err.Error())
+		return
+	}
+	upload, err := t.mpu.acquire(mpuMsg.UploadID, lom.Uname())
+	if err != nil {
+		t.invalmsghdlr(w, r, err.Error(), http.StatusNotFound)
+		return
+	}
+	t.mpu.del(mpuMsg.UploadID, upload)
+	if glog.FastV(4, glog.SmoduleAIS) {
+		glog.Infof("%s: aborted multipart upload %q", lom, mpuMsg.UploadID)
+	}
+}
+
+func parseMultipartMsg(msg *cmn.ActionMsg) (*cmn.MultipartMsg, error) {
+	mpuMsg := &cmn.MultipartMsg{}
+	b, err := jsoniter.Marshal(msg.Value)
+	if err == nil {
+		err = jsoniter.Unmarshal(b, mpuMsg)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse %s request: %v", msg.Action, msg.Value)
+	}
+	if mpuMsg.UploadID == "" {
+		return nil, fmt.Errorf("%s: upload ID is not specified", msg.Action)
+	}
+	return mpuMsg, nil
+}
+
+// validates the list of parts and returns a reader that reads the parts back-to-back
+func (upload *mpuUpload) open(parts []cmn.MultipartPart) (io.ReadCloser, error) {
+	if len(parts) == 0 {
+		return nil, errors.New("no parts to complete the upload")
+	}
+	sorted := sort.SliceIsSorted(parts, func(i, j int) bool { return parts[i].PartNum < parts[j].PartNum })
+	if !sorted {
+		return nil, errors.New("parts must be specified in ascending order")
+	}
+	mr := &multiFileReader{files: make([]*os.File, 0, len(parts))}
+	for i, p := range parts {
+		if i > 0 && p.PartNum == parts[i-1].PartNum {
+			mr.Close()
+			return nil, fmt.Errorf("duplicate part #%d", p.PartNum)
+		}
+		part, ok := upload.parts[p.PartNum]
+		if !ok {
+			mr.Close()
+			return nil, fmt.Errorf("part #%d %s", p.PartNum, cmn.DoesNotExist)
+		}
+		if p.Cksum != "" && part.cksum != nil {
+			if _, val := part.cksum.Get(); val != p.Cksum {
+				mr.Close()
+				return nil, fmt.Errorf("part #%d: checksum mismatch (%s vs %s)", p.PartNum, p.Cksum, val)
+			}
+		}
+		file, err := os.Open(part.workFQN)
+		if err != nil {
+			mr.Close()
+			return nil, err
+		}
+		mr.files = append(mr.files, file)
+	}
+	return mr, nil
+}
+
+// multiFileReader reads the files back-to-back and closes all of them when done
+type multiFileReader struct {
+	files []*os.File
+	idx   int
+}
+
+func (mr *multiFileReader) Read(b []byte) (n int, err error) {
+	for mr.idx < len(mr.files) {
+		n, err = mr.files[mr.idx].Read(b)
+		if err == io.EOF {
+			mr.idx++
+			err = nil
+			if n == 0 {
+				continue
+			}
+		}
+		return
+	}
+	return 0, io.EOF
+}
+
+func (mr *multiFileReader) Close() (err error) {
+	for _, file := range mr.files {
+		if errClose := file.Close(); errClose != nil {
+			err = errClose
+		}
+	}
+	return
+}
@@ -0,0 +1,60 @@
+// Package ais provides core functionality for the AIStore object storage.
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package ais
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMultipartSweep(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mpu-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	reg := newMpuRegistry()
+
+	uploads := make(map[string]string, 3) // uname => upload ID
+	for _, uname := range []string{"b/idle", "b/active", "b/completing"} {
+		uploadID, err := reg.init(uname)
+		if err != nil {
+			t.Fatal(err)
+		}
+		workFQN := filepath.Join(dir, filepath.Base(uname))
+		if err := ioutil.WriteFile(workFQN, []byte(uname), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := reg.addPart(uploadID, uname, 1, &mpuPart{workFQN: workFQN}); err != nil {
+			t.Fatal(err)
+		}
+		uploads[uname] = uploadID
+	}
+	for _, uname := range []string{"b/idle", "b/completing"} {
+		reg.uploads[uploads[uname]].atime = time.Now().Add(-2 * mpuUploadTTL)
+	}
+	if _, err := reg.acquire(uploads["b/completing"], "b/completing"); err != nil {
+		t.Fatal(err)
+	}
+
+	reg.sweep(time.Now().Add(-mpuUploadTTL))
+	if _, ok := reg.uploads[uploads["b/idle"]]; ok {
+		t.Errorf("expected the idle upload to expire")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "idle")); !os.IsNotExist(err) {
+		t.Errorf("expected the part of the idle upload to be removed, err: %v", err)
+	}
+	for _, uname := range []string{"b/active", "b/completing"} {
+		if _, ok := reg.uploads[uploads[uname]]; !ok {
+			t.Errorf("expected upload %q to remain", uname)
+		}
+		if _, err := os.Stat(filepath.Join(dir, filepath.Base(uname))); err != nil {
+			t.Error(err)
+		}
+	}
+}
@@ -0,0 +1,313 @@
+// Package ais provides core functionality for the AIStore object storage.
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package ais
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/NVIDIA/aistore/3rdparty/atomic"
+	"github.com/NVIDIA/aistore/3rdparty/glog"
+	"github.com/NVIDIA/aistore/cluster"
+	"github.com/NVIDIA/aistore/cmn"
+	"github.com/NVIDIA/aistore/fs"
+	jsoniter "github.com/json-iterator/go"
+)
+
+//
+// Bucket quotas (see cmn.QuotaConf). Each target tracks the usage of the
+// buckets that have quotas: the usage gets counted (by traversing local
+// mountpaths, in the background) when first needed and is then updated
+// incrementally upon PUT and DELETE. Operations that remove objects in bulk
+// (LRU, rebalance) mark the usage stale - to be recounted in the background.
+// The parts of multipart uploads in progress are reserved against the
+// capacity quota. The quotas are enforced against the cluster-wide usage: the
+// target adds the usage of the other targets, which it queries in the
+// background every quotaPeersRefresh - and which may thus lag behind. Until
+// both the local count and the first query complete, hard quotas are not
+// enforced. The cluster-wide usage is also aggregated by the proxy (see HEAD
+// bucket).
+//
+
+// how often the usage of the other targets gets refreshed
+const quotaPeersRefresh = 10 * time.Second
+
+type (
+	bckUsage struct {
+		size, objs atomic.Int64
+		reserved   atomic.Int64  // multipart upload parts
+		stale      atomic.Bool   // to be recounted
+		counting   atomic.Bool   // serializes counting
+		seeded     chan struct{} // closed once counted for the first time
+		// usage of the other targets
+		peerSize, peerObjs atomic.Int64
+		peersTime          atomic.Int64 // when last queried (0: never)
+		querying           atomic.Bool  // serializes querying
+	}
+	bckUsageKey struct {
+		bucket string
+		local  bool
+	}
+	quotaTracker struct {
+		t       *targetrunner
+		mtx     sync.Mutex
+		buckets map[bckUsageKey]*bckUsage
+	}
+)
+
+func newQuotaTracker(t *targetrunner) *quotaTracker {
+	return &quotaTracker{t: t, buckets: make(map[bckUsageKey]*bckUsage)}
+}
+
+// returns the (counted) usage of the bucket on this target; starts tracking
+// the bucket if not tracked yet
+func (q *quotaTracker) usage(bucket string, bckIsLocal bool) *bckUsage {
+	key := bckUsageKey{bucket, bckIsLocal}
+	q.mtx.Lock()
+	bu, ok := q.buckets[key]
+	if !ok {
+		bu = &bckUsage{seeded: make(chan struct{})}
+		q.buckets[key] = bu
+	}
+	q.mtx.Unlock()
+
+	if !bu.isSeeded() || bu.stale.Load() {
+		q.recount(bu, key)
+	}
+	return bu
+}
+
+// counts the usage in the background unless already counting
+func (q *quotaTracker) recount(bu *bckUsage, key bckUsageKey) {
+	if !bu.counting.CAS(false, true) {
+		return
+	}
+	bu.stale.Store(false)
+	go func() {
+		q.count(bu, key)
+		if !bu.isSeeded() {
+			close(bu.seeded)
+		}
+		bu.counting.Store(false)
+	}()
+}
+
+func (bu *bckUsage) isSeeded() bool {
+	select {
+	case <-bu.seeded:
+		return true
+	default:
+		return false
+	}
+}
+
+// queries the usage of the other targets in the background if it is due
+// and not being queried already
+func (q *quotaTracker) refreshPeers(bu *bckUsage, key bckUsageKey) {
+	if time.Since(time.Unix(0, bu.peersTime.Load())) < quotaPeersRefresh || !bu.querying.CAS(false, true) {
+		return
+	}
+	go func() {
+		q.queryPeers(bu, key)
+		bu.querying.Store(false)
+	}()
+}
+
+func (q *quotaTracker) queryPeers(bu *bckUsage, key bckUsageKey) {
+	var (
+		size, objs int64
+		query      = url.Values{}
+	)
+	query.Add(cmn.URLParamWhat, cmn.GetWhatBucketUsage)
+	query.Add(cmn.URLParamBckProvider, cmn.BckProviderFromLocal(key.local))
+	results := q.t.broadcastTo(
+		cmn.URLPath(cmn.Version, cmn.Buckets, key.bucket),
+		query,
+		http.MethodGet,
+		nil, // body
+		q.t.smapowner.get(),
+		cmn.GCO.Get().Timeout.Default,
+		cmn.NetworkIntraControl,
+		cluster.Targets,
+	)
+	for res := range results {
+		if res.err != nil {
+			glog.Errorf("%s: failed to get bucket %s usage from %s: %v", q.t.si, key.bucket, res.si, res.errstr)
+			return
+		}
+		usage := cmn.BucketUsage{}
+		if err := jsoniter.Unmarshal(res.outjson, &usage); err != nil {
+			glog.Errorf("%s: failed to unmarshal bucket %s usage from %s: %v", q.t.si, key.bucket, res.si, err)
+			return
+		}
+		size += usage.Size
+		objs += usage.Objs
+	}
+	bu.peerSize.Store(size)
+	bu.peerObjs.Store(objs)
+	bu.peersTime.Store(time.Now().UnixNano())
+}
+
+// returns the usage if the bucket is tracked, nil otherwise
+func (q *quotaTracker) tracked(bucket string, bckIsLocal bool) *bckUsage {
+	q.mtx.Lock()
+	bu := q.buckets[bckUsageKey{bucket, bckIsLocal}]
+	q.mtx.Unlock()
+	return bu
+}
+
+// marks all usages stale
+func (q *quotaTracker) invalidate() {
+	q.mtx.Lock()
+	for _, bu := range q.buckets {
+		bu.stale.Store(true)
+	}
+	q.mtx.Unlock()
+}
+
+func (q *quotaTracker) drop(bucket string, bckIsLocal bool) {
+	q.mtx.Lock()
+	delete(q.buckets, bckUsageKey{bucket, bckIsLocal})
+	q.mtx.Unlock()
+}
+
+// traverses local mountpaths to count the bucket's objects (replicas excluded)
+func (q *quotaTracker) count(bu *bckUsage, key bckUsageKey) {
+	var (
+		size, objs        int64
+		bckProvider       = cmn.BckProviderFromLocal(key.local)
+		config            = cmn.GCO.Get()
+		availablePaths, _ = fs.Mountpaths.Get()
+	)
+	walk := func(fqn string, osfi os.FileInfo, err error) error {
+		if err != nil {
+			if errstr := cmn.PathWalkErr(err); errstr != "" {
+				glog.Error(errstr)
+				return err
+			}
+			return nil
+		}
+		if osfi.Mode().IsDir() {
+			return nil
+		}
+		lom, errstr := cluster.LOM{T: q.t, FQN: fqn, BucketProvider: bckProvider}.Init(config)
+		if errstr != "" {
+			return nil
+		}
+		if _, errstr = lom.Load(true); errstr != "" || !lom.Exists() || lom.IsCopy() {
+			return nil
+		}
+		size += lom.DiskSize()
+		objs++
+		return nil
+	}
+	for _, mpathInfo := range availablePaths {
+		dir := mpathInfo.MakePathBucket(fs.ObjectType, key.bucket, key.local)
+		if err := filepath.Walk(dir, walk); err != nil && !os.IsNotExist(err) {
+			glog.Errorf("%s: failed to traverse, err: %v", dir, err)
+		}
+	}
+	bu.size.Store(size)
+	bu.objs.Store(objs)
+	if glog.FastV(4, glog.SmoduleAIS) {
+		glog.Infof("bucket %s usage: %d objects, %s", key.bucket, objs, cmn.B2S(size, 2))
+	}
+}
+
+// updates the usage (if tracked) upon adding or removing an object
+func (q *quotaTracker) update(lom *cluster.LOM, sizeDelta, objsDelta int64) {
+	bu := q.tracked(lom.Bucket, lom.BckIsLocal)
+	if bu == nil {
+		return
+	}
+	size, objs := bu.size.Add(sizeDelta), bu.objs.Add(objsDelta)
+	if lom.BckProps == nil || sizeDelta <= 0 && objsDelta <= 0 {
+		return
+	}
+	quota := &lom.BckProps.Quota
+	size += bu.peerSize.Load()
+	objs += bu.peerObjs.Load()
+	if exceeds(size, quota.SoftSize) && !exceeds(size-sizeDelta, quota.SoftSize) {
+		glog.Warningf("%s: bucket %s exceeded soft quota on capacity (%s)", q.t.si, lom.Bucket,
+			cmn.B2S(quota.SoftSize, 2))
+	}
+	if exceeds(objs, quota.SoftObjs) && !exceeds(objs-objsDelta, quota.SoftObjs) {
+		glog.Warningf("%s: bucket %s exceeded soft quota on number of objects (%d)", q.t.si, lom.Bucket,
+			quota.SoftObjs)
+	}
+}
+
+// checks the bucket's hard quotas prior to PUT-ting the object of the given size
+// (negative - unknown)
+func (q *quotaTracker) checkPut(lom *cluster.LOM, size int64) (errstr string, errCode int) {
+	if lom.BckProps == nil {
+		return
+	}
+	quota := &lom.BckProps.Quota
+	if quota.HardSize == 0 && quota.HardObjs == 0 {
+		return
+	}
+	bu := q.usage(lom.Bucket, lom.BckIsLocal)
+	q.refreshPeers(bu, bckUsageKey{lom.Bucket, lom.BckIsLocal})
+	if !bu.isSeeded() || bu.peersTime.Load() == 0 {
+		if glog.FastV(4, glog.SmoduleAIS) {
+			glog.Infof("%s: bucket %s usage is being counted, quota not enforced", q.t.si, lom.Bucket)
+		}
+		return
+	}
+	var (
+		usedSize = bu.size.Load() + bu.peerSize.Load() + bu.reserved.Load() + cmn.MaxI64(size, 0)
+		usedObjs = bu.objs.Load() + bu.peerObjs.Load() + 1
+	)
+	if !exceeds(usedSize, quota.HardSize) && !exceeds(usedObjs, quota.HardObjs) {
+		return
+	}
+	// overwriting replaces the existing object (and does not add objects)
+	if _, errstr = lom.Load(true); errstr != "" {
+		return
+	}
+	exists := lom.Exists()
+	if exists {
+		usedSize -= lom.DiskSize()
+	}
+	if exceeds(usedSize, quota.HardSize) {
+		return fmt.Sprintf("%s: bucket %s exceeded hard quota on capacity (%s)", q.t.si, lom.Bucket,
+			cmn.B2S(quota.HardSize, 2)), http.StatusInsufficientStorage
+	}
+	if !exists && exceeds(usedObjs, quota.HardObjs) {
+		return fmt.Sprintf("%s: bucket %s exceeded hard quota on number of objects (%d)", q.t.si, lom.Bucket,
+			quota.HardObjs), http.StatusForbidden
+	}
+	return
+}
+
+// reserves the given size (of a multipart upload part) against the bucket's
+// capacity quota; returns the usage to release the reservation from, or nil if
+// the bucket is not tracked
+func (q *quotaTracker) reserve(lom *cluster.LOM, size int64) *bckUsage {
+	bu := q.tracked(lom.Bucket, lom.BckIsLocal)
+	if bu != nil {
+		bu.reserved.Add(size)
+	}
+	return bu
+}
+
+// returns true if the (cluster-wide) value exceeds the non-zero limit
+func exceeds(val, limit int64) bool { return limit != 0 && val > limit }
+
+// GET { what=usage } /v1/buckets/bucket-name
+func (t *targetrunner) getBucketUsage(w http.ResponseWriter, r *http.Request, bucket string, bckIsLocal bool) {
+	bu := t.quotas.usage(bucket, bckIsLocal)
+	<-bu.seeded
+	usage := cmn.BucketUsage{Size: bu.size.Load(), Objs: bu.objs.Load()}
+	jsbytes, err := jsoniter.Marshal(usage)
+	cmn.AssertNoErr(err)
+	t.writeJSON(w, r, jsbytes, "getusage")
+}
@@ -0,0 +1,136 @@
+// Package ais provides core functionality for the AIStore object storage.
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package ais
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NVIDIA/aistore/cluster"
+	"github.com/NVIDIA/aistore/cmn"
+	"github.com/NVIDIA/aistore/fs"
+)
+
+func TestQuotaExceeds(t *testing.T) {
+	tests := []struct {
+		val, limit int64
+		exceeds    bool
+	}{
+		{100, 0, false}, // no limit
+		{100, 100, false},
+		{101, 100, true},
+		{2 * cmn.GiB, 10 * cmn.GiB, false}, // regardless of the number of targets
+	}
+	for _, tst := range tests {
+		if exceeds(tst.val, tst.limit) != tst.exceeds {
+			t.Errorf("exceeds(%d, %d) != %t", tst.val, tst.limit, tst.exceeds)
+		}
+	}
+}
+
+func TestQuotaValidate(t *testing.T) {
+	tests := []struct {
+		quota cmn.QuotaConf
+		valid bool
+	}{
+		{cmn.QuotaConf{}, true},
+		{cmn.QuotaConf{SoftSize: cmn.GiB, HardSize: 2 * cmn.GiB, SoftObjs: 10, HardObjs: 10}, true},
+		{cmn.QuotaConf{SoftSize: cmn.GiB}, true}, // soft only
+		{cmn.QuotaConf{SoftSize: 2 * cmn.GiB, HardSize: cmn.GiB}, false},
+		{cmn.QuotaConf{SoftObjs: 11, HardObjs: 10}, false},
+		{cmn.QuotaConf{HardObjs: -1}, false},
+	}
+	for _, tst := range tests {
+		err := tst.quota.ValidateAsProps(&cmn.ValidationArgs{BckIsLocal: true})
+		if (err == nil) != tst.valid {
+			t.Errorf("%+v: expected valid=%t, got err: %v", tst.quota, tst.valid, err)
+		}
+	}
+}
+
+func TestQuotaCheckPut(t *testing.T) {
+	dir, err := ioutil.TempDir("", "quota-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldMountpaths := fs.Mountpaths
+	defer func() {
+		os.RemoveAll(dir)
+		fs.Mountpaths = oldMountpaths
+	}()
+	fs.Mountpaths = fs.NewMountedFS()
+	if err := fs.Mountpaths.Add(dir); err != nil {
+		t.Fatal(err)
+	}
+	_ = fs.CSM.RegisterFileType(fs.ObjectType, &fs.ObjectContentResolver{})
+
+	tgt := &targetrunner{rtnamemap: newrtnamemap(), bmdowner: newBmdowner()}
+	bmd := newBucketMD()
+	bmd.add("quota", true, &cmn.BucketProps{
+		Cksum: cmn.CksumConf{Type: cmn.ChecksumNone},
+		Quota: cmn.QuotaConf{HardSize: 1000, HardObjs: 3},
+	})
+	tgt.bmdowner.put(bmd)
+	tgt.quotas = newQuotaTracker(tgt)
+
+	newLOM := func(objname string) *cluster.LOM {
+		lom, errstr := cluster.LOM{T: tgt, Bucket: "quota", Objname: objname, BucketProvider: cmn.LocalBs}.Init()
+		if errstr != "" {
+			t.Fatal(errstr)
+		}
+		return lom
+	}
+	existing := newLOM("existing")
+	if err := cmn.CreateDir(filepath.Dir(existing.FQN)); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(existing.FQN, make([]byte, 400), 0644); err != nil {
+		t.Fatal(err)
+	}
+	existing.SetSize(400)
+	if err := existing.Persist(); err != nil {
+		t.Fatal(err)
+	}
+
+	// this target stores the existing object, the other targets - 500 bytes in 1 object
+	bu := &bckUsage{seeded: make(chan struct{})}
+	close(bu.seeded)
+	bu.size.Store(400)
+	bu.objs.Store(1)
+	bu.peerSize.Store(500)
+	bu.peerObjs.Store(1)
+	bu.peersTime.Store(time.Now().UnixNano())
+	tgt.quotas.buckets[bckUsageKey{"quota", true}] = bu
+
+	tests := []struct {
+		objname string
+		size    int64
+		errCode int
+	}{
+		{"new", 100, 0}, // 1000 bytes, 3 objects
+		{"new", 101, http.StatusInsufficientStorage},
+		{"existing", 500, 0}, // overwrite: 400 bytes replaced
+		{"existing", 501, http.StatusInsufficientStorage},
+	}
+	for _, tst := range tests {
+		errstr, errCode := tgt.quotas.checkPut(newLOM(tst.objname), tst.size)
+		if errCode != tst.errCode {
+			t.Errorf("PUT %s (%d bytes): expected %d, got %d (%s)", tst.objname, tst.size, tst.errCode, errCode, errstr)
+		}
+	}
+
+	// the number of objects: overwriting is still allowed
+	bu.peerObjs.Store(2)
+	if _, errCode := tgt.quotas.checkPut(newLOM("new"), 1); errCode != http.StatusForbidden {
+		t.Errorf("PUT of a new object: expected %d, got %d", http.StatusForbidden, errCode)
+	}
+	if errstr, _ := tgt.quotas.checkPut(newLOM("existing"), 1); errstr != "" {
+		t.Errorf("overwrite: %s", errstr)
+	}
+}
@@ -0,0 +1,261 @@
+// Package ais provides core functionality for the AIStore object storage.
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package ais
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/NVIDIA/aistore/3rdparty/atomic"
+	"github.com/NVIDIA/aistore/3rdparty/glog"
+	"github.com/NVIDIA/aistore/cluster"
+	"github.com/NVIDIA/aistore/cmn"
+	"github.com/NVIDIA/aistore/fs"
+	"github.com/NVIDIA/aistore/stats"
+	jsoniter "github.com/json-iterator/go"
+)
+
+//
+// Synchronizing cached Cloud bucket with the Cloud (see cmn.SyncBucketMsg).
+// Every target lists the Cloud bucket and takes care of the objects it owns
+// (by HRW): fetches those that are new or changed in the Cloud - the version
+// (if listed) or the size differs - and, optionally, evicts the objects it
+// stores that are no longer in the Cloud. The objects pending write-back are
+// skipped either way. Dry run reports the names instead of acting.
+//
+
+const syncPageSize = 1000
+
+type (
+	xactBckSync struct {
+		cmn.XactBase
+		fetched, fetchedSize, evicted, errors atomic.Int64
+	}
+	syncDiff struct {
+		report  cmn.SyncBucketReport
+		started time.Time
+	}
+)
+
+// POST { action: syncbck } /v1/buckets/bucket-name
+func (t *targetrunner) syncBucket(w http.ResponseWriter, r *http.Request, bucket, bckProvider string, bckIsLocal bool,
+	msgInt *actionMsgInternal) {
+	syncMsg := &cmn.SyncBucketMsg{}
+	b, err := jsoniter.Marshal(msgInt.Value)
+	if err == nil {
+		err = jsoniter.Unmarshal(b, syncMsg)
+	}
+	if err != nil {
+		t.invalmsghdlr(w, r, fmt.Sprintf("unable to parse %s request: %v", msgInt.Action, msgInt.Value))
+		return
+	}
+	if bckIsLocal {
+		t.invalmsghdlr(w, r, fmt.Sprintf("%s: %s is not a Cloud bucket", msgInt.Action, bucket))
+		return
+	}
+	ct := t.contextWithAuth(r.Header)
+	if syncMsg.DryRun {
+		diff, err := t.syncDiff(ct, nil, bucket, bckProvider, syncMsg)
+		if err != nil {
+			t.invalmsghdlr(w, r, err.Error())
+			return
+		}
+		jsbytes, err := jsoniter.Marshal(&diff.report)
+		cmn.AssertNoErr(err)
+		t.writeJSON(w, r, jsbytes, "syncbck")
+		return
+	}
+	x := t.xactions.renewBckSync(bucket)
+	if x == nil {
+		t.invalmsghdlr(w, r, fmt.Sprintf("%s: bucket %s is already being synchronized", t.si, bucket), http.StatusConflict)
+		return
+	}
+	go t.runBckSync(ct, x, bucket, bckProvider, syncMsg)
+}
+
+func (t *targetrunner) runBckSync(ct context.Context, x *xactBckSync, bucket, bckProvider string,
+	syncMsg *cmn.SyncBucketMsg) {
+	defer x.EndTime(time.Now())
+	diff, err := t.syncDiff(ct, x, bucket, bckProvider, syncMsg)
+	if err != nil {
+		glog.Errorf("%s: %v", x, err)
+		x.errors.Inc()
+		return
+	}
+	fetch := append(diff.report.New, diff.report.Changed...)
+	for _, objname := range fetch {
+		if x.Aborted() {
+			return
+		}
+		lom, errstr := cluster.LOM{T: t, Bucket: bucket, Objname: objname, BucketProvider: bckProvider}.Init()
+		if errstr == "" {
+			errstr, _ = t.GetCold(ct, lom, true)
+		}
+		if errstr == "skip" { // being fetched by GET
+			continue
+		}
+		if errstr != "" {
+			glog.Errorf("%s: %s", x, errstr)
+			x.errors.Inc()
+			continue
+		}
+		x.fetched.Inc()
+		x.fetchedSize.Add(lom.Size())
+		t.statsif.AddMany(stats.NamedVal64{stats.PrefetchCount, 1}, stats.NamedVal64{stats.PrefetchSize, lom.Size()})
+	}
+	for _, objname := range diff.report.Deleted {
+		if x.Aborted() {
+			return
+		}
+		lom, errstr := cluster.LOM{T: t, Bucket: bucket, Objname: objname, BucketProvider: bckProvider}.Init()
+		if errstr != "" {
+			glog.Errorf("%s: %s", x, errstr)
+			x.errors.Inc()
+			continue
+		}
+		if err := t.syncEvict(ct, lom, diff.started); err != nil {
+			glog.Errorf("%s: %v", x, err)
+			x.errors.Inc()
+			continue
+		}
+		x.evicted.Inc()
+	}
+}
+
+// evicts the object unless it has been written since the sync started
+func (t *targetrunner) syncEvict(ct context.Context, lom *cluster.LOM, started time.Time) error {
+	t.rtnamemap.Lock(lom.Uname(), true)
+	defer t.rtnamemap.Unlock(lom.Uname(), true)
+	if _, errstr := lom.Load(false); errstr != "" {
+		return errors.New(errstr)
+	}
+	if !lom.Exists() || lom.WriteBackPending() {
+		return nil
+	}
+	if finfo, err := os.Stat(lom.FQN); err == nil && finfo.ModTime().After(started) {
+		return nil
+	}
+	return t.objDeleteLocked(ct, lom, true /*evict*/)
+}
+
+// compares the Cloud bucket with the objects this target stores; x (if any)
+// is the xaction that lists the bucket
+func (t *targetrunner) syncDiff(ct context.Context, x cmn.Xact, bucket, bckProvider string,
+	syncMsg *cmn.SyncBucketMsg) (diff *syncDiff, err error) {
+	var (
+		smap   = t.smapowner.get()
+		cloud  = getcloudif(bucket, bckProvider)
+		listed = make(map[string]struct{}, syncPageSize)
+		msg    = &cmn.SelectMsg{Prefix: syncMsg.Prefix, Props: cmn.GetPropsVersion + ", " + cmn.GetPropsSize,
+			PageSize: syncPageSize}
+	)
+	diff = &syncDiff{
+		report:  cmn.SyncBucketReport{New: []string{}, Changed: []string{}, Deleted: []string{}},
+		started: time.Now(),
+	}
+	for {
+		if x != nil {
+			select {
+			case <-x.ChanAbort():
+				return nil, fmt.Errorf("%s aborted", x)
+			default:
+			}
+		}
+		jsbytes, err, _ := cloud.listbucket(ct, bucket, msg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list Cloud bucket %s, err: %v", bucket, err)
+		}
+		list := &cmn.BucketList{}
+		if err := jsoniter.Unmarshal(jsbytes, list); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal Cloud bucket %s list, err: %v", bucket, err)
+		}
+		for _, entry := range list.Entries {
+			si, errstr := hrwTarget(bucket, entry.Name, smap)
+			if errstr != "" {
+				return nil, errors.New(errstr)
+			}
+			if si.DaemonID != t.si.DaemonID {
+				continue
+			}
+			listed[entry.Name] = struct{}{}
+			lom, errstr := cluster.LOM{T: t, Bucket: bucket, Objname: entry.Name, BucketProvider: bckProvider}.Init()
+			if errstr == "" {
+				_, errstr = lom.Load(true)
+			}
+			if errstr != "" {
+				glog.Errorln(errstr)
+				continue
+			}
+			switch {
+			case !lom.Exists():
+				diff.report.New = append(diff.report.New, entry.Name)
+			case lom.WriteBackPending():
+				// the Cloud is behind
+			case entry.Version != "" && lom.Version() != "":
+				if entry.Version != lom.Version() {
+					diff.report.Changed = append(diff.report.Changed, entry.Name)
+				}
+			case entry.Size != lom.Size():
+				diff.report.Changed = append(diff.report.Changed, entry.Name)
+			}
+		}
+		msg.PageMarker = list.PageMarker
+		if msg.PageMarker == "" {
+			break
+		}
+	}
+	if syncMsg.EvictDeleted {
+		diff.report.Deleted, err = t.syncDeleted(bucket, bckProvider, syncMsg.Prefix, listed, diff.started)
+	}
+	return
+}
+
+// returns the names of the objects stored by this target that are not in the Cloud
+func (t *targetrunner) syncDeleted(bucket, bckProvider, prefix string, listed map[string]struct{},
+	started time.Time) ([]string, error) {
+	var (
+		deleted           = []string{}
+		availablePaths, _ = fs.Mountpaths.Get()
+	)
+	walk := func(fqn string, osfi os.FileInfo, err error) error {
+		if err != nil {
+			if errstr := cmn.PathWalkErr(err); errstr != "" {
+				glog.Error(errstr)
+				return err
+			}
+			return nil
+		}
+		if osfi.Mode().IsDir() || osfi.ModTime().After(started) {
+			return nil
+		}
+		lom, errstr := cluster.LOM{T: t, FQN: fqn, BucketProvider: bckProvider}.Init()
+		if errstr != "" || !strings.HasPrefix(lom.Objname, prefix) {
+			return nil
+		}
+		if _, ok := listed[lom.Objname]; ok {
+			return nil
+		}
+		if _, errstr = lom.Load(true); errstr != "" || !lom.Exists() || lom.IsCopy() || lom.Misplaced() {
+			return nil
+		}
+		if !lom.WriteBackPending() {
+			deleted = append(deleted, lom.Objname)
+		}
+		return nil
+	}
+	for _, mpathInfo := range availablePaths {
+		dir := mpathInfo.MakePathBucket(fs.ObjectType, bucket, false /*cloud*/)
+		if err := filepath.Walk(dir, walk); err != nil {
+			return nil, fmt.Errorf("failed to traverse %s, err: %v", dir, err)
+		}
+	}
+	return deleted, nil
+}
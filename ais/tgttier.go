@@ -0,0 +1,396 @@
+// Package ais provides core functionality for the AIStore object storage.
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package ais
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NVIDIA/aistore/3rdparty/glog"
+	"github.com/NVIDIA/aistore/cluster"
+	"github.com/NVIDIA/aistore/cmn"
+	"github.com/NVIDIA/aistore/fs"
+	"github.com/NVIDIA/aistore/stats"
+	jsoniter "github.com/json-iterator/go"
+)
+
+//
+// Multi-tiering: a bucket may be backed by the same-name bucket of another AIStore
+// cluster - the next tier (see cmn.BucketProps.NextTierURL). With the "next_tier"
+// read policy, objects that are not in this cluster are read from the next tier;
+// with the "next_tier" write policy, PUTs and DELETEs are forwarded to the next
+// tier instead of the Cloud. The next tier, in turn, follows its own bucket props,
+// so that tiers form a chain. Forwarded requests carry the IDs of the tiers they
+// have passed through (cmn.HeaderTierPath): a request is never forwarded to a tier
+// that is already in its path, nor beyond tierMaxHops.
+//
+// The next tier is considered down when it does not respond (or fails to forward)
+// and is checked again after tierCheckInterval; meanwhile, Cloud buckets fall back
+// to the Cloud while local buckets are read and written locally.
+//
+
+const (
+	tierMaxHops       = 8
+	tierCheckInterval = 10 * time.Second
+
+	ctxTierPath contextID = "tierPath" // comma-separated IDs of the tiers (see cmn.HeaderTierPath)
+)
+
+type (
+	tierState struct {
+		checked time.Time
+		id      string // ID of the next tier's primary proxy
+		up      bool
+	}
+	tierTracker struct {
+		t      *targetrunner
+		mtx    sync.Mutex
+		states map[string]*tierState // by next tier URL
+	}
+)
+
+func newTierTracker(t *targetrunner) *tierTracker {
+	return &tierTracker{t: t, states: make(map[string]*tierState)}
+}
+
+// returns the next tier URL and the tier path to forward the request with; empty URL
+// when the bucket's policy is not "next_tier" or the next tier cannot be used
+func (tt *tierTracker) route(ct context.Context, bprops *cmn.BucketProps, write bool) (nextURL, tpath string) {
+	if bprops == nil || bprops.NextTierURL == "" {
+		return
+	}
+	policy := bprops.ReadPolicy
+	if write {
+		policy = bprops.WritePolicy
+	}
+	if policy != cmn.RWPolicyNextTier {
+		return
+	}
+	id, up := tt.health(bprops.NextTierURL)
+	if !up {
+		tt.t.statsif.Add(stats.TierFallbackCount, 1)
+		return
+	}
+	var (
+		smap = tt.t.smapowner.get()
+		path = tierPath(ct)
+	)
+	if len(path) == 0 || path[len(path)-1] != smap.ProxySI.DaemonID {
+		path = append(path, smap.ProxySI.DaemonID)
+	}
+	if cmn.StringInSlice(id, path) || len(path) >= tierMaxHops {
+		glog.Errorf("tier loop: %s => %s (%s), path %v", smap.ProxySI.DaemonID, id, bprops.NextTierURL, path)
+		tt.t.statsif.Add(stats.TierFallbackCount, 1)
+		return
+	}
+	return bprops.NextTierURL, strings.Join(path, ",")
+}
+
+// returns the next tier's primary ID and whether the tier is up, checking the tier
+// if it has not been checked for tierCheckInterval
+func (tt *tierTracker) health(nextURL string) (id string, up bool) {
+	tt.mtx.Lock()
+	state, ok := tt.states[nextURL]
+	if ok && time.Since(state.checked) < tierCheckInterval {
+		id, up = state.id, state.up
+		tt.mtx.Unlock()
+		return
+	}
+	tt.mtx.Unlock()
+
+	smap, err := tt.getSmap(nextURL)
+	state = &tierState{checked: time.Now()}
+	if err != nil {
+		glog.Errorf("next tier %s is down, err: %v", nextURL, err)
+	} else {
+		state.id, state.up = smap.ProxySI.DaemonID, true
+	}
+	tt.mtx.Lock()
+	tt.states[nextURL] = state
+	tt.mtx.Unlock()
+	return state.id, state.up
+}
+
+func (tt *tierTracker) down(nextURL string, err error) {
+	glog.Errorf("next tier %s is down, err: %v", nextURL, err)
+	tt.mtx.Lock()
+	tt.states[nextURL] = &tierState{checked: time.Now()}
+	tt.mtx.Unlock()
+}
+
+func (tt *tierTracker) getSmap(nextURL string) (*cluster.Smap, error) {
+	query := url.Values{cmn.URLParamWhat: []string{cmn.GetWhatSmap}}
+	geturl := nextURL + cmn.URLPath(cmn.Version, cmn.Daemon) + "?" + query.Encode()
+	ctx, cancel := context.WithTimeout(context.Background(), cmn.GCO.Get().Timeout.CplaneOperation)
+	defer cancel()
+	req, err := http.NewRequest(http.MethodGet, geturl, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := tt.t.httpclient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", geturl, resp.Status)
+	}
+	smap := &cluster.Smap{}
+	if err = jsoniter.NewDecoder(resp.Body).Decode(smap); err != nil {
+		return nil, fmt.Errorf("GET %s: failed to decode cluster map, err: %v", geturl, err)
+	}
+	if smap.ProxySI == nil {
+		return nil, fmt.Errorf("GET %s: cluster map without primary", geturl)
+	}
+	return smap, nil
+}
+
+func tierPath(ct context.Context) []string {
+	if tpath := getStringFromContext(ct, ctxTierPath); tpath != "" {
+		return strings.Split(tpath, ",")
+	}
+	return nil
+}
+
+//==========================
+//
+// reading and writing through
+//
+//==========================
+
+// cold GET: from the next tier (read policy "next_tier") or, if the next tier does
+// not provide the object, from the Cloud
+func (t *targetrunner) getColdUpstream(ct context.Context, workFQN string, lom *cluster.LOM) (err error, errcode int) {
+	if nextURL, tpath := t.tiers.route(ct, lom.BckProps, false); nextURL != "" {
+		if err, errcode = t.getNextTier(ct, lom, workFQN, nextURL, tpath); err == nil {
+			t.statsif.Add(stats.TierHitCount, 1)
+			return
+		}
+		t.statsif.Add(stats.TierMissCount, 1)
+		if errcode != http.StatusNotFound {
+			glog.Warningf("%v - reading from the Cloud", err)
+		}
+	}
+	return getcloudif(lom.Bucket, lom.BucketProvider).getobj(ct, workFQN, lom)
+}
+
+// local bucket: GET the object from the next tier (read policy "next_tier") and store
+// it locally; returns false if the next tier is not configured (or cannot be used)
+func (t *targetrunner) restoreFromNextTier(ct context.Context, lom *cluster.LOM) (restored bool, err error) {
+	nextURL, tpath := t.tiers.route(ct, lom.BckProps, false)
+	if nextURL == "" {
+		return
+	}
+	workFQN := lom.GenFQN(fs.WorkfileType, fs.WorkfileColdget)
+	if err, _ = t.getNextTier(ct, lom, workFQN, nextURL, tpath); err != nil {
+		t.statsif.Add(stats.TierMissCount, 1)
+		return
+	}
+	if err = cmn.MvFile(workFQN, lom.FQN); err != nil {
+		os.Remove(workFQN)
+		return
+	}
+	if err = lom.Persist(); err != nil {
+		return
+	}
+	lom.ReCache()
+	t.statsif.Add(stats.TierHitCount, 1)
+	return true, nil
+}
+
+// PUT: to the next tier (write policy "next_tier") or to the Cloud; Cloud buckets
+// fall back to the Cloud upon failure, local buckets fail unless the next tier is down
+func (t *targetrunner) putUpstream(ct context.Context, file *os.File, lom *cluster.LOM) (version string, err error,
+	errcode int) {
+	if nextURL, tpath := t.tiers.route(ct, lom.BckProps, true); nextURL != "" {
+		if version, err, errcode = t.putNextTier(ct, file, lom, nextURL, tpath); err == nil {
+			t.statsif.Add(stats.TierPutCount, 1)
+			return
+		}
+		if lom.BckIsLocal {
+			if errcode != http.StatusBadGateway {
+				return
+			}
+			glog.Warningf("%v - PUT locally", err)
+			return "", nil, 0
+		}
+		glog.Warningf("%v - writing to the Cloud", err)
+		t.statsif.Add(stats.TierFallbackCount, 1)
+	}
+	if lom.BckIsLocal {
+		return
+	}
+	return getcloudif(lom.Bucket, lom.BucketProvider).putobj(ct, file, lom)
+}
+
+// DELETE: from the next tier (write policy "next_tier") or from the Cloud; failures
+// are handled as per putUpstream, objects not found in the next tier are ignored
+func (t *targetrunner) deleteUpstream(ct context.Context, lom *cluster.LOM) (err error, errcode int) {
+	if nextURL, tpath := t.tiers.route(ct, lom.BckProps, true); nextURL != "" {
+		if err, errcode = t.deleteNextTier(ct, lom, nextURL, tpath); err == nil {
+			return
+		}
+		if lom.BckIsLocal {
+			if errcode != http.StatusBadGateway && errcode != http.StatusNotFound {
+				return
+			}
+			if errcode == http.StatusBadGateway {
+				glog.Warningf("%v - deleting locally", err)
+			}
+			return nil, 0
+		}
+		glog.Warningf("%v - deleting from the Cloud", err)
+		t.statsif.Add(stats.TierFallbackCount, 1)
+	}
+	if lom.BckIsLocal {
+		return
+	}
+	return getcloudif(lom.Bucket, lom.BucketProvider).deleteobj(ct, lom)
+}
+
+//======================
+//
+// next tier requests
+//
+//======================
+
+// sends the object request to the next tier; a next tier that does not respond is marked
+// down (StatusBadGateway); on success, the caller must close the response body
+func (t *targetrunner) tierDo(ct context.Context, method string, lom *cluster.LOM, nextURL, tpath string,
+	hdr http.Header, open func() (io.ReadCloser, error), size int64) (resp *http.Response, err error, errcode int) {
+	var (
+		body  io.ReadCloser
+		req   *http.Request
+		query = url.Values{cmn.URLParamBckProvider: []string{lom.BucketProvider}}
+		path  = cmn.URLPath(cmn.Version, cmn.Objects, lom.Bucket, lom.Objname) + "?" + query.Encode()
+	)
+	if open != nil {
+		if body, err = open(); err != nil {
+			return nil, err, http.StatusInternalServerError
+		}
+	}
+	if req, err = http.NewRequest(method, nextURL+path, body); err != nil {
+		return nil, err, http.StatusInternalServerError
+	}
+	if open != nil {
+		req.GetBody = open // to follow the proxy's redirect
+		req.ContentLength = size
+	}
+	for k, v := range hdr {
+		req.Header[k] = v
+	}
+	if tpath != "" {
+		req.Header.Set(cmn.HeaderTierPath, tpath)
+	}
+	if token := getStringFromContext(ct, ctxUserToken); token != "" {
+		req.Header.Set("Authorization", tokenStart+" "+token)
+	}
+	if resp, err = t.httpclientLongTimeout.Do(req.WithContext(ct)); err != nil {
+		if ct.Err() == nil {
+			t.tiers.down(nextURL, err)
+		}
+		return nil, fmt.Errorf("%s: %s to the next tier failed, err: %v", lom, method, err), http.StatusBadGateway
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		b, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		msg := strings.TrimSpace(string(b))
+		if msg == "" {
+			msg = resp.Status
+		}
+		return nil, fmt.Errorf("%s: %s to the next tier failed, status %d: %s", lom, method, resp.StatusCode, msg),
+			resp.StatusCode
+	}
+	return
+}
+
+func (t *targetrunner) getNextTier(ct context.Context, lom *cluster.LOM, workFQN, nextURL, tpath string) (err error,
+	errcode int) {
+	resp, err, errcode := t.tierDo(ct, http.MethodGet, lom, nextURL, tpath, nil, nil, 0)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	var (
+		cksum       = cmn.NewCksum(resp.Header.Get(cmn.HeaderObjCksumType), resp.Header.Get(cmn.HeaderObjCksumVal))
+		customMD, _ = cmn.CustomMDFromHeader(resp.Header)
+	)
+	lom.SetCksum(nil)
+	lom.SetVersion(resp.Header.Get(cmn.HeaderObjVersion))
+	lom.SetCustomMD(customMD)
+	roi := &recvObjInfo{
+		t:       t,
+		lom:     lom,
+		r:       resp.Body,
+		workFQN: workFQN,
+		cold:    true,
+	}
+	if err = roi.writeToFile(); err != nil {
+		return err, http.StatusInternalServerError
+	}
+	if cksum != nil && lom.CksumConf().ValidateColdGet && lom.Cksum() != nil {
+		if cksumType, _ := cksum.Get(); cksumType == lom.CksumConf().Type && !cmn.EqCksum(cksum, lom.Cksum()) {
+			os.Remove(workFQN)
+			return fmt.Errorf("%s: bad checksum from the next tier - expected %s, got %s", lom, cksum, lom.Cksum()),
+				http.StatusInternalServerError
+		}
+	}
+	if glog.FastV(4, glog.SmoduleAIS) {
+		glog.Infof("GET %s <= %s", lom, nextURL)
+	}
+	return
+}
+
+func (t *targetrunner) putNextTier(ct context.Context, file *os.File, lom *cluster.LOM, nextURL, tpath string) (
+	version string, err error, errcode int) {
+	content, size, err := lom.ContentAt(file) // decrypted, if need be
+	if err != nil {
+		return "", err, http.StatusInternalServerError
+	}
+	var (
+		hdr  = make(http.Header, len(lom.CustomMD())+2)
+		open = func() (io.ReadCloser, error) { return ioutil.NopCloser(io.NewSectionReader(content, 0, size)), nil }
+	)
+	if cksum := lom.Cksum(); cksum != nil {
+		cksumType, cksumValue := cksum.Get()
+		hdr.Set(cmn.HeaderObjCksumType, cksumType)
+		hdr.Set(cmn.HeaderObjCksumVal, cksumValue)
+	}
+	cmn.CustomMDToHeader(lom.CustomMD(), hdr)
+
+	ctx, cancel := context.WithTimeout(ct, lom.Config().Timeout.SendFile)
+	defer cancel()
+	resp, err, errcode := t.tierDo(ctx, http.MethodPut, lom, nextURL, tpath, hdr, open, size)
+	if err != nil {
+		return
+	}
+	resp.Body.Close()
+	version = resp.Header.Get(cmn.HeaderObjVersion)
+	if glog.FastV(4, glog.SmoduleAIS) {
+		glog.Infof("PUT %s => %s", lom, nextURL)
+	}
+	return
+}
+
+func (t *targetrunner) deleteNextTier(ct context.Context, lom *cluster.LOM, nextURL, tpath string) (err error,
+	errcode int) {
+	resp, err, errcode := t.tierDo(ct, http.MethodDelete, lom, nextURL, tpath, nil, nil, 0)
+	if err != nil {
+		return
+	}
+	resp.Body.Close()
+	if glog.FastV(4, glog.SmoduleAIS) {
+		glog.Infof("DELETE %s => %s", lom, nextURL)
+	}
+	return
+}
@@ -0,0 +1,91 @@
+// Package ais provides core functionality for the AIStore object storage.
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package ais
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/NVIDIA/aistore/cmn"
+	"github.com/NVIDIA/aistore/stats"
+)
+
+type tierStatsMock struct {
+	vals map[string]int64
+}
+
+func (m *tierStatsMock) Add(name string, val int64)            { m.vals[name] += val }
+func (m *tierStatsMock) AddErrorHTTP(method string, val int64) {}
+func (m *tierStatsMock) AddMany(nvs ...stats.NamedVal64) {
+	for _, nv := range nvs {
+		m.vals[nv.Name] += nv.Val
+	}
+}
+func (m *tierStatsMock) Register(name string, kind string) {}
+
+func TestTierRoute(t *testing.T) {
+	config := cmn.GCO.BeginUpdate()
+	config.Timeout.CplaneOperation = time.Second
+	cmn.GCO.CommitUpdate(config)
+
+	// the next tier: serves its cluster map with primary "next"
+	next := newSnode("next", httpProto, cmn.Proxy, &net.TCPAddr{}, &net.TCPAddr{}, &net.TCPAddr{})
+	nextSmap := newSmap()
+	nextSmap.addProxy(next)
+	nextSmap.ProxySI = next
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := json.Marshal(nextSmap)
+		w.Write(b)
+	}))
+	defer srv.Close()
+
+	tgt := &targetrunner{}
+	tgt.httpclient = &http.Client{}
+	tgt.statsif = &tierStatsMock{vals: make(map[string]int64)}
+	tgt.smapowner = newSmapowner()
+	self := newSnode("self", httpProto, cmn.Proxy, &net.TCPAddr{}, &net.TCPAddr{}, &net.TCPAddr{})
+	smap := newSmap()
+	smap.addProxy(self)
+	smap.ProxySI = self
+	tgt.smapowner.put(smap)
+	tgt.tiers = newTierTracker(tgt)
+
+	bprops := &cmn.BucketProps{NextTierURL: srv.URL, ReadPolicy: cmn.RWPolicyNextTier, WritePolicy: cmn.RWPolicyCloud}
+	tests := []struct {
+		name   string
+		tpath  string
+		write  bool
+		route  bool
+		result string
+	}{
+		{"read", "", false, true, "self"},
+		{"write-cloud-policy", "", true, false, ""},
+		{"forwarded", "first", false, true, "first,self"},
+		{"loop", "next,self", false, false, ""},
+		{"too-many-hops", "1,2,3,4,5,6,7", false, false, ""},
+	}
+	for _, test := range tests {
+		ct := context.WithValue(context.Background(), ctxTierPath, test.tpath)
+		nextURL, tpath := tgt.tiers.route(ct, bprops, test.write)
+		if (nextURL != "") != test.route || tpath != test.result {
+			t.Errorf("%s: expected (%t, %q), got (%q, %q)", test.name, test.route, test.result, nextURL, tpath)
+		}
+	}
+
+	// next tier down
+	srv.Close()
+	tgt.tiers = newTierTracker(tgt)
+	if nextURL, _ := tgt.tiers.route(context.Background(), bprops, false); nextURL != "" {
+		t.Errorf("expected no route to the next tier that is down")
+	}
+	if n := tgt.statsif.(*tierStatsMock).vals[stats.TierFallbackCount]; n != 3 {
+		t.Errorf("expected 3 fallbacks, got %d", n)
+	}
+}
@@ -0,0 +1,146 @@
+// Package ais provides core functionality for the AIStore object storage.
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package ais
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/NVIDIA/aistore/3rdparty/glog"
+	"github.com/NVIDIA/aistore/cluster"
+	"github.com/NVIDIA/aistore/cmn"
+	"github.com/NVIDIA/aistore/stats"
+	jsoniter "github.com/json-iterator/go"
+)
+
+//
+// Version history of the objects in local buckets (see cluster/lom_ver.go):
+//   GET    ?what=versions - list the current and the retained older versions
+//   GET    ?version=N     - read the given version
+//   DELETE ?version=N     - remove the given older version
+//
+
+// GET { what=versions } /v1/objects/bucket-name/object-name
+func (t *targetrunner) objGetVersions(w http.ResponseWriter, r *http.Request, lom *cluster.LOM) {
+	t.rtnamemap.Lock(lom.Uname(), false)
+	if _, errstr := lom.Load(true); errstr != "" {
+		t.rtnamemap.Unlock(lom.Uname(), false)
+		t.invalmsghdlr(w, r, errstr)
+		return
+	}
+	vers, err := lom.OlderVersions()
+	if err != nil {
+		t.rtnamemap.Unlock(lom.Uname(), false)
+		t.invalmsghdlr(w, r, fmt.Sprintf("%s: failed to list versions, err: %v", lom, err))
+		return
+	}
+	if lom.Exists() {
+		cur := cmn.ObjVersion{Version: lom.Version(), Size: lom.Size(), Current: true}
+		if lom.Cksum() != nil {
+			cur.CksumType, cur.Checksum = lom.Cksum().Get()
+		}
+		if finfo, err := os.Stat(lom.FQN); err == nil {
+			cur.Mtime = finfo.ModTime()
+		}
+		vers = append(vers, cur)
+	}
+	t.rtnamemap.Unlock(lom.Uname(), false)
+	if len(vers) == 0 {
+		t.invalmsghdlr(w, r, fmt.Sprintf("%s %s", lom, cmn.DoesNotExist), http.StatusNotFound)
+		return
+	}
+	jsbytes, err := jsoniter.Marshal(vers)
+	cmn.AssertNoErr(err)
+	t.writeJSON(w, r, jsbytes, "getversions")
+}
+
+// GET { version=N } /v1/objects/bucket-name/object-name
+// Streams back the retained older version of the object; returns false when the
+// requested version is the current one (to be handled as a regular GET)
+func (t *targetrunner) objGetOlderVersion(w http.ResponseWriter, r *http.Request, lom *cluster.LOM, version string,
+	started time.Time) (handled bool) {
+	t.rtnamemap.Lock(lom.Uname(), false)
+	defer t.rtnamemap.Unlock(lom.Uname(), false)
+	if _, errstr := lom.Load(true); errstr != "" {
+		t.invalmsghdlr(w, r, errstr)
+		return true
+	}
+	if lom.Exists() && lom.Version() == version {
+		return false
+	}
+	ver, fqn, err := lom.OlderVersion(version)
+	if err != nil {
+		if os.IsNotExist(err) {
+			t.invalmsghdlr(w, r, fmt.Sprintf("%s: version %s %s", lom, version, cmn.DoesNotExist), http.StatusNotFound)
+		} else {
+			t.invalmsghdlr(w, r, err.Error())
+		}
+		return true
+	}
+	file, err := lom.OpenVersion(fqn)
+	if err != nil {
+		t.fshc(err, fqn)
+		t.invalmsghdlr(w, r, fmt.Sprintf("%s: version %s, err: %v", lom, version, err), http.StatusInternalServerError)
+		return true
+	}
+	defer file.Close()
+
+	hdr := w.Header()
+	if ver.Checksum != "" {
+		hdr.Add(cmn.HeaderObjCksumType, ver.CksumType)
+		hdr.Add(cmn.HeaderObjCksumVal, ver.Checksum)
+	}
+	hdr.Add(cmn.HeaderObjVersion, ver.Version)
+	hdr.Add(cmn.HeaderObjSize, strconv.FormatInt(ver.Size, 10))
+
+	buf, slab := gmem2.AllocFromSlab2(cmn.MinI64(ver.Size, 8*cmn.MiB))
+	written, err := io.CopyBuffer(w, file, buf)
+	slab.Free(buf)
+	if err != nil {
+		// the status is already sent - log only
+		glog.Errorf("%s: failed to GET version %s, err: %v", lom, version, err)
+		t.statsif.Add(stats.ErrGetCount, 1)
+		return true
+	}
+	delta := time.Since(started)
+	if glog.FastV(4, glog.SmoduleAIS) {
+		glog.Infof("GET: %s, version %s (%s), %d µs", lom, version, cmn.B2S(written, 1), int64(delta/time.Microsecond))
+	}
+	t.statsif.AddMany(
+		stats.NamedVal64{Name: stats.GetThroughput, Val: written},
+		stats.NamedVal64{Name: stats.GetLatency, Val: int64(delta)},
+		stats.NamedVal64{Name: stats.GetCount, Val: 1},
+	)
+	return true
+}
+
+// DELETE { version=N } /v1/objects/bucket-name/object-name
+func (t *targetrunner) objDeleteVersion(w http.ResponseWriter, r *http.Request, lom *cluster.LOM, version string) {
+	t.rtnamemap.Lock(lom.Uname(), true)
+	defer t.rtnamemap.Unlock(lom.Uname(), true)
+	if _, errstr := lom.Load(true); errstr != "" {
+		t.invalmsghdlr(w, r, errstr)
+		return
+	}
+	if lom.Exists() && lom.Version() == version {
+		t.invalmsghdlr(w, r, fmt.Sprintf("%s: version %s is current - delete the object instead", lom, version))
+		return
+	}
+	if err := lom.DelVersion(version); err != nil {
+		if os.IsNotExist(err) {
+			t.invalmsghdlr(w, r, fmt.Sprintf("%s: version %s %s", lom, version, cmn.DoesNotExist), http.StatusNotFound)
+		} else {
+			t.invalmsghdlr(w, r, err.Error())
+		}
+		return
+	}
+	if glog.FastV(4, glog.SmoduleAIS) {
+		glog.Infof("DELETE: %s, version %s", lom, version)
+	}
+}
@@ -0,0 +1,467 @@
+// Package ais provides core functionality for the AIStore object storage.
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package ais
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/NVIDIA/aistore/3rdparty/glog"
+	"github.com/NVIDIA/aistore/cluster"
+	"github.com/NVIDIA/aistore/cmn"
+	"github.com/NVIDIA/aistore/fs"
+	"github.com/NVIDIA/aistore/stats"
+)
+
+//
+// Write-back (see cmn.WriteBackConf). PUT into a Cloud bucket with write-back
+// enabled returns as soon as the object is stored locally; the object is then
+// uploaded to the Cloud in the background. Each pending object is marked by an
+// (empty) file of the fs.WriteBackType content type - the markers are what
+// makes the queue persistent: upon restart the target walks its mountpaths and
+// resumes the uploads. Failed uploads are retried with exponential backoff.
+// Pending objects are never evicted by LRU; explicit eviction and global
+// rebalance upload the object first (see flushLocked). Background upload and
+// flush of the same object are serialized. If the bucket is mirrored, the
+// object is mirrored before PUT is acknowledged.
+//
+// NOTE: background uploads do not carry the original user's credentials
+//
+
+const (
+	wbWorkers       = 4
+	wbQueueSize     = 1024
+	wbRetryMin      = time.Second
+	wbRetryMax      = 5 * time.Minute
+	wbCheckInterval = 10 * time.Second
+)
+
+type (
+	wbEntry struct {
+		bucket, objname string
+		markerFQN       string
+		size            int64
+		gen             int64     // incremented upon every overwrite of the object
+		retries         int       // number of consecutive failures
+		next            time.Time // not to be retried before
+		queued          bool      // posted to the workers (or being uploaded)
+		cancelled       bool      // the object was deleted while pending
+		upl             sync.Mutex
+		uploaded        int64  // generation uploaded by the background upload
+		version         string // and its Cloud version
+	}
+	writeBack struct {
+		t       *targetrunner
+		mtx     sync.Mutex
+		entries map[string]*wbEntry // by uname
+		size    int64               // total size of the pending objects
+		workCh  chan *wbEntry
+		stopCh  chan struct{}
+		put     func(ct context.Context, file *os.File, lom *cluster.LOM) (string, error)
+	}
+)
+
+func newWriteBack(t *targetrunner) *writeBack {
+	wb := &writeBack{
+		t:       t,
+		entries: make(map[string]*wbEntry),
+		workCh:  make(chan *wbEntry, wbQueueSize),
+		stopCh:  make(chan struct{}),
+	}
+	wb.put = wb.putUpstream
+	return wb
+}
+
+func (wb *writeBack) putUpstream(ct context.Context, file *os.File, lom *cluster.LOM) (string, error) {
+	ver, err, _ := wb.t.putUpstream(ct, file, lom)
+	return ver, err
+}
+
+func (wb *writeBack) run() {
+	wb.load()
+	for i := 0; i < wbWorkers; i++ {
+		go wb.work()
+	}
+	ticker := time.NewTicker(wbCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			wb.check()
+		case <-wb.stopCh:
+			return
+		}
+	}
+}
+
+func (wb *writeBack) stop() { close(wb.stopCh) }
+
+func (wb *writeBack) backlog() (cnt, size int64) {
+	wb.mtx.Lock()
+	cnt, size = int64(len(wb.entries)), wb.size
+	wb.mtx.Unlock()
+	return
+}
+
+// marks the object as pending and schedules its upload; must be called under
+// the object's exclusive lock once the object is stored locally
+func (wb *writeBack) enqueue(lom *cluster.LOM) error {
+	markerFQN := lom.WriteBackFQN()
+	file, err := cmn.CreateFile(markerFQN)
+	if err != nil {
+		return err
+	}
+	err = file.Sync()
+	file.Close()
+	if err != nil {
+		os.Remove(markerFQN)
+		return err
+	}
+	wb.mtx.Lock()
+	e, ok := wb.entries[lom.Uname()]
+	if !ok {
+		e = &wbEntry{bucket: lom.Bucket, objname: lom.Objname}
+		wb.entries[lom.Uname()] = e
+	}
+	e.markerFQN = markerFQN
+	wb.size += lom.Size() - e.size
+	e.size = lom.Size()
+	e.gen++
+	e.retries, e.next = 0, time.Time{}
+	wb.postLocked(e)
+	wb.mtx.Unlock()
+	return nil
+}
+
+// resumes the uploads that were pending when the target stopped
+func (wb *writeBack) load() {
+	availablePaths, _ := fs.Mountpaths.Get()
+	for _, mpathInfo := range availablePaths {
+		dir := mpathInfo.MakePath(fs.WriteBackType, false /*cloud*/)
+		if err := filepath.Walk(dir, wb.walk); err != nil {
+			glog.Errorf("failed to traverse %s, err: %v", dir, err)
+		}
+	}
+	if cnt, size := wb.backlog(); cnt > 0 {
+		glog.Infof("write-back: %d object(s), %s total, pending upload", cnt, cmn.B2S(size, 2))
+	}
+}
+
+func (wb *writeBack) walk(fqn string, osfi os.FileInfo, err error) error {
+	if err != nil {
+		if errstr := cmn.PathWalkErr(err); errstr != "" {
+			glog.Error(errstr)
+			return err
+		}
+		return nil
+	}
+	if osfi.Mode().IsDir() {
+		return nil
+	}
+	parsedFQN, err := fs.Mountpaths.FQN2Info(fqn)
+	if err != nil {
+		glog.Warningf("write-back: invalid marker %s, err: %v", fqn, err)
+		return nil
+	}
+	lom, errstr := cluster.LOM{T: wb.t, Bucket: parsedFQN.Bucket, Objname: parsedFQN.Objname,
+		BucketProvider: cmn.CloudBs}.Init()
+	if errstr == "" {
+		_, errstr = lom.Load(true)
+	}
+	if errstr != "" {
+		glog.Warningf("write-back: %s/%s, err: %s", parsedFQN.Bucket, parsedFQN.Objname, errstr)
+		return nil
+	}
+	wb.mtx.Lock()
+	if _, ok := wb.entries[lom.Uname()]; !ok { // not overwritten since the target restarted
+		e := &wbEntry{bucket: lom.Bucket, objname: lom.Objname, markerFQN: fqn, size: lom.Size(), gen: 1}
+		wb.entries[lom.Uname()] = e
+		wb.size += e.size
+		wb.postLocked(e)
+	}
+	wb.mtx.Unlock()
+	return nil
+}
+
+// posts the entry to the workers unless already posted; when the work channel
+// is full the entry gets picked up later by the periodic check
+func (wb *writeBack) postLocked(e *wbEntry) {
+	if e.queued {
+		return
+	}
+	select {
+	case wb.workCh <- e:
+		e.queued = true
+	default:
+	}
+}
+
+func (wb *writeBack) check() {
+	now := time.Now()
+	wb.mtx.Lock()
+	for _, e := range wb.entries {
+		if !e.queued && !e.next.After(now) {
+			wb.postLocked(e)
+		}
+	}
+	wb.mtx.Unlock()
+}
+
+func (wb *writeBack) work() {
+	for {
+		select {
+		case e := <-wb.workCh:
+			wb.upload(e)
+		case <-wb.stopCh:
+			return
+		}
+	}
+}
+
+func (wb *writeBack) upload(e *wbEntry) {
+	wb.mtx.Lock()
+	gen, cancelled := e.gen, e.cancelled
+	wb.mtx.Unlock()
+	if cancelled {
+		return
+	}
+	lom, errstr := cluster.LOM{T: wb.t, Bucket: e.bucket, Objname: e.objname, BucketProvider: cmn.CloudBs}.Init()
+	if errstr != "" {
+		wb.failed(e, errors.New(errstr))
+		return
+	}
+	uname := lom.Uname()
+
+	// open the object under lock; the upload itself does not block overwrites
+	// as the (open) file remains intact when the object gets replaced
+	wb.t.rtnamemap.Lock(uname, false)
+	if _, errstr = lom.Load(true); errstr != "" {
+		wb.t.rtnamemap.Unlock(uname, false)
+		wb.failed(e, errors.New(errstr))
+		return
+	}
+	if !lom.Exists() { // removed by other means in the meantime
+		wb.t.rtnamemap.Unlock(uname, false)
+		wb.done(e, gen)
+		return
+	}
+	file, err := os.Open(lom.FQN)
+	wb.t.rtnamemap.Unlock(uname, false)
+	if err != nil {
+		wb.failed(e, err)
+		return
+	}
+	// serialize vs flushLocked that, in turn, holds the object's lock - hence,
+	// no locking of the object while holding upl
+	e.upl.Lock()
+	wb.mtx.Lock()
+	flushed := wb.entries[uname] != e
+	wb.mtx.Unlock()
+	if flushed {
+		e.upl.Unlock()
+		file.Close()
+		return
+	}
+	ver, err := wb.put(context.Background(), file, lom)
+	file.Close()
+	if err == nil {
+		wb.mtx.Lock()
+		e.uploaded, e.version = gen, ver
+		wb.mtx.Unlock()
+	}
+	e.upl.Unlock()
+	if err != nil {
+		wb.failed(e, err)
+		return
+	}
+
+	wb.t.rtnamemap.Lock(uname, true)
+	defer wb.t.rtnamemap.Unlock(uname, true)
+	if _, errstr = lom.Load(false); errstr != "" {
+		glog.Errorf("write-back: %s", errstr)
+	}
+	wb.mtx.Lock()
+	cancelled = e.cancelled
+	wb.mtx.Unlock()
+	if cancelled {
+		// deleted while being uploaded: undo the upload unless the object was PUT again
+		if !lom.Exists() {
+			if err, _ := wb.t.deleteUpstream(context.Background(), lom); err != nil {
+				glog.Errorf("write-back: %s: failed to delete from the Cloud, err: %v", lom, err)
+			}
+		}
+		return
+	}
+	if !wb.done(e, gen) {
+		return
+	}
+	if lom.Exists() {
+		lom.SetVersion(ver)
+		if err := lom.Persist(); err != nil {
+			glog.Errorf("write-back: failed to persist %s, err: %v", lom, err)
+		}
+		lom.ReCache()
+	}
+	wb.t.statsif.AddMany(
+		stats.NamedVal64{stats.WriteBackCount, 1},
+		stats.NamedVal64{stats.WriteBackSize, lom.Size()})
+	if glog.FastV(4, glog.SmoduleAIS) {
+		glog.Infof("write-back: %s uploaded (version %q)", lom, ver)
+	}
+}
+
+// removes the entry (and its marker) unless the object was overwritten after
+// the upload had started - in which case the object gets uploaded again
+func (wb *writeBack) done(e *wbEntry, gen int64) bool {
+	uname := cluster.Bo2Uname(e.bucket, e.objname)
+	wb.mtx.Lock()
+	if wb.entries[uname] != e { // flushed or cancelled
+		wb.mtx.Unlock()
+		return false
+	}
+	if e.gen != gen {
+		e.queued, e.retries, e.next = false, 0, time.Time{}
+		wb.postLocked(e)
+		wb.mtx.Unlock()
+		return false
+	}
+	delete(wb.entries, uname)
+	wb.size -= e.size
+	wb.mtx.Unlock()
+	if err := os.Remove(e.markerFQN); err != nil && !os.IsNotExist(err) {
+		glog.Errorf("write-back: failed to remove %s, err: %v", e.markerFQN, err)
+	}
+	return true
+}
+
+func (wb *writeBack) failed(e *wbEntry, err error) {
+	wb.mtx.Lock()
+	e.queued = false
+	e.retries++
+	backoff := wbBackoff(e.retries)
+	e.next = time.Now().Add(backoff)
+	retries := e.retries
+	wb.mtx.Unlock()
+	glog.Errorf("write-back: %s/%s: upload failed (attempt %d, next in %v), err: %v",
+		e.bucket, e.objname, retries, backoff, err)
+	wb.t.statsif.Add(stats.ErrWriteBackCount, 1)
+}
+
+// exponential: 1s, 2s, 4s, ... up to wbRetryMax
+func wbBackoff(retries int) time.Duration {
+	if retries > 16 {
+		return wbRetryMax
+	}
+	return cmn.MinDuration(wbRetryMin<<uint(retries-1), wbRetryMax)
+}
+
+// synchronously uploads the object if pending (unless the background upload
+// has just uploaded it); must be called under the object's exclusive lock
+func (wb *writeBack) flushLocked(ct context.Context, lom *cluster.LOM) error {
+	wb.mtx.Lock()
+	e, ok := wb.entries[lom.Uname()]
+	wb.mtx.Unlock()
+	if !ok || !lom.Exists() {
+		return nil
+	}
+	e.upl.Lock()
+	defer e.upl.Unlock()
+	wb.mtx.Lock()
+	ver, uploaded := e.version, e.uploaded == e.gen
+	wb.mtx.Unlock()
+	if !uploaded {
+		file, err := os.Open(lom.FQN)
+		if err != nil {
+			return err
+		}
+		ver, err = wb.put(ct, file, lom)
+		file.Close()
+		if err != nil {
+			return err
+		}
+	}
+	wb.mtx.Lock()
+	if wb.entries[lom.Uname()] == e {
+		delete(wb.entries, lom.Uname())
+		wb.size -= e.size
+	}
+	wb.mtx.Unlock()
+	if err := os.Remove(e.markerFQN); err != nil && !os.IsNotExist(err) {
+		glog.Errorf("write-back: failed to remove %s, err: %v", e.markerFQN, err)
+	}
+	lom.SetVersion(ver)
+	if err := lom.Persist(); err != nil {
+		glog.Errorf("write-back: failed to persist %s, err: %v", lom, err)
+	}
+	lom.ReCache()
+	wb.t.statsif.AddMany(
+		stats.NamedVal64{stats.WriteBackCount, 1},
+		stats.NamedVal64{stats.WriteBackSize, lom.Size()})
+	return nil
+}
+
+// synchronously uploads all pending objects of the bucket (e.g., prior to evicting the bucket)
+func (wb *writeBack) flushBucket(bucket string) error {
+	objnames := make([]string, 0, 16)
+	wb.mtx.Lock()
+	for _, e := range wb.entries {
+		if e.bucket == bucket {
+			objnames = append(objnames, e.objname)
+		}
+	}
+	wb.mtx.Unlock()
+	for _, objname := range objnames {
+		lom, errstr := cluster.LOM{T: wb.t, Bucket: bucket, Objname: objname, BucketProvider: cmn.CloudBs}.Init()
+		if errstr != "" {
+			return errors.New(errstr)
+		}
+		wb.t.rtnamemap.Lock(lom.Uname(), true)
+		if _, errstr = lom.Load(false); errstr == "" {
+			if err := wb.flushLocked(context.Background(), lom); err != nil {
+				errstr = fmt.Sprintf("%s: write-back failed, err: %v", lom, err)
+			}
+		}
+		wb.t.rtnamemap.Unlock(lom.Uname(), true)
+		if errstr != "" {
+			return errors.New(errstr)
+		}
+	}
+	return nil
+}
+
+// drops the pending upload of the object that is being deleted; returns true
+// if the object was pending; must be called under the object's exclusive lock
+func (wb *writeBack) cancelLocked(lom *cluster.LOM) (pending bool) {
+	wb.mtx.Lock()
+	e, ok := wb.entries[lom.Uname()]
+	if ok {
+		delete(wb.entries, lom.Uname())
+		wb.size -= e.size
+		e.cancelled = true
+	}
+	wb.mtx.Unlock()
+	if ok {
+		if err := os.Remove(e.markerFQN); err != nil && !os.IsNotExist(err) {
+			glog.Errorf("write-back: failed to remove %s, err: %v", e.markerFQN, err)
+		}
+	}
+	return ok
+}
+
+// the object is acknowledged before it is uploaded - make sure it is on stable storage
+func fsyncFile(fqn string) error {
+	file, err := os.OpenFile(fqn, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	err = file.Sync()
+	file.Close()
+	return err
+}
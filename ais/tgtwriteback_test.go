@@ -0,0 +1,248 @@
+// Package ais provides core functionality for the AIStore object storage.
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package ais
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/NVIDIA/aistore/cluster"
+	"github.com/NVIDIA/aistore/cmn"
+	"github.com/NVIDIA/aistore/fs"
+	"github.com/NVIDIA/aistore/memsys"
+)
+
+func TestWriteBackBackoff(t *testing.T) {
+	tests := []struct {
+		retries int
+		backoff time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{5, 16 * time.Second},
+		{9, 256 * time.Second},
+		{10, wbRetryMax},
+		{100, wbRetryMax},
+	}
+	for _, tst := range tests {
+		if b := wbBackoff(tst.retries); b != tst.backoff {
+			t.Errorf("retries %d: expected %v, got %v", tst.retries, tst.backoff, b)
+		}
+	}
+}
+
+func TestWriteBackSchedule(t *testing.T) {
+	wb := newWriteBack(nil)
+	now := time.Now()
+	due := &wbEntry{bucket: "b", objname: "due", size: 10}
+	later := &wbEntry{bucket: "b", objname: "later", size: 20, next: now.Add(time.Hour)}
+	wb.entries["b/due"], wb.entries["b/later"] = due, later
+	wb.size = 30
+
+	wb.check()
+	if len(wb.workCh) != 1 || <-wb.workCh != due || !due.queued || later.queued {
+		t.Fatalf("expected only the due entry to be posted")
+	}
+	wb.check() // already posted
+	if len(wb.workCh) != 0 {
+		t.Fatalf("expected no duplicate posting")
+	}
+	if cnt, size := wb.backlog(); cnt != 2 || size != 30 {
+		t.Errorf("expected backlog (2, 30), got (%d, %d)", cnt, size)
+	}
+
+	// overwrite while being uploaded: the upload must not complete the entry
+	gen := due.gen
+	due.gen++
+	if wb.done(due, gen) || !due.queued || len(wb.workCh) != 1 {
+		t.Errorf("expected the overwritten entry to be re-posted")
+	}
+}
+
+func TestWriteBackValidate(t *testing.T) {
+	conf := cmn.WriteBackConf{Enabled: true}
+	if err := conf.ValidateAsProps(&cmn.ValidationArgs{BckIsLocal: true}); err == nil {
+		t.Errorf("expected write-back to be rejected for local buckets")
+	}
+	if err := conf.ValidateAsProps(&cmn.ValidationArgs{BckIsLocal: false}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func newWriteBackTest(t *testing.T) (wb *writeBack, cleanup func()) {
+	dir, err := ioutil.TempDir("", "wb-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldMountpaths, oldProvider := fs.Mountpaths, cmn.GCO.Get().CloudProvider
+	fs.Mountpaths = fs.NewMountedFS()
+	if err := fs.Mountpaths.Add(dir); err != nil {
+		t.Fatal(err)
+	}
+	_ = fs.CSM.RegisterFileType(fs.ObjectType, &fs.ObjectContentResolver{})
+	_ = fs.CSM.RegisterFileType(fs.WorkfileType, &fs.WorkfileContentResolver{})
+	_ = fs.CSM.RegisterFileType(fs.WriteBackType, &fs.WriteBackContentResolver{})
+	cmn.GCO.Get().CloudProvider = cmn.ProviderAmazon
+	gmem2 = &memsys.Mem2{Name: "wbmem"}
+	if err := gmem2.Init(true); err != nil {
+		t.Fatal(err)
+	}
+
+	tgt := &targetrunner{}
+	tgt.statsif = &tierStatsMock{vals: make(map[string]int64)}
+	tgt.rtnamemap = newrtnamemap()
+	tgt.bmdowner = newBmdowner()
+	bmd := newBucketMD()
+	bmd.add("wb", false, &cmn.BucketProps{
+		Cksum:     cmn.CksumConf{Type: cmn.ChecksumNone},
+		WriteBack: cmn.WriteBackConf{Enabled: true},
+	})
+	tgt.bmdowner.put(bmd)
+	wb = newWriteBack(tgt)
+	cleanup = func() {
+		os.RemoveAll(dir)
+		fs.Mountpaths = oldMountpaths
+		cmn.GCO.Get().CloudProvider = oldProvider
+	}
+	return
+}
+
+// creates the object along with its write-back marker, as if PUT prior to restart
+func newWriteBackObj(t *testing.T, wb *writeBack, objname string, size int) *cluster.LOM {
+	lom, errstr := cluster.LOM{T: wb.t, Bucket: "wb", Objname: objname, BucketProvider: cmn.CloudBs}.Init()
+	if errstr != "" {
+		t.Fatal(errstr)
+	}
+	if err := cmn.CreateDir(filepath.Dir(lom.FQN)); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(lom.FQN, make([]byte, size), 0644); err != nil {
+		t.Fatal(err)
+	}
+	lom.SetSize(int64(size))
+	if err := lom.Persist(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmn.CreateDir(filepath.Dir(lom.WriteBackFQN())); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(lom.WriteBackFQN(), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return lom
+}
+
+func TestWriteBackLoad(t *testing.T) {
+	wb, cleanup := newWriteBackTest(t)
+	defer cleanup()
+	newWriteBackObj(t, wb, "a/dirty", 100)
+	newWriteBackObj(t, wb, "b/dirty", 50)
+
+	wb.load()
+	if cnt, size := wb.backlog(); cnt != 2 || size != 150 {
+		t.Fatalf("expected backlog (2, 150), got (%d, %d)", cnt, size)
+	}
+	if len(wb.workCh) != 2 {
+		t.Errorf("expected both objects to be posted, got %d", len(wb.workCh))
+	}
+	wb.load() // already pending
+	if cnt, _ := wb.backlog(); cnt != 2 || len(wb.workCh) != 2 {
+		t.Errorf("expected no duplicates upon reload")
+	}
+}
+
+func TestWriteBackUploadFlush(t *testing.T) {
+	wb, cleanup := newWriteBackTest(t)
+	defer cleanup()
+	var (
+		calls, running int32
+		fail           bool
+		started        = make(chan struct{}, 1)
+		release        = make(chan struct{})
+	)
+	wb.put = func(ct context.Context, file *os.File, lom *cluster.LOM) (string, error) {
+		if atomic.AddInt32(&running, 1) > 1 {
+			t.Errorf("%s: concurrent uploads", lom)
+		}
+		defer atomic.AddInt32(&running, -1)
+		n := atomic.AddInt32(&calls, 1)
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+		if fail {
+			return "", errors.New("upload failed")
+		}
+		return fmt.Sprintf("v%d", n), nil
+	}
+	flush := func(lom *cluster.LOM) error {
+		wb.t.rtnamemap.Lock(lom.Uname(), true)
+		defer wb.t.rtnamemap.Unlock(lom.Uname(), true)
+		if _, errstr := lom.Load(false); errstr != "" {
+			return errors.New(errstr)
+		}
+		return wb.flushLocked(context.Background(), lom)
+	}
+
+	// background upload
+	close(release)
+	lom := newWriteBackObj(t, wb, "upload", 10)
+	wb.load()
+	wb.upload(<-wb.workCh)
+	if cnt, _ := wb.backlog(); cnt != 0 || lom.WriteBackPending() {
+		t.Fatalf("expected the upload to complete")
+	}
+	if _, errstr := lom.Load(false); errstr != "" || lom.Version() != "v1" {
+		t.Errorf("expected version v1, got %q (%s)", lom.Version(), errstr)
+	}
+
+	// flush while the background upload is in progress: the object is uploaded once
+	release = make(chan struct{})
+	lom = newWriteBackObj(t, wb, "flush", 10)
+	wb.load()
+	e := <-wb.workCh
+	done := make(chan struct{})
+	go func() {
+		wb.upload(e)
+		close(done)
+	}()
+	<-started
+	errCh := make(chan error, 1)
+	go func() { errCh <- flush(lom) }()
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	if err := <-errCh; err != nil {
+		t.Fatal(err)
+	}
+	<-done
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Errorf("expected the object to be uploaded once, got %d upload(s)", n-1)
+	}
+	if cnt, _ := wb.backlog(); cnt != 0 || lom.WriteBackPending() {
+		t.Errorf("expected the flush to complete")
+	}
+	if _, errstr := lom.Load(false); errstr != "" || lom.Version() != "v2" {
+		t.Errorf("expected version v2, got %q (%s)", lom.Version(), errstr)
+	}
+
+	// failed flush keeps the object pending
+	fail = true
+	lom = newWriteBackObj(t, wb, "fail", 10)
+	wb.load()
+	if err := flush(lom); err == nil {
+		t.Errorf("expected the flush to fail")
+	}
+	if cnt, _ := wb.backlog(); cnt != 1 || !lom.WriteBackPending() {
+		t.Errorf("expected the object to remain pending")
+	}
+}
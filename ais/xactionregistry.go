@@ -31,6 +31,9 @@ type (
 	xactLRU struct {
 		cmn.XactBase
 	}
+	xactLifecycle struct {
+		cmn.XactBase
+	}
 	xactPrefetch struct {
 		cmn.XactBase
 	}
@@ -56,7 +59,7 @@ func newXactions() *xactionsRegistry {
 	return &xactionsRegistry{}
 }
 
-var mountpathXactions = []string{cmn.ActLRU, cmn.ActPutCopies, cmn.ActMakeNCopies, cmn.ActECGet, cmn.ActECPut, cmn.ActECRespond, cmn.ActLocalReb, cmn.ActLoadLomCache}
+var mountpathXactions = []string{cmn.ActLRU, cmn.ActLifecycle, cmn.ActPutCopies, cmn.ActMakeNCopies, cmn.ActECGet, cmn.ActECPut, cmn.ActECRespond, cmn.ActLocalReb, cmn.ActLoadLomCache, cmn.ActCopyBucket, cmn.ActECScrub, cmn.ActECEncode}
 
 func (r *xactionsRegistry) abortBuckets(buckets ...string) {
 	wg := &sync.WaitGroup{}
@@ -37,6 +37,10 @@ type (
 		baseXactEntry
 		xact *xactLRU
 	}
+	lifecycleEntry struct {
+		baseXactEntry
+		xact *xactLifecycle
+	}
 	prefetchEntry struct {
 		sync.RWMutex
 		stats stats.PrefetchTargetStats
@@ -88,11 +92,35 @@ type (
 		xact   *mirror.XactBckMakeNCopies
 		bucket string
 	}
+	copyBckEntry struct {
+		sync.RWMutex
+		stats  stats.CopyBckTargetStats
+		xact   *mirror.XactBckCopy
+		bucket string
+	}
+	syncBckEntry struct {
+		sync.RWMutex
+		stats  stats.SyncBckTargetStats
+		xact   *xactBckSync
+		bucket string
+	}
 	loadLomCacheEntry struct {
 		baseXactEntry
 		xact   *mirror.XactBckLoadLomCache
 		bucket string
 	}
+	scrubECEntry struct {
+		sync.RWMutex
+		stats  stats.ScrubECTargetStats
+		xact   *ec.XactScrub
+		bucket string
+	}
+	encodeECEntry struct {
+		sync.RWMutex
+		stats  stats.EncodeECTargetStats
+		xact   *ec.XactEncode
+		bucket string
+	}
 )
 
 //
@@ -120,6 +148,27 @@ func (r *xactionsRegistry) renewLRU() *xactLRU {
 	return entry.xact
 }
 
+func (r *xactionsRegistry) renewLifecycle() *xactLifecycle {
+	entry := &lifecycleEntry{}
+	entry.Lock()
+	defer entry.Unlock()
+
+	val, loaded := r.globalXacts.LoadOrStore(cmn.ActLifecycle, entry)
+
+	if loaded {
+		entry = val.(*lifecycleEntry)
+		entry.Lock()
+		defer entry.Unlock()
+		if isXrunning(entry.xact) {
+			return nil
+		}
+	}
+	id := r.uniqueID()
+	entry.xact = &xactLifecycle{XactBase: *cmn.NewXactBase(id, cmn.ActLifecycle)}
+	r.byID.Store(id, entry)
+	return entry.xact
+}
+
 func (r *xactionsRegistry) renewPrefetch() *xactPrefetch {
 	entry := &prefetchEntry{}
 	entry.Lock()
@@ -412,6 +461,126 @@ func (r *xactionsRegistry) renewBckMakeNCopies(bucket string, t *targetrunner, c
 	r.byID.Store(id, entry)
 }
 
+// returns nil if the bucket is already being copied
+func (r *xactionsRegistry) renewBckCopy(bucket, dstBucket string, t *targetrunner, bckIsLocal bool,
+	match func(string) bool, fetch mirror.CopyFetchFunc, send mirror.CopySendFunc) *mirror.XactBckCopy {
+	bckXacts := r.bucketsXacts(bucket)
+
+	newEntry := &copyBckEntry{}
+	newEntry.Lock()
+	defer newEntry.Unlock()
+	val, loaded := bckXacts.LoadOrStore(cmn.ActCopyBucket, newEntry)
+
+	var entry *copyBckEntry
+
+	if loaded {
+		entry = val.(*copyBckEntry)
+		entry.Lock()
+		defer entry.Unlock()
+		if isXrunning(entry.xact) {
+			return nil
+		}
+	} else {
+		entry = newEntry
+	}
+	id := r.uniqueID()
+	x := mirror.NewXactBckCopy(id, bucket, dstBucket, t, bckIsLocal, match, fetch, send)
+	go x.Run()
+	entry.xact = x
+	entry.bucket = bucket
+	r.byID.Store(id, entry)
+	return x
+}
+
+// returns nil if the bucket is already being synchronized
+func (r *xactionsRegistry) renewBckSync(bucket string) *xactBckSync {
+	bckXacts := r.bucketsXacts(bucket)
+
+	newEntry := &syncBckEntry{}
+	newEntry.Lock()
+	defer newEntry.Unlock()
+	val, loaded := bckXacts.LoadOrStore(cmn.ActSyncBucket, newEntry)
+
+	var entry *syncBckEntry
+
+	if loaded {
+		entry = val.(*syncBckEntry)
+		entry.Lock()
+		defer entry.Unlock()
+		if isXrunning(entry.xact) {
+			return nil
+		}
+	} else {
+		entry = newEntry
+	}
+	id := r.uniqueID()
+	entry.xact = &xactBckSync{XactBase: *cmn.NewXactBaseWithBucket(id, cmn.ActSyncBucket, bucket, false /*cloud*/)}
+	entry.bucket = bucket
+	r.byID.Store(id, entry)
+	return entry.xact
+}
+
+// returns nil if the bucket is already being scrubbed
+func (r *xactionsRegistry) renewScrubEC(bucket string) *ec.XactScrub {
+	bckXacts := r.bucketsXacts(bucket)
+
+	newEntry := &scrubECEntry{}
+	newEntry.Lock()
+	defer newEntry.Unlock()
+	val, loaded := bckXacts.LoadOrStore(cmn.ActECScrub, newEntry)
+
+	var entry *scrubECEntry
+
+	if loaded {
+		entry = val.(*scrubECEntry)
+		entry.Lock()
+		defer entry.Unlock()
+		if isXrunning(entry.xact) {
+			return nil
+		}
+	} else {
+		entry = newEntry
+	}
+	id := r.uniqueID()
+	x := ECM.newScrubXact(bucket)
+	x.XactBase = *cmn.NewXactBaseWithBucket(id, cmn.ActECScrub, bucket, true /* local */)
+	entry.xact = x
+	entry.bucket = bucket
+	r.byID.Store(id, entry)
+	return x
+}
+
+// returns nil if the bucket is already being encoded (by the xaction that
+// has not been aborted)
+func (r *xactionsRegistry) renewEncodeEC(bucket string) *ec.XactEncode {
+	bckXacts := r.bucketsXacts(bucket)
+
+	newEntry := &encodeECEntry{}
+	newEntry.Lock()
+	defer newEntry.Unlock()
+	val, loaded := bckXacts.LoadOrStore(cmn.ActECEncode, newEntry)
+
+	var entry *encodeECEntry
+
+	if loaded {
+		entry = val.(*encodeECEntry)
+		entry.Lock()
+		defer entry.Unlock()
+		if isXrunning(entry.xact) && !entry.xact.Aborted() {
+			return nil
+		}
+	} else {
+		entry = newEntry
+	}
+	id := r.uniqueID()
+	x := ECM.newEncodeXact(bucket)
+	x.XactBase = *cmn.NewXactBaseWithBucket(id, cmn.ActECEncode, bucket, true /* local */)
+	entry.xact = x
+	entry.bucket = bucket
+	r.byID.Store(id, entry)
+	return x
+}
+
 func (r *xactionsRegistry) renewBckLoadLomCache(bucket string, t cluster.Target, bckIsLocal bool) {
 	bckXacts := r.bucketsXacts(bucket)
 	newEntry := &loadLomCacheEntry{}
@@ -498,6 +667,19 @@ func (e *lruEntry) Abort() {
 	}
 }
 
+func (e *lifecycleEntry) Get() cmn.Xact { return e.xact }
+func (e *lifecycleEntry) Stats() stats.XactStats {
+	e.RLock()
+	s := e.stats.FromXact(e.xact, "")
+	e.RUnlock()
+	return s
+}
+func (e *lifecycleEntry) Abort() {
+	if e.xact != nil && !e.xact.Finished() {
+		e.xact.Abort()
+	}
+}
+
 func (e *prefetchEntry) Get() cmn.Xact { return e.xact }
 func (e *prefetchEntry) Stats() stats.XactStats {
 	e.RLock()
@@ -647,6 +829,92 @@ func (e *putCopiesEntry) Abort() {
 	}
 }
 
+func (e *copyBckEntry) Get() cmn.Xact { return e.xact }
+func (e *copyBckEntry) Stats() stats.XactStats {
+	e.RLock()
+	e.stats.FromXact(e.xact, e.bucket)
+	e.stats.XactCountX = e.xact.ObjectsCnt()
+	e.stats.Ext = stats.ExtCopyBckStats{
+		DstBucket:      e.xact.DstBucket(),
+		NumCopiedFiles: e.xact.ObjectsCnt(),
+		NumCopiedBytes: e.xact.BytesCnt(),
+		NumErrors:      e.xact.ErrorsCnt(),
+	}
+	s := &e.stats
+	e.RUnlock()
+	return s
+}
+func (e *copyBckEntry) Abort() {
+	if e.xact != nil && !e.xact.Finished() {
+		e.xact.Abort()
+	}
+}
+
+func (e *syncBckEntry) Get() cmn.Xact { return e.xact }
+func (e *syncBckEntry) Stats() stats.XactStats {
+	e.RLock()
+	e.stats.FromXact(e.xact, e.bucket)
+	e.stats.XactCountX = e.xact.fetched.Load() + e.xact.evicted.Load()
+	e.stats.Ext = stats.ExtSyncBckStats{
+		NumFetchedFiles: e.xact.fetched.Load(),
+		NumFetchedBytes: e.xact.fetchedSize.Load(),
+		NumEvictedFiles: e.xact.evicted.Load(),
+		NumErrors:       e.xact.errors.Load(),
+	}
+	s := &e.stats
+	e.RUnlock()
+	return s
+}
+func (e *syncBckEntry) Abort() {
+	if e.xact != nil && !e.xact.Finished() {
+		e.xact.Abort()
+	}
+}
+
+func (e *scrubECEntry) Get() cmn.Xact { return e.xact }
+func (e *scrubECEntry) Stats() stats.XactStats {
+	e.RLock()
+	e.stats.FromXact(e.xact, e.bucket)
+	ecStats := e.xact.Stats()
+	e.stats.XactCountX = ecStats.ScrubObj
+	e.stats.Ext = stats.ExtScrubECStats{
+		NumObjs:      ecStats.ScrubObj,
+		NumMissing:   ecStats.ScrubMissing,
+		NumCorrupted: ecStats.ScrubCorrupt,
+		NumRepaired:  ecStats.ScrubRepaired,
+		NumErrors:    ecStats.ScrubErr,
+	}
+	s := &e.stats
+	e.RUnlock()
+	return s
+}
+func (e *scrubECEntry) Abort() {
+	if e.xact != nil && !e.xact.Finished() {
+		e.xact.Abort()
+	}
+}
+
+func (e *encodeECEntry) Get() cmn.Xact { return e.xact }
+func (e *encodeECEntry) Stats() stats.XactStats {
+	e.RLock()
+	e.stats.FromXact(e.xact, e.bucket)
+	ecStats := e.xact.Stats()
+	e.stats.XactCountX = ecStats.BckEncoded + ecStats.BckReEncoded
+	e.stats.Ext = stats.ExtEncodeECStats{
+		NumEncoded:   ecStats.BckEncoded,
+		NumReEncoded: ecStats.BckReEncoded,
+		NumErrors:    ecStats.BckEncodeErr,
+	}
+	s := &e.stats
+	e.RUnlock()
+	return s
+}
+func (e *encodeECEntry) Abort() {
+	if e.xact != nil && !e.xact.Finished() {
+		e.xact.Abort()
+	}
+}
+
 func (e *loadLomCacheEntry) Get() cmn.Xact { return e.xact }
 func (e *loadLomCacheEntry) Stats() stats.XactStats {
 	e.RLock()
@@ -133,6 +133,12 @@ func HeadBucket(baseParams *BaseParams, bucket string, query ...url.Values) (p *
 	} else {
 		return
 	}
+	if v := r.Header.Get(cmn.HeaderBucketVerMaxVersions); v != "" {
+		if verProps.MaxVersions, err = strconv.Atoi(v); err != nil {
+			return
+		}
+	}
+	verProps.RetentionStr = r.Header.Get(cmn.HeaderBucketVerRetention)
 
 	lruProps := cmn.LRUConf{
 		DontEvictTimeStr:   r.Header.Get(cmn.HeaderBucketDontEvictTime),
@@ -190,6 +196,41 @@ func HeadBucket(baseParams *BaseParams, bucket string, query ...url.Values) (p *
 	} else {
 		return
 	}
+	ecProps.Code = r.Header.Get(cmn.HeaderBucketECCode)
+	if s := r.Header.Get(cmn.HeaderBucketECLocalGroups); s != "" {
+		if n, err = strconv.ParseInt(s, 10, 32); err != nil {
+			return
+		}
+		ecProps.LocalGroups = int(n)
+	}
+
+	quotaProps := cmn.QuotaConf{}
+	for hdr, v := range map[string]*int64{
+		cmn.HeaderBucketQuotaSoftSize: &quotaProps.SoftSize,
+		cmn.HeaderBucketQuotaHardSize: &quotaProps.HardSize,
+		cmn.HeaderBucketQuotaSoftObjs: &quotaProps.SoftObjs,
+		cmn.HeaderBucketQuotaHardObjs: &quotaProps.HardObjs,
+	} {
+		if s := r.Header.Get(hdr); s != "" {
+			if *v, err = strconv.ParseInt(s, 10, 64); err != nil {
+				return
+			}
+		}
+	}
+
+	writeBackProps := cmn.WriteBackConf{}
+	if s := r.Header.Get(cmn.HeaderBucketWriteBack); s != "" {
+		if writeBackProps.Enabled, err = strconv.ParseBool(s); err != nil {
+			return
+		}
+	}
+
+	encryptionProps := cmn.EncryptionConf{KeyID: r.Header.Get(cmn.HeaderBucketEncryptionKey)}
+	if s := r.Header.Get(cmn.HeaderBucketEncryption); s != "" {
+		if encryptionProps.Enabled, err = strconv.ParseBool(s); err != nil {
+			return
+		}
+	}
 
 	p = &cmn.BucketProps{
 		CloudProvider: r.Header.Get(cmn.HeaderCloudProvider),
@@ -201,6 +242,42 @@ func HeadBucket(baseParams *BaseParams, bucket string, query ...url.Values) (p *
 		LRU:           lruProps,
 		Mirror:        mirrorProps,
 		EC:            ecProps,
+		Quota:         quotaProps,
+		WriteBack:     writeBackProps,
+		Encryption:    encryptionProps,
+	}
+	return
+}
+
+// GetBucketUsage API
+//
+// Returns the cluster-wide capacity usage and number of objects of the bucket;
+// the usage is tracked (and reported) only for the buckets that have quotas
+func GetBucketUsage(baseParams *BaseParams, bucket string, query ...url.Values) (usage *cmn.BucketUsage, err error) {
+	var (
+		path      = cmn.URLPath(cmn.Version, cmn.Buckets, bucket)
+		optParams = OptionalParams{}
+		r         *http.Response
+	)
+	baseParams.Method = http.MethodHead
+	if len(query) > 0 {
+		optParams.Query = query[0]
+	}
+	if r, err = doHTTPRequestGetResp(baseParams, path, nil, optParams); err != nil {
+		return
+	}
+	defer r.Body.Close()
+
+	usage = &cmn.BucketUsage{}
+	if s := r.Header.Get(cmn.HeaderBucketUsedSize); s != "" {
+		if usage.Size, err = strconv.ParseInt(s, 10, 64); err != nil {
+			return nil, err
+		}
+	}
+	if s := r.Header.Get(cmn.HeaderBucketUsedObjs); s != "" {
+		if usage.Objs, err = strconv.ParseInt(s, 10, 64); err != nil {
+			return nil, err
+		}
 	}
 	return
 }
@@ -272,6 +349,83 @@ func RenameLocalBucket(baseParams *BaseParams, oldName, newName string) error {
 	return err
 }
 
+// CopyBucket API
+//
+// CopyBucket starts an extended action (xaction) that copies the objects of a given bucket
+// (optionally, only those that pass the filters in the message) into the destination bucket;
+// the progress can be monitored via GetXactionResponse
+func CopyBucket(baseParams *BaseParams, bucket, bckProvider string, msg *cmn.CopyBucketMsg) error {
+	b, err := jsoniter.Marshal(cmn.ActionMsg{Action: cmn.ActCopyBucket, Value: msg})
+	if err != nil {
+		return err
+	}
+	baseParams.Method = http.MethodPost
+	path := cmn.URLPath(cmn.Version, cmn.Buckets, bucket)
+	query := url.Values{cmn.URLParamBckProvider: []string{bckProvider}}
+	_, err = DoHTTPRequest(baseParams, path, b, OptionalParams{Query: query})
+	return err
+}
+
+// SyncBucket API
+//
+// SyncBucket starts an extended action (xaction) that synchronizes the cached
+// Cloud bucket with the Cloud: fetches the objects that are new or changed in
+// the Cloud and, if requested, evicts the objects deleted from the Cloud.
+// With msg.DryRun set, nothing is done - the returned report lists the objects instead
+func SyncBucket(baseParams *BaseParams, bucket, bckProvider string, msg *cmn.SyncBucketMsg) (*cmn.SyncBucketReport, error) {
+	b, err := jsoniter.Marshal(cmn.ActionMsg{Action: cmn.ActSyncBucket, Value: msg})
+	if err != nil {
+		return nil, err
+	}
+	baseParams.Method = http.MethodPost
+	path := cmn.URLPath(cmn.Version, cmn.Buckets, bucket)
+	query := url.Values{cmn.URLParamBckProvider: []string{bckProvider}}
+	resp, err := DoHTTPRequest(baseParams, path, b, OptionalParams{Query: query})
+	if err != nil || !msg.DryRun {
+		return nil, err
+	}
+	report := &cmn.SyncBucketReport{}
+	if err = jsoniter.Unmarshal(resp, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// ScrubBucketEC API
+//
+// ScrubBucketEC starts an extended action (xaction) that verifies the slices
+// and replicas of the erasure coded objects in the local bucket and re-encodes
+// the objects whose slices or replicas are missing or corrupted
+func ScrubBucketEC(baseParams *BaseParams, bucket string) error {
+	b, err := jsoniter.Marshal(cmn.ActionMsg{Action: cmn.ActECScrub})
+	if err != nil {
+		return err
+	}
+	baseParams.Method = http.MethodPost
+	path := cmn.URLPath(cmn.Version, cmn.Buckets, bucket)
+	query := url.Values{cmn.URLParamBckProvider: []string{cmn.LocalBs}}
+	_, err = DoHTTPRequest(baseParams, path, b, OptionalParams{Query: query})
+	return err
+}
+
+// EncodeBucketEC API
+//
+// EncodeBucketEC starts an extended action (xaction) that erasure codes the
+// existing objects of the local bucket: the objects that are not protected
+// yet, and the objects encoded with EC configuration different from the
+// current one
+func EncodeBucketEC(baseParams *BaseParams, bucket string) error {
+	b, err := jsoniter.Marshal(cmn.ActionMsg{Action: cmn.ActECEncode})
+	if err != nil {
+		return err
+	}
+	baseParams.Method = http.MethodPost
+	path := cmn.URLPath(cmn.Version, cmn.Buckets, bucket)
+	query := url.Values{cmn.URLParamBckProvider: []string{cmn.LocalBs}}
+	_, err = DoHTTPRequest(baseParams, path, b, OptionalParams{Query: query})
+	return err
+}
+
 // DeleteList API
 //
 // DeleteList sends a HTTP request to remove a list of objects from a bucket
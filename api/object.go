@@ -43,6 +43,7 @@ type PutObjectArgs struct {
 	Object         string
 	Hash           string
 	Reader         cmn.ReadOpenCloser
+	CustomMD       cmn.SimpleKVs // user-defined metadata (optional)
 }
 
 // HeadObject API
@@ -75,9 +76,15 @@ func HeadObject(baseParams *BaseParams, bucket, bckProvider, object string, chec
 		return nil, err
 	}
 
+	customMD, err := cmn.CustomMDFromHeader(r.Header)
+	if err != nil {
+		return nil, err
+	}
+
 	return &cmn.ObjectProps{
-		Size:    size,
-		Version: r.Header.Get(cmn.HeaderObjVersion),
+		Size:     size,
+		Version:  r.Header.Get(cmn.HeaderObjVersion),
+		CustomMD: customMD,
 	}, nil
 }
 
@@ -94,6 +101,41 @@ func DeleteObject(baseParams *BaseParams, bucket, object, bckProvider string) er
 	return err
 }
 
+// DeleteObjectVersion API
+//
+// Deletes the specified older (retained) version of an object in a local bucket
+func DeleteObjectVersion(baseParams *BaseParams, bucket, object, bckProvider, version string) error {
+	baseParams.Method = http.MethodDelete
+	path := cmn.URLPath(cmn.Version, cmn.Objects, bucket, object)
+	query := url.Values{cmn.URLParamBckProvider: []string{bckProvider}, cmn.URLParamVersion: []string{version}}
+	params := OptionalParams{Query: query}
+
+	_, err := DoHTTPRequest(baseParams, path, nil, params)
+	return err
+}
+
+// GetObjectVersions API
+//
+// Returns the versions of an object: the retained older versions (local buckets
+// only), oldest first, followed by the current version. To read a given version,
+// use GetObject with cmn.URLParamVersion in GetObjectInput.Query.
+func GetObjectVersions(baseParams *BaseParams, bucket, bckProvider, object string) ([]cmn.ObjVersion, error) {
+	baseParams.Method = http.MethodGet
+	path := cmn.URLPath(cmn.Version, cmn.Objects, bucket, object)
+	query := url.Values{cmn.URLParamBckProvider: []string{bckProvider}, cmn.URLParamWhat: []string{cmn.GetWhatObjVersions}}
+	params := OptionalParams{Query: query}
+
+	b, err := DoHTTPRequest(baseParams, path, nil, params)
+	if err != nil {
+		return nil, err
+	}
+	vers := make([]cmn.ObjVersion, 0, 4)
+	if err = jsoniter.Unmarshal(b, &vers); err != nil {
+		return nil, err
+	}
+	return vers, nil
+}
+
 // EvictObject API
 //
 // Evicts an object specified by bucket/object
@@ -198,6 +240,30 @@ func GetObjectWithValidation(baseParams *BaseParams, bucket, object string, opti
 	return n, nil
 }
 
+// GetObjectsBatch API
+//
+// Reads multiple objects (from the same bucket) in a single request and writes the
+// resulting TAR (or tar.gz, depending on msg.Format) archive into the writer.
+// Archive entries are named after the objects and follow the requested order.
+func GetObjectsBatch(baseParams *BaseParams, bucket, bckProvider string, msg *cmn.GetBatchMsg, w io.Writer) (n int64, err error) {
+	b, err := jsoniter.Marshal(cmn.ActionMsg{Action: cmn.ActGetBatch, Value: msg})
+	if err != nil {
+		return 0, err
+	}
+	baseParams.Method = http.MethodPost
+	path := cmn.URLPath(cmn.Version, cmn.Buckets, bucket)
+	optParams := OptionalParams{
+		Header: http.Header{"Content-Type": []string{"application/json"}},
+		Query:  url.Values{cmn.URLParamBckProvider: []string{bckProvider}},
+	}
+	resp, err := doHTTPRequestGetResp(baseParams, path, b, optParams)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	return io.Copy(w, resp.Body)
+}
+
 // PutObject API
 //
 // Creates an object from the body of the io.Reader parameter and puts it in the 'bucket' bucket
@@ -206,20 +272,33 @@ func GetObjectWithValidation(baseParams *BaseParams, bucket, object string, opti
 // If the object hash passed in is not empty, the value is set
 // in the request header with the default checksum type "xxhash"
 func PutObject(args PutObjectArgs, replicateOpts ...ReplicateObjectInput) error {
+	var query = url.Values{}
+	query.Add(cmn.URLParamBckProvider, args.BucketProvider)
+	header := http.Header{}
+	if len(replicateOpts) > 0 {
+		header.Set(cmn.HeaderObjReplicSrc, replicateOpts[0].SourceURL)
+	}
+	resp, err := doPutRequest(args, query, header)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func doPutRequest(args PutObjectArgs, query url.Values, header http.Header) (*http.Response, error) {
 	handle, err := args.Reader.Open()
 	if err != nil {
-		return fmt.Errorf("failed to open reader, err: %v", err)
+		return nil, fmt.Errorf("failed to open reader, err: %v", err)
 	}
 	defer handle.Close()
 
 	path := cmn.URLPath(cmn.Version, cmn.Objects, args.Bucket, args.Object)
-	var query = url.Values{}
-	query.Add(cmn.URLParamBckProvider, args.BucketProvider)
 	reqURL := args.BaseParams.URL + path + "?" + query.Encode()
 
 	req, err := http.NewRequest(http.MethodPut, reqURL, handle)
 	if err != nil {
-		return fmt.Errorf("failed to create new HTTP request, err: %v", err)
+		return nil, fmt.Errorf("failed to create new HTTP request, err: %v", err)
 	}
 
 	// The HTTP package doesn't automatically set this for files, so it has to be done manually
@@ -227,13 +306,14 @@ func PutObject(args PutObjectArgs, replicateOpts ...ReplicateObjectInput) error
 	req.GetBody = func() (io.ReadCloser, error) {
 		return args.Reader.Open()
 	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
 	if args.Hash != "" {
 		req.Header.Set(cmn.HeaderObjCksumType, cmn.ChecksumXXHash)
 		req.Header.Set(cmn.HeaderObjCksumVal, args.Hash)
 	}
-	if len(replicateOpts) > 0 {
-		req.Header.Set(cmn.HeaderObjReplicSrc, replicateOpts[0].SourceURL)
-	}
+	cmn.CustomMDToHeader(args.CustomMD, req.Header)
 
 	resp, err := args.BaseParams.Client.Do(req)
 	if err != nil {
@@ -248,11 +328,165 @@ func PutObject(args PutObjectArgs, replicateOpts ...ReplicateObjectInput) error
 	}
 
 	if err != nil {
-		return fmt.Errorf("failed to %s, err: %v", http.MethodPut, err)
+		return nil, fmt.Errorf("failed to %s, err: %v", http.MethodPut, err)
 	}
-	defer resp.Body.Close()
+	if _, err = checkBadStatus(req, resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp, nil
+}
+
+// InitMultipartUpload API
+//
+// Initiates multipart upload of the object and returns the upload ID
+// to be used with PutObjectPart, CompleteMultipartUpload and AbortMultipartUpload
+func InitMultipartUpload(baseParams *BaseParams, bucket, bckProvider, object string) (string, error) {
+	msg, err := jsoniter.Marshal(cmn.ActionMsg{Action: cmn.ActInitMultipart})
+	if err != nil {
+		return "", err
+	}
+	baseParams.Method = http.MethodPost
+	path := cmn.URLPath(cmn.Version, cmn.Objects, bucket, object)
+	query := url.Values{cmn.URLParamBckProvider: []string{bckProvider}}
+	b, err := DoHTTPRequest(baseParams, path, msg, OptionalParams{Query: query})
+	if err != nil {
+		return "", err
+	}
+	mpuMsg := cmn.MultipartMsg{}
+	if err = jsoniter.Unmarshal(b, &mpuMsg); err != nil {
+		return "", err
+	}
+	return mpuMsg.UploadID, nil
+}
+
+// PutObjectPart API
+//
+// Uploads a single part of the multipart upload. Parts can be uploaded in any
+// order and in parallel; re-uploading a part replaces its previous content.
+// Returns the checksum of the part as computed by the storage target.
+func PutObjectPart(args PutObjectArgs, uploadID string, partNum int) (string, error) {
+	var query = url.Values{}
+	query.Add(cmn.URLParamBckProvider, args.BucketProvider)
+	query.Add(cmn.URLParamUploadID, uploadID)
+	query.Add(cmn.URLParamPartNum, strconv.Itoa(partNum))
+	resp, err := doPutRequest(args, query, http.Header{})
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	return resp.Header.Get(cmn.HeaderObjCksumVal), nil
+}
+
+// CompleteMultipartUpload API
+//
+// Creates the object by concatenating the specified parts in the specified (ascending) order
+func CompleteMultipartUpload(baseParams *BaseParams, bucket, bckProvider, object, uploadID string,
+	parts []cmn.MultipartPart) error {
+	return doMultipartAction(baseParams, bucket, bckProvider, object,
+		cmn.ActCompleteMultipart, &cmn.MultipartMsg{UploadID: uploadID, Parts: parts})
+}
+
+// AbortMultipartUpload API
+//
+// Aborts multipart upload and removes all the uploaded parts
+func AbortMultipartUpload(baseParams *BaseParams, bucket, bckProvider, object, uploadID string) error {
+	return doMultipartAction(baseParams, bucket, bckProvider, object,
+		cmn.ActAbortMultipart, &cmn.MultipartMsg{UploadID: uploadID})
+}
+
+func doMultipartAction(baseParams *BaseParams, bucket, bckProvider, object, action string,
+	mpuMsg *cmn.MultipartMsg) error {
+	msg, err := jsoniter.Marshal(cmn.ActionMsg{Action: action, Value: mpuMsg})
+	if err != nil {
+		return err
+	}
+	baseParams.Method = http.MethodPost
+	path := cmn.URLPath(cmn.Version, cmn.Objects, bucket, object)
+	query := url.Values{cmn.URLParamBckProvider: []string{bckProvider}}
+	_, err = DoHTTPRequest(baseParams, path, msg, OptionalParams{Query: query})
+	return err
+}
+
+// AppendObject API
+//
+// Appends the content of args.Reader to the object. The first append (empty
+// handle) returns a handle that must be passed to the subsequent appends and,
+// eventually, to FlushObject. Appended content becomes visible only after flush.
+func AppendObject(args PutObjectArgs, handle string) (string, error) {
+	var query = url.Values{}
+	query.Add(cmn.URLParamBckProvider, args.BucketProvider)
+	query.Add(cmn.URLParamAppendType, cmn.AppendOp)
+	if handle != "" {
+		query.Add(cmn.URLParamHandle, handle)
+	}
+	resp, err := doPutRequest(args, query, http.Header{})
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	return resp.Header.Get(cmn.HeaderAppendHandle), nil
+}
 
-	_, err = checkBadStatus(req, resp)
+// FlushObject API
+//
+// Commits all the content appended via the handle as the new version of the object
+func FlushObject(baseParams *BaseParams, bucket, bckProvider, object, handle string) error {
+	baseParams.Method = http.MethodPut
+	path := cmn.URLPath(cmn.Version, cmn.Objects, bucket, object)
+	query := url.Values{}
+	query.Add(cmn.URLParamBckProvider, bckProvider)
+	query.Add(cmn.URLParamAppendType, cmn.FlushOp)
+	query.Add(cmn.URLParamHandle, handle)
+	_, err := DoHTTPRequest(baseParams, path, nil, OptionalParams{Query: query})
+	return err
+}
+
+// ConcatObjects API
+//
+// Creates (or overwrites) the object by concatenating the specified objects
+// of the same bucket in the specified order
+func ConcatObjects(baseParams *BaseParams, bucket, bckProvider, object string, objnames []string) error {
+	msg, err := jsoniter.Marshal(cmn.ActionMsg{Action: cmn.ActConcat, Value: &cmn.ConcatMsg{Objnames: objnames}})
+	if err != nil {
+		return err
+	}
+	baseParams.Method = http.MethodPost
+	path := cmn.URLPath(cmn.Version, cmn.Objects, bucket, object)
+	query := url.Values{cmn.URLParamBckProvider: []string{bckProvider}}
+	_, err = DoHTTPRequest(baseParams, path, msg, OptionalParams{Query: query})
+	return err
+}
+
+// SetObjectCustomMD API
+//
+// Updates user-defined metadata of an existing object: the specified keys are
+// added or overwritten, keys with empty values are removed
+func SetObjectCustomMD(baseParams *BaseParams, bucket, bckProvider, object string, md cmn.SimpleKVs) error {
+	msg, err := jsoniter.Marshal(cmn.ActionMsg{Action: cmn.ActSetCustomMD, Value: md})
+	if err != nil {
+		return err
+	}
+	baseParams.Method = http.MethodPost
+	path := cmn.URLPath(cmn.Version, cmn.Objects, bucket, object)
+	query := url.Values{cmn.URLParamBckProvider: []string{bckProvider}}
+	_, err = DoHTTPRequest(baseParams, path, msg, OptionalParams{Query: query})
+	return err
+}
+
+// CopyObject API
+//
+// Copies the object into the destination bucket (of the same or a different provider)
+// under the name specified in the message (the original name, if not specified)
+func CopyObject(baseParams *BaseParams, bucket, bckProvider, object string, msg *cmn.CopyObjectMsg) error {
+	b, err := jsoniter.Marshal(cmn.ActionMsg{Action: cmn.ActCopyObject, Value: msg})
+	if err != nil {
+		return err
+	}
+	baseParams.Method = http.MethodPost
+	path := cmn.URLPath(cmn.Version, cmn.Objects, bucket, object)
+	query := url.Values{cmn.URLParamBckProvider: []string{bckProvider}}
+	_, err = DoHTTPRequest(baseParams, path, b, OptionalParams{Query: query})
 	return err
 }
 
@@ -59,7 +59,7 @@ func isSyscallWriteError(err error) bool {
 }
 
 func isValidProvider(prov string) bool {
-	return prov == cmn.ProviderAmazon || prov == cmn.ProviderGoogle || prov == cmn.ProviderAIS
+	return prov == cmn.ProviderAmazon || prov == cmn.ProviderGoogle || prov == cmn.ProviderAzure || prov == cmn.ProviderAIS
 }
 
 func checkRESTItems(w http.ResponseWriter, r *http.Request, itemsAfter int, items ...string) ([]string, error) {
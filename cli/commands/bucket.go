@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"net/url"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -29,6 +30,7 @@ const (
 	bucketGetProps   = commandProps
 	bucketNWayMirror = cmn.ActMakeNCopies
 	bucketEvict      = commandEvict
+	bucketUsage      = "usage"
 )
 
 var (
@@ -39,6 +41,7 @@ var (
 	objLimitFlag      = cli.StringFlag{Name: "limit", Usage: "limit object count", Value: "0"}
 	templateFlag      = cli.StringFlag{Name: "template", Usage: "template for matching object names"}
 	copiesFlag        = cli.IntFlag{Name: "copies", Usage: "number of object replicas", Value: 1}
+	bypassCacheFlag   = cli.BoolFlag{Name: "bypass-cache", Usage: "list cloud bucket without using the proxy's cache of listings"}
 
 	baseBucketFlags = []cli.Flag{
 		bucketFlag,
@@ -63,6 +66,7 @@ var (
 				objPropsFlag,
 				objLimitFlag,
 				showUnmatchedFlag,
+				bypassCacheFlag,
 			},
 			baseBucketFlags...),
 		bucketSetProps: append(
@@ -76,6 +80,9 @@ var (
 			[]cli.Flag{copiesFlag},
 			baseBucketFlags...),
 		bucketEvict: []cli.Flag{bucketFlag},
+		bucketUsage: append(
+			[]cli.Flag{jsonFlag},
+			baseBucketFlags...),
 	}
 
 	bucketGeneric        = "%s bucket %s --bucket <value>"
@@ -85,6 +92,7 @@ var (
 	bucketResetPropsText = fmt.Sprintf(bucketGeneric, cliName, bucketResetProps)
 	bucketGetPropsText   = fmt.Sprintf(bucketGeneric, cliName, bucketGetProps)
 	bucketEvictText      = fmt.Sprintf(bucketGeneric, cliName, bucketEvict)
+	bucketUsageText      = fmt.Sprintf(bucketGeneric, cliName, bucketUsage)
 	bucketNamesText      = fmt.Sprintf("%s bucket %s", cliName, bucketNames)
 	bucketRenameText     = fmt.Sprintf("%s bucket %s --bucket <value> --new-bucket <value>", cliName, commandRename)
 	bucketSetPropsText   = fmt.Sprintf("%s bucket %s --bucket <value> key=value ...", cliName, bucketSetProps)
@@ -176,6 +184,14 @@ var (
 					Action:       bucketHandler,
 					BashComplete: flagList,
 				},
+				{
+					Name:         bucketUsage,
+					Usage:        "returns bucket capacity usage and number of objects vs bucket quotas",
+					UsageText:    bucketUsageText,
+					Flags:        bucketFlags[bucketUsage],
+					Action:       bucketHandler,
+					BashComplete: flagList,
+				},
 			},
 		},
 	}
@@ -211,6 +227,8 @@ func bucketHandler(c *cli.Context) (err error) {
 		err = bucketProps(c, baseParams, bucket)
 	case bucketNWayMirror:
 		err = configureNCopies(c, baseParams, bucket)
+	case bucketUsage:
+		err = bucketUsageQuota(c, baseParams, bucket)
 	default:
 		return fmt.Errorf(invalidCmdMsg, command)
 	}
@@ -294,7 +312,7 @@ func listBucketObj(c *cli.Context, baseParams *api.BaseParams, bucket string) er
 	query.Add(cmn.URLParamBckProvider, parseFlag(c, bckProviderFlag))
 	query.Add(cmn.URLParamPrefix, prefix)
 
-	msg := &cmn.SelectMsg{PageSize: pagesize, Props: props}
+	msg := &cmn.SelectMsg{PageSize: pagesize, Props: props, BypassCache: flagIsSet(c, bypassCacheFlag)}
 	objList, err := api.ListBucket(baseParams, bucket, msg, limit, query)
 	if err != nil {
 		return err
@@ -382,6 +400,32 @@ func bucketProps(c *cli.Context, baseParams *api.BaseParams, bucket string) (err
 	return templates.DisplayOutput(bckProps, templates.BucketPropsTmpl, flagIsSet(c, jsonFlag))
 }
 
+// Get bucket usage and quotas
+func bucketUsageQuota(c *cli.Context, baseParams *api.BaseParams, bucket string) (err error) {
+	bckProvider, err := cmn.BckProviderFromStr(parseFlag(c, bckProviderFlag))
+	if err != nil {
+		return
+	}
+	query := url.Values{cmn.URLParamBckProvider: []string{bckProvider}}
+	bckProps, err := api.HeadBucket(baseParams, bucket, query)
+	if err != nil {
+		return
+	}
+	if !bckProps.Quota.Enabled() {
+		fmt.Printf("%s bucket has no quotas\n", bucket)
+		return
+	}
+	usage, err := api.GetBucketUsage(baseParams, bucket, query)
+	if err != nil {
+		return
+	}
+	out := struct {
+		Quota cmn.QuotaConf   `json:"quota"`
+		Usage cmn.BucketUsage `json:"usage"`
+	}{bckProps.Quota, *usage}
+	return templates.DisplayOutput(out, templates.BucketUsageTmpl, flagIsSet(c, jsonFlag))
+}
+
 // Configure bucket as n-way mirror
 func configureNCopies(c *cli.Context, baseParams *api.BaseParams, bucket string) (err error) {
 	bckProvider, err := cmn.BckProviderFromStr(parseFlag(c, bckProviderFlag))
@@ -416,6 +460,25 @@ func printBucketNames(bucketNames *cmn.BucketNames, regex, bckProvider string) {
 		fmt.Println()
 	}
 
+	if len(bucketNames.CloudByProvider) > 1 {
+		providers := make([]string, 0, len(bucketNames.CloudByProvider))
+		for provider := range bucketNames.CloudByProvider {
+			providers = append(providers, provider)
+		}
+		sort.Strings(providers)
+		for i, provider := range providers {
+			if i > 0 {
+				fmt.Println()
+			}
+			cloudBuckets := regexFilter(regex, bucketNames.CloudByProvider[provider])
+			fmt.Printf("Cloud Buckets: %s (%d)\n", provider, len(cloudBuckets))
+			for _, bucket := range cloudBuckets {
+				fmt.Println(bucket)
+			}
+		}
+		return
+	}
+
 	cloudBuckets := regexFilter(regex, bucketNames.Cloud)
 	fmt.Printf("Cloud Buckets (%d)\n", len(cloudBuckets))
 	for _, bucket := range cloudBuckets {
@@ -207,8 +207,23 @@ const (
 		ReplicationConfTmpl + CksumConfTmpl + VerConfTmpl + FSpathsConfTmpl +
 		TestFSPConfTmpl + NetConfTmpl + FSHCConfTmpl + AuthConfTmpl + KeepaliveConfTmpl + DownloaderTmpl
 
+	BucketQuotaConfTmpl = "\n{{$obj := .Quota}}Bucket Quota\n" +
+		" Soft Size: {{$obj.SoftSize}}\t Hard Size: {{$obj.HardSize}}\n" +
+		" Soft Objects: {{$obj.SoftObjs}}\t Hard Objects: {{$obj.HardObjs}}\n"
+
+	BucketWriteBackConfTmpl = "\n{{$obj := .WriteBack}}Bucket Write-Back\n" +
+		" Enabled: {{$obj.Enabled}}\n"
+
+	BucketEncryptionConfTmpl = "\n{{$obj := .Encryption}}Bucket Encryption\n" +
+		" Enabled: {{$obj.Enabled}}\t Key ID: {{$obj.KeyID}}\n"
+
 	BucketPropsTmpl = "\nCloud Provider: {{.CloudProvider}}\n" +
-		BucketVerConfTmpl + CksumConfTmpl + LRUConfTmpl + MirrorConfTmpl + ECConfTmpl
+		BucketVerConfTmpl + CksumConfTmpl + LRUConfTmpl + MirrorConfTmpl + ECConfTmpl + BucketQuotaConfTmpl +
+		BucketWriteBackConfTmpl + BucketEncryptionConfTmpl
+
+	BucketUsageTmpl = "\t Used\t Soft Quota\t Hard Quota\n" +
+		"Size\t {{FormatBytesSigned .Usage.Size 2}}\t {{FormatBytesSigned .Quota.SoftSize 2}}\t {{FormatBytesSigned .Quota.HardSize 2}}\n" +
+		"Objects\t {{.Usage.Objs}}\t {{.Quota.SoftObjs}}\t {{.Quota.HardObjs}}\n"
 
 	DownloadListHeader = "JOB ID\t STATUS\t DESCRIPTION\n"
 	DownloadListBody   = "{{$value.ID}}\t " +
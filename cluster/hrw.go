@@ -49,7 +49,11 @@ func HrwTarget(bucket, objname string, smap *Smap) (si *Snode, errstr string) {
 }
 
 // Returns count number of first targets with highest random weight. The list
-// of targets is sorted from the greatest to least.
+// of targets is sorted from the greatest to least. When targets have failure
+// domains, the list is spread across them: first go the targets with the
+// highest weight in their domains, then the second highest, and so on.
+// The first target is always the one returned by HrwTarget. Domains are not
+// capped - see cmn.ECConf.DomainSpread for the EC validation of domain sizes.
 // Returns error if the cluster does not have enough targets
 func HrwTargetList(bucket, objname string, smap *Smap, count int) (si []*Snode, errstr string) {
 	if count <= 0 {
@@ -69,16 +73,43 @@ func HrwTargetList(bucket, objname string, smap *Smap, count int) (si []*Snode,
 	name := Bo2Uname(bucket, objname)
 	digest := xxhash.ChecksumString64S(name, MLCG32)
 
-	i := 0
+	i, labeled := 0, false
 	for _, sinfo := range smap.Tmap {
 		cs := xoshiro256.Hash(sinfo.idDigest ^ digest)
 		arr[i] = tsi{sinfo, cs}
+		labeled = labeled || sinfo.FailureDomain != ""
 		i++
 	}
 
 	sort.Slice(arr, func(i, j int) bool { return arr[i].hash > arr[j].hash })
-	for i := 0; i < count; i++ {
-		si[i] = arr[i].node
+	if !labeled {
+		for i := 0; i < count; i++ {
+			si[i] = arr[i].node
+		}
+		return
+	}
+
+	// every pass takes at most one more target from each domain; targets
+	// without failure domain are never skipped
+	var (
+		picked = make([]bool, len(arr))
+		used   = make(map[string]int, len(arr))
+	)
+	for n, limit := 0, 1; n < count; limit++ {
+		for i := 0; i < len(arr) && n < count; i++ {
+			if picked[i] {
+				continue
+			}
+			if domain := arr[i].node.FailureDomain; domain != "" {
+				if used[domain] >= limit {
+					continue
+				}
+				used[domain]++
+			}
+			picked[i] = true
+			si[n] = arr[i].node
+			n++
+		}
 	}
 
 	return
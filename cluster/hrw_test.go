@@ -0,0 +1,110 @@
+// Package cluster provides common interfaces and local access to cluster-level metadata
+/*
+ * Copyright (c) 2018, NVIDIA CORPORATION. All rights reserved.
+ */
+package cluster
+
+import (
+	"fmt"
+
+	"github.com/NVIDIA/aistore/cmn"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HRW", func() {
+	// 3 racks with 3 targets each
+	newSmap := func(labeled bool) *Smap {
+		smap := &Smap{Tmap: make(NodeMap)}
+		for i := 0; i < 9; i++ {
+			si := &Snode{DaemonID: fmt.Sprintf("t%d", i)}
+			if labeled {
+				si.FailureDomain = fmt.Sprintf("rack%d", i%3)
+			}
+			smap.Tmap[si.DaemonID] = si
+		}
+		smap.InitDigests()
+		return smap
+	}
+
+	Describe("HrwTargetList", func() {
+		It("should keep HRW order if targets have no failure domains", func() {
+			smap := newSmap(false)
+			for i := 0; i < 100; i++ {
+				objname := fmt.Sprintf("obj%d", i)
+				list, errstr := HrwTargetList("bucket", objname, smap, 5)
+				Expect(errstr).To(BeEmpty())
+				all, _ := HrwTargetList("bucket", objname, smap, smap.CountTargets())
+				Expect(list).To(Equal(all[:5]))
+			}
+		})
+
+		It("should spread targets across failure domains", func() {
+			smap := newSmap(true)
+			Expect(smap.DomainSizes()).To(Equal([]int{3, 3, 3}))
+			for i := 0; i < 100; i++ {
+				objname := fmt.Sprintf("obj%d", i)
+				first, _ := HrwTarget("bucket", objname, smap)
+				list, errstr := HrwTargetList("bucket", objname, smap, 7)
+				Expect(errstr).To(BeEmpty())
+				Expect(list[0]).To(Equal(first))
+
+				perDomain := make(map[string]int)
+				for j, si := range list {
+					perDomain[si.FailureDomain]++
+					if j < 3 {
+						Expect(perDomain[si.FailureDomain]).To(Equal(1))
+					}
+				}
+				for _, cnt := range perDomain {
+					Expect(cnt).To(BeNumerically("<=", 3))
+					Expect(cnt).To(BeNumerically(">=", 2))
+				}
+
+				// shorter lists are prefixes of longer ones
+				short, _ := HrwTargetList("bucket", objname, smap, 4)
+				Expect(short).To(Equal(list[:4]))
+			}
+		})
+
+		It("should treat targets without failure domain as separate domains", func() {
+			smap := newSmap(true)
+			smap.Tmap["t0"].FailureDomain = ""
+			smap.Tmap["t3"].FailureDomain = ""
+			Expect(smap.DomainSizes()).To(Equal([]int{3, 3, 1, 1, 1}))
+			Expect(newSmap(false).DomainSizes()).To(BeNil())
+		})
+
+		It("should not place more slices in a domain than EC can lose if domain sizes allow", func() {
+			// domains of 1, 1 and 10 targets
+			smap := &Smap{Tmap: make(NodeMap)}
+			for i := 0; i < 12; i++ {
+				si := &Snode{DaemonID: fmt.Sprintf("t%d", i), FailureDomain: "big"}
+				if i < 2 {
+					si.FailureDomain = fmt.Sprintf("small%d", i)
+				}
+				smap.Tmap[si.DaemonID] = si
+			}
+			smap.InitDigests()
+			conf := cmn.ECConf{Enabled: true, DataSlices: 2, ParitySlices: 2}
+			domains := smap.DomainSizes()
+			Expect(domains).To(Equal([]int{10, 1, 1}))
+			Expect(conf.ValidateAsProps(&cmn.ValidationArgs{BckIsLocal: true, TargetCnt: 12, Domains: domains})).
+				To(HaveOccurred())
+
+			// 1 data and 2 parity slices: at most 2 of 4 in the large domain
+			conf.DataSlices = 1
+			Expect(conf.ValidateAsProps(&cmn.ValidationArgs{BckIsLocal: true, TargetCnt: 12, Domains: domains})).
+				To(Succeed())
+			for i := 0; i < 100; i++ {
+				list, errstr := HrwTargetList("bucket", fmt.Sprintf("obj%d", i), smap, conf.RequiredEncodeTargets())
+				Expect(errstr).To(BeEmpty())
+				perDomain := make(map[string]int)
+				for _, si := range list {
+					perDomain[si.FailureDomain]++
+				}
+				Expect(perDomain["big"]).To(BeNumerically("<=", conf.MaxLostSlices()))
+			}
+		})
+	})
+})
@@ -43,6 +43,8 @@ type (
 		atime   int64
 		atimefs int64
 		copyFQN []string
+		custom  cmn.SimpleKVs // user-defined metadata (see cmn.HeaderObjCustomPrefix)
+		keyID   string        // encryption key (see lom_enc.go)
 		bckID   uint64
 	}
 	LOM struct {
@@ -107,6 +109,11 @@ func (lom *LOM) SetBID(bid uint64)           { lom.md.bckID = bid } // ditto
 func (lom *LOM) IsCopy() bool {
 	return len(lom.md.copyFQN) == 1 && lom.md.copyFQN[0] == lom.HrwFQN // is a local copy of an object
 }
+
+// user-defined metadata; NOTE: the map is shared with the cached copies of the LOM
+// and therefore must be replaced (never modified in place)
+func (lom *LOM) CustomMD() cmn.SimpleKVs      { return lom.md.custom }
+func (lom *LOM) SetCustomMD(md cmn.SimpleKVs) { lom.md.custom = md }
 func (lom *LOM) Config() *cmn.Config {
 	if lom.config == nil {
 		lom.config = cmn.GCO.Get()
@@ -129,10 +136,18 @@ func (lom *LOM) VerConf() *cmn.VersionConf {
 	return conf
 }
 func (lom *LOM) RebalanceConf() *cmn.RebalanceConf { return &lom.BckProps.Rebalance }
+func (lom *LOM) WriteBackConf() *cmn.WriteBackConf { return &lom.BckProps.WriteBack }
 func (lom *LOM) GenFQN(ty, prefix string) string {
 	return fs.CSM.GenContentParsedFQN(lom.ParsedFQN, ty, prefix)
 }
 
+// write-back marker: exists while the (Cloud) object is yet to be uploaded
+func (lom *LOM) WriteBackFQN() string { return lom.GenFQN(fs.WriteBackType, "") }
+func (lom *LOM) WriteBackPending() bool {
+	_, err := os.Stat(lom.WriteBackFQN())
+	return err == nil
+}
+
 //
 // local copy management
 //
@@ -426,7 +441,7 @@ func (lom *LOM) CksumComputeIfMissing() (cksum cmn.Cksummer, errstr string) {
 }
 
 func (lom *LOM) computeXXHash(fqn string, size int64) (cksumstr, errstr string) {
-	file, err := os.Open(fqn)
+	file, err := lom.Open(fqn)
 	if err != nil {
 		errstr = fmt.Sprintf("%s, err: %v", fqn, err)
 		return
@@ -649,8 +664,8 @@ func (lom *LOM) FromFS() (errstr string) {
 		}
 		return
 	}
-	if lom.md.size != finfo.Size() { // corruption or tampering
-		errstr = fmt.Sprintf("%s[%s]: invalid size (%d != %d)", lom, lom.FQN, lom.md.size, finfo.Size())
+	if lom.DiskSize() != finfo.Size() { // corruption or tampering
+		errstr = fmt.Sprintf("%s[%s]: invalid size (%d != %d)", lom, lom.FQN, lom.DiskSize(), finfo.Size())
 		return
 	}
 	atime := ios.GetATime(finfo)
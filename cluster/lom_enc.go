@@ -0,0 +1,111 @@
+// Package cluster provides common interfaces and local access to cluster-level metadata
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package cluster
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/NVIDIA/aistore/cmn"
+	"github.com/NVIDIA/aistore/encryption"
+)
+
+//
+// Encryption at rest (see cmn.EncryptionConf). An encrypted object records the
+// ID of its key; its size (lom.Size) is the size of plaintext, while the size
+// of the file on disk (lom.DiskSize) includes the encryption overhead. Local
+// copies (mirrors) and EC replicas keep both the encrypted content and the key
+// ID as is; EC slices are computed over the encrypted content, which is what EC
+// restores. Migrated (rebalanced) objects and their older versions (see
+// lom_ver.go) are sent decrypted and encrypted anew by the receiving target -
+// in accordance with the current bucket properties.
+//
+
+type (
+	// ObjReader reads the (decrypted, if need be) content of the object
+	ObjReader interface {
+		io.ReadSeeker
+		io.ReaderAt
+		io.Closer
+		Open() (io.ReadCloser, error)
+		Name() string
+	}
+)
+
+var (
+	_ ObjReader = &cmn.FileHandle{}
+	_ ObjReader = &encryption.File{}
+)
+
+func (lom *LOM) KeyID() string       { return lom.md.keyID }
+func (lom *LOM) SetKeyID(id string)  { lom.md.keyID = id }
+func (lom *LOM) Encrypted() bool     { return lom.md.keyID != "" }
+func (lom *LOM) DiskSize() int64     { return diskSize(lom.md.size, lom.md.keyID) }
+func (lom *LOM) SetDiskSize(n int64) { lom.md.size = plainSize(n, lom.md.keyID) }
+
+// EncryptKeyID returns the ID of the key to encrypt the object being written, if any
+func (lom *LOM) EncryptKeyID() string {
+	if !lom.BckIsLocal || lom.BckProps == nil || !lom.BckProps.Encryption.Enabled {
+		return ""
+	}
+	return lom.BckProps.Encryption.KeyID
+}
+
+func diskSize(size int64, keyID string) int64 {
+	if keyID == "" {
+		return size
+	}
+	return encryption.EncryptedSize(size)
+}
+
+func plainSize(size int64, keyID string) int64 {
+	if keyID == "" {
+		return size
+	}
+	return encryption.PlainSize(size)
+}
+
+// Open opens the object's file (the object itself or any of its copies) for reading
+func (lom *LOM) Open(fqn string) (ObjReader, error) { return openObj(fqn, lom.md.keyID) }
+
+// ContentAt returns the reader of the object's content and its size given the open file
+func (lom *LOM) ContentAt(file *os.File) (io.ReaderAt, int64, error) {
+	finfo, err := file.Stat()
+	if err != nil {
+		return nil, 0, err
+	}
+	if !lom.Encrypted() {
+		return file, finfo.Size(), nil
+	}
+	key, err := encryption.Key(lom.md.keyID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: %v", lom, err)
+	}
+	r, err := encryption.NewReader(file, finfo.Size(), key)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: %v", lom, err)
+	}
+	return r, r.Size(), nil
+}
+
+func openObj(fqn, keyID string) (ObjReader, error) {
+	if keyID == "" {
+		fh, err := cmn.NewFileHandle(fqn)
+		if err != nil {
+			return nil, err
+		}
+		return fh, nil
+	}
+	key, err := encryption.Key(keyID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fqn, err)
+	}
+	file, err := encryption.Open(fqn, key)
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
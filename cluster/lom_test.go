@@ -5,6 +5,7 @@ package cluster_test
 
 import (
 	"crypto/rand"
+	"errors"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -25,6 +26,7 @@ const (
 
 	bucketLocalA = "LOM_TEST_Local_1"
 	bucketLocalB = "LOM_TEST_Local_2"
+	bucketLocalC = "LOM_TEST_Local_3"
 
 	bucketCloudA = "LOM_TEST_Cloud_1"
 	bucketCloudB = "LOM_TEST_Cloud_2"
@@ -47,6 +49,7 @@ var _ = Describe("LOM", func() {
 	_ = fs.Mountpaths.Add(mpath2)
 	_ = fs.CSM.RegisterFileType(fs.ObjectType, &fs.ObjectContentResolver{})
 	_ = fs.CSM.RegisterFileType(fs.WorkfileType, &fs.WorkfileContentResolver{})
+	_ = fs.CSM.RegisterFileType(fs.VersionType, &fs.VersionContentResolver{})
 
 	tMock := cluster.NewTargetMock(cluster.BownerMock{BMD: cluster.BMD{
 		LBmap: map[string]*cmn.BucketProps{
@@ -58,6 +61,10 @@ var _ = Describe("LOM", func() {
 				Cksum: cmn.CksumConf{Type: cmn.ChecksumXXHash},
 				LRU:   cmn.LRUConf{Enabled: true},
 			},
+			bucketLocalC: {
+				Cksum:      cmn.CksumConf{Type: cmn.ChecksumNone},
+				Versioning: cmn.VersionConf{Enabled: true, MaxVersions: 2},
+			},
 			sameBucketName: {},
 		},
 		CBmap: map[string]*cmn.BucketProps{
@@ -542,6 +549,110 @@ var _ = Describe("LOM", func() {
 			})
 		})
 
+		Describe("Versions", func() {
+			testObject := "foldr/test-obj.ext"
+			localFQN := filepath.Join(mpath, fs.ObjectType, cmn.LocalBs, bucketLocalC, testObject)
+			versionFQN := func(ver string) string {
+				return filepath.Join(mpath, fs.VersionType, cmn.LocalBs, bucketLocalC, testObject+".v"+ver)
+			}
+			putVersion := func(ver string) *cluster.LOM {
+				lom := NewBasicLom(localFQN, tMock)
+				_, errstr := lom.Load(false)
+				Expect(errstr).To(BeEmpty())
+				Expect(lom.ArchiveVersion(func() error {
+					createTestFile(localFQN, len(ver))
+					return nil
+				})).To(BeEmpty())
+				lom.SetSize(int64(len(ver)))
+				lom.SetVersion(ver)
+				Expect(lom.Persist()).NotTo(HaveOccurred())
+				lom.Uncache()
+				return lom
+			}
+
+			It("should retain older versions upon overwrite", func() {
+				var lom *cluster.LOM
+				for i := 1; i <= 4; i++ {
+					lom = putVersion(strconv.Itoa(i))
+				}
+				vers, err := lom.OlderVersions()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(vers).To(HaveLen(3))
+				for i, ver := range vers {
+					Expect(ver.Version).To(Equal(strconv.Itoa(i + 1)))
+					Expect(ver.Current).To(BeFalse())
+				}
+
+				ver, fqn, err := lom.OlderVersion("2")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fqn).To(Equal(versionFQN("2")))
+				Expect(ver.Size).To(BeEquivalentTo(1))
+
+				_, _, err = lom.OlderVersion("4")
+				Expect(os.IsNotExist(err)).To(BeTrue())
+			})
+
+			It("should delete and trim older versions", func() {
+				var lom *cluster.LOM
+				for i := 1; i <= 4; i++ {
+					lom = putVersion(strconv.Itoa(i))
+				}
+				Expect(lom.DelVersion("1")).NotTo(HaveOccurred())
+				Expect(versionFQN("1")).NotTo(BeAnExistingFile())
+
+				n, _, errstr := lom.TrimVersions(1, 0)
+				Expect(errstr).To(BeEmpty())
+				Expect(n).To(BeEquivalentTo(1))
+				Expect(versionFQN("2")).NotTo(BeAnExistingFile())
+				Expect(versionFQN("3")).To(BeAnExistingFile())
+
+				n, _, errstr = lom.TrimVersions(1, time.Nanosecond)
+				Expect(errstr).To(BeEmpty())
+				Expect(n).To(BeEquivalentTo(1))
+
+				putVersion("5")
+				Expect(lom.DelAllVersions()).To(BeEmpty())
+				vers, err := lom.OlderVersions()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(vers).To(BeEmpty())
+				Expect(localFQN).To(BeAnExistingFile())
+			})
+
+			It("should keep the object if it fails to be replaced", func() {
+				putVersion("1")
+				lom := putVersion("2")
+				_, errstr := lom.Load(false)
+				Expect(errstr).To(BeEmpty())
+				errstr = lom.ArchiveVersion(func() error { return errors.New("dummy") })
+				Expect(errstr).NotTo(BeEmpty())
+				Expect(versionFQN("2")).NotTo(BeAnExistingFile())
+				Expect(versionFQN("1")).To(BeAnExistingFile())
+				finfo, err := os.Stat(localFQN)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(finfo.Size()).To(BeEquivalentTo(1))
+			})
+
+			It("should move older versions along with the object", func() {
+				var lom *cluster.LOM
+				for i := 1; i <= 3; i++ {
+					lom = putVersion(strconv.Itoa(i))
+				}
+				dstFQN := filepath.Join(mpath2, fs.ObjectType, cmn.LocalBs, bucketLocalC, testObject)
+				dst := NewBasicLom(dstFQN, tMock)
+				Expect(lom.MoveVersions(dst, nil)).To(BeEmpty())
+
+				vers, err := lom.OlderVersions()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(vers).To(BeEmpty())
+				vers, err = dst.OlderVersions()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(vers).To(HaveLen(2))
+				Expect(vers[1].Version).To(Equal("2"))
+				Expect(vers[1].Size).To(BeEquivalentTo(1))
+				Expect(dst.DelAllVersions()).To(BeEmpty())
+			})
+		})
+
 		Describe("LomCopy", func() {
 			testFileSize := 123
 			testObject := "foldr/test-obj.ext"
@@ -0,0 +1,320 @@
+// Package cluster provides common interfaces and local access to cluster-level metadata
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package cluster
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/NVIDIA/aistore/3rdparty/glog"
+	"github.com/NVIDIA/aistore/cmn"
+	"github.com/NVIDIA/aistore/fs"
+)
+
+//
+// Version history of the objects in local buckets. When enabled (see
+// cmn.VersionConf.MaxVersions), overwriting an object moves its current
+// version aside - into the fs.VersionType content directory of the same
+// mountpath - instead of destroying it. Older versions carry their original
+// metadata (version, size, checksum) and follow the object: local rebalance
+// moves them to the object's new mountpath, global rebalance sends them to
+// the object's new target. The number of retained versions and their age are
+// limited by LRU (see lru package).
+//
+
+type verFile struct {
+	fqn     string
+	version string
+	num     uint64
+	size    int64
+	mtime   time.Time
+}
+
+// VerFQN returns the location of the given older version of the object
+func (lom *LOM) VerFQN(version string) string { return lom.GenFQN(fs.VersionType, version) }
+
+// ArchiveVersion retains the current version of the object (if any) as an older
+// version and replaces the object via the given callback (that, e.g., renames
+// a workfile over the object). The current version is hard-linked first, so
+// that the object stays in place if the replacement fails - the version is
+// then rolled back. Must be called under the object's exclusive lock.
+func (lom *LOM) ArchiveVersion(replace func() error) (errstr string) {
+	verFQN, errstr := lom.linkVersion()
+	if errstr != "" {
+		return
+	}
+	if err := replace(); err != nil {
+		if verFQN != "" {
+			if errRm := os.Remove(verFQN); errRm != nil && !os.IsNotExist(errRm) {
+				glog.Errorf("Nested (%v): %s: failed to roll back version %s, err: %v", err, lom, verFQN, errRm)
+			}
+		}
+		return fmt.Sprintf("%s: failed to replace, err: %v", lom, err)
+	}
+	if verFQN == "" {
+		return
+	}
+	// from now on, the version's mtime is the time it was superseded (see TrimVersions)
+	now := time.Now()
+	if err := os.Chtimes(verFQN, now, now); err != nil {
+		glog.Warningf("%s: failed to update mtime of version %s, err: %v", lom, verFQN, err)
+	}
+	return
+}
+
+// hard-links the current version of the object (if the history is kept) into
+// the version directory; returns the version's location, empty if not linked
+func (lom *LOM) linkVersion() (verFQN, errstr string) {
+	if !lom.BckIsLocal || !lom.VerConf().KeepsHistory() || !lom.exists {
+		return
+	}
+	md, err := lom.lmfs(false)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			errstr = fmt.Sprintf("%s: failed to load metadata, err: %v", lom, err)
+		}
+		return
+	}
+	if md.version == "" { // written with versioning disabled
+		return
+	}
+	verFQN = lom.VerFQN(md.version)
+	if err = cmn.CreateDir(filepath.Dir(verFQN)); err == nil {
+		if err = os.Remove(verFQN); err == nil || os.IsNotExist(err) {
+			err = os.Link(lom.FQN, verFQN)
+		}
+	}
+	if err != nil {
+		return "", fmt.Sprintf("%s: failed to retain version %s, err: %v", lom, md.version, err)
+	}
+	return
+}
+
+// SaveVersion turns the workfile into the given older version of the object;
+// the version's metadata (see VersionMeta) and mtime are those of the original
+func (lom *LOM) SaveVersion(workFQN, version string, md []byte, mtime time.Time) error {
+	if len(md) > 0 {
+		if err := fs.SetXattr(workFQN, cmn.XattrLOM, md); err != nil {
+			return err
+		}
+	}
+	if err := os.Chtimes(workFQN, mtime, mtime); err != nil {
+		return err
+	}
+	return cmn.MvFile(workFQN, lom.VerFQN(version))
+}
+
+// AdoptVersion moves the older version of the object stored elsewhere (e.g.,
+// on another mountpath) into the object's version directory
+func (lom *LOM) AdoptVersion(srcFQN, version string, buf []byte) error {
+	if srcFQN == lom.VerFQN(version) {
+		return nil
+	}
+	finfo, err := os.Stat(srcFQN)
+	if err != nil {
+		return err
+	}
+	md, err := VersionMeta(srcFQN)
+	if err != nil {
+		return err
+	}
+	workFQN := lom.GenFQN(fs.WorkfileType, fs.WorkfileRebalance)
+	if err = cmn.CopyFile(srcFQN, workFQN, buf); err == nil {
+		err = lom.SaveVersion(workFQN, version, md, finfo.ModTime())
+	}
+	if err != nil {
+		os.Remove(workFQN)
+		return err
+	}
+	return os.Remove(srcFQN)
+}
+
+// MoveVersions moves all older versions of the object to the object's new
+// location (local rebalance)
+func (lom *LOM) MoveVersions(dst *LOM, buf []byte) (errstr string) {
+	files, err := lom.verFiles()
+	if err != nil {
+		return err.Error()
+	}
+	for _, vf := range files {
+		if err := dst.AdoptVersion(vf.fqn, vf.version, buf); err != nil {
+			errstr = fmt.Sprintf("%s: failed to move version %s, err: %v", lom, vf.version, err)
+		}
+	}
+	return
+}
+
+// VersionMeta returns the metadata of the older version stored at fqn, as is
+func VersionMeta(fqn string) ([]byte, error) {
+	md, err := fs.GetXattr(fqn, cmn.XattrLOM)
+	if os.IsNotExist(err) {
+		return nil, err
+	}
+	return md, nil // best effort, as with objVersion
+}
+
+// RekeyVersionMeta returns the metadata of the older version (see VersionMeta)
+// updated for the version's content stored anew: its size (plaintext) and the
+// ID of the key it is now encrypted with, if any (see lom_enc.go)
+func RekeyVersionMeta(b []byte, size int64, keyID string) ([]byte, error) {
+	md := &lmeta{}
+	if len(b) > 0 {
+		if err := md.unmarshal(string(b)); err != nil {
+			return nil, err
+		}
+	}
+	md.size, md.keyID = size, keyID
+	return []byte(md.marshal()), nil
+}
+
+// OlderVersions returns the retained older versions of the object, oldest first
+func (lom *LOM) OlderVersions() (vers []cmn.ObjVersion, err error) {
+	files, err := lom.verFiles()
+	if err != nil {
+		return nil, err
+	}
+	vers = make([]cmn.ObjVersion, 0, len(files))
+	for _, vf := range files {
+		vers = append(vers, vf.objVersion())
+	}
+	return
+}
+
+// OlderVersion returns the retained older version of the object and its location
+func (lom *LOM) OlderVersion(version string) (ver *cmn.ObjVersion, fqn string, err error) {
+	if _, err = strconv.ParseUint(version, 10, 64); err != nil {
+		return nil, "", fmt.Errorf("%s: invalid version %q", lom, version)
+	}
+	fqn = lom.VerFQN(version)
+	finfo, err := os.Stat(fqn)
+	if err != nil {
+		return
+	}
+	vf := &verFile{fqn: fqn, version: version, size: finfo.Size(), mtime: finfo.ModTime()}
+	v := vf.objVersion()
+	return &v, fqn, nil
+}
+
+// DelVersion removes the given older version of the object
+func (lom *LOM) DelVersion(version string) error {
+	_, fqn, err := lom.OlderVersion(version)
+	if err != nil {
+		return err
+	}
+	return os.Remove(fqn)
+}
+
+// DelAllVersions removes all older versions of the object
+func (lom *LOM) DelAllVersions() (errstr string) {
+	files, err := lom.verFiles()
+	if err != nil {
+		return err.Error()
+	}
+	for _, vf := range files {
+		if err := os.Remove(vf.fqn); err != nil && !os.IsNotExist(err) {
+			errstr = fmt.Sprintf("%s: failed to remove version %s, err: %v", lom, vf.version, err)
+		}
+	}
+	return
+}
+
+// TrimVersions removes the older versions of the object that exceed the maximum
+// number of retained versions or have been superseded more than retention ago
+// (zero retention - no time limit); returns the number and size of removed versions
+func (lom *LOM) TrimVersions(maxVersions int, retention time.Duration) (n, size int64, errstr string) {
+	files, err := lom.verFiles()
+	if err != nil {
+		return 0, 0, err.Error()
+	}
+	var (
+		expired = time.Now().Add(-retention)
+		excess  = len(files) - maxVersions
+	)
+	for i, vf := range files {
+		if i >= excess && (retention == 0 || vf.mtime.After(expired)) {
+			continue
+		}
+		if err := os.Remove(vf.fqn); err != nil {
+			if !os.IsNotExist(err) {
+				errstr = fmt.Sprintf("%s: failed to remove version %s, err: %v", lom, vf.version, err)
+			}
+			continue
+		}
+		n++
+		size += vf.size
+	}
+	return
+}
+
+// lists the versions of the object stored in its version directory, sorted by version
+func (lom *LOM) verFiles() (files []*verFile, err error) {
+	var (
+		dir, base = filepath.Split(lom.VerFQN("0"))
+		objBase   = base[:len(base)-len(".v0")]
+	)
+	finfos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			err = nil
+		}
+		return
+	}
+	for _, finfo := range finfos {
+		if finfo.IsDir() {
+			continue
+		}
+		orig, version, ok := fs.ParseVersionFQN(finfo.Name())
+		if !ok || orig != objBase {
+			continue
+		}
+		num, _ := strconv.ParseUint(version, 10, 64)
+		files = append(files, &verFile{
+			fqn:     filepath.Join(dir, finfo.Name()),
+			version: version,
+			num:     num,
+			size:    finfo.Size(),
+			mtime:   finfo.ModTime(),
+		})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].num < files[j].num })
+	return
+}
+
+// best effort: the checksum is omitted if the version's metadata cannot be read
+func (vf *verFile) objVersion() cmn.ObjVersion {
+	ver := cmn.ObjVersion{Version: vf.version, Size: vf.size, Mtime: vf.mtime}
+	b, err := fs.GetXattr(vf.fqn, cmn.XattrLOM)
+	if err != nil || len(b) == 0 {
+		return ver
+	}
+	md := &lmeta{}
+	if err = md.unmarshal(string(b)); err != nil {
+		glog.Warningf("%s: invalid metadata, err: %v", vf.fqn, err)
+		return ver
+	}
+	if md.cksum != nil {
+		ver.CksumType, ver.Checksum = md.cksum.Get()
+	}
+	ver.Size = md.size // not including the encryption overhead, if any
+	return ver
+}
+
+// OpenVersion opens the older version of the object for reading
+func (lom *LOM) OpenVersion(fqn string) (ObjReader, error) {
+	var keyID string
+	if b, err := fs.GetXattr(fqn, cmn.XattrLOM); err == nil && len(b) > 0 {
+		md := &lmeta{}
+		if err = md.unmarshal(string(b)); err != nil {
+			return nil, fmt.Errorf("%s: invalid metadata, err: %v", fqn, err)
+		}
+		keyID = md.keyID
+	}
+	return openObj(fqn, keyID)
+}
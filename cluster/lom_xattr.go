@@ -5,9 +5,11 @@
 package cluster
 
 import (
+	"encoding/base64"
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"syscall"
 
@@ -25,6 +27,8 @@ const (
 	lomObjVer
 	lomObjSiz
 	lomObjCps
+	lomCustom
+	lomKeyID
 
 	// NOTE: must be the last field
 	numXattrs
@@ -72,11 +76,11 @@ func (lom *LOM) Persist() (err error) {
 func (md *lmeta) unmarshal(mdstr string) (err error) {
 	const invalid = "invalid lmeta "
 	var (
-		records                                           []string
-		payload                                           string
-		expectedCksm, actualCksm                          uint64
-		lomCksumKind, lomCksumVal                         string
-		haveSiz, haveCsmKnd, haveCsmVal, haveVer, haveCps bool
+		records                                                                []string
+		payload                                                                string
+		expectedCksm, actualCksm                                               uint64
+		lomCksumKind, lomCksumVal                                              string
+		haveSiz, haveCsmKnd, haveCsmVal, haveVer, haveCps, haveCustom, haveKey bool
 	)
 	expectedCksm = binary.BigEndian.Uint64([]byte(mdstr))
 	payload = mdstr[cmn.SizeofI64:]
@@ -121,6 +125,24 @@ func (md *lmeta) unmarshal(mdstr string) (err error) {
 				md.copyFQN = strings.Split(val, cpyfqnSepa)
 				haveCps = true
 			}
+		case lomCustom:
+			if val != "" {
+				if haveCustom {
+					return errors.New(invalid + "#9")
+				}
+				if md.custom, err = unmarshalCustom(val); err != nil {
+					return errors.New(invalid + "#10")
+				}
+				haveCustom = true
+			}
+		case lomKeyID:
+			if val != "" {
+				if haveKey {
+					return errors.New(invalid + "#11")
+				}
+				md.keyID = val
+				haveKey = true
+			}
 		default:
 			return errors.New(invalid + "#6")
 		}
@@ -153,6 +175,8 @@ func (md *lmeta) marshal() (payload string) {
 	records[lomObjVer] = xattrRec(lomObjVer, md.version, bkey)
 	records[lomObjSiz] = xattrRec(lomObjSiz, string(bb), bkey)
 	records[lomObjCps] = xattrRec(lomObjCps, strings.Join(md.copyFQN, cpyfqnSepa), bkey)
+	records[lomCustom] = xattrRec(lomCustom, marshalCustom(md.custom), bkey)
+	records[lomKeyID] = xattrRec(lomKeyID, md.keyID, bkey)
 	payload = strings.Join(records[0:], recordSepa)
 	//
 	// checksum, append, and return
@@ -168,3 +192,57 @@ func xattrRec(key int, value string, b [cmn.SizeofI16]byte) string {
 	binary.BigEndian.PutUint16(bb, uint16(key))
 	return string(bb) + value
 }
+
+// user-defined metadata is stored as a sequence of length-prefixed
+// key and value strings, sorted by key and base64-encoded (so that
+// arbitrary keys and values never contain the record separator)
+func marshalCustom(custom cmn.SimpleKVs) string {
+	if len(custom) == 0 {
+		return ""
+	}
+	var (
+		b    [cmn.SizeofI16]byte
+		bb   = b[0:]
+		sb   strings.Builder
+		keys = make([]string, 0, len(custom))
+	)
+	for k := range custom {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, s := range []string{k, custom[k]} {
+			binary.BigEndian.PutUint16(bb, uint16(len(s)))
+			sb.Write(bb)
+			sb.WriteString(s)
+		}
+	}
+	return base64.StdEncoding.EncodeToString([]byte(sb.String()))
+}
+
+func unmarshalCustom(enc string) (custom cmn.SimpleKVs, err error) {
+	var (
+		kv [2]string
+		b  []byte
+	)
+	if b, err = base64.StdEncoding.DecodeString(enc); err != nil {
+		return
+	}
+	val := string(b)
+	custom = make(cmn.SimpleKVs, 4)
+	for len(val) > 0 {
+		for i := range kv {
+			if len(val) < cmn.SizeofI16 {
+				return nil, errors.New("truncated")
+			}
+			l := int(binary.BigEndian.Uint16([]byte(val)))
+			val = val[cmn.SizeofI16:]
+			if len(val) < l {
+				return nil, errors.New("truncated")
+			}
+			kv[i], val = val[:l], val[l:]
+		}
+		custom[kv[0]] = kv[1]
+	}
+	return
+}
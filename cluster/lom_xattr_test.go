@@ -9,6 +9,7 @@ import (
 
 	"github.com/NVIDIA/aistore/cluster"
 	"github.com/NVIDIA/aistore/cmn"
+	"github.com/NVIDIA/aistore/encryption"
 	"github.com/NVIDIA/aistore/fs"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -88,6 +89,74 @@ var _ = Describe("LOM Xattributes", func() {
 				Expect(lom.Version()).To(BeEquivalentTo(newLom.Version()))
 				Expect(lom.CopyFQN()).To(BeEquivalentTo(newLom.CopyFQN()))
 			})
+
+			It("should save and restore custom metadata", func() {
+				lom := filePut(localFQN, testFileSize, tMock)
+				lom.SetCksum(cmn.NewCksum(cmn.ChecksumXXHash, "testchecksum"))
+				lom.SetCustomMD(cmn.SimpleKVs{"color": "blue", "owner": "alice"})
+				Expect(lom.Persist()).NotTo(HaveOccurred())
+
+				lom.Uncache()
+				newLom := NewBasicLom(localFQN, tMock)
+				_, errstr := newLom.Load(false)
+				Expect(errstr).To(BeEmpty())
+				Expect(newLom.CustomMD()).To(Equal(lom.CustomMD()))
+
+				lom.SetCustomMD(nil)
+				Expect(lom.Persist()).NotTo(HaveOccurred())
+				Expect(lom.LoadMetaFromFS()).NotTo(HaveOccurred())
+				Expect(lom.CustomMD()).To(BeEmpty())
+			})
+
+			It("should save and restore encryption key ID", func() {
+				lom := filePut(localFQN, testFileSize, tMock)
+				lom.SetKeyID("k1")
+				Expect(lom.Persist()).NotTo(HaveOccurred())
+
+				newLom := NewBasicLom(localFQN, tMock)
+				Expect(newLom.LoadMetaFromFS()).NotTo(HaveOccurred())
+				Expect(newLom.KeyID()).To(Equal("k1"))
+				Expect(newLom.Size()).To(BeEquivalentTo(testFileSize))
+				Expect(newLom.DiskSize()).To(Equal(encryption.EncryptedSize(int64(testFileSize))))
+
+				lom.SetKeyID("")
+				Expect(lom.Persist()).NotTo(HaveOccurred())
+				newLom = NewBasicLom(localFQN, tMock)
+				Expect(newLom.LoadMetaFromFS()).NotTo(HaveOccurred())
+				Expect(newLom.Encrypted()).To(BeFalse())
+				Expect(newLom.DiskSize()).To(BeEquivalentTo(testFileSize))
+			})
+
+			It("should rekey the metadata of an older version", func() {
+				lom := filePut(localFQN, testFileSize, tMock)
+				lom.SetCksum(cmn.NewCksum(cmn.ChecksumXXHash, "testchecksum"))
+				lom.SetVersion("3")
+				lom.SetKeyID("k1")
+				Expect(lom.Persist()).NotTo(HaveOccurred())
+				md, err := cluster.VersionMeta(localFQN)
+				Expect(err).NotTo(HaveOccurred())
+
+				for _, keyID := range []string{"k2", ""} {
+					rekeyed, err := cluster.RekeyVersionMeta(md, int64(testFileSize), keyID)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(fs.SetXattr(localFQN, cmn.XattrLOM, rekeyed)).NotTo(HaveOccurred())
+					newLom := NewBasicLom(localFQN, tMock)
+					Expect(newLom.LoadMetaFromFS()).NotTo(HaveOccurred())
+					Expect(newLom.KeyID()).To(Equal(keyID))
+					Expect(newLom.Size()).To(BeEquivalentTo(testFileSize))
+					Expect(newLom.Version()).To(Equal("3"))
+					Expect(newLom.Cksum()).To(BeEquivalentTo(lom.Cksum()))
+				}
+
+				// no metadata to start with: created anew
+				rekeyed, err := cluster.RekeyVersionMeta(nil, int64(testFileSize), "k2")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fs.SetXattr(localFQN, cmn.XattrLOM, rekeyed)).NotTo(HaveOccurred())
+				newLom := NewBasicLom(localFQN, tMock)
+				Expect(newLom.LoadMetaFromFS()).NotTo(HaveOccurred())
+				Expect(newLom.KeyID()).To(Equal("k2"))
+				Expect(newLom.Size()).To(BeEquivalentTo(testFileSize))
+			})
 		})
 
 		Describe("LoadMetaFromFS", func() {
@@ -7,6 +7,7 @@ package cluster
 import (
 	"fmt"
 	"reflect"
+	"sort"
 
 	"github.com/NVIDIA/aistore/3rdparty/glog"
 	"github.com/NVIDIA/aistore/cmn"
@@ -41,9 +42,10 @@ type NetInfo struct {
 type Snode struct {
 	DaemonID        string  `json:"daemon_id"`
 	DaemonType      string  `json:"daemon_type"`
-	PublicNet       NetInfo `json:"public_net"`        // cmn.NetworkPublic
-	IntraControlNet NetInfo `json:"intra_control_net"` // cmn.NetworkIntraControl
-	IntraDataNet    NetInfo `json:"intra_data_net"`    // cmn.NetworkIntraData
+	PublicNet       NetInfo `json:"public_net"`               // cmn.NetworkPublic
+	IntraControlNet NetInfo `json:"intra_control_net"`        // cmn.NetworkIntraControl
+	IntraDataNet    NetInfo `json:"intra_data_net"`           // cmn.NetworkIntraData
+	FailureDomain   string  `json:"failure_domain,omitempty"` // rack, zone, etc. (optional)
 	idDigest        uint64
 }
 
@@ -54,12 +56,12 @@ func (d *Snode) Digest() uint64 {
 	return d.idDigest
 }
 
-const snodefmt = "[\n\tDaemonID: %s,\n\tDaemonType: %s, \n\tPublicNet: %s,\n\tIntraControl: %s,\n\tIntraData: %s,\n\tidDigest: %d]"
+const snodefmt = "[\n\tDaemonID: %s,\n\tDaemonType: %s, \n\tPublicNet: %s,\n\tIntraControl: %s,\n\tIntraData: %s,\n\tFailureDomain: %s,\n\tidDigest: %d]"
 
 func (d *Snode) String() string {
 	if glog.FastV(4, glog.SmoduleCluster) {
 		return fmt.Sprintf(snodefmt, d.DaemonID, d.DaemonType, d.PublicNet.DirectURL,
-			d.IntraControlNet.DirectURL, d.IntraDataNet.DirectURL, d.idDigest)
+			d.IntraControlNet.DirectURL, d.IntraDataNet.DirectURL, d.FailureDomain, d.idDigest)
 	}
 	return d.DaemonID
 }
@@ -82,7 +84,8 @@ func (a *Snode) Equals(b *Snode) bool {
 	return a.DaemonID == b.DaemonID && a.DaemonType == b.DaemonType &&
 		reflect.DeepEqual(a.PublicNet, b.PublicNet) &&
 		reflect.DeepEqual(a.IntraControlNet, b.IntraControlNet) &&
-		reflect.DeepEqual(a.IntraDataNet, b.IntraDataNet)
+		reflect.DeepEqual(a.IntraDataNet, b.IntraDataNet) &&
+		a.FailureDomain == b.FailureDomain
 }
 
 //see printname() in clustermap.go
@@ -132,6 +135,34 @@ func (m *Smap) InitDigests() {
 func (m *Smap) CountTargets() int { return len(m.Tmap) }
 func (m *Smap) CountProxies() int { return len(m.Pmap) }
 
+// Returns the numbers of targets in each failure domain, or nil if none of the
+// targets has one. A target without failure domain is a domain on its own.
+func (m *Smap) DomainSizes() (sizes []int) {
+	var (
+		domains   = make(map[string]int, len(m.Tmap))
+		unlabeled int
+	)
+	for _, si := range m.Tmap {
+		if si.FailureDomain == "" {
+			unlabeled++
+			continue
+		}
+		domains[si.FailureDomain]++
+	}
+	if len(domains) == 0 {
+		return nil
+	}
+	sizes = make([]int, 0, len(domains)+unlabeled)
+	for _, size := range domains {
+		sizes = append(sizes, size)
+	}
+	for i := 0; i < unlabeled; i++ {
+		sizes = append(sizes, 1)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(sizes)))
+	return
+}
+
 func (m *Smap) GetTarget(sid string) *Snode {
 	si, ok := m.Tmap[sid]
 	if !ok {
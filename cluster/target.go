@@ -25,7 +25,9 @@ type Target interface {
 	AvgCapUsed(config *cmn.Config, used ...int32) (int32, bool)
 	IsRebalancing() bool
 	RunLRU()
+	RunLifecycle()
 	PrefetchQueueLen() int
+	WriteBackBacklog() (cnt, size int64)
 	Prefetch()
 	GetBowner() Bowner
 	FSHC(err error, path string)
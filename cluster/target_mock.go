@@ -34,6 +34,7 @@ func (t *TargetMock) AvgCapUsed(config *cmn.Config, used ...int32) (avgCapUsed i
 }
 func (t *TargetMock) IsRebalancing() bool         { return false }
 func (t *TargetMock) RunLRU()                     {}
+func (t *TargetMock) RunLifecycle()               {}
 func (t *TargetMock) PrefetchQueueLen() int       { return 0 }
 func (t *TargetMock) Prefetch()                   {}
 func (t *TargetMock) GetBowner() Bowner           { return t.BO }
@@ -46,3 +47,6 @@ func (t *TargetMock) Receive(workFQN string, reader io.ReadCloser, lom *LOM, rec
 	return nil
 }
 func (t *TargetMock) GetFSPRG() fs.PathRunGroup { return nil }
+func (t *TargetMock) WriteBackBacklog() (cnt, size int64) {
+	return 0, 0
+}
@@ -51,6 +51,7 @@ type XactKindType map[string]XactKindMeta
 var XactKind = XactKindType{
 	// global kinds
 	ActLRU:          {true},
+	ActLifecycle:    {true},
 	ActElection:     {true},
 	ActLocalReb:     {true},
 	ActGlobalReb:    {true},
@@ -63,8 +64,12 @@ var XactKind = XactKindType{
 	ActECGet:       {},
 	ActECPut:       {},
 	ActECRespond:   {},
+	ActECScrub:     {},
+	ActECEncode:    {},
 	ActMakeNCopies: {},
 	ActPutCopies:   {},
+	ActCopyBucket:  {},
+	ActSyncBucket:  {},
 }
 
 // ActionMsg.Action enum (includes xactions)
@@ -73,6 +78,7 @@ const (
 	ActGlobalReb    = "rebalance"      // global cluster-wide rebalance
 	ActLocalReb     = "localrebalance" // local rebalance
 	ActLRU          = "lru"
+	ActLifecycle    = "lifecycle"
 	ActSyncLB       = "synclb"
 	ActCreateLB     = "createlb"
 	ActDestroyLB    = "destroylb"
@@ -99,12 +105,36 @@ const (
 	ActPutCopies    = "putcopies"
 	ActMakeNCopies  = "makencopies"
 	ActLoadLomCache = "loadlomcache"
-	ActECGet        = "ecget"  // erasure decode objects
-	ActECPut        = "ecput"  // erasure encode objects
-	ActECRespond    = "ecresp" // respond to other targets' EC requests
+	ActECGet        = "ecget"    // erasure decode objects
+	ActECPut        = "ecput"    // erasure encode objects
+	ActECRespond    = "ecresp"   // respond to other targets' EC requests
+	ActECScrub      = "ecscrub"  // verify and repair slices and replicas of EC'ed objects
+	ActECEncode     = "ecencode" // erasure encode existing objects of a bucket
 	ActStartGFN     = "metasync-start-gfn"
 	ActRecoverBck   = "recoverbck"
 
+	// Actions for multipart upload (/v1/objects/bucket-name/object-name)
+	ActInitMultipart     = "initmultipart"
+	ActCompleteMultipart = "completemultipart"
+	ActAbortMultipart    = "abortmultipart"
+
+	// Action to build a new object out of existing ones (/v1/objects/bucket-name/object-name)
+	ActConcat = "concat"
+
+	// Action to update user-defined metadata of an existing object (/v1/objects/bucket-name/object-name)
+	ActSetCustomMD = "setcustommd"
+
+	// Action to read multiple objects in a single request (/v1/buckets/bucket-name)
+	ActGetBatch = "getbatch"
+
+	// Actions to copy objects into another bucket, possibly of a different provider:
+	// the entire bucket (/v1/buckets/bucket-name) and a single object (/v1/objects/bucket-name/object-name)
+	ActCopyBucket = "copybck"
+	ActCopyObject = "copyobj"
+
+	// Action to synchronize cached Cloud bucket with the Cloud (/v1/buckets/bucket-name)
+	ActSyncBucket = "syncbck"
+
 	// Actions for manipulating mountpaths (/v1/daemon/mountpaths)
 	ActMountpathEnable  = "enable"
 	ActMountpathDisable = "disable"
@@ -122,9 +152,12 @@ const (
 
 // Cloud Provider enum
 const (
-	ProviderAmazon = "aws"
-	ProviderGoogle = "gcp"
-	ProviderAIS    = "ais"
+	ProviderAmazon    = "aws"
+	ProviderGoogle    = "gcp"
+	ProviderAzure     = "azure"
+	ProviderHTTP      = "http"       // read-only HTTP(S) origin
+	ProviderRemoteAIS = "remote_ais" // another AIStore cluster
+	ProviderAIS       = "ais"
 )
 
 // Header Key enum
@@ -150,6 +183,8 @@ const (
 	HeaderBucketValidateRange   = "cksum.enable_read_range" // Byte range validation policy used for objects in the bucket
 	HeaderBucketVerEnabled      = "ver.enabled"             // Enable/disable object versioning in a bucket
 	HeaderBucketVerValidateWarm = "ver.validate_warm_get"   // Validate version on warm GET
+	HeaderBucketVerMaxVersions  = "ver.max_versions"        // Number of older versions retained per object
+	HeaderBucketVerRetention    = "ver.retention"           // How long older versions are retained once superseded
 	HeaderBucketLRUEnabled      = "lru.enabled"             // LRU is run on a bucket only if this field is true
 	HeaderBucketLRULowWM        = "lru.lowwm"               // Capacity usage low water mark
 	HeaderBucketLRUHighWM       = "lru.highwm"              // Capacity usage high water mark
@@ -162,7 +197,18 @@ const (
 	HeaderBucketECMinSize       = "ec.objsize_limit"        // Objects under MinSize copied instead of being EC'ed
 	HeaderBucketECData          = "ec.data_slices"          // number of data chunks for EC
 	HeaderBucketECParity        = "ec.parity_slices"        // number of parity chunks for EC/copies for small files
+	HeaderBucketECCode          = "ec.code"                 // erasure code: rs or lrc
+	HeaderBucketECLocalGroups   = "ec.local_groups"         // LRC: number of local groups of data slices
 	HeaderRebalanceEnabled      = "rebalance.enabled"       // starts rebalance automatically on Smap/Mountpath changes when set to true
+	HeaderBucketQuotaSoftSize   = "quota.soft_size"         // bucket capacity (bytes) above which PUTs get logged as exceeding the quota
+	HeaderBucketQuotaHardSize   = "quota.hard_size"         // bucket capacity (bytes) above which PUTs get rejected
+	HeaderBucketQuotaSoftObjs   = "quota.soft_objects"      // number of objects above which PUTs get logged as exceeding the quota
+	HeaderBucketQuotaHardObjs   = "quota.hard_objects"      // number of objects above which PUTs of new objects get rejected
+	HeaderBucketUsedSize        = "quota.used_size"         // bucket usage (bytes), cluster-wide (HEAD bucket response only)
+	HeaderBucketUsedObjs        = "quota.used_objects"      // number of objects, cluster-wide (HEAD bucket response only)
+	HeaderBucketWriteBack       = "write_back.enabled"      // PUT to a Cloud bucket returns before the object is uploaded to the Cloud
+	HeaderBucketEncryption      = "encryption.enabled"      // objects of a local bucket are encrypted at rest
+	HeaderBucketEncryptionKey   = "encryption.key_id"       // ID of the key new objects get encrypted with
 
 	// object meta
 	HeaderObjCksumType = "ObjCksumType" // Checksum Type (xxhash, md5, none)
@@ -171,6 +217,11 @@ const (
 	HeaderObjReplicSrc = "ObjReplicSrc" // In replication PUT request specifies the source target
 	HeaderObjSize      = "ObjSize"      // Object size (bytes)
 	HeaderObjVersion   = "ObjVersion"   // Object version/generation - local or Cloud
+	HeaderAppendHandle = "AppendHandle" // Append handle (see URLParamHandle)
+	HeaderTierPath     = "TierPath"     // IDs of the tiers (primary proxies) a request has been forwarded by
+
+	// user-defined object metadata: one header per key, e.g. "ObjMeta-Color: blue"
+	HeaderObjCustomPrefix = "ObjMeta-"
 )
 
 // URL Query "?name1=val1&name2=..."
@@ -184,6 +235,12 @@ const (
 	URLParamBckProvider = "bprovider"    // "local" | "cloud"
 	URLParamPrefix      = "prefix"       // prefix for list objects in a bucket
 	URLParamRegex       = "regex"        // param for only returning dsort/downloader processes where the description contains regex
+	URLParamUploadID    = "uploadid"     // multipart upload ID (as returned by ActInitMultipart)
+	URLParamPartNum     = "partnum"      // multipart upload part number (1 to MaxMultipartParts)
+	URLParamAppendType  = "appendty"     // "append" | "flush" (see AppendOp and FlushOp)
+	URLParamHandle      = "handle"       // append handle (as returned by the first append in HeaderAppendHandle)
+	URLParamArchPath    = "archpath"     // GET a single file (member) of an archive object (.tar, .tgz, .tar.gz, .zip)
+	URLParamVersion     = "version"      // GET or DELETE a given (older) version of an object
 	// internal use
 	URLParamFromID           = "fid" // source target ID
 	URLParamToID             = "tid" // destination target ID
@@ -198,6 +255,7 @@ const (
 	URLParamUnixTime         = "utm" // Unix time: number of nanoseconds elapsed since 01/01/70 UTC
 	URLParamReadahead        = "rah" // Proxy to target: readeahed
 	URLParamIsGFNRequest     = "gfn" // true if the request is a Get From Neighbor request
+	URLParamS3               = "s3c" // true: the request comes via S3 API (see ais/s3compat) - errors are returned as S3 XML
 
 	// dsort
 	URLParamTotalCompressedSize   = "tcs"
@@ -232,6 +290,8 @@ type SelectMsg struct {
 	PageMarker string `json:"pagemarker"`  // marker - the last object in previous page
 	PageSize   int    `json:"pagesize"`    // maximum number of entries returned by list bucket call
 	Fast       bool   `json:"fast"`        // performs a fast traversal of the bucket contents (returns only names)
+
+	BypassCache bool `json:"bypass_cache,omitempty"` // list Cloud bucket without using the proxy's cache of listings
 }
 
 // ListRangeMsgBase contains fields common to Range and List operations
@@ -254,6 +314,106 @@ type RangeMsg struct {
 	Range  string `json:"range"`
 }
 
+// MultipartPart identifies a single uploaded part of a multipart upload.
+// Checksum, if specified, must match the one returned by the part's PUT.
+type MultipartPart struct {
+	PartNum int    `json:"part_num"`
+	Cksum   string `json:"cksum,omitempty"`
+}
+
+// MultipartMsg is returned by ActInitMultipart and is the value of
+// ActCompleteMultipart (ordered list of parts) and ActAbortMultipart
+type MultipartMsg struct {
+	UploadID string          `json:"upload_id"`
+	Parts    []MultipartPart `json:"parts,omitempty"`
+}
+
+const (
+	MaxMultipartParts = 10000 // maximum number of parts in a single multipart upload
+)
+
+const (
+	MaxCustomMDSize = 2 * KiB // maximum total size of user-defined object metadata (keys and values)
+	MaxCustomMDKeys = 64      // maximum number of user-defined metadata keys
+)
+
+// append PUT types (URLParamAppendType)
+const (
+	AppendOp = "append" // append data to the object's pending (not yet visible) content
+	FlushOp  = "flush"  // make the appended content visible as the new version of the object
+)
+
+// ConcatMsg is the value of ActConcat: ordered list of the objects (in the
+// same bucket) to concatenate into the destination object
+type ConcatMsg struct {
+	Objnames []string `json:"objnames"`
+}
+
+// GetBatchMsg is the value of ActGetBatch: the objects to read, specified
+// either by names or by a template (e.g. "shard-{0000..0999}.tar"), and
+// the format of the resulting archive
+type GetBatchMsg struct {
+	Objnames    []string `json:"objnames,omitempty"`
+	Template    string   `json:"template,omitempty"`
+	Format      string   `json:"format,omitempty"`       // BatchFormatTar (default) | BatchFormatTgz
+	SkipMissing bool     `json:"skip_missing,omitempty"` // omit missing objects instead of failing the request
+}
+
+// GetBatchMsg formats
+const (
+	BatchFormatTar = "tar"
+	BatchFormatTgz = "tgz"
+)
+
+// CopyBucketMsg is the value of ActCopyBucket: the destination bucket and,
+// optionally, the filters selecting the objects to copy (all the specified
+// filters must match)
+type CopyBucketMsg struct {
+	Bucket      string `json:"bucket"`
+	BckProvider string `json:"bprovider,omitempty"`
+	Prefix      string `json:"prefix,omitempty"`
+	Regex       string `json:"regex,omitempty"`
+	Template    string `json:"template,omitempty"` // e.g. "shard-{0000..0999}.tar"
+}
+
+// CopyObjectMsg is the value of ActCopyObject: the destination bucket and
+// the name of the copy (defaults to the name of the source object)
+type CopyObjectMsg struct {
+	Bucket      string `json:"bucket"`
+	BckProvider string `json:"bprovider,omitempty"`
+	Objname     string `json:"objname,omitempty"`
+}
+
+// SyncBucketMsg is the value of ActSyncBucket: fetch the objects that are new
+// or changed in the Cloud (and, optionally, evict the objects deleted from the
+// Cloud) - or only report what would be done (dry run)
+type SyncBucketMsg struct {
+	Prefix       string `json:"prefix,omitempty"`
+	EvictDeleted bool   `json:"evict_deleted,omitempty"`
+	DryRun       bool   `json:"dry_run,omitempty"`
+}
+
+// SyncBucketReport is the result of ActSyncBucket dry run: the names of the
+// objects that are new in the Cloud, changed in the Cloud (different version
+// or size), and deleted from the Cloud (reported only with EvictDeleted)
+type SyncBucketReport struct {
+	New     []string `json:"new"`
+	Changed []string `json:"changed"`
+	Deleted []string `json:"deleted"`
+}
+
+// ObjVersion describes a version of the object - the current one or one of the
+// retained older versions (see VersionConf.MaxVersions). For older versions,
+// Mtime is the time the version was superseded.
+type ObjVersion struct {
+	Version   string    `json:"version"`
+	Size      int64     `json:"size"`
+	CksumType string    `json:"cksum_type,omitempty"`
+	Checksum  string    `json:"checksum,omitempty"`
+	Mtime     time.Time `json:"mtime"`
+	Current   bool      `json:"current,omitempty"`
+}
+
 // MountpathList contains two lists:
 // * Available - list of local mountpaths available to the storage target
 // * Disabled  - list of disabled mountpaths, the mountpaths that generated
@@ -287,6 +447,8 @@ const (
 	GetWhatSysInfo      = "sysinfo"
 	GetWhatDaemonStatus = "status"
 	GetWhatBucketMetaX  = "bucketmdxattr"
+	GetWhatObjVersions  = "versions" // GET /v1/objects/bucket-name/object-name?what=versions
+	GetWhatBucketUsage  = "usage"    // GET /v1/buckets/bucket-name?what=usage (target only)
 )
 
 // SelectMsg.TimeFormat enum
@@ -313,6 +475,7 @@ const (
 	GetTargetURL     = "targetURL"
 	GetPropsStatus   = "status"
 	GetPropsCopies   = "copies"
+	GetPropsCustomMD = "custom"
 )
 
 // BucketEntry.Status
@@ -331,18 +494,19 @@ const (
 // BucketEntry corresponds to a single entry in the BucketList and
 // contains file and directory metadata as per the SelectMsg
 type BucketEntry struct {
-	Name      string `json:"name"`                // name of the object - note: does not include the bucket name
-	Size      int64  `json:"size,omitempty"`      // size in bytes
-	Ctime     string `json:"ctime,omitempty"`     // formatted as per SelectMsg.TimeFormat
-	Checksum  string `json:"checksum,omitempty"`  // checksum
-	Type      string `json:"type,omitempty"`      // "file" OR "directory"
-	Atime     string `json:"atime,omitempty"`     // formatted as per SelectMsg.TimeFormat
-	Bucket    string `json:"bucket,omitempty"`    // parent bucket name
-	Version   string `json:"version,omitempty"`   // version/generation ID. In GCP it is int64, in AWS it is a string
-	TargetURL string `json:"targetURL,omitempty"` // URL of target which has the entry
-	Status    string `json:"status,omitempty"`    // empty - normal object, it can be "moved", "deleted" etc
-	Copies    int16  `json:"copies,omitempty"`    // ## copies (non-replicated = 1)
-	IsCached  bool   `json:"iscached,omitempty"`  // if the file is cached on one of targets
+	Name      string    `json:"name"`                // name of the object - note: does not include the bucket name
+	Size      int64     `json:"size,omitempty"`      // size in bytes
+	Ctime     string    `json:"ctime,omitempty"`     // formatted as per SelectMsg.TimeFormat
+	Checksum  string    `json:"checksum,omitempty"`  // checksum
+	Type      string    `json:"type,omitempty"`      // "file" OR "directory"
+	Atime     string    `json:"atime,omitempty"`     // formatted as per SelectMsg.TimeFormat
+	Bucket    string    `json:"bucket,omitempty"`    // parent bucket name
+	Version   string    `json:"version,omitempty"`   // version/generation ID. In GCP it is int64, in AWS it is a string
+	TargetURL string    `json:"targetURL,omitempty"` // URL of target which has the entry
+	Status    string    `json:"status,omitempty"`    // empty - normal object, it can be "moved", "deleted" etc
+	Copies    int16     `json:"copies,omitempty"`    // ## copies (non-replicated = 1)
+	IsCached  bool      `json:"iscached,omitempty"`  // if the file is cached on one of targets
+	CustomMD  SimpleKVs `json:"custom,omitempty"`    // user-defined metadata
 }
 
 // BucketList represents the contents of a given bucket - somewhat analogous to the 'ls <bucket-name>'
@@ -355,6 +519,8 @@ type BucketList struct {
 type BucketNames struct {
 	Cloud []string `json:"cloud"`
 	Local []string `json:"local"`
+	// Cloud bucket names grouped by Cloud provider
+	CloudByProvider map[string][]string `json:"cloud_by_provider,omitempty"`
 }
 
 const (
@@ -366,6 +532,7 @@ const (
 const (
 	// l1
 	Version = "v1"
+	S3      = "s3" // S3 compatibility API (see ais/s3compat)
 	// l2
 	Buckets   = "buckets"
 	Objects   = "objects"
@@ -849,13 +1016,19 @@ const (
 	RWPolicyNextTier = "next_tier"
 )
 
+// key providers (see KeyProviderConf)
+const (
+	KeyProviderFile = "file"
+)
+
 // BucketProps defines the configuration of the bucket with regard to
 // its type, checksum, and LRU. These characteristics determine its behaviour
 // in response to operations on the bucket itself or the objects inside the bucket.
 type BucketProps struct {
-	// CloudProvider can be "aws", "gcp" (clouds) - or "ais".
+	// CloudProvider can be "aws", "gcp", "azure", "http", "remote_ais" (clouds) - or "ais".
 	// If a bucket is local, CloudProvider must be "ais".
-	// Otherwise, it must be "aws" or "gcp".
+	// Otherwise, it must be one of the Cloud providers configured in the cluster
+	// (see Config.CloudProvider); empty means the cluster's default.
 	CloudProvider string `json:"cloud_provider,omitempty"`
 
 	// Versioning defines what kind of buckets should use versioning to
@@ -890,22 +1063,127 @@ type BucketProps struct {
 	// Rebalance defines auto-rebalance policy for the bucket
 	Rebalance RebalanceConf `json:"rebalance"`
 
+	// Lifecycle defines the rules to delete, evict or transition the bucket's
+	// objects based on their age
+	Lifecycle LifecycleConf `json:"lifecycle"`
+
+	// Quota limits the bucket's capacity and number of objects
+	Quota QuotaConf `json:"quota"`
+
+	// WriteBack defines whether the objects PUT into a Cloud bucket are
+	// uploaded to the Cloud synchronously (default) or in the background
+	WriteBack WriteBackConf `json:"write_back"`
+
+	// Encryption defines whether the objects of a local bucket are stored
+	// encrypted and with which key
+	Encryption EncryptionConf `json:"encryption"`
+
 	// unique bucket ID
 	BID uint64
 }
 
+// QuotaConf - per-bucket quotas; zero value means no limit. Each target enforces
+// its (equal) share of the quota as the objects are uniformly distributed
+// across targets; exceeding a hard quota fails the PUT, exceeding a soft one
+// is logged
+type QuotaConf struct {
+	SoftSize int64 `json:"soft_size"`    // bytes
+	HardSize int64 `json:"hard_size"`    // bytes
+	SoftObjs int64 `json:"soft_objects"` // number of objects
+	HardObjs int64 `json:"hard_objects"` // number of objects
+}
+
+// BucketUsage - the bucket's capacity usage (excluding replicas, older
+// versions and EC slices) and number of objects
+type BucketUsage struct {
+	Size int64 `json:"size"`
+	Objs int64 `json:"objects"`
+}
+
+func (c *QuotaConf) Enabled() bool {
+	return c.SoftSize > 0 || c.HardSize > 0 || c.SoftObjs > 0 || c.HardObjs > 0
+}
+
+// WriteBackConf - when enabled, PUT into a Cloud bucket is acknowledged as soon
+// as the object is stored (and, if configured, mirrored) locally; the target
+// then uploads the object to the Cloud in the background (see ais/tgtwriteback.go)
+type WriteBackConf struct {
+	Enabled bool `json:"enabled"`
+}
+
+// EncryptionConf - when enabled, the objects written into a local bucket are
+// encrypted at rest with the key KeyID that the targets get from the configured
+// key provider (see KeyProviderConf); each object records the ID of its key, so
+// that changing KeyID (key rotation) or disabling encryption applies to new
+// writes only
+type EncryptionConf struct {
+	Enabled bool   `json:"enabled"`
+	KeyID   string `json:"key_id"`
+}
+
 // ECConfig - per-bucket erasure coding configuration
 type ECConf struct {
-	ObjSizeLimit int64 `json:"objsize_limit"` // objects below this size are replicated instead of EC'ed
-	DataSlices   int   `json:"data_slices"`   // number of data slices
-	ParitySlices int   `json:"parity_slices"` // number of parity slices/replicas
-	Enabled      bool  `json:"enabled"`       // EC is enabled
+	ObjSizeLimit int64  `json:"objsize_limit"`          // objects below this size are replicated instead of EC'ed
+	DataSlices   int    `json:"data_slices"`            // number of data slices
+	ParitySlices int    `json:"parity_slices"`          // number of parity slices/replicas
+	Code         string `json:"code,omitempty"`         // erasure code: ECCodeRS (default) or ECCodeLRC
+	LocalGroups  int    `json:"local_groups,omitempty"` // LRC: number of local groups (and local parity slices)
+	Enabled      bool   `json:"enabled"`                // EC is enabled
+}
+
+// ECConf.Code enum
+const (
+	ECCodeRS  = "rs"  // Reed-Solomon
+	ECCodeLRC = "lrc" // Locally Repairable Code: Reed-Solomon plus a XOR parity slice per group of data slices
+)
+
+// LifecycleConf - per-bucket object lifecycle rules, periodically evaluated by
+// each target against the objects it stores (see lifecycle package)
+type LifecycleConf struct {
+	Rules []LifecycleRule `json:"rules,omitempty"`
+}
+
+// LifecycleRule applies the action to the objects (with names starting with
+// the prefix) that are older than the age; the age is counted from the last
+// access (atime) or modification (mtime)
+type LifecycleRule struct {
+	Name   string `json:"name,omitempty"`
+	Prefix string `json:"prefix,omitempty"`
+	AgeStr string `json:"age"`              // e.g. "168h"
+	AgeBy  string `json:"age_by,omitempty"` // LifecycleAgeByAtime (default) | LifecycleAgeByMtime
+	Action string `json:"action"`           // LifecycleDelete | LifecycleEvict | LifecycleTransition
+}
+
+// LifecycleRule enums
+const (
+	LifecycleDelete     = "delete"     // delete the object (from the Cloud as well, if Cloud bucket)
+	LifecycleEvict      = "evict"      // evict the object cached from the Cloud (Cloud buckets only)
+	LifecycleTransition = "transition" // move the object to the next tier (see BucketProps.NextTierURL)
+
+	LifecycleAgeByAtime = "atime"
+	LifecycleAgeByMtime = "mtime"
+
+	MaxLifecycleRules = 64
+)
+
+// Age returns the rule's age (the rule must be validated)
+func (r *LifecycleRule) Age() time.Duration {
+	d, _ := time.ParseDuration(r.AgeStr)
+	return d
 }
 
-func (c *ECConf) Updatable(field string) bool {
-	return (c.Enabled && !(field == HeaderBucketECData ||
-		field == HeaderBucketECParity ||
-		field == HeaderBucketECMinSize)) || !c.Enabled
+// Matches returns true if the rule applies to the object with the given name
+func (r *LifecycleRule) Matches(objname string) bool { return strings.HasPrefix(objname, r.Prefix) }
+
+func (c *LifecycleConf) Enabled() bool { return len(c.Rules) > 0 }
+
+func (c *LifecycleConf) HasAction(action string) bool {
+	for i := range c.Rules {
+		if c.Rules[i].Action == action {
+			return true
+		}
+	}
+	return false
 }
 
 func (c *ECConf) RequiredEncodeTargets() int {
@@ -918,10 +1196,33 @@ func (c *ECConf) RequiredRestoreTargets() int {
 	return c.DataSlices + 1
 }
 
+// Returns the number of slices (the object included) that can always be lost
+// at once: for LRC it is the number of global parity slices + 1
+func (c *ECConf) MaxLostSlices() int {
+	if c.Code == ECCodeLRC {
+		return c.ParitySlices - c.LocalGroups + 1
+	}
+	return c.ParitySlices
+}
+
+// Given the sizes of target failure domains, returns the number of slices (the
+// object included) that can be placed with no more than MaxLostSlices per
+// domain. Targets are taken from the domains round-robin (see
+// cluster.HrwTargetList), and so the object survives the loss of any one
+// domain if the returned number is at least RequiredEncodeTargets.
+func (c *ECConf) DomainSpread(domains []int) (placed int) {
+	lost := c.MaxLostSlices()
+	for _, size := range domains {
+		placed += Min(size, lost)
+	}
+	return
+}
+
 // ObjectProps
 type ObjectProps struct {
-	Size    int
-	Version string
+	Size     int
+	Version  string
+	CustomMD SimpleKVs
 }
 
 func DefaultBucketProps() *BucketProps {
@@ -964,9 +1265,13 @@ func (to *BucketProps) CopyFrom(from *BucketProps) {
 	to.Mirror = from.Mirror
 	to.EC = from.EC
 	to.Rebalance = from.Rebalance
+	to.Lifecycle = from.Lifecycle
+	to.Quota = from.Quota
+	to.WriteBack = from.WriteBack
+	to.Encryption = from.Encryption
 }
 
-func (bp *BucketProps) Validate(bckIsLocal bool, targetCnt int, urlOutsideCluster func(string) bool) error {
+func (bp *BucketProps) Validate(bckIsLocal bool, targetCnt int, domains []int, urlOutsideCluster func(string) bool) error {
 	if bp.NextTierURL != "" {
 		if _, err := url.ParseRequestURI(bp.NextTierURL); err != nil {
 			return fmt.Errorf("invalid next tier URL: %s, err: %v", bp.NextTierURL, err)
@@ -975,7 +1280,7 @@ func (bp *BucketProps) Validate(bckIsLocal bool, targetCnt int, urlOutsideCluste
 			return fmt.Errorf("invalid next tier URL: %s, URL is in current cluster", bp.NextTierURL)
 		}
 	}
-	if err := validateCloudProvider(bp.CloudProvider, bckIsLocal); err != nil {
+	if err := validateBucketCloudProvider(bp.CloudProvider, bckIsLocal); err != nil {
 		return err
 	}
 	if bp.ReadPolicy != "" && bp.ReadPolicy != RWPolicyCloud && bp.ReadPolicy != RWPolicyNextTier {
@@ -992,8 +1297,8 @@ func (bp *BucketProps) Validate(bckIsLocal bool, targetCnt int, urlOutsideCluste
 	}
 	if bp.NextTierURL != "" {
 		if bp.CloudProvider == "" {
-			return fmt.Errorf("tiered bucket must use one of the supported cloud providers (%s | %s | %s)",
-				ProviderAmazon, ProviderGoogle, ProviderAIS)
+			return fmt.Errorf("tiered bucket must use one of the supported cloud providers (%s | %s | %s | %s | %s | %s)",
+				ProviderAmazon, ProviderGoogle, ProviderAzure, ProviderHTTP, ProviderRemoteAIS, ProviderAIS)
 		}
 		if bp.ReadPolicy == "" {
 			bp.ReadPolicy = RWPolicyNextTier
@@ -1005,8 +1310,13 @@ func (bp *BucketProps) Validate(bckIsLocal bool, targetCnt int, urlOutsideCluste
 		}
 	}
 
-	validationArgs := &ValidationArgs{BckIsLocal: bckIsLocal, TargetCnt: targetCnt}
-	validators := []PropsValidator{&bp.Cksum, &bp.LRU, &bp.Mirror, &bp.EC}
+	if bp.NextTierURL == "" && bp.Lifecycle.HasAction(LifecycleTransition) {
+		return fmt.Errorf("lifecycle action %q requires next tier URL", LifecycleTransition)
+	}
+
+	validationArgs := &ValidationArgs{BckIsLocal: bckIsLocal, TargetCnt: targetCnt, Domains: domains}
+	validators := []PropsValidator{&bp.Cksum, &bp.LRU, &bp.Mirror, &bp.EC, &bp.Versioning, &bp.Lifecycle, &bp.Quota,
+		&bp.WriteBack, &bp.Encryption}
 	for _, validator := range validators {
 		if err := validator.ValidateAsProps(validationArgs); err != nil {
 			return err
@@ -1016,15 +1326,29 @@ func (bp *BucketProps) Validate(bckIsLocal bool, targetCnt int, urlOutsideCluste
 }
 
 func validateCloudProvider(provider string, bckIsLocal bool) error {
-	if provider != "" && provider != ProviderAmazon && provider != ProviderGoogle && provider != ProviderAIS {
-		return fmt.Errorf("invalid cloud provider: %s, must be one of (%s | %s | %s)", provider,
-			ProviderAmazon, ProviderGoogle, ProviderAIS)
+	if provider != "" && provider != ProviderAmazon && provider != ProviderGoogle && provider != ProviderAzure &&
+		provider != ProviderHTTP && provider != ProviderRemoteAIS && provider != ProviderAIS {
+		return fmt.Errorf("invalid cloud provider: %s, must be one of (%s | %s | %s | %s | %s | %s)", provider,
+			ProviderAmazon, ProviderGoogle, ProviderAzure, ProviderHTTP, ProviderRemoteAIS, ProviderAIS)
 	} else if bckIsLocal && provider != ProviderAIS && provider != "" {
 		return fmt.Errorf("local bucket can only have '%s' as the cloud provider", ProviderAIS)
 	}
 	return nil
 }
 
+func validateBucketCloudProvider(provider string, bckIsLocal bool) error {
+	if err := validateCloudProvider(provider, bckIsLocal); err != nil {
+		return err
+	}
+	if bckIsLocal || provider == "" || provider == ProviderAIS {
+		return nil
+	}
+	if cloudProvider := GCO.Get().CloudProvider; !StringInSlice(provider, ParseCloudProviders(cloudProvider)) {
+		return fmt.Errorf("cloud provider %s is not configured in the cluster (%q)", provider, cloudProvider)
+	}
+	return nil
+}
+
 func ReadXactionRequestMessage(actionMsg *ActionMsg) (*XactionExtMsg, error) {
 	xactMsg := &XactionExtMsg{}
 	xactMsgJSON, err := jsoniter.Marshal(actionMsg.Value)
@@ -9,6 +9,8 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
 	"reflect"
 	"strconv"
@@ -31,9 +33,12 @@ var (
 	// Translates the various query values for URLParamBckProvider for cluster use
 	bckProviderMap = map[string]string{
 		// Cloud values
-		CloudBs:        CloudBs,
-		ProviderAmazon: CloudBs,
-		ProviderGoogle: CloudBs,
+		CloudBs:           CloudBs,
+		ProviderAmazon:    CloudBs,
+		ProviderGoogle:    CloudBs,
+		ProviderAzure:     CloudBs,
+		ProviderHTTP:      CloudBs,
+		ProviderRemoteAIS: CloudBs,
 
 		// Local values
 		LocalBs:     LocalBs,
@@ -60,7 +65,18 @@ func BckProviderFromStr(provider string) (val string, err error) {
 	return
 }
 func IsValidCloudProvider(bckProvider, cloudProvider string) bool {
-	return bckProvider == cloudProvider || bckProvider == CloudBs
+	return bckProvider == CloudBs || StringInSlice(bckProvider, ParseCloudProviders(cloudProvider))
+}
+
+// ParseCloudProviders returns the Cloud providers listed in the comma-separated
+// config.CloudProvider; the first one is the default
+func ParseCloudProviders(cloudProvider string) (providers []string) {
+	for _, provider := range strings.Split(cloudProvider, ",") {
+		if provider = strings.TrimSpace(provider); provider != "" {
+			providers = append(providers, provider)
+		}
+	}
+	return
 }
 
 // $CONFDIR/*
@@ -88,6 +104,11 @@ const (
 	// EC
 	MinSliceCount = 1  // minimum number of data or parity slices
 	MaxSliceCount = 32 // maximum number of data or parity slices
+
+	// versioning
+	MaxObjVersions = 1000 // maximum number of older versions retained per object
+
+	DefaultLifecycleTime = time.Hour // see PeriodConf.LifecycleTime
 )
 
 const (
@@ -108,7 +129,8 @@ const (
 type (
 	ValidationArgs struct {
 		BckIsLocal bool
-		TargetCnt  int // for EC
+		TargetCnt  int   // for EC
+		Domains    []int // ditto: sizes of target failure domains; nil if targets have no failure domains
 	}
 
 	Validator interface {
@@ -137,11 +159,21 @@ var (
 	_ Validator = &NetConf{}
 	_ Validator = &DownloaderConf{}
 	_ Validator = &DSortConf{}
+	_ Validator = &HTTPOriginConf{}
+	_ Validator = &RemoteAISConf{}
+	_ Validator = &CloudReqConf{}
+	_ Validator = &ProxyConf{}
+	_ Validator = &KeyProviderConf{}
 
 	_ PropsValidator = &CksumConf{}
 	_ PropsValidator = &LRUConf{}
 	_ PropsValidator = &MirrorConf{}
 	_ PropsValidator = &ECConf{}
+	_ PropsValidator = &VersionConf{}
+	_ PropsValidator = &LifecycleConf{}
+	_ PropsValidator = &QuotaConf{}
+	_ PropsValidator = &WriteBackConf{}
+	_ PropsValidator = &EncryptionConf{}
 
 	// Debugging
 	pkgDebug = make(map[string]glog.Level)
@@ -296,7 +328,7 @@ func (gco *globalConfigOwner) Subscribe(cl ConfigListener) {
 //
 type Config struct {
 	Confdir          string          `json:"confdir"`
-	CloudProvider    string          `json:"cloudprovider"`
+	CloudProvider    string          `json:"cloudprovider"` // comma-separated, the first is the default
 	Mirror           MirrorConf      `json:"mirror"`
 	Readahead        RahConf         `json:"readahead"`
 	Log              LogConf         `json:"log"`
@@ -317,6 +349,10 @@ type Config struct {
 	KeepaliveTracker KeepaliveConf   `json:"keepalivetracker"`
 	Downloader       DownloaderConf  `json:"downloader"`
 	DSort            DSortConf       `json:"distributed_sort"`
+	HTTPOrigin       HTTPOriginConf  `json:"http_origin"`
+	RemoteAIS        RemoteAISConf   `json:"remote_ais"`
+	CloudReq         CloudReqConf    `json:"cloud_requests"`
+	KeyProvider      KeyProviderConf `json:"key_provider"`
 }
 
 type MirrorConf struct {
@@ -345,9 +381,11 @@ type LogConf struct {
 type PeriodConf struct {
 	StatsTimeStr     string `json:"stats_time"`
 	RetrySyncTimeStr string `json:"retry_sync_time"`
+	LifecycleTimeStr string `json:"lifecycle_time"` // how often to evaluate bucket lifecycle rules
 	// omitempty
 	StatsTime     time.Duration `json:"-"`
 	RetrySyncTime time.Duration `json:"-"`
+	LifecycleTime time.Duration `json:"-"`
 }
 
 // timeoutconfig contains timeouts used for intra-cluster communication
@@ -375,6 +413,10 @@ type ProxyConf struct {
 	PrimaryURL   string `json:"primary_url"`
 	OriginalURL  string `json:"original_url"`
 	DiscoveryURL string `json:"discovery_url"`
+
+	// ListCacheTTL: how long a proxy caches Cloud bucket listings (0 - no caching)
+	ListCacheTTLStr string        `json:"list_cache_ttl"`
+	ListCacheTTL    time.Duration `json:"-"`
 }
 
 type LRUConf struct {
@@ -461,6 +503,8 @@ type VersionConf struct {
 	Type            string `json:"type"`              // inherited/owned
 	Enabled         bool   `json:"enabled"`           // defined by the Versioning; can be redefined on a bucket level
 	ValidateWarmGet bool   `json:"validate_warm_get"` // validate object version upon warm GET
+	MaxVersions     int    `json:"max_versions"`      // number of older versions to retain (local buckets only); 0 - none
+	RetentionStr    string `json:"retention"`         // how long to retain older versions once superseded; "" - no limit
 }
 
 type TestfspathConf struct {
@@ -537,6 +581,40 @@ type DSortConf struct {
 	MissingShards     string `json:"missing_shards"`
 }
 
+// HTTPOriginConf maps Cloud bucket names to the base URLs of the HTTP(S) servers
+// the buckets are read from (cloud provider "http")
+type HTTPOriginConf struct {
+	Buckets SimpleKVs `json:"buckets"`
+}
+
+// RemoteAISConf defines another AIStore cluster used as the Cloud (cloud provider "remote_ais")
+type RemoteAISConf struct {
+	URL string `json:"url"` // URL of the remote cluster's primary proxy (or any other proxy)
+}
+
+// CloudReqConf limits the rate of requests to the Cloud (per provider and bucket)
+// and defines how the requests the Cloud fails or throttles get retried. The
+// limits apply to each target separately: the cluster as a whole sends up to
+// (number of targets) * RateLimit requests per second to a given bucket.
+type CloudReqConf struct {
+	RateLimit     float64                  `json:"rate_limit"`          // requests per second (0: unlimited)
+	Burst         int                      `json:"burst"`               // max requests at once (0: the rate limit, rounded up)
+	MaxRetries    int                      `json:"max_retries"`         // 0: no retries
+	BackoffStr    string                   `json:"backoff"`             // initial retry delay, doubled with every retry
+	Backoff       time.Duration            `json:"-"`                   // omitempty
+	MaxBackoffStr string                   `json:"max_backoff"`         // max retry delay
+	MaxBackoff    time.Duration            `json:"-"`                   // omitempty
+	RetryOnStatus []int                    `json:"retry_on_status"`     // HTTP status codes to retry, e.g. 429 and 503 (SlowDown)
+	Providers     map[string]*CloudReqConf `json:"providers,omitempty"` // by Cloud provider (e.g. "aws"): replaces all of the above
+}
+
+// KeyProviderConf defines where the targets get the keys to encrypt the objects
+// in the buckets with encryption enabled (see EncryptionConf)
+type KeyProviderConf struct {
+	Provider string `json:"provider"` // "" (none) | "file" | other registered provider
+	KeyFile  string `json:"key_file"` // provider "file": JSON file with base64-encoded keys by key ID
+}
+
 func SetLogLevel(config *Config, loglevel string) (err error) {
 	v := flag.Lookup("v").Value
 	if v == nil {
@@ -633,13 +711,25 @@ func (c *Config) Validate() error {
 	validators := []Validator{
 		&c.Disk, &c.LRU, &c.Mirror, &c.Cksum,
 		&c.Timeout, &c.Periodic, &c.Rebalance, &c.KeepaliveTracker, &c.Net, &c.Ver,
-		&c.Downloader,
+		&c.Downloader, &c.HTTPOrigin, &c.RemoteAIS, &c.CloudReq, &c.Proxy, &c.KeyProvider,
 	}
 	for _, validator := range validators {
 		if err := validator.Validate(); err != nil {
 			return err
 		}
 	}
+	providers := ParseCloudProviders(c.CloudProvider)
+	for i, provider := range providers {
+		if provider == ProviderAIS {
+			return fmt.Errorf("invalid cloudprovider: %q is not a Cloud provider", provider)
+		}
+		if err := validateCloudProvider(provider, false); err != nil {
+			return err
+		}
+		if StringInSlice(provider, providers[:i]) {
+			return fmt.Errorf("invalid cloudprovider: duplicate %q", provider)
+		}
+	}
 	return nil
 }
 
@@ -732,9 +822,35 @@ func (c *VersionConf) Validate() error {
 	if c.ValidateWarmGet && !c.Enabled {
 		return errors.New("validate-warm-get requires versioning to be enabled")
 	}
+	if c.MaxVersions < 0 || c.MaxVersions > MaxObjVersions {
+		return fmt.Errorf("bad version.max_versions: %d (expected value in range [0, %d])", c.MaxVersions, MaxObjVersions)
+	}
+	if c.RetentionStr != "" {
+		if d, err := time.ParseDuration(c.RetentionStr); err != nil || d <= 0 {
+			return fmt.Errorf("bad version.retention format %s (expected positive duration)", c.RetentionStr)
+		}
+	}
 	return nil
 }
-func (c *VersionConf) ValidateAsProps() error { return c.Validate() }
+
+func (c *VersionConf) ValidateAsProps(args *ValidationArgs) error {
+	if c.Type == PropInherit {
+		return nil
+	}
+	return c.Validate()
+}
+
+// Retention returns how long older versions are retained (0 - no limit)
+func (c *VersionConf) Retention() time.Duration {
+	if c.RetentionStr == "" {
+		return 0
+	}
+	d, _ := time.ParseDuration(c.RetentionStr) // validated
+	return d
+}
+
+// KeepsHistory returns true if older versions of the objects are to be retained
+func (c *VersionConf) KeepsHistory() bool { return c.Enabled && c.MaxVersions > 0 }
 
 func (c *MirrorConf) Validate() error {
 	if c.UtilThresh < 0 || c.UtilThresh > 100 {
@@ -767,6 +883,23 @@ func (c *ECConf) Validate() error {
 	if c.ParitySlices < MinSliceCount || c.ParitySlices > MaxSliceCount {
 		return fmt.Errorf("bad ec.parity_slices: %d (expected value in range [%d, %d])", c.ParitySlices, MinSliceCount, MaxSliceCount)
 	}
+	switch c.Code {
+	case "", ECCodeRS:
+		if c.LocalGroups != 0 {
+			return fmt.Errorf("bad ec.local_groups: %d (local groups require %q code)", c.LocalGroups, ECCodeLRC)
+		}
+	case ECCodeLRC:
+		if c.LocalGroups < 1 || c.LocalGroups > c.DataSlices {
+			return fmt.Errorf("bad ec.local_groups: %d (expected value in range [1, %d])", c.LocalGroups, c.DataSlices)
+		}
+		// local parity slices are counted in ec.parity_slices, and at least one must be global
+		if c.ParitySlices <= c.LocalGroups {
+			return fmt.Errorf("bad ec.parity_slices: %d (%q code with %d local groups requires at least %d)",
+				c.ParitySlices, ECCodeLRC, c.LocalGroups, c.LocalGroups+1)
+		}
+	default:
+		return fmt.Errorf("bad ec.code: %q (expected %q or %q)", c.Code, ECCodeRS, ECCodeLRC)
+	}
 	return nil
 }
 
@@ -787,6 +920,79 @@ func (c *ECConf) ValidateAsProps(args *ValidationArgs) error {
 			required, c.DataSlices, c.ParitySlices, args.TargetCnt)
 
 	}
+	if len(args.Domains) == 0 {
+		return nil
+	}
+	if placed, required := c.DomainSpread(args.Domains), c.RequiredEncodeTargets(); placed < required {
+		return fmt.Errorf(
+			"erasure coding with %d data and %d parity slices cannot survive a failure domain loss: "+
+				"only %d of %d slices fit with no more than %d per domain (failure domain sizes: %v)",
+			c.DataSlices, c.ParitySlices, placed, required, c.MaxLostSlices(), args.Domains)
+	}
+	return nil
+}
+
+func (c *LifecycleConf) ValidateAsProps(args *ValidationArgs) error {
+	if len(c.Rules) > MaxLifecycleRules {
+		return fmt.Errorf("too many lifecycle rules: %d (max %d)", len(c.Rules), MaxLifecycleRules)
+	}
+	for i := range c.Rules {
+		rule := &c.Rules[i]
+		if d, err := time.ParseDuration(rule.AgeStr); err != nil || d <= 0 {
+			return fmt.Errorf("lifecycle rule #%d: bad age %q (expected positive duration)", i, rule.AgeStr)
+		}
+		switch rule.AgeBy {
+		case "":
+			rule.AgeBy = LifecycleAgeByAtime
+		case LifecycleAgeByAtime, LifecycleAgeByMtime:
+		default:
+			return fmt.Errorf("lifecycle rule #%d: invalid age_by %q (expected one of: %s, %s)",
+				i, rule.AgeBy, LifecycleAgeByAtime, LifecycleAgeByMtime)
+		}
+		switch rule.Action {
+		case LifecycleDelete, LifecycleTransition:
+		case LifecycleEvict:
+			if args.BckIsLocal {
+				return fmt.Errorf("lifecycle rule #%d: action %q is not supported for local buckets", i, rule.Action)
+			}
+		default:
+			return fmt.Errorf("lifecycle rule #%d: invalid action %q (expected one of: %s, %s, %s)",
+				i, rule.Action, LifecycleDelete, LifecycleEvict, LifecycleTransition)
+		}
+	}
+	return nil
+}
+
+func (c *QuotaConf) ValidateAsProps(args *ValidationArgs) error {
+	if c.SoftSize < 0 || c.HardSize < 0 || c.SoftObjs < 0 || c.HardObjs < 0 {
+		return fmt.Errorf("invalid quota %+v (expected non-negative values)", *c)
+	}
+	if c.HardSize > 0 && c.SoftSize > c.HardSize {
+		return fmt.Errorf("soft quota on capacity (%d) exceeds hard quota (%d)", c.SoftSize, c.HardSize)
+	}
+	if c.HardObjs > 0 && c.SoftObjs > c.HardObjs {
+		return fmt.Errorf("soft quota on number of objects (%d) exceeds hard quota (%d)", c.SoftObjs, c.HardObjs)
+	}
+	return nil
+}
+
+func (c *WriteBackConf) ValidateAsProps(args *ValidationArgs) error {
+	if c.Enabled && args.BckIsLocal {
+		return errors.New("write-back applies to Cloud buckets only")
+	}
+	return nil
+}
+
+func (c *EncryptionConf) ValidateAsProps(args *ValidationArgs) error {
+	if !c.Enabled {
+		return nil
+	}
+	if !args.BckIsLocal {
+		return errors.New("encryption applies to local buckets only")
+	}
+	if c.KeyID == "" {
+		return errors.New("encryption requires key ID")
+	}
 	return nil
 }
 
@@ -832,6 +1038,12 @@ func (c *PeriodConf) Validate() (err error) {
 	if c.RetrySyncTime, err = time.ParseDuration(c.RetrySyncTimeStr); err != nil {
 		return fmt.Errorf("bad periodic.retry_sync_time format %s, err %v", c.RetrySyncTimeStr, err)
 	}
+	if c.LifecycleTimeStr == "" {
+		c.LifecycleTimeStr = DefaultLifecycleTime.String()
+	}
+	if c.LifecycleTime, err = time.ParseDuration(c.LifecycleTimeStr); err != nil {
+		return fmt.Errorf("bad periodic.lifecycle_time format %s, err %v", c.LifecycleTimeStr, err)
+	}
 	return nil
 }
 
@@ -913,6 +1125,97 @@ func (c *DownloaderConf) Validate() (err error) {
 	return nil
 }
 
+func (c *HTTPOriginConf) Validate() (err error) {
+	for bucket, baseURL := range c.Buckets {
+		u, err := url.Parse(baseURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("bad http_origin.buckets[%s] URL %q (expecting http(s)://host[/path])", bucket, baseURL)
+		}
+	}
+	return nil
+}
+
+func (c *RemoteAISConf) Validate() (err error) {
+	if c.URL == "" {
+		return nil
+	}
+	u, err := url.Parse(c.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("bad remote_ais.url %q (expecting http(s)://host:port)", c.URL)
+	}
+	return nil
+}
+
+func (c *CloudReqConf) Validate() (err error) {
+	if err = c.validate(); err != nil {
+		return
+	}
+	for provider, pc := range c.Providers {
+		if provider == CloudBs || bckProviderMap[provider] != CloudBs || pc == nil {
+			return fmt.Errorf("bad cloud_requests.providers: invalid Cloud provider %q", provider)
+		}
+		if len(pc.Providers) > 0 {
+			return fmt.Errorf("bad cloud_requests.providers.%s: providers cannot be nested", provider)
+		}
+		if err = pc.validate(); err != nil {
+			return fmt.Errorf("cloud_requests.providers.%s: %v", provider, err)
+		}
+	}
+	return nil
+}
+
+// returns the configuration of the requests to the given Cloud provider
+func (c *CloudReqConf) Provider(provider string) *CloudReqConf {
+	if pc, ok := c.Providers[provider]; ok {
+		return pc
+	}
+	return c
+}
+
+func (c *CloudReqConf) validate() (err error) {
+	if c.RateLimit < 0 || c.Burst < 0 || c.MaxRetries < 0 {
+		return fmt.Errorf("bad cloud_requests config: rate_limit %v, burst %d, max_retries %d (expecting non-negative)",
+			c.RateLimit, c.Burst, c.MaxRetries)
+	}
+	if c.BackoffStr == "" {
+		c.BackoffStr = "1s"
+	}
+	if c.MaxBackoffStr == "" {
+		c.MaxBackoffStr = "1m"
+	}
+	if c.Backoff, err = time.ParseDuration(c.BackoffStr); err != nil || c.Backoff <= 0 {
+		return fmt.Errorf("bad cloud_requests.backoff %s", c.BackoffStr)
+	}
+	if c.MaxBackoff, err = time.ParseDuration(c.MaxBackoffStr); err != nil || c.MaxBackoff < c.Backoff {
+		return fmt.Errorf("bad cloud_requests.max_backoff %s (expecting duration >= backoff %s)",
+			c.MaxBackoffStr, c.BackoffStr)
+	}
+	for _, status := range c.RetryOnStatus {
+		if status < http.StatusBadRequest || status > 599 {
+			return fmt.Errorf("bad cloud_requests.retry_on_status %d (expecting 4xx or 5xx)", status)
+		}
+	}
+	return nil
+}
+
+func (c *ProxyConf) Validate() (err error) {
+	if c.ListCacheTTLStr == "" {
+		c.ListCacheTTL = 0
+		return nil
+	}
+	if c.ListCacheTTL, err = time.ParseDuration(c.ListCacheTTLStr); err != nil || c.ListCacheTTL < 0 {
+		return fmt.Errorf("bad proxy.list_cache_ttl %s", c.ListCacheTTLStr)
+	}
+	return nil
+}
+
+func (c *KeyProviderConf) Validate() (err error) {
+	if c.Provider == KeyProviderFile && c.KeyFile == "" {
+		return fmt.Errorf("key_provider %q requires key_file", c.Provider)
+	}
+	return nil
+}
+
 func (c *DSortConf) Validate() (err error) {
 	if !StringInSlice(c.DuplicatedRecords, SupportedReactions) {
 		return fmt.Errorf("bad c.duplicated_records: %s (expecting one of: %s)", c.DuplicatedRecords, SupportedReactions)
@@ -980,6 +1283,8 @@ func (conf *Config) update(key, value string) (Validator, error) {
 	// PERIODIC
 	case "stats_time", "periodic.stats_time":
 		return &conf.Periodic, updateValue(&conf.Periodic.StatsTimeStr)
+	case "periodic.lifecycle_time":
+		return &conf.Periodic, updateValue(&conf.Periodic.LifecycleTimeStr)
 
 	// LRU
 	case "lru_enabled", "lru.enabled":
@@ -1040,6 +1345,10 @@ func (conf *Config) update(key, value string) (Validator, error) {
 		return nil, updateValue(&conf.Ver.Enabled)
 	case "validate_version_warm_get", "version.validate_warm_get":
 		return nil, updateValue(&conf.Ver.ValidateWarmGet)
+	case "version.max_versions":
+		return &conf.Ver, updateValue(&conf.Ver.MaxVersions)
+	case "version.retention":
+		return &conf.Ver, updateValue(&conf.Ver.RetentionStr)
 
 	// FSHC
 	case "fshc_enabled", "fshc.enabled":
@@ -1063,6 +1372,22 @@ func (conf *Config) update(key, value string) (Validator, error) {
 	case "keepalivetracker.target.factor":
 		return &conf.KeepaliveTracker, updateValue(&conf.KeepaliveTracker.Target.Factor)
 
+	// CLOUD REQUESTS
+	case "cloud_requests.rate_limit":
+		return &conf.CloudReq, updateValue(&conf.CloudReq.RateLimit)
+	case "cloud_requests.burst":
+		return &conf.CloudReq, updateValue(&conf.CloudReq.Burst)
+	case "cloud_requests.max_retries":
+		return &conf.CloudReq, updateValue(&conf.CloudReq.MaxRetries)
+	case "cloud_requests.backoff":
+		return &conf.CloudReq, updateValue(&conf.CloudReq.BackoffStr)
+	case "cloud_requests.max_backoff":
+		return &conf.CloudReq, updateValue(&conf.CloudReq.MaxBackoffStr)
+
+	// PROXY
+	case "proxy.list_cache_ttl":
+		return &conf.Proxy, updateValue(&conf.Proxy.ListCacheTTLStr)
+
 	// DISTRIBUTED SORT
 	case "distributed_sort.duplicated_records":
 		return &conf.DSort, updateValue(&conf.DSort.DuplicatedRecords)
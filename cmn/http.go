@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html"
 	"io"
@@ -236,3 +237,50 @@ func ReqWithContext(method, url string, body []byte) (*http.Request, context.Con
 	req = req.WithContext(ctx)
 	return req, ctx, cancel, nil
 }
+
+// CustomMDFromHeader returns user-defined object metadata carried in the
+// HeaderObjCustomPrefix-prefixed headers, or nil if there is none.
+// Keys are case-insensitive (as per HTTP) and are returned in lower case.
+func CustomMDFromHeader(hdr http.Header) (md SimpleKVs, err error) {
+	prefix := strings.ToLower(HeaderObjCustomPrefix)
+	for k, v := range hdr {
+		lk := strings.ToLower(k)
+		if !strings.HasPrefix(lk, prefix) || len(v) == 0 {
+			continue
+		}
+		if md == nil {
+			md = make(SimpleKVs, 4)
+		}
+		md[lk[len(prefix):]] = v[0]
+	}
+	if err = ValidateCustomMD(md); err != nil {
+		md = nil
+	}
+	return
+}
+
+// CustomMDToHeader adds user-defined object metadata to the header
+func CustomMDToHeader(md SimpleKVs, hdr http.Header) {
+	for k, v := range md {
+		hdr.Set(HeaderObjCustomPrefix+k, v)
+	}
+}
+
+// ValidateCustomMD checks that the keys are non-empty and that the user-defined
+// metadata does not exceed MaxCustomMDKeys and MaxCustomMDSize
+func ValidateCustomMD(md SimpleKVs) error {
+	var size int
+	if len(md) > MaxCustomMDKeys {
+		return fmt.Errorf("custom metadata: number of keys %d exceeds the maximum %d", len(md), MaxCustomMDKeys)
+	}
+	for k, v := range md {
+		if k == "" {
+			return errors.New("custom metadata: empty key")
+		}
+		size += len(k) + len(v)
+	}
+	if size > MaxCustomMDSize {
+		return fmt.Errorf("custom metadata size %d exceeds the maximum %d", size, MaxCustomMDSize)
+	}
+	return nil
+}
@@ -5,6 +5,8 @@
 package cmn
 
 import (
+	"net/http"
+	"strings"
 	"testing"
 )
 
@@ -61,3 +63,28 @@ func TestMatchRESTItemsTooShort(t *testing.T) {
 		t.Errorf("expected error, apiItems returned: %v", apiItems)
 	}
 }
+
+func TestCustomMDFromHeader(t *testing.T) {
+	hdr := http.Header{}
+	hdr.Set(HeaderObjCustomPrefix+"Color", "blue")
+	hdr.Set("objmeta-owner", "alice")
+	hdr.Set(HeaderObjCksumType, ChecksumXXHash)
+	md, err := CustomMDFromHeader(hdr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(md) != 2 || md["color"] != "blue" || md["owner"] != "alice" {
+		t.Errorf("unexpected custom metadata: %v", md)
+	}
+
+	out := http.Header{}
+	CustomMDToHeader(md, out)
+	if out.Get(HeaderObjCustomPrefix+"color") != "blue" {
+		t.Errorf("unexpected header: %v", out)
+	}
+
+	hdr.Set(HeaderObjCustomPrefix+"Big", strings.Repeat("x", MaxCustomMDSize))
+	if _, err := CustomMDFromHeader(hdr); err == nil {
+		t.Error("expected error on oversized custom metadata")
+	}
+}
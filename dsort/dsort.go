@@ -14,7 +14,6 @@ import (
 	"io"
 	"math"
 	"net/http"
-	"os"
 	"runtime"
 	"runtime/debug"
 	"sort"
@@ -190,7 +189,7 @@ ExtractAllShards:
 				}
 
 				m.ctx.nameLocker.Lock(lom.Uname(), false)
-				f, err := os.Open(lom.FQN)
+				f, err := lom.Open(lom.FQN)
 				if err != nil {
 					m.releaseExtractSema()
 					m.ctx.nameLocker.Unlock(lom.Uname(), false)
@@ -355,17 +354,12 @@ func (m *Manager) createShard(s *extract.Shard) (err error) {
 		m.ctx.nameLocker.Lock(lom.Uname(), false)
 		defer m.ctx.nameLocker.Unlock(lom.Uname(), false)
 
-		file, err := cmn.NewFileHandle(lom.FQN)
+		file, err := lom.Open(lom.FQN)
 		if err != nil {
 			return err
 		}
 
-		stat, err := file.Stat()
-		if err != nil {
-			return err
-		}
-
-		if stat.Size() <= 0 {
+		if lom.Size() <= 0 {
 			goto exit
 		}
 
@@ -375,7 +369,7 @@ func (m *Manager) createShard(s *extract.Shard) (err error) {
 			Objname: shardName,
 			IsLocal: bckProvider == cmn.LocalBs,
 			ObjAttrs: transport.ObjectAttrs{
-				Size:       stat.Size(),
+				Size:       lom.Size(),
 				CksumType:  cksumType,
 				CksumValue: cksumValue,
 			},
@@ -0,0 +1,124 @@
+// Package extract provides provides functions for working with compressed files
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package extract
+
+import (
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/NVIDIA/aistore/cmn"
+)
+
+// supported archive extensions (same as dsort's)
+const (
+	ExtTar    = ".tar"
+	ExtTgz    = ".tgz"
+	ExtTarTgz = ".tar.gz"
+	ExtZip    = ".zip"
+)
+
+var (
+	_ RecordExtractor = &memberExtractor{}
+
+	ErrMemberNotFound     = errors.New("archive member not found")
+	ErrUnsupportedArchive = errors.New("unsupported archive format")
+
+	errMemberFound = errors.New("archive member found") // stops the traversal
+)
+
+type (
+	// MemberLoc is the location of the member's content within an uncompressed archive
+	MemberLoc struct {
+		Offset int64 `json:"offset"`
+		Size   int64 `json:"size"`
+	}
+
+	// MemberIndex maps names of the archive members to their locations
+	MemberIndex map[string]MemberLoc
+
+	// memberExtractor looks for a single member and, when the archive is
+	// uncompressed, indexes all the members on the way
+	memberExtractor struct {
+		name    string
+		w       io.Writer
+		sr      *io.SectionReader // nil when offsets cannot be indexed
+		index   MemberIndex
+		written int64
+		found   bool
+	}
+)
+
+// ArchiveExt returns the (supported) archive extension of the object or
+// empty string if the object does not look like an archive
+func ArchiveExt(objname string) string {
+	for _, ext := range []string{ExtTarTgz, ExtTar, ExtTgz, ExtZip} {
+		if strings.HasSuffix(objname, ext) {
+			return ext
+		}
+	}
+	return ""
+}
+
+// ReadMember finds the named member in the archive and writes its content into
+// the writer. The format of the archive is determined by its extension (see
+// ArchiveExt). For uncompressed tarballs ReadMember also returns the index of
+// all the archive's members, so that subsequent reads could go directly to the
+// member's location.
+func ReadMember(sr *io.SectionReader, ext, name string, w io.Writer) (n int64, index MemberIndex, err error) {
+	var (
+		ec ExtractCreator
+		me = &memberExtractor{name: name, w: w}
+	)
+	switch ext {
+	case ExtTar:
+		ec = NewTarExtractCreator(false)
+		me.sr, me.index = sr, make(MemberIndex)
+	case ExtTgz, ExtTarTgz:
+		ec = NewTarExtractCreator(true)
+	case ExtZip:
+		ec = NewZipExtractCreator()
+	default:
+		return 0, nil, ErrUnsupportedArchive
+	}
+	if _, err = sr.Seek(0, io.SeekStart); err != nil {
+		return
+	}
+	if _, _, err = ec.ExtractShard("", sr, me, false); err != nil && err != errMemberFound {
+		return me.written, nil, err
+	}
+	if !me.found {
+		return 0, me.index, ErrMemberNotFound
+	}
+	return me.written, me.index, nil
+}
+
+func (me *memberExtractor) ExtractRecord(fqn, name string, r cmn.ReadSizer, metadata []byte, toDisk bool) (int64, error) {
+	return me.ExtractRecordWithBuffer(fqn, name, r, metadata, toDisk, nil)
+}
+
+func (me *memberExtractor) ExtractRecordWithBuffer(_, name string, r cmn.ReadSizer, _ []byte, _ bool,
+	buf []byte) (n int64, err error) {
+	if me.sr != nil {
+		// the tar reader has just consumed the header: current offset is where the content starts
+		if _, ok := me.index[name]; !ok { // duplicates: the first one wins, same as below
+			offset, _ := me.sr.Seek(0, io.SeekCurrent)
+			me.index[name] = MemberLoc{Offset: offset, Size: r.Size()}
+		}
+	}
+	if me.found || name != me.name {
+		return 0, nil // unread content gets skipped
+	}
+	if buf == nil {
+		n, err = io.Copy(me.w, r)
+	} else {
+		n, err = io.CopyBuffer(me.w, r, buf)
+	}
+	me.found, me.written = true, n
+	if err == nil && me.sr == nil {
+		err = errMemberFound // nothing to index - stop right away
+	}
+	return
+}
@@ -0,0 +1,115 @@
+// Package extract provides provides functions for working with compressed files
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package extract
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ReadMember", func() {
+	var members = []struct {
+		name    string
+		content []byte
+	}{
+		{"a/1.txt", []byte("first")},
+		{"b/2.bin", bytes.Repeat([]byte{0xab}, 3000)},
+		{"c", []byte("last")},
+	}
+
+	createTar := func(gzipped bool) []byte {
+		var (
+			buf = &bytes.Buffer{}
+			w   io.Writer
+			gzw *gzip.Writer
+		)
+		w = buf
+		if gzipped {
+			gzw = gzip.NewWriter(buf)
+			w = gzw
+		}
+		tw := tar.NewWriter(w)
+		for _, m := range members {
+			Expect(tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: m.name, Size: int64(len(m.content)), Mode: 0644})).NotTo(HaveOccurred())
+			_, err := tw.Write(m.content)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(tw.Close()).NotTo(HaveOccurred())
+		if gzw != nil {
+			Expect(gzw.Close()).NotTo(HaveOccurred())
+		}
+		return buf.Bytes()
+	}
+
+	createZip := func() []byte {
+		buf := &bytes.Buffer{}
+		zw := zip.NewWriter(buf)
+		for _, m := range members {
+			w, err := zw.Create(m.name)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = w.Write(m.content)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(zw.Close()).NotTo(HaveOccurred())
+		return buf.Bytes()
+	}
+
+	readAll := func(archive []byte, ext string, indexed bool) {
+		sr := io.NewSectionReader(bytes.NewReader(archive), 0, int64(len(archive)))
+		for _, m := range members {
+			buf := &bytes.Buffer{}
+			n, index, err := ReadMember(sr, ext, m.name, buf)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(n).To(BeEquivalentTo(len(m.content)))
+			Expect(buf.Bytes()).To(Equal(m.content))
+			if !indexed {
+				Expect(index).To(BeNil())
+				continue
+			}
+			Expect(index).To(HaveLen(len(members)))
+			for _, m := range members {
+				loc, ok := index[m.name]
+				Expect(ok).To(BeTrue())
+				b, err := ioutil.ReadAll(io.NewSectionReader(sr, loc.Offset, loc.Size))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(b).To(Equal(m.content))
+			}
+		}
+		_, _, err := ReadMember(sr, ext, "does-not-exist", ioutil.Discard)
+		Expect(err).To(Equal(ErrMemberNotFound))
+	}
+
+	It("should read members of a tarball and index them", func() {
+		readAll(createTar(false), ExtTar, true)
+	})
+
+	It("should read members of a compressed tarball", func() {
+		readAll(createTar(true), ExtTgz, false)
+	})
+
+	It("should read members of a zip archive", func() {
+		readAll(createZip(), ExtZip, false)
+	})
+
+	It("should detect archive extensions", func() {
+		Expect(ArchiveExt("shard.tar")).To(Equal(ExtTar))
+		Expect(ArchiveExt("dir/shard.tar.gz")).To(Equal(ExtTarTgz))
+		Expect(ArchiveExt("shard.tgz")).To(Equal(ExtTgz))
+		Expect(ArchiveExt("shard.zip")).To(Equal(ExtZip))
+		Expect(ArchiveExt("shard.bin")).To(BeEmpty())
+	})
+
+	It("should fail on unsupported archive", func() {
+		_, _, err := ReadMember(io.NewSectionReader(bytes.NewReader(nil), 0, 0), ".rar", "a", ioutil.Discard)
+		Expect(err).To(Equal(ErrUnsupportedArchive))
+	})
+})
@@ -6,7 +6,9 @@ package ec
 
 import (
 	"errors"
+	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"time"
 
@@ -26,6 +28,8 @@ import (
 //		DataSlices: [2-32]    # the number of data slices
 //		ParitySlices: [2-32]  # the number of parity slices
 //		ObjSizeLimit: 0       # replication versus erasure coding
+//		Code: rs|lrc          # erasure code, Reed-Solomon by default
+//		LocalGroups: [1-32]   # LRC only: the number of local groups
 //
 // NOTE: replicating small object is cheaper than erasure encoding.
 // The ObjSizeLimit option sets the corresponding threshold. Set it to the
@@ -39,7 +43,8 @@ import (
 //
 // NOTE: All slices and replicas must be on the different targets. The target
 // list is calculated by HrwTargetList. The first target in the list is the
-// "main" target that keeps the full object, the others keep only slices/replicas
+// "main" target that keeps the full object, the others keep only slices/replicas.
+// If targets have failure domains, HrwTargetList spreads the list across them
 //
 // NOTE: All slices must be of the same size. So, the last slice can be padded
 // with zeros. In most cases, padding results in the total size of data
@@ -59,12 +64,16 @@ import (
 //		size - size of the original object (required for correct restoration)
 //		data - the number of data slices (unused if the object was replicated)
 //		parity - the number of parity slices
+//		code - the erasure code the object was encoded with (see Erasure codes)
+//		local_groups - LRC only: the number of local groups
 //		copy - whether the object was replicated or erasure encoded
 //		chk - original object checksum (used to choose the correct slices when
 //			restoring the object, sort of versioning)
 //		sliceid - used if the object was encoded, the ordinal number of slice
 //			starting from 1 (0 means 'full copy' - either orignal object or
 //			its replica)
+//		daemons - the targets the object was encoded for: the main target
+//			followed by the targets that received slices/replicas
 //
 //
 // How protection works.
@@ -105,6 +114,59 @@ import (
 // NOTE: the slices are stored on targets in random order, except the first
 //	     PUT when the main target stores the slices in the order of HrwTargetList
 //		 algorithm returns.
+//
+// Scrub:
+// 1. Scrub is a per-bucket xaction (see XactScrub) that runs on every target
+//	  and walks local metafiles. A target checks only the objects it is the
+//	  main target for - the other metafiles belong to slices and replicas.
+// 2. The main target validates the checksum of the main object. Corrupted
+//	  object is restored from slices/replicas just like a missing one on GET.
+// 3. Otherwise, the main target requests metadata and then all slices/replicas
+//	  from the targets, and validates the checksums of the received data.
+// 4. If any slice or replica is missing, obsolete, or corrupted, the main
+//	  target encodes the object anew - and resends all its slices/replicas.
+// 5. The checks are done under the object's read lock. Restoring or encoding
+//	  takes the exclusive one - provided the object has not changed meanwhile.
+//
+// Bucket encoding:
+// 1. Enabling EC protects only the objects PUT afterwards. To protect the
+//	  existing ones, run the per-bucket encoding xaction (see XactEncode): every
+//	  target walks the objects it is the main target for, and encodes those that
+//	  have no metafile.
+// 2. The same xaction re-encodes objects whose metafile does not match the
+//	  bucket's EC configuration - the primary proxy starts it when the number of
+//	  data or parity slices, the erasure code, or the size limit, changes.
+// 3. When the object gets re-encoded with fewer slices/replicas, the targets
+//	  that are not in the new HrwTargetList are requested to delete theirs. A
+//	  target that receives a slice deletes the object's replica, if any - and
+//	  vice versa.
+//
+// Global rebalance:
+// 1. When a target joins or leaves the cluster, HrwTargetList changes - and so
+//	  does the placement of slices/replicas. The slices are not moved between
+//	  the targets; instead, the main target encodes the object anew (see
+//	  XactPut.Rebalance). The placement recorded in the metafile (daemons)
+//	  tells whether it has changed.
+// 2. If the main target remains the same, it re-encodes the object, and the
+//	  targets that are not in the new placement are requested to delete their
+//	  slices/replicas.
+// 3. If the object moves to a new main target, the latter encodes it upon
+//	  receiving (as any PUT). The former main target - the one that knows the
+//	  old placement - requests the targets that are not in the new placement
+//	  to delete their slices/replicas.
+// 4. Replicas are not rebalanced as objects: only the main object is.
+//
+// Erasure codes (see Encoder):
+// 1. Reed-Solomon (default): any `data` slices out of `data`+`parity` restore
+//	  the object, so restoring even a single lost slice reads `data` slices.
+// 2. LRC (Locally Repairable Code): data slices are split into `local_groups`
+//	  groups, and `parity` slices are: `parity`-`local_groups` global
+//	  Reed-Solomon ones followed by a XOR parity slice per group. A group that
+//	  has lost a single data slice restores it from the rest of the group, so
+//	  only the group's slices are read; otherwise the global parity is used.
+//	  Any `parity`-`local_groups`+1 lost slices can be restored.
+// 3. The code is recorded in the metafile: the objects are decoded with the
+//	  code they were encoded with, regardless of the bucket's current one.
 
 const (
 	SliceType = "ec"   // object slice prefix
@@ -115,6 +177,7 @@ const (
 	ActSplit   = "split"
 	ActRestore = "restore"
 	ActDelete  = "delete"
+	ActScrub   = "scrub"
 
 	RespStreamName = "ec-resp"
 	ReqStreamName  = "ec-req"
@@ -153,12 +216,17 @@ const (
 type (
 	// Metadata - EC information stored in metafiles for every encoded object
 	Metadata struct {
-		Size        int64  `json:"size"`              // size of original file (after EC'ing the total size of slices differs from original)
-		Data        int    `json:"data"`              // the number of data slices
-		Parity      int    `json:"parity"`            // the number of parity slices
-		SliceID     int    `json:"sliceid,omitempty"` // 0 for full replica, 1 to N for slices
-		ObjChecksum string `json:"obj_chk"`           // checksum of the original object
-		IsCopy      bool   `json:"copy"`              // object is replicated(true) or encoded(false)
+		Size        int64         `json:"size"`                   // size of original file (after EC'ing the total size of slices differs from original)
+		Data        int           `json:"data"`                   // the number of data slices
+		Parity      int           `json:"parity"`                 // the number of parity slices
+		Code        string        `json:"code,omitempty"`         // erasure code the object was encoded with (empty means Reed-Solomon)
+		LocalGroups int           `json:"local_groups,omitempty"` // LRC: the number of local groups
+		SliceID     int           `json:"sliceid,omitempty"`      // 0 for full replica, 1 to N for slices
+		ObjChecksum string        `json:"obj_chk"`                // checksum of the original object
+		IsCopy      bool          `json:"copy"`                   // object is replicated(true) or encoded(false)
+		CustomMD    cmn.SimpleKVs `json:"custom,omitempty"`       // user-defined metadata of the original object
+		KeyID       string        `json:"key_id,omitempty"`       // encryption key of the original object (see cluster.LOM.KeyID)
+		Daemons     []string      `json:"daemons,omitempty"`      // targets the object was encoded for, main target first
 	}
 
 	// request - structure to request an object to be EC'ed or restored
@@ -169,8 +237,9 @@ type (
 		IsCopy bool         // replicate or use erasure coding
 
 		// private properties
-		putTime time.Time // time when the object is put into main queue
-		tm      time.Time // to measure different steps
+		putTime time.Time    // time when the object is put into main queue
+		tm      time.Time    // to measure different steps
+		scrub   *scrubResult // ActScrub: what the scrub has found out
 	}
 
 	RequestsControlMsg struct {
@@ -262,11 +331,38 @@ func (m *Metadata) marshal() ([]byte, error) {
 	return jsoniter.Marshal(m)
 }
 
-//nolint:unused
 func (m *Metadata) unmarshal(b []byte) error {
 	return jsoniter.Unmarshal(b, m)
 }
 
+// the encoder of the code the object was encoded with
+func (m *Metadata) encoder() (Encoder, error) {
+	return newEncoder(m.Code, m.Data, m.Parity, m.LocalGroups)
+}
+
+// whether the object was encoded with the given erasure code (objects encoded
+// before the code got recorded were encoded with Reed-Solomon)
+func (m *Metadata) encodedWith(conf *cmn.ECConf) bool {
+	code := m.Code
+	if code == "" {
+		code = cmn.ECCodeRS
+	}
+	return code == ecCode(conf) && m.LocalGroups == conf.LocalGroups
+}
+
+// LoadMetadata reads and parses the metafile
+func LoadMetadata(fqn string) (*Metadata, error) {
+	b, err := ioutil.ReadFile(fqn)
+	if err != nil {
+		return nil, err
+	}
+	meta := &Metadata{}
+	if err := meta.unmarshal(b); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %q: %v", fqn, err)
+	}
+	return meta, nil
+}
+
 var (
 	mem2         = &memsys.Mem2{Name: "ec", MinPctFree: 10}
 	slicePadding = make([]byte, 64) // for padding EC slices
@@ -307,7 +403,7 @@ func readFile(lom *cluster.LOM) (sgl *memsys.SGL, err error) {
 		return nil, err
 	}
 
-	sgl = mem2.NewSGL(lom.Size())
+	sgl = mem2.NewSGL(lom.DiskSize())
 	buf, slab := mem2.AllocFromSlab2(cmn.KiB * 32)
 	_, err = io.CopyBuffer(sgl, f, buf)
 	f.Close()
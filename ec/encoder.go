@@ -0,0 +1,456 @@
+// Package ec provides erasure coding (EC) based data protection for AIStore.
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package ec
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/NVIDIA/aistore/cmn"
+	"github.com/klauspost/reedsolomon"
+)
+
+// size of the blocks the LRC encoder processes the slices by
+const lrcBlockSize = 256 * cmn.KiB
+
+type (
+	// Encoder calculates parity slices of an object, and reconstructs its
+	// lost slices. The slices are ordered: data slices first, parity ones next
+	Encoder interface {
+		// Encode reads the data slices and writes the parity ones
+		Encode(data []io.Reader, parity []io.Writer) error
+		// Reconstruct writes the missing slices: a slice is missing if its
+		// reader in valid is nil, and it is written if its writer in fill is not
+		Reconstruct(valid []io.Reader, fill []io.Writer) error
+		// RepairSet returns the indices of the slices sufficient to reconstruct
+		// all data slices, given the available ones (avail[i] == true)
+		RepairSet(avail []bool) []int
+	}
+
+	// Reed-Solomon: any DataSlices slices restore the object
+	rsEncoder struct {
+		reedsolomon.StreamEncoder
+	}
+
+	// Locally Repairable Code: data slices are split into local groups, each
+	// protected by its own XOR parity slice - in addition to the global
+	// Reed-Solomon parity slices. A single lost data slice is restored from
+	// the rest of its group. Slices are ordered: data, global parity, local parity
+	lrcEncoder struct {
+		rs      reedsolomon.Encoder
+		data    int
+		global  int
+		members [][]int  // data slices of each local group
+		gen     [][]byte // generator matrix: slice = gen[slice] x data slices
+	}
+)
+
+// the bucket's erasure code; Reed-Solomon unless configured otherwise
+func ecCode(conf *cmn.ECConf) string {
+	if conf.Code == "" {
+		return cmn.ECCodeRS
+	}
+	return conf.Code
+}
+
+// creates the encoder of the given code; empty code means Reed-Solomon
+func newEncoder(code string, data, parity, groups int) (Encoder, error) {
+	switch code {
+	case "", cmn.ECCodeRS:
+		stream, err := reedsolomon.NewStreamC(data, parity, true, true)
+		if err != nil {
+			return nil, err
+		}
+		return &rsEncoder{stream}, nil
+	case cmn.ECCodeLRC:
+		return newLRCEncoder(data, parity, groups)
+	default:
+		return nil, fmt.Errorf("unsupported erasure code %q", code)
+	}
+}
+
+//
+// Reed-Solomon
+//
+
+// all slices are read, as before: the extra ones are used if any is corrupted
+func (e *rsEncoder) RepairSet(avail []bool) []int {
+	set := make([]int, 0, len(avail))
+	for i, ok := range avail {
+		if ok {
+			set = append(set, i)
+		}
+	}
+	return set
+}
+
+//
+// LRC
+//
+
+func newLRCEncoder(data, parity, groups int) (*lrcEncoder, error) {
+	if groups < 1 || groups > data || parity <= groups {
+		return nil, fmt.Errorf("invalid LRC layout: %d data, %d parity slices, %d local groups", data, parity, groups)
+	}
+	rs, err := reedsolomon.New(data, parity-groups, reedsolomon.WithCauchyMatrix())
+	if err != nil {
+		return nil, err
+	}
+	e := &lrcEncoder{rs: rs, data: data, global: parity - groups, members: make([][]int, groups)}
+	for i := 0; i < data; i++ {
+		g := i * groups / data
+		e.members[g] = append(e.members[g], i)
+	}
+
+	// the rows of data slices are unit vectors, of local parity slices - the
+	// group membership; the coefficients of global parity slices are obtained
+	// by encoding unit vectors
+	e.gen = make([][]byte, e.total())
+	for i := 0; i < data; i++ {
+		e.gen[i] = make([]byte, data)
+		e.gen[i][i] = 1
+	}
+	for p := 0; p < e.global; p++ {
+		e.gen[data+p] = make([]byte, data)
+	}
+	shards := make([][]byte, data+e.global)
+	for j := 0; j < data; j++ {
+		for i := range shards {
+			shards[i] = []byte{0}
+		}
+		shards[j][0] = 1
+		if err := rs.Encode(shards); err != nil {
+			return nil, err
+		}
+		for p := 0; p < e.global; p++ {
+			e.gen[data+p][j] = shards[data+p][0]
+		}
+	}
+	for g, members := range e.members {
+		row := make([]byte, data)
+		for _, i := range members {
+			row[i] = 1
+		}
+		e.gen[e.local(g)] = row
+	}
+	return e, nil
+}
+
+// index of the local parity slice of the group
+func (e *lrcEncoder) local(g int) int { return e.data + e.global + g }
+
+func (e *lrcEncoder) total() int { return e.data + e.global + len(e.members) }
+
+func (e *lrcEncoder) Encode(data []io.Reader, parity []io.Writer) error {
+	if len(data) != e.data || len(parity) != e.global+len(e.members) {
+		return reedsolomon.ErrTooFewShards
+	}
+	bufs := e.alloc()
+	for {
+		n, eof, err := readBlock(data, bufs)
+		if err != nil {
+			return err
+		}
+		if n != 0 {
+			shards := make([][]byte, e.total())
+			for i := range shards {
+				shards[i] = bufs[i][:n]
+			}
+			if err := e.rs.Encode(shards[:e.data+e.global]); err != nil {
+				return err
+			}
+			for g := range e.members {
+				e.xorGroup(shards, g, e.local(g))
+			}
+			for i, w := range parity {
+				if _, err := w.Write(shards[e.data+i]); err != nil {
+					return err
+				}
+			}
+		}
+		if eof {
+			return nil
+		}
+	}
+}
+
+func (e *lrcEncoder) Reconstruct(valid []io.Reader, fill []io.Writer) error {
+	if len(valid) != e.total() || len(fill) != e.total() {
+		return reedsolomon.ErrTooFewShards
+	}
+	var (
+		avail    = make([]bool, e.total())
+		local    = make([]int, 0, len(e.members)) // groups that restore their data slice locally
+		rows     []int                            // slices to restore the rest of data slices from
+		dec      [][]byte                         // ... and the matrix to do it
+		lost     []int                            // data slices that are not restored locally
+		reEncode bool                             // a global parity slice is missing
+	)
+	for i, r := range valid {
+		avail[i] = r != nil
+	}
+	for g, members := range e.members {
+		cnt := 0
+		for _, i := range members {
+			if !avail[i] {
+				cnt++
+			}
+		}
+		if cnt == 1 && avail[e.local(g)] {
+			local = append(local, g)
+		}
+	}
+	restored := append([]bool(nil), avail...)
+	for _, g := range local {
+		for _, i := range e.members[g] {
+			restored[i] = true
+		}
+	}
+	for i := 0; i < e.data; i++ {
+		if !restored[i] {
+			lost = append(lost, i)
+		}
+	}
+	if len(lost) != 0 {
+		var err error
+		if rows, dec, err = e.decodeMatrix(restored); err != nil {
+			return err
+		}
+	}
+	for i := e.data; i < e.data+e.global; i++ {
+		reEncode = reEncode || !avail[i]
+	}
+
+	bufs := e.alloc()
+	for {
+		n, eof, err := readBlock(valid, bufs)
+		if err != nil {
+			return err
+		}
+		if n != 0 {
+			shards := make([][]byte, e.total())
+			for i := range shards {
+				shards[i] = bufs[i][:n]
+			}
+			// 1. local repair: a group that has lost a single data slice
+			for _, g := range local {
+				for _, i := range e.members[g] {
+					if !avail[i] {
+						e.xorGroup(shards, g, i)
+					}
+				}
+			}
+			// 2. global repair: the rest of data slices
+			for _, i := range lost {
+				out := shards[i]
+				for j := range out {
+					out[j] = 0
+				}
+				for j, row := range rows {
+					galMulAdd(dec[i][j], shards[row], out)
+				}
+			}
+			// 3. parity slices are recalculated from data ones
+			if reEncode {
+				if err := e.rs.Encode(shards[:e.data+e.global]); err != nil {
+					return err
+				}
+			}
+			for g := range e.members {
+				if l := e.local(g); !avail[l] {
+					e.xorGroup(shards, g, l)
+				}
+			}
+			for i, w := range fill {
+				if w == nil {
+					continue
+				}
+				if _, err := w.Write(shards[i]); err != nil {
+					return err
+				}
+			}
+		}
+		if eof {
+			return nil
+		}
+	}
+}
+
+// selects the available slices that restore data slices, and calculates the
+// matrix that does it: data[i] = sum(dec[i][j] * slice[rows[j]]). Gauss-Jordan
+// elimination over the generator matrix rows of the available slices
+func (e *lrcEncoder) decodeMatrix(avail []bool) (rows []int, dec [][]byte, err error) {
+	type basis struct {
+		vec   []byte // combination of data slices, reduced to a unit vector
+		comb  []byte // ... as the combination of the selected slices
+		pivot int
+	}
+	var bases []*basis
+	for r, ok := range avail {
+		if !ok || len(rows) == e.data {
+			continue
+		}
+		v := append([]byte(nil), e.gen[r]...)
+		c := make([]byte, e.data)
+		c[len(rows)] = 1
+		for _, b := range bases {
+			if f := v[b.pivot]; f != 0 {
+				galMulAdd(f, b.vec, v)
+				galMulAdd(f, b.comb, c)
+			}
+		}
+		pivot := -1
+		for j, x := range v {
+			if x != 0 {
+				pivot = j
+				break
+			}
+		}
+		if pivot < 0 {
+			continue // linearly dependent on the selected slices
+		}
+		inv := galInv(v[pivot])
+		galMulSlice(inv, v)
+		galMulSlice(inv, c)
+		for _, b := range bases {
+			if f := b.vec[pivot]; f != 0 {
+				galMulAdd(f, v, b.vec)
+				galMulAdd(f, c, b.comb)
+			}
+		}
+		bases = append(bases, &basis{vec: v, comb: c, pivot: pivot})
+		rows = append(rows, r)
+	}
+	if len(rows) < e.data {
+		return nil, nil, reedsolomon.ErrTooFewShards
+	}
+	dec = make([][]byte, e.data)
+	for _, b := range bases {
+		dec[b.pivot] = b.comb
+	}
+	return rows, dec, nil
+}
+
+// the data slices of the groups that have lost at most one slice, plus the
+// local parity slices to restore the lost ones. If any group has lost more,
+// the global parity is required - and all available slices are returned
+func (e *lrcEncoder) RepairSet(avail []bool) []int {
+	set := make([]int, 0, e.data+len(e.members))
+	for g, members := range e.members {
+		lost := 0
+		for _, i := range members {
+			if avail[i] {
+				set = append(set, i)
+			} else {
+				lost++
+			}
+		}
+		if lost == 0 {
+			continue
+		}
+		if lost > 1 || !avail[e.local(g)] {
+			return (&rsEncoder{}).RepairSet(avail)
+		}
+		set = append(set, e.local(g))
+	}
+	return set
+}
+
+// dst = XOR of all shards of the group (its data slices and local parity) except dst
+func (e *lrcEncoder) xorGroup(shards [][]byte, g, dst int) {
+	out := shards[dst]
+	for i := range out {
+		out[i] = 0
+	}
+	xor := func(idx int) {
+		if idx == dst {
+			return
+		}
+		for i, b := range shards[idx] {
+			out[i] ^= b
+		}
+	}
+	for _, idx := range e.members[g] {
+		xor(idx)
+	}
+	xor(e.local(g))
+}
+
+func (e *lrcEncoder) alloc() [][]byte {
+	bufs := make([][]byte, e.total())
+	for i := range bufs {
+		bufs[i] = make([]byte, lrcBlockSize)
+	}
+	return bufs
+}
+
+// reads the next block of every non-nil reader; all readers must return the
+// same number of bytes. Returns true if the end of slices is reached
+func readBlock(readers []io.Reader, bufs [][]byte) (n int, eof bool, err error) {
+	n = -1
+	for i, r := range readers {
+		if r == nil {
+			continue
+		}
+		m, err := io.ReadFull(r, bufs[i])
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			eof = true
+		} else if err != nil {
+			return 0, false, err
+		}
+		if n >= 0 && m != n {
+			return 0, false, reedsolomon.ErrShardSize
+		}
+		n = m
+	}
+	if n < 0 {
+		return 0, false, reedsolomon.ErrTooFewShards
+	}
+	return n, eof, nil
+}
+
+//
+// GF(2^8) arithmetic - the same field as the one reedsolomon uses
+//
+
+var galExp, galLog = galTables()
+
+func galTables() (exp [510]byte, log [256]byte) {
+	x := 1
+	for i := 0; i < 255; i++ {
+		exp[i], exp[i+255] = byte(x), byte(x)
+		log[x] = byte(i)
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= 0x11d // x^8 + x^4 + x^3 + x^2 + 1
+		}
+	}
+	return
+}
+
+func galMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return galExp[int(galLog[a])+int(galLog[b])]
+}
+
+func galInv(a byte) byte { return galExp[255-int(galLog[a])] }
+
+// out += c * in
+func galMulAdd(c byte, in, out []byte) {
+	if c == 0 {
+		return
+	}
+	for i, b := range in {
+		out[i] ^= galMul(c, b)
+	}
+}
+
+func galMulSlice(c byte, v []byte) {
+	for i, b := range v {
+		v[i] = galMul(c, b)
+	}
+}
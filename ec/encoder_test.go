@@ -0,0 +1,254 @@
+// Package ec provides erasure coding (EC) based data protection for AIStore.
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package ec
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/NVIDIA/aistore/cmn"
+)
+
+type encoderLayout struct {
+	code                 string
+	data, parity, groups int
+}
+
+var encoderLayouts = []encoderLayout{
+	{cmn.ECCodeRS, 2, 2, 0},
+	{cmn.ECCodeRS, 4, 2, 0},
+	{cmn.ECCodeLRC, 2, 2, 1},
+	{cmn.ECCodeLRC, 4, 3, 2},
+	{cmn.ECCodeLRC, 5, 4, 2},
+	{cmn.ECCodeLRC, 6, 3, 1},
+	{cmn.ECCodeLRC, 6, 5, 3},
+}
+
+func (l *encoderLayout) String() string {
+	return fmt.Sprintf("%s %d+%d/%d", l.code, l.data, l.parity, l.groups)
+}
+
+// encodes random data slices and returns all slices: data, then parity
+func encodeSlices(t *testing.T, e Encoder, data, parity, size int, rnd *rand.Rand) [][]byte {
+	slices := make([][]byte, data+parity)
+	readers := make([]io.Reader, data)
+	for i := 0; i < data; i++ {
+		slices[i] = make([]byte, size)
+		rnd.Read(slices[i])
+		readers[i] = bytes.NewReader(slices[i])
+	}
+	bufs := make([]*bytes.Buffer, parity)
+	writers := make([]io.Writer, parity)
+	for i := range writers {
+		bufs[i] = &bytes.Buffer{}
+		writers[i] = bufs[i]
+	}
+	if err := e.Encode(readers, writers); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	for i, buf := range bufs {
+		if buf.Len() != size {
+			t.Fatalf("parity slice %d: expected %d bytes, got %d", i, size, buf.Len())
+		}
+		slices[data+i] = buf.Bytes()
+	}
+	return slices
+}
+
+// reconstructs the slices that are not available (only the ones in use are
+// read) and compares them with the originals; returns false if the slices
+// cannot be reconstructed
+func reconstructSlices(t *testing.T, e Encoder, slices [][]byte, avail, use []bool) bool {
+	var (
+		valid = make([]io.Reader, len(slices))
+		fill  = make([]io.Writer, len(slices))
+		bufs  = make([]*bytes.Buffer, len(slices))
+	)
+	for i := range slices {
+		if use[i] {
+			valid[i] = bytes.NewReader(slices[i])
+		} else if !avail[i] {
+			bufs[i] = &bytes.Buffer{}
+			fill[i] = bufs[i]
+		}
+	}
+	if err := e.Reconstruct(valid, fill); err != nil {
+		return false
+	}
+	for i, buf := range bufs {
+		if buf != nil && !bytes.Equal(buf.Bytes(), slices[i]) {
+			t.Errorf("slice %d: reconstructed data differs from the original", i)
+		}
+	}
+	return true
+}
+
+func TestEncoderReconstruct(t *testing.T) {
+	const iterations = 50
+	seed := time.Now().UnixNano()
+	rnd := rand.New(rand.NewSource(seed))
+	for i := range encoderLayouts {
+		layout := &encoderLayouts[i]
+		e, err := newEncoder(layout.code, layout.data, layout.parity, layout.groups)
+		if err != nil {
+			t.Fatalf("%s: %v", layout, err)
+		}
+		total := layout.data + layout.parity
+		// any `parity` (RS), or `parity`-`groups`+1 (LRC), lost slices can be restored
+		canLose := layout.parity
+		if layout.code == cmn.ECCodeLRC {
+			canLose = layout.parity - layout.groups + 1
+		}
+		for _, size := range []int{1, 1000, lrcBlockSize + 17} {
+			slices := encodeSlices(t, e, layout.data, layout.parity, size, rnd)
+			for it := 0; it < iterations; it++ {
+				avail := make([]bool, total)
+				for j := range avail {
+					avail[j] = true
+				}
+				lost := 1 + rnd.Intn(layout.parity)
+				for _, j := range rnd.Perm(total)[:lost] {
+					avail[j] = false
+				}
+				if !reconstructSlices(t, e, slices, avail, avail) && lost <= canLose {
+					t.Errorf("%s (seed %d): failed to reconstruct %d bytes with %d lost slices %v",
+						layout, seed, size, lost, avail)
+				}
+			}
+		}
+	}
+}
+
+// a single lost data slice per group is restored from the slices of RepairSet
+// alone, which (for LRC) does not include global parity slices
+func TestEncoderLocalRepair(t *testing.T) {
+	seed := time.Now().UnixNano()
+	rnd := rand.New(rand.NewSource(seed))
+	for i := range encoderLayouts {
+		layout := &encoderLayouts[i]
+		if layout.code != cmn.ECCodeLRC {
+			continue
+		}
+		e, err := newLRCEncoder(layout.data, layout.parity, layout.groups)
+		if err != nil {
+			t.Fatalf("%s: %v", layout, err)
+		}
+		slices := encodeSlices(t, e, layout.data, layout.parity, 1000, rnd)
+		avail := make([]bool, e.total())
+		for j := range avail {
+			avail[j] = true
+		}
+		if set := e.RepairSet(avail); len(set) != layout.data {
+			t.Errorf("%s: nothing lost, expected the data slices, got %v", layout, set)
+		}
+		for _, members := range e.members {
+			avail[members[rnd.Intn(len(members))]] = false
+		}
+		set := e.RepairSet(avail)
+		if len(set) != layout.data {
+			t.Errorf("%s (seed %d): expected %d slices to repair from, got %v", layout, seed, layout.data, set)
+		}
+		use := make([]bool, e.total())
+		for _, j := range set {
+			if j >= e.data && j < e.data+e.global {
+				t.Errorf("%s (seed %d): global parity slice %d in repair set %v", layout, seed, j, set)
+			}
+			use[j] = true
+		}
+		if !reconstructSlices(t, e, slices, avail, use) {
+			t.Errorf("%s (seed %d): failed to repair locally from %v", layout, seed, set)
+		}
+
+		// two lost slices in a group require global parity: all available slices
+		if len(e.members[0]) > 1 {
+			avail[e.members[0][0]], avail[e.members[0][1]] = false, false
+			cnt := 0
+			for _, ok := range avail {
+				if ok {
+					cnt++
+				}
+			}
+			if set := e.RepairSet(avail); len(set) != cnt {
+				t.Errorf("%s: expected all %d available slices, got %v", layout, cnt, set)
+			}
+		}
+	}
+}
+
+// the decoding matrix times the generator rows of the selected slices must
+// be the identity matrix
+func TestLRCDecodeMatrix(t *testing.T) {
+	seed := time.Now().UnixNano()
+	rnd := rand.New(rand.NewSource(seed))
+	for i := range encoderLayouts {
+		layout := &encoderLayouts[i]
+		if layout.code != cmn.ECCodeLRC {
+			continue
+		}
+		e, err := newLRCEncoder(layout.data, layout.parity, layout.groups)
+		if err != nil {
+			t.Fatalf("%s: %v", layout, err)
+		}
+		for it := 0; it < 50; it++ {
+			avail := make([]bool, e.total())
+			for j := range avail {
+				avail[j] = true
+			}
+			for _, j := range rnd.Perm(e.total())[:1+rnd.Intn(layout.parity)] {
+				avail[j] = false
+			}
+			rows, dec, err := e.decodeMatrix(avail)
+			if err != nil {
+				continue // too many lost - covered by TestEncoderReconstruct
+			}
+			if len(rows) != e.data {
+				t.Fatalf("%s: expected %d rows, got %v", layout, e.data, rows)
+			}
+			for _, r := range rows {
+				if !avail[r] {
+					t.Fatalf("%s: row %d of a lost slice selected", layout, r)
+				}
+			}
+			for d := 0; d < e.data; d++ {
+				v := make([]byte, e.data)
+				for j, r := range rows {
+					galMulAdd(dec[d][j], e.gen[r], v)
+				}
+				for j, x := range v {
+					if (j == d && x != 1) || (j != d && x != 0) {
+						t.Fatalf("%s (seed %d): avail %v: data slice %d decodes to %v", layout, seed, avail, d, v)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestLRCXorGroup(t *testing.T) {
+	e, err := newLRCEncoder(5, 4, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+	shards := make([][]byte, e.total())
+	for i := range shards {
+		shards[i] = make([]byte, 64)
+		rnd.Read(shards[i])
+	}
+	for g, members := range e.members {
+		e.xorGroup(shards, g, e.local(g))
+		for _, i := range members {
+			orig := append([]byte(nil), shards[i]...)
+			e.xorGroup(shards, g, i)
+			if !bytes.Equal(orig, shards[i]) {
+				t.Errorf("group %d: data slice %d is not restored from the rest of the group", g, i)
+			}
+		}
+	}
+}
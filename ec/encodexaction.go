@@ -0,0 +1,166 @@
+// Package ec provides erasure coding (EC) based data protection for AIStore.
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package ec
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/NVIDIA/aistore/3rdparty/glog"
+	"github.com/NVIDIA/aistore/cluster"
+	"github.com/NVIDIA/aistore/cmn"
+	"github.com/NVIDIA/aistore/fs"
+)
+
+type (
+	// XactEncode walks the bucket's objects and encodes those, for which this
+	// target is the main one, that are not protected yet - or that were
+	// encoded with EC configuration different from the current one. The
+	// encoding itself is done by XactPut, exactly as on PUT
+	XactEncode struct {
+		cmn.XactBase
+		t          cluster.Target
+		smap       cluster.Sowner
+		si         *cluster.Snode
+		namelocker cluster.NameLocker
+		stats      stats
+		bckName    string
+		putXact    func() *XactPut // running put xaction of the bucket
+	}
+)
+
+func NewEncodeXact(t cluster.Target, smap cluster.Sowner, si *cluster.Snode, bucket string,
+	nl cluster.NameLocker, putXact func() *XactPut) *XactEncode {
+	return &XactEncode{
+		t:          t,
+		smap:       smap,
+		si:         si,
+		namelocker: nl,
+		stats:      stats{bckName: bucket},
+		bckName:    bucket,
+		putXact:    putXact,
+	}
+}
+
+func (r *XactEncode) Run() error {
+	glog.Infof("Starting %s", r)
+	var (
+		wg                = &sync.WaitGroup{}
+		availablePaths, _ = fs.Mountpaths.Get()
+	)
+	for _, mpathInfo := range availablePaths {
+		wg.Add(1)
+		go func(mpathInfo *fs.MountpathInfo) {
+			defer wg.Done()
+			if err := r.jog(mpathInfo); err != nil {
+				glog.Errorf("%s: %v", r, err)
+			}
+		}(mpathInfo)
+	}
+	wg.Wait()
+	r.EndTime(time.Now())
+	glog.Infof("%s finished\n%s", r, r.Stats())
+	return nil
+}
+
+func (r *XactEncode) Stats() *ECStats { return r.stats.stats() }
+
+func (r *XactEncode) jog(mpathInfo *fs.MountpathInfo) error {
+	dir := mpathInfo.MakePathBucket(fs.ObjectType, r.bckName, true /*local*/)
+	walk := func(fqn string, osfi os.FileInfo, err error) error {
+		if r.Aborted() {
+			return fmt.Errorf("%s aborted", r)
+		}
+		if err != nil {
+			if errstr := cmn.PathWalkErr(err); errstr != "" {
+				glog.Error(errstr)
+				return err
+			}
+			return nil
+		}
+		if !osfi.Mode().IsDir() {
+			r.encodeObj(fqn)
+		}
+		return nil
+	}
+	if err := filepath.Walk(dir, walk); err != nil {
+		return fmt.Errorf("failed to traverse %s, err: %v", dir, err)
+	}
+	return nil
+}
+
+func (r *XactEncode) encodeObj(fqn string) {
+	lom, errstr := cluster.LOM{FQN: fqn, T: r.t}.Init()
+	if errstr != "" {
+		glog.Warning(errstr)
+		return
+	}
+	// only the main target encodes the object; replicas and mirror copies are skipped
+	si, errstr := cluster.HrwTarget(lom.Bucket, lom.Objname, r.smap.Get())
+	if errstr != "" || si.DaemonID != r.si.DaemonID || lom.FQN != lom.HrwFQN {
+		return
+	}
+
+	r.namelocker.Lock(lom.Uname(), true)
+	defer r.namelocker.Unlock(lom.Uname(), true)
+	if _, errstr = lom.Load(false); errstr != "" {
+		glog.Errorf("%s: %s", r, errstr)
+		r.stats.bckEncodeErr.Inc()
+		return
+	}
+	if !lom.Exists() || lom.BckProps == nil || !lom.BckProps.EC.Enabled {
+		return
+	}
+	if _, errstr = lom.CksumComputeIfMissing(); errstr != "" {
+		glog.Errorf("%s: %s", r, errstr)
+		r.stats.bckEncodeErr.Inc()
+		return
+	}
+
+	isCopy := IsECCopy(lom.Size(), &lom.BckProps.EC)
+	encoded, upToDate := r.checkMeta(lom, isCopy)
+	if upToDate {
+		return
+	}
+	req := &Request{
+		Action: ActSplit,
+		IsCopy: isCopy,
+		LOM:    lom,
+		ErrCh:  make(chan error, 1),
+	}
+	r.putXact().Encode(req)
+	if err := <-req.ErrCh; err != nil {
+		glog.Errorf("%s: failed to encode %s, err: %v", r, lom, err)
+		r.stats.bckEncodeErr.Inc()
+		return
+	}
+	if encoded {
+		r.stats.bckReEncoded.Inc()
+	} else {
+		r.stats.bckEncoded.Inc()
+	}
+}
+
+// returns whether the object has been encoded, and whether its metafile
+// matches the object and the current EC configuration of the bucket
+func (r *XactEncode) checkMeta(lom *cluster.LOM, isCopy bool) (encoded, upToDate bool) {
+	b, err := ioutil.ReadFile(fs.CSM.GenContentFQN(lom.FQN, MetaType, ""))
+	if err != nil {
+		return false, false
+	}
+	meta := &Metadata{}
+	if err := meta.unmarshal(b); err != nil {
+		return true, false
+	}
+	_, cksumValue := lom.Cksum().Get()
+	ecConf := &lom.BckProps.EC
+	upToDate = meta.IsCopy == isCopy && meta.Parity == ecConf.ParitySlices &&
+		(isCopy || (meta.Data == ecConf.DataSlices && meta.encodedWith(ecConf))) && meta.ObjChecksum == cksumValue
+	return true, upToDate
+}
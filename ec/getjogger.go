@@ -22,7 +22,6 @@ import (
 	"github.com/NVIDIA/aistore/transport"
 	"github.com/OneOfOne/xxhash"
 	jsoniter "github.com/json-iterator/go"
-	"github.com/klauspost/reedsolomon"
 )
 
 // a mountpath getJogger: processes GET requests to one mountpath
@@ -65,7 +64,7 @@ func (c *getJogger) stop() {
 // starts EC process
 func (c *getJogger) ec(req *Request) {
 	switch req.Action {
-	case ActRestore:
+	case ActRestore, ActScrub:
 		c.sema <- struct{}{}
 		toDisk := useDisk(0 /*size of the original object is unknown*/)
 		c.jobID++
@@ -81,6 +80,14 @@ func (c *getJogger) ec(req *Request) {
 			}
 		}
 		restore := func(req *Request, toDisk bool, buffer []byte, cb func(error)) {
+			if req.Action == ActScrub {
+				err := c.scrub(req, toDisk, buffer)
+				if cb != nil {
+					cb(err)
+				}
+				<-c.sema
+				return
+			}
 			err := c.restore(req, toDisk, buffer)
 			c.parent.stats.updateDecodeTime(time.Since(req.tm), err != nil)
 			if cb != nil {
@@ -175,7 +182,7 @@ func (c *getJogger) copyMissingReplicas(lom *cluster.LOM, reader cmn.ReadOpenClo
 
 	src := &dataSource{
 		reader:   srcReader,
-		size:     lom.Size(),
+		size:     lom.DiskSize(),
 		metadata: metadata,
 		reqType:  ReqPut,
 	}
@@ -225,7 +232,9 @@ func (c *getJogger) restoreReplicatedFromMemory(req *Request, meta *Metadata, no
 	// Save received replica and its metadata locally - it is main replica
 	objFQN := req.LOM.FQN
 	req.LOM.FQN = objFQN
-	req.LOM.SetSize(writer.Size())
+	req.LOM.SetKeyID(meta.KeyID)
+	req.LOM.SetDiskSize(writer.Size())
+	req.LOM.SetCustomMD(meta.CustomMD)
 	tmpFQN := fs.CSM.GenContentFQN(objFQN, fs.WorkfileType, "ec")
 	if _, err := cmn.SaveReaderSafe(tmpFQN, objFQN, memsys.NewReader(writer), buffer, false); err != nil {
 		writer.Free()
@@ -298,6 +307,13 @@ func (c *getJogger) restoreReplicatedFromDisk(req *Request, meta *Metadata, node
 	if err := cmn.MvFile(tmpFQN, objFQN); err != nil {
 		return err
 	}
+	finfo, err := os.Stat(objFQN)
+	if err != nil {
+		return err
+	}
+	req.LOM.SetKeyID(meta.KeyID)
+	req.LOM.SetDiskSize(finfo.Size())
+	req.LOM.SetCustomMD(meta.CustomMD)
 
 	if err := req.LOM.Persist(); err != nil {
 		return err
@@ -333,7 +349,8 @@ func (c *getJogger) restoreReplicatedFromDisk(req *Request, meta *Metadata, node
 // Returns:
 // * []slice - a list of received slices in correct order (missing slices = nil)
 // * map[int]string - a map of slice locations: SliceID <-> DaemonID
-func (c *getJogger) requestSlices(req *Request, meta *Metadata, nodes map[string]*Metadata, toDisk bool) ([]*slice, map[int]string, error) {
+func (c *getJogger) requestSlices(req *Request, meta *Metadata, nodes map[string]*Metadata, needed map[int]bool,
+	toDisk bool) ([]*slice, map[int]string, error) {
 	wgSlices := cmn.NewTimeoutGroup()
 	sliceCnt := meta.Data + meta.Parity
 	slices := make([]*slice, sliceCnt)
@@ -344,6 +361,12 @@ func (c *getJogger) requestSlices(req *Request, meta *Metadata, nodes map[string
 		if v.SliceID < 1 || v.SliceID > sliceCnt {
 			glog.Errorf("Node %s has invalid slice ID %d", k, v.SliceID)
 		}
+		// the slice is not needed to restore the object: it is neither
+		// requested nor reconstructed, and the target keeps it as is
+		if needed != nil && !needed[v.SliceID] {
+			idToNode[v.SliceID] = k
+			continue
+		}
 
 		if glog.V(4) {
 			glog.Infof("Slice %s/%s ID %d requesting from %s", req.LOM.Bucket, req.LOM.Objname, v.SliceID, k)
@@ -481,6 +504,9 @@ func (c *getJogger) restoreMainObj(req *Request, meta *Metadata, slices []*slice
 	// allocate memory for reconstructed(missing) slices - EC requirement,
 	// and open existing slices for reading
 	for i, sl := range slices {
+		if _, ok := idToNode[i+1]; sl == nil && ok {
+			continue // exists but has not been requested
+		}
 		if sl != nil && sl.writer != nil {
 			sz := sl.n
 			if glog.V(4) {
@@ -527,7 +553,7 @@ func (c *getJogger) restoreMainObj(req *Request, meta *Metadata, slices []*slice
 	if glog.V(4) {
 		glog.Infof("Reconstructing %s/%s", req.LOM.Bucket, req.LOM.Objname)
 	}
-	stream, err := reedsolomon.NewStreamC(meta.Data, meta.Parity, true, true)
+	stream, err := meta.encoder()
 	if err != nil {
 		return restored, err
 	}
@@ -597,7 +623,9 @@ func (c *getJogger) restoreMainObj(req *Request, meta *Metadata, slices []*slice
 
 	c.diskCh <- struct{}{}
 	req.LOM.FQN = mainFQN
-	req.LOM.SetSize(meta.Size)
+	req.LOM.SetKeyID(meta.KeyID)
+	req.LOM.SetDiskSize(meta.Size)
+	req.LOM.SetCustomMD(meta.CustomMD)
 	if version != "" {
 		req.LOM.SetVersion(version)
 	}
@@ -610,6 +638,10 @@ func (c *getJogger) restoreMainObj(req *Request, meta *Metadata, slices []*slice
 		return restored, err
 	}
 	<-c.diskCh
+	if meta.KeyID != "" && meta.ObjChecksum != "" {
+		// the content is encrypted while the object's checksum is computed over plaintext
+		hash = meta.ObjChecksum
+	}
 	req.LOM.SetCksum(cmn.NewCksum(cmn.ChecksumXXHash, hash))
 	// Persist called without a lock. It's not a problem, as the LOM should not be used as the object is missing
 	if err := req.LOM.Persist(); err != nil {
@@ -784,6 +816,48 @@ func (c *getJogger) uploadRestoredSlices(req *Request, meta *Metadata, slices []
 // * meta - rebuild object's metadata
 // * nodes - the list of targets that responded with valid metadata
 func (c *getJogger) restoreEncoded(req *Request, meta *Metadata, nodes map[string]*Metadata, toDisk bool, buffer []byte) error {
+	needed := neededSlices(meta, nodes)
+	err := c.restoreEncodedFrom(req, meta, nodes, needed, toDisk, buffer)
+	if err != nil && needed != nil {
+		// e.g, a slice of the local group is corrupted - use all of them
+		glog.Warningf("Failed to restore %s/%s from %d slices, retrying with all: %v",
+			req.LOM.Bucket, req.LOM.Objname, len(needed), err)
+		err = c.restoreEncodedFrom(req, meta, nodes, nil, toDisk, buffer)
+	}
+	return err
+}
+
+// returns the IDs of the slices that are sufficient to restore the object,
+// or nil if all existing ones are required. With LRC, a single lost data
+// slice requires only the rest of its local group
+func neededSlices(meta *Metadata, nodes map[string]*Metadata) map[int]bool {
+	enc, err := meta.encoder()
+	if err != nil {
+		return nil
+	}
+	sliceCnt := meta.Data + meta.Parity
+	avail := make([]bool, sliceCnt)
+	availCnt := 0
+	for _, md := range nodes {
+		if md.SliceID >= 1 && md.SliceID <= sliceCnt && !avail[md.SliceID-1] {
+			avail[md.SliceID-1] = true
+			availCnt++
+		}
+	}
+	set := enc.RepairSet(avail)
+	if len(set) >= availCnt {
+		return nil
+	}
+	needed := make(map[int]bool, len(set))
+	for _, idx := range set {
+		needed[idx+1] = true
+	}
+	return needed
+}
+
+// restores the object from the slices: all existing ones if needed is nil
+func (c *getJogger) restoreEncodedFrom(req *Request, meta *Metadata, nodes map[string]*Metadata, needed map[int]bool,
+	toDisk bool, buffer []byte) error {
 	if glog.V(4) {
 		glog.Infof("Starting EC restore %s/%s", req.LOM.Bucket, req.LOM.Objname)
 	}
@@ -796,8 +870,8 @@ func (c *getJogger) restoreEncoded(req *Request, meta *Metadata, nodes map[strin
 		}
 	}
 
-	// download all slices from the targets that have sent metadata
-	slices, idToNode, err := c.requestSlices(req, meta, nodes, toDisk)
+	// download the slices from the targets that have sent metadata
+	slices, idToNode, err := c.requestSlices(req, meta, nodes, needed, toDisk)
 	if err != nil {
 		freeWriters()
 		return err
@@ -985,3 +1059,174 @@ func (c *getJogger) requestMeta(req *Request) (meta *Metadata, nodes map[string]
 
 	return meta, nodes, nil
 }
+
+// Entry point of scrub: validates the main object, and then its slices or
+// replicas stored on the other targets. The findings are recorded in
+// req.scrub - it is up to the caller to restore or re-encode the object if
+// need be. Runs under the object's read lock
+func (c *getJogger) scrub(req *Request, toDisk bool, buffer []byte) error {
+	var (
+		lom        = req.LOM
+		res        = req.scrub
+		cksumValue string
+	)
+	if errstr := lom.ValidateDiskChecksum(); errstr != "" {
+		glog.Errorf("EC scrub: %s", errstr)
+		res.corrupt++
+		res.mainCorrupt = true
+		return nil
+	}
+	if lom.Cksum() != nil {
+		_, cksumValue = lom.Cksum().Get()
+	}
+
+	metaFQN := fs.CSM.GenContentFQN(lom.FQN, MetaType, "")
+	b, err := ioutil.ReadFile(metaFQN)
+	if err != nil {
+		return err
+	}
+	local := &Metadata{}
+	if err := local.unmarshal(b); err != nil {
+		return fmt.Errorf("failed to unmarshal %q: %v", metaFQN, err)
+	}
+	sliceCnt := local.Parity
+	if !local.IsCopy {
+		sliceCnt += local.Data
+	}
+	if local.ObjChecksum != cksumValue {
+		// the object has changed since it was encoded
+		res.missing = sliceCnt
+		return nil
+	}
+
+	meta, nodes, err := c.requestMeta(req)
+	if err == ErrorNoMetafile || (err == nil && meta.ObjChecksum != cksumValue) {
+		res.missing = sliceCnt
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if local.IsCopy {
+		err = c.scrubReplicas(req, nodes, buffer)
+	} else {
+		err = c.scrubSlices(req, meta, nodes, toDisk, buffer)
+	}
+	if err != nil {
+		return err
+	}
+
+	// all the targets that must have a slice/replica
+	targets, errstr := cluster.HrwTargetList(lom.Bucket, lom.Objname, c.parent.smap.Get(), sliceCnt+1)
+	if errstr != "" {
+		return errors.New(errstr)
+	}
+	for _, si := range targets {
+		if si.DaemonID == c.parent.si.DaemonID {
+			continue
+		}
+		if _, ok := nodes[si.DaemonID]; !ok {
+			res.missing++
+		}
+	}
+	return nil
+}
+
+// requests the replica from every target that has its metadata, and compares
+// the received data with the main object (replicas are its exact copies)
+func (c *getJogger) scrubReplicas(req *Request, nodes map[string]*Metadata, buffer []byte) error {
+	file, err := os.Open(req.LOM.FQN)
+	if err != nil {
+		return err
+	}
+	cksumValue, errstr := cmn.ComputeXXHash(file, buffer)
+	file.Close()
+	if errstr != "" {
+		return errors.New(errstr)
+	}
+	for node := range nodes {
+		uname := unique(node, req.LOM.Bucket, req.LOM.Objname)
+		iReqBuf, err := c.parent.newIntraReq(reqGet, nil).Marshal()
+		if err != nil {
+			return err
+		}
+		// replicated objects are small - no need to use disk
+		w := mem2.NewSGL(cmn.KiB)
+		if err := c.parent.readRemote(req.LOM, node, uname, iReqBuf, w); err != nil {
+			w.Free()
+			return fmt.Errorf("failed to read replica from %s: %v", node, err)
+		}
+		if w.Size() == 0 {
+			glog.Warningf("EC scrub: %s/%s: no replica on %s", req.LOM.Bucket, req.LOM.Objname, node)
+			req.scrub.missing++
+			w.Free()
+			continue
+		}
+		replicaValue, errstr := cmn.ComputeXXHash(memsys.NewReader(w), buffer)
+		w.Free()
+		if errstr != "" {
+			return errors.New(errstr)
+		}
+		if replicaValue != cksumValue {
+			glog.Warningf("EC scrub: %s/%s: replica on %s is corrupted", req.LOM.Bucket, req.LOM.Objname, node)
+			req.scrub.corrupt++
+		}
+	}
+	return nil
+}
+
+// requests all slices from the targets that have their metadata, and
+// validates the received data against the checksums stored with the slices
+func (c *getJogger) scrubSlices(req *Request, meta *Metadata, nodes map[string]*Metadata, toDisk bool, buffer []byte) error {
+	slices, _, err := c.requestSlices(req, meta, nodes, nil, toDisk)
+	for k := range nodes {
+		c.parent.unregWriter(unique(k, req.LOM.Bucket, req.LOM.Objname))
+	}
+	defer func() {
+		for _, sl := range slices {
+			if sl != nil {
+				if sgl, ok := sl.writer.(*memsys.SGL); ok {
+					sgl.Free()
+				}
+			}
+		}
+		freeSlices(slices)
+	}()
+	if err != nil {
+		return err
+	}
+
+	sliceSize := SliceSize(meta.Size, meta.Data)
+	for i, sl := range slices {
+		if sl == nil {
+			continue // no metadata - counted by the caller
+		}
+		if sl.n == 0 {
+			glog.Warningf("EC scrub: %s/%s: slice %d is missing", req.LOM.Bucket, req.LOM.Objname, i+1)
+			req.scrub.missing++
+			continue
+		}
+		if sl.cksum == nil {
+			continue // nothing to validate against
+		}
+		var cksumValue, errstr string
+		if sgl, ok := sl.writer.(*memsys.SGL); ok {
+			cksumValue, errstr = cmn.ComputeXXHash(memsys.NewReader(sgl), buffer)
+		} else {
+			fh, err := cmn.NewFileHandle(sl.workFQN)
+			if err != nil {
+				return err
+			}
+			cksumValue, errstr = cmn.ComputeXXHash(fh, buffer)
+			fh.Close()
+		}
+		if errstr != "" {
+			return errors.New(errstr)
+		}
+		if sl.n != sliceSize || !cmn.EqCksum(cmn.NewCksum(cmn.ChecksumXXHash, cksumValue), sl.cksum) {
+			glog.Warningf("EC scrub: %s/%s: slice %d is corrupted", req.LOM.Bucket, req.LOM.Objname, i+1)
+			req.scrub.corrupt++
+		}
+	}
+	return nil
+}
@@ -137,12 +137,14 @@ func (r *XactGet) Run() (err error) {
 			}
 		case req := <-r.ecCh:
 			lastAction = time.Now()
-			if req.Action != ActRestore {
+			switch req.Action {
+			case ActRestore:
+				r.stats.updateDecode()
+			case ActScrub:
+			default:
 				glog.Errorf("Invalid request's action %s for getxaction", req.Action)
-				break
+				continue
 			}
-
-			r.stats.updateDecode()
 			r.dispatchRequest(req)
 		case mpathRequest := <-r.mpathReqCh:
 			switch mpathRequest.action {
@@ -229,6 +231,15 @@ func (r *XactGet) Decode(req *Request) {
 	r.dispatchEncodingRequest(req)
 }
 
+// Scrub schedules the object to be checked: whether all its slices or replicas
+// exist and are valid (see XactScrub). The result is put into request.ErrCh
+func (r *XactGet) Scrub(req *Request) {
+	req.putTime = time.Now()
+	req.tm = time.Now()
+
+	r.dispatchEncodingRequest(req)
+}
+
 // Cleanup deletes all object slices or copies after the main object is removed
 func (r *XactGet) Cleanup(req *Request) {
 	req.putTime = time.Now()
@@ -275,7 +286,7 @@ func (r *XactGet) dispatchRequest(req *Request) {
 	}
 
 	r.IncPending()
-	if req.Action == ActRestore {
+	if req.Action == ActRestore || req.Action == ActScrub {
 		jogger, ok := r.getJoggers[req.LOM.ParsedFQN.MpathInfo.Path]
 		cmn.AssertMsg(ok, "Invalid mountpath given in EC request")
 		r.stats.updateQueue(len(jogger.workCh))
@@ -21,7 +21,6 @@ import (
 	"github.com/NVIDIA/aistore/memsys"
 	"github.com/NVIDIA/aistore/transport"
 	"github.com/OneOfOne/xxhash"
-	"github.com/klauspost/reedsolomon"
 )
 
 // a mountpath putJogger: processes PUT/DEL requests to one mountpath
@@ -47,7 +46,7 @@ func (c *putJogger) run() {
 			ecConf := req.LOM.BckProps.EC
 
 			c.parent.stats.updateWaitTime(time.Since(req.tm))
-			memRequired := req.LOM.Size() * int64(ecConf.DataSlices+ecConf.ParitySlices) / int64(ecConf.ParitySlices)
+			memRequired := req.LOM.DiskSize() * int64(ecConf.DataSlices+ecConf.ParitySlices) / int64(ecConf.ParitySlices)
 			c.toDisk = useDisk(memRequired)
 			req.tm = time.Now()
 			c.ec(req)
@@ -117,11 +116,16 @@ func (c *putJogger) encode(req *Request) error {
 	ecConf := req.LOM.BckProps.EC
 	_, cksumValue := req.LOM.Cksum().Get()
 	meta := &Metadata{
-		Size:        req.LOM.Size(),
+		Size:        req.LOM.DiskSize(),
 		Data:        ecConf.DataSlices,
 		Parity:      ecConf.ParitySlices,
 		IsCopy:      req.IsCopy,
 		ObjChecksum: cksumValue,
+		CustomMD:    req.LOM.CustomMD(),
+		KeyID:       req.LOM.KeyID(),
+	}
+	if !req.IsCopy {
+		meta.Code, meta.LocalGroups = ecCode(&ecConf), ecConf.LocalGroups
 	}
 
 	// calculate the number of targets required to encode the object
@@ -131,11 +135,20 @@ func (c *putJogger) encode(req *Request) error {
 	if !req.IsCopy {
 		reqTargets += ecConf.DataSlices
 	}
-	targetCnt := len(c.parent.smap.Get().Tmap)
+	smap := c.parent.smap.Get()
+	targetCnt := len(smap.Tmap)
 	if targetCnt < reqTargets {
 		return fmt.Errorf("object %s/%s requires %d targets to encode, only %d found",
 			req.LOM.Bucket, req.LOM.Objname, reqTargets, targetCnt)
 	}
+	targets, errstr := cluster.HrwTargetList(req.LOM.Bucket, req.LOM.Objname, smap, reqTargets)
+	if errstr != "" {
+		return errors.New(errstr)
+	}
+	meta.Daemons = make([]string, 0, len(targets))
+	for _, si := range targets {
+		meta.Daemons = append(meta.Daemons, si.DaemonID)
+	}
 
 	metabuf, err := meta.marshal()
 	if err != nil {
@@ -147,6 +160,7 @@ func (c *putJogger) encode(req *Request) error {
 	if errstr != "" {
 		return errors.New(errstr)
 	}
+	c.cleanupStale(req, metaFQN, meta)
 	if _, err := cmn.SaveReader(metaFQN, bytes.NewReader(metabuf), c.buffer, false); err != nil {
 		return err
 	}
@@ -199,6 +213,50 @@ func (c *putJogger) cleanup(req *Request) error {
 	return c.parent.reqBundle.SendV(hdr, nil, nil)
 }
 
+// the object was encoded before, for a different set of targets (EC
+// configuration or the cluster map has changed): the targets that are not
+// going to receive anything must delete what they have got
+func (c *putJogger) cleanupStale(req *Request, metaFQN string, meta *Metadata) {
+	oldMeta, err := LoadMetadata(metaFQN)
+	if err != nil {
+		return
+	}
+	lom := req.LOM
+	// this target used to keep a slice, and now it is the main one
+	if oldMeta.SliceID != 0 {
+		if fqn, _, errstr := cluster.FQN(SliceType, lom.Bucket, lom.Objname, lom.BckIsLocal); errstr == "" {
+			if err := os.Remove(fqn); err != nil && !os.IsNotExist(err) {
+				glog.Errorf("Failed to remove stale slice %q: %v", fqn, err)
+			}
+		}
+	}
+	smap := c.parent.smap.Get()
+	if len(oldMeta.Daemons) == 0 {
+		// the placement is unknown: assume the object was encoded by this
+		// target, and the cluster map has not changed since
+		oldCnt := oldMeta.Parity
+		if !oldMeta.IsCopy {
+			oldCnt += oldMeta.Data
+		}
+		oldCnt = cmn.Min(oldCnt, smap.CountTargets()-1)
+		if oldCnt+1 <= len(meta.Daemons) {
+			return
+		}
+		targets, errstr := cluster.HrwTargetList(lom.Bucket, lom.Objname, smap, oldCnt+1)
+		if errstr != "" {
+			glog.Errorf("Failed to cleanup stale slices of %s/%s: %s", lom.Bucket, lom.Objname, errstr)
+			return
+		}
+		for _, si := range targets {
+			oldMeta.Daemons = append(oldMeta.Daemons, si.DaemonID)
+		}
+	}
+	stale := staleTargets(oldMeta.Daemons, meta.Daemons, smap)
+	if err := c.parent.deleteStale(lom.Bucket, lom.Objname, stale); err != nil {
+		glog.Errorf("Failed to cleanup stale slices of %s/%s: %v", lom.Bucket, lom.Objname, err)
+	}
+}
+
 // Sends object replicas to targets that must have replicas after the client
 // uploads the main replica
 func (c *putJogger) createCopies(req *Request, metadata *Metadata) error {
@@ -233,7 +291,7 @@ func (c *putJogger) createCopies(req *Request, metadata *Metadata) error {
 	}
 	src := &dataSource{
 		reader:   fh,
-		size:     req.LOM.Size(),
+		size:     req.LOM.DiskSize(),
 		metadata: metadata,
 		reqType:  ReqPut,
 	}
@@ -261,12 +319,13 @@ func calculateDataSlicesHashes(slices []*slice, wg *sync.WaitGroup, errCh chan e
 
 // generateSlicesToMemory gets FQN to the original file and encodes it into EC slices
 // * fqn - the path to original object
+// * enc - the encoder of the bucket's erasure code
 // * dataSlices - the number of data slices
 // * paritySlices - the number of parity slices
 // Returns:
 // * SGL that hold all the objects data
 // * constructed from the main object slices
-func generateSlicesToMemory(lom *cluster.LOM, dataSlices, paritySlices int) (cmn.ReadOpenCloser, []*slice, error) {
+func generateSlicesToMemory(lom *cluster.LOM, enc Encoder, dataSlices, paritySlices int) (cmn.ReadOpenCloser, []*slice, error) {
 	var (
 		totalCnt = paritySlices + dataSlices
 		slices   = make([]*slice, totalCnt)
@@ -278,7 +337,7 @@ func generateSlicesToMemory(lom *cluster.LOM, dataSlices, paritySlices int) (cmn
 	if err != nil {
 		return sgl, slices, err
 	}
-	fileSize := lom.Size()
+	fileSize := lom.DiskSize()
 
 	sliceSize := SliceSize(fileSize, dataSlices)
 	padSize := sliceSize*int64(dataSlices) - fileSize
@@ -327,13 +386,8 @@ func generateSlicesToMemory(lom *cluster.LOM, dataSlices, paritySlices int) (cmn
 		sliceWriters[i] = io.MultiWriter(writers[i], hashes[i])
 	}
 
-	stream, err := reedsolomon.NewStreamC(dataSlices, paritySlices, true, true)
-	if err != nil {
-		return sgl, slices, err
-	}
-
 	// Calculate slices and it's hashes
-	if err := stream.Encode(readers, sliceWriters); err != nil {
+	if err := enc.Encode(readers, sliceWriters); err != nil {
 		return sgl, slices, err
 	}
 
@@ -350,12 +404,13 @@ func generateSlicesToMemory(lom *cluster.LOM, dataSlices, paritySlices int) (cmn
 
 // generateSlicesToDisk gets FQN to the original file and encodes it into EC slices
 // * fqn - the path to original object
+// * enc - the encoder of the bucket's erasure code
 // * dataSlices - the number of data slices
 // * paritySlices - the number of parity slices
 // Returns:
 // * Main object file handle
 // * constructed from the main object slices
-func generateSlicesToDisk(fqn string, dataSlices, paritySlices int) (cmn.ReadOpenCloser, []*slice, error) {
+func generateSlicesToDisk(fqn string, enc Encoder, dataSlices, paritySlices int) (cmn.ReadOpenCloser, []*slice, error) {
 	var (
 		totalCnt = paritySlices + dataSlices
 		slices   = make([]*slice, totalCnt)
@@ -429,13 +484,8 @@ func generateSlicesToDisk(fqn string, dataSlices, paritySlices int) (cmn.ReadOpe
 		sliceWriters[i] = io.MultiWriter(writers[i], hashes[i])
 	}
 
-	stream, err := reedsolomon.NewStreamC(dataSlices, paritySlices, true, true)
-	if err != nil {
-		return fh, slices, err
-	}
-
 	// Calculate slices and it's hashes
-	if err := stream.Encode(readers, sliceWriters); err != nil {
+	if err := enc.Encode(readers, sliceWriters); err != nil {
 		return fh, slices, err
 	}
 
@@ -478,10 +528,14 @@ func (c *putJogger) sendSlices(req *Request, meta *Metadata) ([]*slice, error) {
 		slices    []*slice
 		err       error
 	)
+	enc, err := meta.encoder()
+	if err != nil {
+		return nil, err
+	}
 	if c.toDisk {
-		objReader, slices, err = generateSlicesToDisk(req.LOM.FQN, ecConf.DataSlices, ecConf.ParitySlices)
+		objReader, slices, err = generateSlicesToDisk(req.LOM.FQN, enc, ecConf.DataSlices, ecConf.ParitySlices)
 	} else {
-		objReader, slices, err = generateSlicesToMemory(req.LOM, ecConf.DataSlices, ecConf.ParitySlices)
+		objReader, slices, err = generateSlicesToMemory(req.LOM, enc, ecConf.DataSlices, ecConf.ParitySlices)
 	}
 
 	if err != nil {
@@ -493,7 +547,7 @@ func (c *putJogger) sendSlices(req *Request, meta *Metadata) ([]*slice, error) {
 	wg := sync.WaitGroup{}
 	ch := make(chan error, totalCnt)
 	mainObj := &slice{refCnt: *atomic.NewInt32(int32(ecConf.DataSlices)), obj: objReader}
-	sliceSize := SliceSize(req.LOM.Size(), ecConf.DataSlices)
+	sliceSize := SliceSize(req.LOM.DiskSize(), ecConf.DataSlices)
 
 	// transfer a slice to remote target
 	// If the slice is data one - no immediate cleanup is required because this
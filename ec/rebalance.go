@@ -0,0 +1,145 @@
+// Package ec provides erasure coding (EC) based data protection for AIStore.
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package ec
+
+import (
+	"errors"
+	"os"
+
+	"github.com/NVIDIA/aistore/3rdparty/glog"
+	"github.com/NVIDIA/aistore/cluster"
+	"github.com/NVIDIA/aistore/cmn"
+	"github.com/NVIDIA/aistore/fs"
+	"github.com/NVIDIA/aistore/transport"
+)
+
+// Rebalance brings the placement of the object's slices/replicas in line with
+// the current cluster map (see "Global rebalance" above). It is called by the
+// global rebalance for every local metafile of the bucket; lom is the object
+// the metafile belongs to. Returns true if the object has been re-encoded
+func (r *XactPut) Rebalance(metaFQN string, lom *cluster.LOM, nl cluster.NameLocker) (bool, error) {
+	meta, err := LoadMetadata(metaFQN)
+	if err != nil {
+		return false, err
+	}
+	cnt := meta.Parity
+	if !meta.IsCopy {
+		cnt += meta.Data
+	}
+	smap := r.smap.Get()
+	targets, errstr := cluster.HrwTargetList(lom.Bucket, lom.Objname, smap, cmn.Min(cnt+1, smap.CountTargets()))
+	if errstr != "" {
+		return false, errors.New(errstr)
+	}
+	daemons := make([]string, 0, len(targets))
+	for _, si := range targets {
+		daemons = append(daemons, si.DaemonID)
+	}
+
+	if daemons[0] != r.si.DaemonID {
+		// the object moves to the new main target, which is going to encode
+		// it upon receiving: the former main one cleans up the old placement
+		if len(meta.Daemons) == 0 || meta.Daemons[0] != r.si.DaemonID {
+			return false, nil
+		}
+		if err := r.deleteStale(lom.Bucket, lom.Objname, staleTargets(meta.Daemons, daemons, smap)); err != nil {
+			return false, err
+		}
+		if !cmn.StringInSlice(r.si.DaemonID, daemons) {
+			if err := os.Remove(metaFQN); err != nil && !os.IsNotExist(err) {
+				return false, err
+			}
+		}
+		return false, nil
+	}
+
+	// this target is the main one: re-encode the object if the placement has
+	// changed; if this target used to keep a slice, the object is yet to arrive
+	// (or, if the former main target has left, to be restored by GET)
+	if meta.SliceID != 0 || samePlacement(meta.Daemons, daemons) {
+		return false, nil
+	}
+	nl.Lock(lom.Uname(), true)
+	defer nl.Unlock(lom.Uname(), true)
+	if _, errstr := lom.Load(false); errstr != "" {
+		return false, errors.New(errstr)
+	}
+	if !lom.Exists() || lom.BckProps == nil || !lom.BckProps.EC.Enabled {
+		return false, nil // if lost, the object is going to be restored by GET
+	}
+	if _, errstr := lom.CksumComputeIfMissing(); errstr != "" {
+		return false, errors.New(errstr)
+	}
+	if glog.V(4) {
+		glog.Infof("Re-encoding %s: placement %v => %v", lom, meta.Daemons, daemons)
+	}
+	req := &Request{
+		Action: ActSplit,
+		IsCopy: IsECCopy(lom.Size(), &lom.BckProps.EC),
+		LOM:    lom,
+		ErrCh:  make(chan error, 1),
+	}
+	r.Encode(req)
+	if err := <-req.ErrCh; err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// IsReplica returns true if the object is not the main one but its replica
+// kept by this target (daemonID). The objects encoded before their placement
+// got recorded (see Metadata.Daemons) are never considered replicas
+func IsReplica(lom *cluster.LOM, daemonID string) bool {
+	meta, err := LoadMetadata(fs.CSM.GenContentFQN(lom.FQN, MetaType, ""))
+	if err != nil {
+		return false
+	}
+	return meta.SliceID == 0 && len(meta.Daemons) != 0 && meta.Daemons[0] != daemonID
+}
+
+// the same targets, in any order: slices and replicas are interchangeable
+func samePlacement(old, daemons []string) bool {
+	if len(old) != len(daemons) || len(old) == 0 || old[0] != daemons[0] {
+		return false
+	}
+	for _, id := range daemons {
+		if !cmn.StringInSlice(id, old) {
+			return false
+		}
+	}
+	return true
+}
+
+// returns the targets of the old placement that are not in the new one
+// (skipping the targets that have left the cluster)
+func staleTargets(old, daemons []string, smap *cluster.Smap) []*cluster.Snode {
+	var stale []*cluster.Snode
+	for _, id := range old {
+		if cmn.StringInSlice(id, daemons) {
+			continue
+		}
+		if si := smap.GetTarget(id); si != nil {
+			stale = append(stale, si)
+		}
+	}
+	return stale
+}
+
+// requests the targets to delete the object's slices/replicas and metafiles
+func (r *xactECBase) deleteStale(bucket, objname string, stale []*cluster.Snode) error {
+	if len(stale) == 0 {
+		return nil // NOTE: no destinations means broadcast
+	}
+	request, err := r.newIntraReq(reqDel, nil).Marshal()
+	if err != nil {
+		return err
+	}
+	hdr := transport.Header{
+		Bucket:  bucket,
+		Objname: objname,
+		Opaque:  request,
+	}
+	return r.reqBundle.Send(hdr, nil, nil, stale)
+}
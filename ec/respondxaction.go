@@ -94,6 +94,31 @@ func (r *XactRespond) removeObjAndMeta(bucket, objname string, bckIsLocal bool)
 	return nil
 }
 
+// the object got re-encoded and switched from replicas to slices or vice versa:
+// a received slice replaces the replica stored before, and the other way around.
+// The full object is never removed from its main target
+func (r *XactRespond) removeObsolete(bucket, objname string, bckIsLocal, isSlice bool) error {
+	tp := SliceType
+	if isSlice {
+		si, errstr := cluster.HrwTarget(bucket, objname, r.smap.Get())
+		if errstr != "" {
+			return errors.New(errstr)
+		}
+		if si.DaemonID == r.si.DaemonID {
+			return nil
+		}
+		tp = fs.ObjectType
+	}
+	fqn, _, errstr := cluster.FQN(tp, bucket, objname, bckIsLocal)
+	if errstr != "" {
+		return errors.New(errstr)
+	}
+	if err := os.Remove(fqn); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
 // DispatchReq is responsible for handling request from other targets
 func (r *XactRespond) DispatchReq(iReq IntraReq, bucket, objName string) {
 	bckIsLocal := r.bmd.Get().IsLocal(bucket)
@@ -207,7 +232,11 @@ func (r *XactRespond) DispatchResp(iReq IntraReq, bucket, objName string, objAtt
 			}
 			lom.SetVersion(objAttrs.Version)
 			lom.SetAtimeUnix(objAttrs.Atime)
-			lom.SetSize(objAttrs.Size)
+			if !iReq.IsSlice {
+				lom.SetKeyID(meta.KeyID) // replica: encrypted as the original object, if need be
+			}
+			lom.SetDiskSize(objAttrs.Size)
+			lom.SetCustomMD(objAttrs.CustomMD)
 
 			// LOM checksum is filled with checksum of a slice. Source object's checksum is stored in metadata
 			if objAttrs.CksumType != "" {
@@ -245,6 +274,10 @@ func (r *XactRespond) DispatchResp(iReq IntraReq, bucket, objName string, objAtt
 		slab.Free(buf)
 		if err != nil {
 			glog.Errorf("Failed to save metadata to %q: %v", metaFQN, err)
+			return
+		}
+		if err := r.removeObsolete(bucket, objName, bckIsLocal, iReq.IsSlice); err != nil {
+			glog.Errorf("Failed to cleanup %s/%s: %v", bucket, objName, err)
 		}
 	default:
 		// should be unreachable
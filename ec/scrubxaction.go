@@ -0,0 +1,202 @@
+// Package ec provides erasure coding (EC) based data protection for AIStore.
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package ec
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/NVIDIA/aistore/3rdparty/glog"
+	"github.com/NVIDIA/aistore/cluster"
+	"github.com/NVIDIA/aistore/cmn"
+	"github.com/NVIDIA/aistore/fs"
+)
+
+type (
+	// XactScrub walks the bucket's metafiles and checks that the objects, for
+	// which this target is the main one, are fully protected: every slice or
+	// replica exists on the target it must be on, and none is corrupted. The
+	// objects that are not get encoded anew. The checks themselves are done by
+	// XactGet - the one that receives the responses of the other targets
+	XactScrub struct {
+		cmn.XactBase
+		t          cluster.Target
+		smap       cluster.Sowner
+		si         *cluster.Snode
+		namelocker cluster.NameLocker
+		stats      stats
+		bckName    string
+		getXact    func() *XactGet // running get xaction of the bucket
+		putXact    func() *XactPut // running put xaction of the bucket
+	}
+
+	// what the scrub has found out about a given object
+	scrubResult struct {
+		missing     int  // slices/replicas that are missing or obsolete
+		corrupt     int  // slices/replicas, or the main object, that are corrupted
+		mainLost    bool // the main object is missing
+		mainCorrupt bool // the main object is corrupted
+	}
+)
+
+func NewScrubXact(t cluster.Target, smap cluster.Sowner, si *cluster.Snode, bucket string,
+	nl cluster.NameLocker, getXact func() *XactGet, putXact func() *XactPut) *XactScrub {
+	return &XactScrub{
+		t:          t,
+		smap:       smap,
+		si:         si,
+		namelocker: nl,
+		stats:      stats{bckName: bucket},
+		bckName:    bucket,
+		getXact:    getXact,
+		putXact:    putXact,
+	}
+}
+
+func (r *XactScrub) Run() error {
+	glog.Infof("Starting %s", r)
+	var (
+		wg                = &sync.WaitGroup{}
+		availablePaths, _ = fs.Mountpaths.Get()
+	)
+	for _, mpathInfo := range availablePaths {
+		wg.Add(1)
+		go func(mpathInfo *fs.MountpathInfo) {
+			defer wg.Done()
+			if err := r.jog(mpathInfo); err != nil {
+				glog.Errorf("%s: %v", r, err)
+			}
+		}(mpathInfo)
+	}
+	wg.Wait()
+	r.EndTime(time.Now())
+	glog.Infof("%s finished\n%s", r, r.Stats())
+	return nil
+}
+
+func (r *XactScrub) Stats() *ECStats { return r.stats.stats() }
+
+func (r *XactScrub) jog(mpathInfo *fs.MountpathInfo) error {
+	dir := mpathInfo.MakePathBucket(MetaType, r.bckName, true /*local*/)
+	walk := func(fqn string, osfi os.FileInfo, err error) error {
+		if r.Aborted() {
+			return fmt.Errorf("%s aborted", r)
+		}
+		if err != nil {
+			if errstr := cmn.PathWalkErr(err); errstr != "" {
+				glog.Error(errstr)
+				return err
+			}
+			return nil
+		}
+		if !osfi.Mode().IsDir() {
+			r.scrubObj(fqn)
+		}
+		return nil
+	}
+	if err := filepath.Walk(dir, walk); err != nil {
+		return fmt.Errorf("failed to traverse %s, err: %v", dir, err)
+	}
+	return nil
+}
+
+func (r *XactScrub) scrubObj(metaFQN string) {
+	metaLOM, errstr := cluster.LOM{FQN: metaFQN, T: r.t}.Init()
+	if errstr != "" {
+		glog.Warning(errstr)
+		return
+	}
+	lom, errstr := cluster.LOM{Bucket: metaLOM.Bucket, Objname: metaLOM.Objname, T: r.t}.Init()
+	if errstr != "" {
+		glog.Warning(errstr)
+		return
+	}
+	// only the main target checks the object, and only its metafile counts
+	si, errstr := cluster.HrwTarget(lom.Bucket, lom.Objname, r.smap.Get())
+	if errstr != "" || si.DaemonID != r.si.DaemonID || fs.CSM.GenContentFQN(lom.FQN, MetaType, "") != metaFQN {
+		return
+	}
+
+	res, cksum := r.check(lom)
+	if res == nil || (res.missing == 0 && res.corrupt == 0) {
+		return
+	}
+	r.repair(lom, res, cksum)
+}
+
+// checks the object and its slices/replicas under the read lock - the
+// object stays readable while the other targets are being queried
+func (r *XactScrub) check(lom *cluster.LOM) (res *scrubResult, cksum cmn.Cksummer) {
+	r.namelocker.Lock(lom.Uname(), false)
+	defer r.namelocker.Unlock(lom.Uname(), false)
+	if _, errstr := lom.Load(false); errstr != "" {
+		glog.Errorf("%s: %s", r, errstr)
+		r.stats.scrubErr.Inc()
+		return nil, nil
+	}
+	if lom.BckProps == nil || !lom.BckProps.EC.Enabled {
+		return nil, nil
+	}
+	res = &scrubResult{}
+	if !lom.Exists() {
+		res.missing++
+		res.mainLost = true
+		r.stats.updateScrub(res, nil)
+		return res, nil
+	}
+	req := &Request{LOM: lom, Action: ActScrub, ErrCh: make(chan error, 1), scrub: res}
+	r.getXact().Scrub(req)
+	err := <-req.ErrCh
+	r.stats.updateScrub(res, err)
+	if err != nil {
+		glog.Errorf("%s: failed to scrub %s, err: %v", r, lom, err)
+		return nil, nil
+	}
+	return res, lom.Cksum()
+}
+
+// restores the main object or re-encodes it under the exclusive lock - unless
+// the object has changed (and got encoded anew) since it was checked
+func (r *XactScrub) repair(lom *cluster.LOM, res *scrubResult, cksum cmn.Cksummer) {
+	r.namelocker.Lock(lom.Uname(), true)
+	defer r.namelocker.Unlock(lom.Uname(), true)
+	if _, errstr := lom.Load(false); errstr != "" {
+		glog.Errorf("%s: %s", r, errstr)
+		r.stats.scrubErr.Inc()
+		return
+	}
+	if lom.BckProps == nil || !lom.BckProps.EC.Enabled {
+		return
+	}
+	if res.mainLost {
+		if lom.Exists() {
+			return
+		}
+	} else if !lom.Exists() || !cmn.EqCksum(cksum, lom.Cksum()) {
+		return
+	}
+
+	req := &Request{LOM: lom, ErrCh: make(chan error, 1)}
+	if res.mainLost || res.mainCorrupt {
+		// restore the main object, along with its slices/replicas
+		req.Action = ActRestore
+		r.getXact().Decode(req)
+	} else {
+		// recalculate and resend all slices/replicas
+		glog.Warningf("%s: %s has %d missing and %d corrupted slices, re-encoding", r, lom, res.missing, res.corrupt)
+		req.Action = ActSplit
+		req.IsCopy = IsECCopy(lom.Size(), &lom.BckProps.EC)
+		r.putXact().Encode(req)
+	}
+	if err := <-req.ErrCh; err != nil {
+		glog.Errorf("%s: failed to repair %s, err: %v", r, lom, err)
+		r.stats.scrubErr.Inc()
+		return
+	}
+	r.stats.scrubRepaired.Inc()
+}
@@ -31,6 +31,16 @@ type stats struct {
 	deleteErr  atomic.Int64
 	objTime    atomic.Int64
 	objCnt     atomic.Int64
+	// scrub and bucket encoding counters are totals: unlike the others, they
+	// are not reset when read
+	scrubObj      atomic.Int64
+	scrubMissing  atomic.Int64
+	scrubCorrupt  atomic.Int64
+	scrubRepaired atomic.Int64
+	scrubErr      atomic.Int64
+	bckEncoded    atomic.Int64
+	bckReEncoded  atomic.Int64
+	bckEncodeErr  atomic.Int64
 }
 
 // ECStats are stats for clients-side apps - calculated from raw counters
@@ -63,6 +73,22 @@ type ECStats struct {
 	GetReq int64
 	// total number of encode requests
 	PutReq int64
+	// number of objects checked by EC scrub (see XactScrub)
+	ScrubObj int64
+	// number of slices and replicas that scrub found missing or obsolete
+	ScrubMissing int64
+	// number of objects, slices and replicas that scrub found corrupted
+	ScrubCorrupt int64
+	// number of objects that scrub repaired
+	ScrubRepaired int64
+	// total number of errors while scrubbing objects
+	ScrubErr int64
+	// number of existing objects that got encoded by XactEncode
+	BckEncoded int64
+	// number of objects that XactEncode encoded anew with the changed EC configuration
+	BckReEncoded int64
+	// total number of errors while encoding existing objects
+	BckEncodeErr int64
 	// name of the bucket
 	BckName string
 }
@@ -116,6 +142,15 @@ func (s *stats) updateObjTime(d time.Duration) {
 	s.objCnt.Inc()
 }
 
+func (s *stats) updateScrub(res *scrubResult, err error) {
+	s.scrubObj.Inc()
+	s.scrubMissing.Add(int64(res.missing))
+	s.scrubCorrupt.Add(int64(res.corrupt))
+	if err != nil {
+		s.scrubErr.Inc()
+	}
+}
+
 func (s *stats) stats() *ECStats {
 	st := &ECStats{BckName: s.bckName}
 
@@ -164,11 +199,20 @@ func (s *stats) stats() *ECStats {
 	st.DecodeErr = s.decodeErr.Swap(0)
 	st.DeleteErr = s.deleteErr.Swap(0)
 
+	st.ScrubObj = s.scrubObj.Load()
+	st.ScrubMissing = s.scrubMissing.Load()
+	st.ScrubCorrupt = s.scrubCorrupt.Load()
+	st.ScrubRepaired = s.scrubRepaired.Load()
+	st.ScrubErr = s.scrubErr.Load()
+	st.BckEncoded = s.bckEncoded.Load()
+	st.BckReEncoded = s.bckReEncoded.Load()
+	st.BckEncodeErr = s.bckEncodeErr.Load()
+
 	return st
 }
 
 func (s *ECStats) String() string {
-	if s.ObjTime == 0 {
+	if s.ObjTime == 0 && s.ScrubObj == 0 && s.BckEncoded == 0 && s.BckReEncoded == 0 {
 		return ""
 	}
 
@@ -193,5 +237,15 @@ func (s *ECStats) String() string {
 
 	lines = append(lines, fmt.Sprintf("Requests count: encode %d, restore %d, delete %d", s.PutReq, s.GetReq, s.DelReq))
 
+	if s.ScrubObj != 0 {
+		lines = append(lines, fmt.Sprintf("Scrub objects: %d, missing: %d, corrupted: %d, repaired: %d, errors: %d",
+			s.ScrubObj, s.ScrubMissing, s.ScrubCorrupt, s.ScrubRepaired, s.ScrubErr))
+	}
+
+	if s.BckEncoded != 0 || s.BckReEncoded != 0 {
+		lines = append(lines, fmt.Sprintf("Bucket encoding: encoded %d, re-encoded %d, errors: %d",
+			s.BckEncoded, s.BckReEncoded, s.BckEncodeErr))
+	}
+
 	return strings.Join(lines, "\n")
 }
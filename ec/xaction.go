@@ -61,9 +61,11 @@ type (
 )
 
 const (
-	XactGetType = "xactecget"
-	XactPutType = "xactecput"
-	XactResType = "xactecreq"
+	XactGetType    = "xactecget"
+	XactPutType    = "xactecput"
+	XactResType    = "xactecreq"
+	XactScrubType  = "xactecscrub"
+	XactEncodeType = "xactecencode"
 )
 
 func newXactReqECBase() xactReqBase {
@@ -160,17 +162,18 @@ func (r *xactECBase) dataResponse(act intraReqType, fqn, bucket, objname, id str
 	if errstr := lom.FromFS(); errstr != "" {
 		glog.Warningf("Failed to read file stats #2: %s", errstr)
 	}
-	if err == nil && lom.Size() != 0 {
-		sz = lom.Size()
+	if err == nil && lom.DiskSize() != 0 {
+		sz = lom.DiskSize()
 		reader = fh
 	} else {
 		ireq.Exists = false
 	}
 	cmn.Assert((sz == 0 && reader == nil) || (sz != 0 && reader != nil))
 	objAttrs := transport.ObjectAttrs{
-		Size:    sz,
-		Version: lom.Version(),
-		Atime:   lom.Atime().UnixNano(),
+		Size:     sz,
+		Version:  lom.Version(),
+		Atime:    lom.Atime().UnixNano(),
+		CustomMD: lom.CustomMD(),
 	}
 
 	if lom.Cksum() != nil {
@@ -333,9 +336,10 @@ func (r *xactECBase) writeRemote(daemonIDs []string, lom *cluster.LOM, src *data
 		return err
 	}
 	objAttrs := transport.ObjectAttrs{
-		Size:    src.size,
-		Version: lom.Version(),
-		Atime:   lom.Atime().UnixNano(),
+		Size:     src.size,
+		Version:  lom.Version(),
+		Atime:    lom.Atime().UnixNano(),
+		CustomMD: lom.CustomMD(),
 	}
 	if lom.Cksum() != nil {
 		objAttrs.CksumType, objAttrs.CksumValue = lom.Cksum().Get()
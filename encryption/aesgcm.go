@@ -0,0 +1,329 @@
+// Package encryption provides server-side encryption of the objects at rest
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/NVIDIA/aistore/cmn"
+)
+
+//
+// Encrypted content is a header followed by the object's plaintext split into
+// chunks, each sealed with AES-GCM separately - so that reading a range of the
+// object takes decrypting only the chunks the range overlaps:
+//
+// | magic (4) | version (1) | reserved (3) | salt (16) | chunk 0 + tag | chunk 1 + tag | ...
+//
+// - every object is encrypted with its own key derived from the master key
+//   (see KeyProvider) and the object's random salt;
+// - the nonce of a chunk is its index, and the last chunk is authenticated as
+//   such - reordering, truncating or extending the content fails decryption.
+//
+
+const (
+	ChunkSize  = 64 * cmn.KiB // plaintext bytes per chunk
+	TagSize    = 16           // GCM authentication tag per chunk
+	HeaderSize = 24
+	KeySize    = 32 // master keys are AES-256
+
+	magic    = "AISE"
+	version  = 1
+	saltSize = 16
+)
+
+var ErrCorrupted = errors.New("encrypted content is corrupted or the key is wrong")
+
+type (
+	// Writer encrypts the content written to it; Close writes the last chunk
+	Writer struct {
+		w      io.Writer
+		aead   cipher.AEAD
+		buf    []byte // plaintext of the chunk being written
+		sealed []byte
+		idx    uint64
+		closed bool
+	}
+	// Reader decrypts the content it reads from the underlying io.ReaderAt;
+	// ReadAt is safe for concurrent use, Read and Seek are not
+	Reader struct {
+		r      io.ReaderAt
+		aead   cipher.AEAD
+		size   int64 // plaintext
+		chunks int64
+		off    int64 // Read/Seek offset
+		mtx    sync.Mutex
+		cached int64  // index of the chunk decrypted in buf (-1: none)
+		buf    []byte // plaintext of the cached chunk
+		sealed []byte
+	}
+	// File is an encrypted file opened for reading; it implements cmn.ReadOpenCloser
+	File struct {
+		*Reader
+		file *os.File
+		key  []byte
+	}
+)
+
+// EncryptedSize returns the size of the encrypted content given the size of plaintext
+func EncryptedSize(size int64) int64 {
+	chunks := (size + ChunkSize - 1) / ChunkSize
+	if chunks == 0 {
+		chunks = 1 // empty content is a single empty chunk
+	}
+	return HeaderSize + size + chunks*TagSize
+}
+
+// PlainSize returns the size of plaintext given the size of the encrypted content,
+// or -1 if the latter is invalid
+func PlainSize(encSize int64) int64 {
+	body := encSize - HeaderSize
+	if body < TagSize {
+		return -1
+	}
+	chunks := (body + ChunkSize + TagSize - 1) / (ChunkSize + TagSize)
+	size := body - chunks*TagSize
+	if size < 0 || EncryptedSize(size) != encSize {
+		return -1
+	}
+	return size
+}
+
+func newAEAD(key, salt []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("invalid key size %d (expecting %d)", len(key), KeySize)
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(salt)
+	block, err := aes.NewCipher(mac.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func nonce(idx uint64, b []byte) []byte {
+	binary.BigEndian.PutUint64(b, idx)
+	return b
+}
+
+func aad(last bool) []byte {
+	if last {
+		return []byte{1}
+	}
+	return []byte{0}
+}
+
+//
+// Writer
+//
+
+func NewWriter(w io.Writer, key []byte) (*Writer, error) {
+	var hdr [HeaderSize]byte
+	copy(hdr[:], magic)
+	hdr[len(magic)] = version
+	salt := hdr[HeaderSize-saltSize:]
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key, salt)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = w.Write(hdr[:]); err != nil {
+		return nil, err
+	}
+	return &Writer{
+		w:      w,
+		aead:   aead,
+		buf:    make([]byte, 0, ChunkSize),
+		sealed: make([]byte, 0, ChunkSize+TagSize),
+	}, nil
+}
+
+func (w *Writer) Write(p []byte) (n int, err error) {
+	for len(p) > 0 {
+		// the chunk is sealed once more content follows - the last one is sealed by Close
+		if len(w.buf) == ChunkSize {
+			if err = w.seal(false); err != nil {
+				return
+			}
+		}
+		k := copy(w.buf[len(w.buf):ChunkSize], p)
+		w.buf = w.buf[:len(w.buf)+k]
+		p = p[k:]
+		n += k
+	}
+	return
+}
+
+// Close writes the last chunk; it does not close the underlying writer
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	return w.seal(true)
+}
+
+func (w *Writer) seal(last bool) error {
+	var b [12]byte
+	w.sealed = w.aead.Seal(w.sealed[:0], nonce(w.idx, b[:]), w.buf, aad(last))
+	w.buf = w.buf[:0]
+	w.idx++
+	_, err := w.w.Write(w.sealed)
+	return err
+}
+
+//
+// Reader
+//
+
+// NewReader returns the reader of plaintext given the encrypted content and its size
+func NewReader(r io.ReaderAt, encSize int64, key []byte) (*Reader, error) {
+	size := PlainSize(encSize)
+	if size < 0 {
+		return nil, fmt.Errorf("invalid size of encrypted content %d", encSize)
+	}
+	var hdr [HeaderSize]byte
+	if _, err := r.ReadAt(hdr[:], 0); err != nil {
+		return nil, err
+	}
+	if string(hdr[:len(magic)]) != magic || hdr[len(magic)] != version {
+		return nil, errors.New("unsupported format of encrypted content")
+	}
+	aead, err := newAEAD(key, hdr[HeaderSize-saltSize:])
+	if err != nil {
+		return nil, err
+	}
+	chunks := (size + ChunkSize - 1) / ChunkSize
+	if chunks == 0 {
+		chunks = 1
+	}
+	return &Reader{
+		r:      r,
+		aead:   aead,
+		size:   size,
+		chunks: chunks,
+		cached: -1,
+		buf:    make([]byte, 0, ChunkSize),
+		sealed: make([]byte, ChunkSize+TagSize),
+	}, nil
+}
+
+// Size returns the size of plaintext
+func (r *Reader) Size() int64 { return r.size }
+
+func (r *Reader) ReadAt(p []byte, off int64) (n int, err error) {
+	if off < 0 {
+		return 0, errors.New("negative offset")
+	}
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	for len(p) > 0 && off < r.size {
+		idx := off / ChunkSize
+		if err = r.load(idx); err != nil {
+			return
+		}
+		k := copy(p, r.buf[off-idx*ChunkSize:])
+		p = p[k:]
+		n += k
+		off += int64(k)
+	}
+	if len(p) > 0 {
+		err = io.EOF
+	}
+	return
+}
+
+// decrypts the chunk (under lock)
+func (r *Reader) load(idx int64) error {
+	if idx == r.cached {
+		return nil
+	}
+	var (
+		b      [12]byte
+		plain  = cmn.MinI64(ChunkSize, r.size-idx*ChunkSize)
+		sealed = r.sealed[:plain+TagSize]
+	)
+	n, err := r.r.ReadAt(sealed, HeaderSize+idx*(ChunkSize+TagSize))
+	if n < len(sealed) {
+		if err == nil || err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	r.cached = -1
+	if r.buf, err = r.aead.Open(r.buf[:0], nonce(uint64(idx), b[:]), sealed, aad(idx == r.chunks-1)); err != nil {
+		return ErrCorrupted
+	}
+	r.cached = idx
+	return nil
+}
+
+func (r *Reader) Read(p []byte) (n int, err error) {
+	n, err = r.ReadAt(p, r.off)
+	r.off += int64(n)
+	if n > 0 && err == io.EOF {
+		err = nil
+	}
+	return
+}
+
+func (r *Reader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.off
+	case io.SeekEnd:
+		offset += r.size
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+	r.off = offset
+	return offset, nil
+}
+
+//
+// File
+//
+
+// Open opens the encrypted file for reading
+func Open(fqn string, key []byte) (*File, error) {
+	file, err := os.Open(fqn)
+	if err != nil {
+		return nil, err
+	}
+	finfo, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	r, err := NewReader(file, finfo.Size(), key)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("%s: %v", fqn, err)
+	}
+	return &File{Reader: r, file: file, key: key}, nil
+}
+
+func (f *File) Name() string { return f.file.Name() }
+func (f *File) Close() error { return f.file.Close() }
+
+// Open reopens the file - to read it from the beginning
+func (f *File) Open() (io.ReadCloser, error) { return Open(f.file.Name(), f.key) }
@@ -0,0 +1,162 @@
+// Package encryption provides server-side encryption of the objects at rest
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package encryption
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NVIDIA/aistore/cmn"
+)
+
+func encrypt(t *testing.T, plain, key []byte) []byte {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// odd-sized writes - to cross chunk boundaries
+	for p := plain; len(p) > 0; {
+		k := cmn.Min(len(p), 1000+rand.Intn(ChunkSize))
+		if _, err := w.Write(p[:k]); err != nil {
+			t.Fatal(err)
+		}
+		p = p[k:]
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestRoundTrip(t *testing.T) {
+	key := make([]byte, KeySize)
+	rand.Read(key)
+	for _, size := range []int{0, 1, ChunkSize - 1, ChunkSize, ChunkSize + 1, 3*ChunkSize + 17} {
+		plain := make([]byte, size)
+		rand.Read(plain)
+		enc := encrypt(t, plain, key)
+		if int64(len(enc)) != EncryptedSize(int64(size)) {
+			t.Fatalf("size %d: expected %d encrypted bytes, got %d", size, EncryptedSize(int64(size)), len(enc))
+		}
+		if PlainSize(int64(len(enc))) != int64(size) {
+			t.Fatalf("size %d: got plain size %d", size, PlainSize(int64(len(enc))))
+		}
+		r, err := NewReader(bytes.NewReader(enc), int64(len(enc)), key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := ioutil.ReadAll(r)
+		if err != nil || !bytes.Equal(b, plain) {
+			t.Fatalf("size %d: round trip failed, err: %v", size, err)
+		}
+		// range reads
+		for i := 0; i < 10 && size > 0; i++ {
+			off := rand.Intn(size)
+			length := rand.Intn(size-off) + 1
+			b := make([]byte, length)
+			if n, err := r.ReadAt(b, int64(off)); n != length || (err != nil && err != io.EOF) {
+				t.Fatalf("size %d: ReadAt(%d, %d): %d, %v", size, off, length, n, err)
+			}
+			if !bytes.Equal(b, plain[off:off+length]) {
+				t.Fatalf("size %d: ReadAt(%d, %d): wrong content", size, off, length)
+			}
+		}
+	}
+}
+
+func TestTamper(t *testing.T) {
+	key := make([]byte, KeySize)
+	rand.Read(key)
+	plain := make([]byte, 2*ChunkSize+100)
+	rand.Read(plain)
+	enc := encrypt(t, plain, key)
+
+	read := func(enc, key []byte) error {
+		r, err := NewReader(bytes.NewReader(enc), int64(len(enc)), key)
+		if err != nil {
+			return err
+		}
+		_, err = ioutil.ReadAll(r)
+		return err
+	}
+	if err := read(enc, key); err != nil {
+		t.Fatal(err)
+	}
+	// flipped bit
+	bad := append([]byte(nil), enc...)
+	bad[HeaderSize+ChunkSize+TagSize+5] ^= 1
+	if err := read(bad, key); err != ErrCorrupted {
+		t.Fatalf("expected %v, got %v", ErrCorrupted, err)
+	}
+	// truncated at the chunk boundary: the last chunk is missing
+	bad = enc[:HeaderSize+2*(ChunkSize+TagSize)]
+	if err := read(bad, key); err != ErrCorrupted {
+		t.Fatalf("expected %v, got %v", ErrCorrupted, err)
+	}
+	// wrong key
+	other := make([]byte, KeySize)
+	rand.Read(other)
+	if err := read(enc, other); err != ErrCorrupted {
+		t.Fatalf("expected %v, got %v", ErrCorrupted, err)
+	}
+}
+
+func TestFileProvider(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	var (
+		path = filepath.Join(dir, "keys.json")
+		k1   = bytes.Repeat([]byte{1}, KeySize)
+		k2   = bytes.Repeat([]byte{2}, KeySize)
+	)
+	write := func(keys map[string][]byte) {
+		b := []byte("{")
+		for id, key := range keys {
+			if len(b) > 1 {
+				b = append(b, ',')
+			}
+			b = append(b, `"`+id+`":"`+base64.StdEncoding.EncodeToString(key)+`"`...)
+		}
+		if err := ioutil.WriteFile(path, append(b, '}'), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(map[string][]byte{"k1": k1})
+	if err := Init(&cmn.KeyProviderConf{Provider: cmn.KeyProviderFile, KeyFile: path}); err != nil {
+		t.Fatal(err)
+	}
+	defer Init(&cmn.KeyProviderConf{})
+
+	if key, err := Key("k1"); err != nil || !bytes.Equal(key, k1) {
+		t.Fatalf("expected key k1, got %v", err)
+	}
+	if _, err := Key("k2"); err == nil {
+		t.Fatalf("expected key k2 not to be found")
+	}
+	// rotation: the new key is picked up without restart - but the key file
+	// is not re-read more often than keyReloadInterval
+	write(map[string][]byte{"k1": k1, "k2": k2})
+	if _, err := Key("k2"); err == nil {
+		t.Fatalf("expected key k2 not to be found until the reload interval elapses")
+	}
+	p := provider.(*fileProvider)
+	p.reload.Lock()
+	p.reloaded = time.Now().Add(-keyReloadInterval)
+	p.reload.Unlock()
+	if key, err := Key("k2"); err != nil || !bytes.Equal(key, k2) {
+		t.Fatalf("expected key k2, got %v", err)
+	}
+}
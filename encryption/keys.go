@@ -0,0 +1,156 @@
+// Package encryption provides server-side encryption of the objects at rest
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package encryption
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"github.com/NVIDIA/aistore/cmn"
+	jsoniter "github.com/json-iterator/go"
+)
+
+type (
+	// KeyProvider returns the (master) key by its ID; the keys are never
+	// stored with the objects - only their IDs are (see cluster.LOM.KeyID)
+	KeyProvider interface {
+		Key(id string) ([]byte, error)
+	}
+	// NewProvider creates the key provider given the configuration
+	NewProvider func(conf *cmn.KeyProviderConf) (KeyProvider, error)
+
+	// fileProvider reads the keys from the local JSON file:
+	// {"<key ID>": "<base64-encoded 32-byte key>", ...}
+	fileProvider struct {
+		mtx      sync.RWMutex
+		path     string
+		keys     map[string][]byte
+		reload   sync.Mutex // serializes reloads
+		reloaded time.Time  // last reload attempt (under reload)
+	}
+)
+
+// the key file is re-read at most this often - when an unknown key is requested
+const keyReloadInterval = 10 * time.Second
+
+var (
+	ErrNoProvider = errors.New("no key provider configured")
+
+	providers = map[string]NewProvider{cmn.KeyProviderFile: newFileProvider}
+	provider  KeyProvider
+	mtx       sync.RWMutex
+)
+
+// RegisterProvider makes the key provider available via configuration (see cmn.KeyProviderConf)
+func RegisterProvider(name string, newProvider NewProvider) {
+	mtx.Lock()
+	providers[name] = newProvider
+	mtx.Unlock()
+}
+
+// Init creates the configured key provider
+func Init(conf *cmn.KeyProviderConf) (err error) {
+	var p KeyProvider
+	mtx.Lock()
+	defer mtx.Unlock()
+	if conf.Provider != "" {
+		newProvider, ok := providers[conf.Provider]
+		if !ok {
+			return fmt.Errorf("unknown key provider %q", conf.Provider)
+		}
+		if p, err = newProvider(conf); err != nil {
+			return
+		}
+	}
+	provider = p
+	return
+}
+
+// Key returns the key by its ID from the configured key provider
+func Key(id string) ([]byte, error) {
+	mtx.RLock()
+	p := provider
+	mtx.RUnlock()
+	if p == nil {
+		return nil, ErrNoProvider
+	}
+	key, err := p.Key(id)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("key %q: invalid size %d (expecting %d)", id, len(key), KeySize)
+	}
+	return key, nil
+}
+
+//
+// fileProvider
+//
+
+func newFileProvider(conf *cmn.KeyProviderConf) (KeyProvider, error) {
+	p := &fileProvider{path: conf.KeyFile, reloaded: time.Now()}
+	if err := p.load(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *fileProvider) load() error {
+	b, err := ioutil.ReadFile(p.path)
+	if err != nil {
+		return err
+	}
+	encoded := make(map[string]string)
+	if err = jsoniter.Unmarshal(b, &encoded); err != nil {
+		return fmt.Errorf("%s: %v", p.path, err)
+	}
+	keys := make(map[string][]byte, len(encoded))
+	for id, s := range encoded {
+		if keys[id], err = base64.StdEncoding.DecodeString(s); err != nil {
+			return fmt.Errorf("%s: key %q: %v", p.path, id, err)
+		}
+	}
+	p.mtx.Lock()
+	p.keys = keys
+	p.mtx.Unlock()
+	return nil
+}
+
+func (p *fileProvider) Key(id string) ([]byte, error) {
+	p.mtx.RLock()
+	key, ok := p.keys[id]
+	p.mtx.RUnlock()
+	if ok {
+		return key, nil
+	}
+	// the key may have been added (rotation) since loaded
+	if err := p.reloadIfDue(); err != nil {
+		return nil, err
+	}
+	p.mtx.RLock()
+	key, ok = p.keys[id]
+	p.mtx.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("key %q not found in %s", id, p.path)
+	}
+	return key, nil
+}
+
+// re-reads the key file unless it has been (attempted to be) re-read within
+// keyReloadInterval - requests for unknown keys must not hit the file every time
+func (p *fileProvider) reloadIfDue() error {
+	p.reload.Lock()
+	defer p.reload.Unlock()
+	if time.Since(p.reloaded) < keyReloadInterval {
+		return nil
+	}
+	p.reloaded = time.Now()
+	return p.load()
+}
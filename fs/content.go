@@ -32,8 +32,10 @@ import (
  */
 
 const (
-	ObjectType   = "obj"
-	WorkfileType = "work"
+	ObjectType    = "obj"
+	WorkfileType  = "work"
+	VersionType   = "ver" // older (superseded) versions of the objects
+	WriteBackType = "wb"  // markers of the Cloud objects pending (asynchronous) upload
 )
 
 type (
@@ -191,8 +193,10 @@ func (f *ContentSpecMgr) PermToProcess(fqn string) (ok bool) {
 // FIXME: This should be probably placed somewhere else \/
 
 type (
-	ObjectContentResolver   struct{}
-	WorkfileContentResolver struct{}
+	ObjectContentResolver    struct{}
+	WorkfileContentResolver  struct{}
+	VersionContentResolver   struct{}
+	WriteBackContentResolver struct{}
 )
 
 func (wf *ObjectContentResolver) PermToMove() bool    { return true }
@@ -244,3 +248,45 @@ func (wf *WorkfileContentResolver) ParseUniqueFQN(base string) (orig string, old
 
 	return base[:tieIndex], filePID != pid, true
 }
+
+func (vr *VersionContentResolver) PermToMove() bool    { return false }
+func (vr *VersionContentResolver) PermToEvict() bool   { return true }
+func (vr *VersionContentResolver) PermToProcess() bool { return false }
+
+// GenUniqueFQN appends the version (prefix) to the object name: <base>.v<version>
+func (vr *VersionContentResolver) GenUniqueFQN(base, prefix string) string {
+	return base + ".v" + prefix
+}
+
+func (vr *VersionContentResolver) ParseUniqueFQN(base string) (orig string, old bool, ok bool) {
+	orig, _, ok = ParseVersionFQN(base)
+	return
+}
+
+// ParseVersionFQN splits the (base) name generated by VersionContentResolver
+// into the original name and the version
+func ParseVersionFQN(base string) (orig, version string, ok bool) {
+	idx := strings.LastIndex(base, ".v")
+	if idx <= 0 || idx+2 == len(base) {
+		return "", "", false
+	}
+	version = base[idx+2:]
+	if _, err := strconv.ParseUint(version, 10, 64); err != nil {
+		return "", "", false
+	}
+	return base[:idx], version, true
+}
+
+// write-back markers are neither moved nor evicted: the target that wrote the
+// object is the one to upload it (see ais/tgtwriteback.go)
+func (wr *WriteBackContentResolver) PermToMove() bool    { return false }
+func (wr *WriteBackContentResolver) PermToEvict() bool   { return false }
+func (wr *WriteBackContentResolver) PermToProcess() bool { return false }
+
+func (wr *WriteBackContentResolver) GenUniqueFQN(base, prefix string) string {
+	return base
+}
+
+func (wr *WriteBackContentResolver) ParseUniqueFQN(base string) (orig string, old bool, ok bool) {
+	return base, false, true
+}
@@ -0,0 +1,38 @@
+package fs
+
+import (
+	"testing"
+)
+
+func TestParseVersionFQN(t *testing.T) {
+	tests := []struct {
+		base        string
+		wantOrig    string
+		wantVersion string
+		wantOK      bool
+	}{
+		{"objname.v1", "objname", "1", true},
+		{"obj.name.v12", "obj.name", "12", true},
+		{"objname.v3.v7", "objname.v3", "7", true},
+		{"objname.tar.v10", "objname.tar", "10", true},
+		{"objname", "", "", false},
+		{"objname.v", "", "", false},
+		{"objname.vx", "", "", false},
+		{"objname.v1.tar", "", "", false},
+		{".v1", "", "", false},
+	}
+	vr := &VersionContentResolver{}
+	for _, tt := range tests {
+		orig, version, ok := ParseVersionFQN(tt.base)
+		if orig != tt.wantOrig || version != tt.wantVersion || ok != tt.wantOK {
+			t.Errorf("ParseVersionFQN(%q) = (%q, %q, %t), want (%q, %q, %t)",
+				tt.base, orig, version, ok, tt.wantOrig, tt.wantVersion, tt.wantOK)
+		}
+		if !ok {
+			continue
+		}
+		if ufqn := vr.GenUniqueFQN(orig, version); ufqn != tt.base {
+			t.Errorf("GenUniqueFQN(%q, %q) = %q, want %q", orig, version, ufqn, tt.base)
+		}
+	}
+}
@@ -15,12 +15,15 @@ import (
 
 const (
 	// prefixes for workfiles created by various services
-	WorkfileReplication = "repl"   // replication runner
-	WorkfileRemote      = "remote" // getting object from neighbor target while rebalance is running
-	WorkfileColdget     = "cold"   // object GET: coldget
-	WorkfilePut         = "put"    // object PUT
-	WorkfileRebalance   = "reb"    // rebalance
-	WorkfileFSHC        = "fshc"   // FSHC test file
+	WorkfileReplication = "repl"    // replication runner
+	WorkfileRemote      = "remote"  // getting object from neighbor target while rebalance is running
+	WorkfileColdget     = "cold"    // object GET: coldget
+	WorkfilePut         = "put"     // object PUT
+	WorkfileRebalance   = "reb"     // rebalance
+	WorkfileFSHC        = "fshc"    // FSHC test file
+	WorkfileMultipart   = "mpu"     // part of a multipart upload
+	WorkfileAppend      = "app"     // object PUT: append
+	WorkfileArchIndex   = "archidx" // index of an archive's members
 )
 
 // MountedFS should be able to resolve FQNs
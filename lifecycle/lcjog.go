@@ -0,0 +1,158 @@
+// Package lifecycle evaluates per-bucket object lifecycle rules: deletes, evicts,
+// or transitions to the next tier the objects that are older than configured.
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package lifecycle
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NVIDIA/aistore/3rdparty/glog"
+	"github.com/NVIDIA/aistore/cluster"
+	"github.com/NVIDIA/aistore/cmn"
+	"github.com/NVIDIA/aistore/fs"
+	"github.com/NVIDIA/aistore/stats"
+)
+
+const throttleNumObjects = 16 // check mountpath utilization every so many actions
+
+// contextual lifecycle "jogger" traverses the objects of a given bucket on a
+// given mountpath and applies the first matching rule, if any
+
+func (lctx *lcctx) jog(wg *sync.WaitGroup, joggers map[string]*lcctx, errCh chan struct{}) {
+	defer wg.Done()
+	lctx.joggers = joggers
+	lctx.now = time.Now()
+	lctx.bucketDir = lctx.mpathInfo.MakePathBucket(fs.ObjectType, lctx.bucket, lctx.bckIsLocal)
+	if err := filepath.Walk(lctx.bucketDir, lctx.walk); err != nil {
+		s := err.Error()
+		if strings.Contains(s, "xaction") {
+			glog.Infof("%s: stopping traversal: %s", lctx.bucketDir, s)
+		} else if !os.IsNotExist(err) {
+			glog.Errorf("%s: failed to traverse, err: %v", lctx.bucketDir, err)
+		}
+	}
+	if lctx.numAct > 0 {
+		glog.Infof("%s: bucket %s - %d objects visited, %d lifecycle actions", lctx.mpathInfo,
+			lctx.bucket, lctx.numObjs, lctx.numAct)
+	}
+	if lctx.aborted {
+		errCh <- struct{}{}
+	}
+}
+
+func (lctx *lcctx) walk(fqn string, osfi os.FileInfo, err error) error {
+	if err != nil {
+		if errstr := cmn.PathWalkErr(err); errstr != "" {
+			glog.Error(errstr)
+			return err
+		}
+		return nil
+	}
+	if osfi.Mode().IsDir() {
+		return nil
+	}
+	if err = lctx.yieldTerm(); err != nil {
+		return err
+	}
+	bckProvider := cmn.BckProviderFromLocal(lctx.bckIsLocal)
+	lom, errstr := cluster.LOM{T: lctx.ini.T, FQN: fqn, BucketProvider: bckProvider}.Init(lctx.config)
+	if errstr != "" {
+		return nil
+	}
+	rule := lctx.match(lom.Objname)
+	if rule == nil {
+		return nil
+	}
+	if _, errstr = lom.Load(true); errstr != "" || !lom.Exists() {
+		return nil
+	}
+	// replicas go with their objects; misplaced objects are taken care of by rebalance and LRU
+	if lom.IsCopy() || lom.Misplaced() {
+		return nil
+	}
+	lctx.numObjs++
+	since := osfi.ModTime()
+	if rule.AgeBy == cmn.LifecycleAgeByAtime && lom.AtimeUnix() != 0 {
+		since = lom.Atime()
+	}
+	if lctx.now.Sub(since) < rule.Age() {
+		return nil
+	}
+	return lctx.act(lom, rule)
+}
+
+// returns the first rule that selects the object by name
+func (lctx *lcctx) match(objname string) *cmn.LifecycleRule {
+	for i := range lctx.rules {
+		if lctx.rules[i].Matches(objname) {
+			return &lctx.rules[i]
+		}
+	}
+	return nil
+}
+
+func (lctx *lcctx) act(lom *cluster.LOM, rule *cmn.LifecycleRule) error {
+	var (
+		action ActionFunc
+		name   string
+	)
+	switch rule.Action {
+	case cmn.LifecycleDelete:
+		action, name = lctx.ini.Delete, stats.LcDeleteCount
+	case cmn.LifecycleEvict:
+		action, name = lctx.ini.Evict, stats.LcEvictCount
+	case cmn.LifecycleTransition:
+		action, name = lctx.ini.Transition, stats.LcTransitCount
+	default:
+		cmn.AssertMsg(false, rule.Action)
+	}
+	if err := action(lom); err != nil {
+		if !os.IsNotExist(err) {
+			glog.Errorf("%s: rule %q (%s) failed, err: %v", lom, rule.Name, rule.Action, err)
+		}
+		return nil
+	}
+	if glog.V(4) {
+		glog.Infof("%s: rule %q (%s)", lom, rule.Name, rule.Action)
+	}
+	lctx.ini.Statsif.Add(name, 1)
+	lctx.numAct++
+	if lctx.numAct%throttleNumObjects == 0 {
+		lctx.config = cmn.GCO.Get()
+		lctx.now = time.Now()
+		lctx.throttle = !lctx.mpathInfo.IsIdle(lctx.config, lctx.now)
+	}
+	return nil
+}
+
+func (lctx *lcctx) yieldTerm() error {
+	xlc := lctx.ini.Xlc
+	select {
+	case <-xlc.ChanAbort():
+		stopAll(lctx.joggers, lctx.mpathInfo.Path)
+		lctx.aborted = true
+		return fmt.Errorf("%s aborted, exiting", xlc)
+	case <-lctx.stopCh:
+		lctx.aborted = true
+		return fmt.Errorf("%s aborted, exiting", xlc)
+	default:
+		if lctx.throttle {
+			time.Sleep(cmn.ThrottleSleepMin)
+		} else {
+			runtime.Gosched()
+		}
+		break
+	}
+	if xlc.Finished() {
+		return fmt.Errorf("%s aborted, exiting", xlc)
+	}
+	return nil
+}
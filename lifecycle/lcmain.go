@@ -0,0 +1,145 @@
+// Package lifecycle evaluates per-bucket object lifecycle rules: deletes, evicts,
+// or transitions to the next tier the objects that are older than configured.
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package lifecycle
+
+import (
+	"sync"
+	"time"
+
+	"github.com/NVIDIA/aistore/3rdparty/glog"
+	"github.com/NVIDIA/aistore/cluster"
+	"github.com/NVIDIA/aistore/cmn"
+	"github.com/NVIDIA/aistore/fs"
+	"github.com/NVIDIA/aistore/stats"
+)
+
+// ============================================= Summary ===========================================
+//
+// Lifecycle rules (cmn.LifecycleConf) are configured on a per-bucket basis. Each rule selects the
+// objects by name prefix and age - the time elapsed since the object's last access (atime) or
+// modification (mtime) - and specifies the action to apply: delete, evict (Cloud buckets only),
+// or transition to the next tier. The first rule (in the configured order) that matches a given
+// object wins.
+//
+// Lifecycle is implemented as an extended action (xaction) that gets periodically triggered
+// on each target (config.Periodic.LifecycleTime). Similar to LRU (see lru/lrujog.go), the
+// xaction runs one "jogger" per mountpath; each jogger traverses the local objects of the
+// buckets that have lifecycle rules. Unlike LRU, lifecycle does not depend on the used capacity.
+//
+// The actions themselves are provided by the target (see InitLifecycle) as they entail removing
+// the object's replicas and other associated content, deleting from the Cloud, and so on.
+//
+// ============================================= Summary ===========================================
+
+type (
+	// ActionFunc applies the lifecycle action to the object; it must take care of locking
+	ActionFunc func(lom *cluster.LOM) error
+
+	InitLifecycle struct {
+		Xlc        cmn.Xact
+		Statsif    stats.Tracker
+		T          cluster.Target
+		Delete     ActionFunc
+		Evict      ActionFunc
+		Transition ActionFunc
+	}
+
+	// lcctx represents a single lifecycle context that runs in a single goroutine (jogger)
+	// that traverses the objects of a given bucket on a given mountpath
+	lcctx struct {
+		// runtime
+		now             time.Time
+		numObjs, numAct int64
+		// init-time
+		ini        InitLifecycle
+		stopCh     chan struct{}
+		joggers    map[string]*lcctx
+		mpathInfo  *fs.MountpathInfo
+		config     *cmn.Config
+		bucket     string
+		bucketDir  string
+		bckIsLocal bool
+		rules      []cmn.LifecycleRule
+		throttle   bool
+		aborted    bool
+	}
+)
+
+// Buckets returns true if at least one bucket has lifecycle rules
+func Buckets(bmd *cluster.BMD) bool {
+	for _, m := range []map[string]*cmn.BucketProps{bmd.LBmap, bmd.CBmap} {
+		for _, props := range m {
+			if props.Lifecycle.Enabled() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+//================================ initiation ==================================
+//
+// for each bucket that has lifecycle rules: construct per-mountpath joggers and
+// run them all; serialize buckets
+//
+//==============================================================================
+
+func InitAndRun(ini *InitLifecycle) {
+	var (
+		wg     = &sync.WaitGroup{}
+		config = cmn.GCO.Get()
+		bmd    = ini.T.GetBowner().Get()
+	)
+	glog.Infof("%s started", ini.Xlc)
+	availablePaths, _ := fs.Mountpaths.Get()
+	for _, bckIsLocal := range []bool{false, true} {
+		m := bmd.CBmap
+		if bckIsLocal {
+			m = bmd.LBmap
+		}
+		for bucket, props := range m {
+			if !props.Lifecycle.Enabled() {
+				continue
+			}
+			joggers := make(map[string]*lcctx, len(availablePaths))
+			errCh := make(chan struct{}, len(availablePaths))
+			for mpath, mpathInfo := range availablePaths {
+				joggers[mpath] = newlc(ini, mpathInfo, config, bucket, bckIsLocal, props.Lifecycle.Rules)
+			}
+			for _, j := range joggers {
+				wg.Add(1)
+				go j.jog(wg, joggers, errCh)
+			}
+			wg.Wait()
+			close(errCh)
+			if _, ok := <-errCh; ok {
+				return // aborted
+			}
+		}
+	}
+}
+
+func newlc(ini *InitLifecycle, mpathInfo *fs.MountpathInfo, config *cmn.Config, bucket string, bckIsLocal bool,
+	rules []cmn.LifecycleRule) *lcctx {
+	return &lcctx{
+		ini:        *ini,
+		stopCh:     make(chan struct{}, 1),
+		mpathInfo:  mpathInfo,
+		config:     config,
+		bucket:     bucket,
+		bckIsLocal: bckIsLocal,
+		rules:      rules,
+	}
+}
+
+func stopAll(joggers map[string]*lcctx, exceptMpath string) {
+	for _, j := range joggers {
+		if j.mpathInfo.Path == exceptMpath {
+			continue
+		}
+		j.stopCh <- struct{}{}
+	}
+}
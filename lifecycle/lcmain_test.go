@@ -0,0 +1,169 @@
+// Package lifecycle evaluates per-bucket object lifecycle rules: deletes, evicts,
+// or transitions to the next tier the objects that are older than configured.
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package lifecycle
+
+import (
+	"crypto/rand"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/NVIDIA/aistore/cluster"
+	"github.com/NVIDIA/aistore/cmn"
+	"github.com/NVIDIA/aistore/fs"
+	"github.com/NVIDIA/aistore/stats"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestLifecycleMain(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Lifecycle Suite")
+}
+
+const (
+	fileSize   = cmn.KiB
+	basePath   = "/tmp/lifecycle-tests/"
+	bucketName = "lc-bck"
+	filesPath  = basePath + fs.ObjectType + "/local/" + bucketName
+)
+
+func newTargetLcMock(rules []cmn.LifecycleRule) *cluster.TargetMock {
+	bo := cluster.BownerMock{BMD: cluster.BMD{
+		LBmap: map[string]*cmn.BucketProps{
+			bucketName: {
+				BID:       cluster.BisLocalBit | 1,
+				Cksum:     cmn.CksumConf{Type: cmn.ChecksumNone},
+				Lifecycle: cmn.LifecycleConf{Rules: rules},
+			},
+		},
+	}}
+	return cluster.NewTargetMock(bo)
+}
+
+// the actions record the object names and remove the objects
+func newInitLc(t cluster.Target, acted map[string]string) *InitLifecycle {
+	action := func(name string) ActionFunc {
+		return func(lom *cluster.LOM) error {
+			acted[lom.Objname] = name
+			return os.Remove(lom.FQN)
+		}
+	}
+	return &InitLifecycle{
+		Xlc:        &xactMock{},
+		Statsif:    &statsLcMock{},
+		T:          t,
+		Delete:     action(cmn.LifecycleDelete),
+		Evict:      action(cmn.LifecycleEvict),
+		Transition: action(cmn.LifecycleTransition),
+	}
+}
+
+func createAndAddMountpath(path string) {
+	cmn.CreateDir(path)
+	fs.Mountpaths = fs.NewMountedFS()
+	fs.Mountpaths.Add(path)
+
+	fs.CSM.RegisterFileType(fs.ObjectType, &fs.ObjectContentResolver{})
+	fs.CSM.RegisterFileType(fs.WorkfileType, &fs.WorkfileContentResolver{})
+}
+
+// creates the object and sets its mtime age ago
+func saveObject(t cluster.Target, objname string, age time.Duration) {
+	var (
+		fqn  = path.Join(filesPath, objname)
+		buff = make([]byte, fileSize)
+	)
+	_, err := cmn.SaveReader(fqn, rand.Reader, buff, false, fileSize)
+	Expect(err).NotTo(HaveOccurred())
+	lom, errstr := cluster.LOM{T: t, FQN: fqn}.Init()
+	Expect(errstr).To(BeEmpty())
+	lom.SetSize(fileSize)
+	lom.IncObjectVersion()
+	Expect(lom.Persist()).NotTo(HaveOccurred())
+	mtime := time.Now().Add(-age)
+	Expect(os.Chtimes(fqn, mtime, mtime)).NotTo(HaveOccurred())
+}
+
+var _ = Describe("Lifecycle tests", func() {
+	Describe("InitAndRun", func() {
+		var (
+			acted map[string]string
+			rules = []cmn.LifecycleRule{
+				{Name: "tmp", Prefix: "tmp/", AgeStr: "1h", AgeBy: cmn.LifecycleAgeByMtime, Action: cmn.LifecycleDelete},
+				{Name: "all", AgeStr: "24h", AgeBy: cmn.LifecycleAgeByMtime, Action: cmn.LifecycleTransition},
+			}
+		)
+
+		BeforeEach(func() {
+			createAndAddMountpath(basePath)
+			cmn.CreateDir(filesPath)
+			acted = make(map[string]string)
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(basePath)
+		})
+
+		It("should not fail when there are no objects", func() {
+			t := newTargetLcMock(rules)
+			InitAndRun(newInitLc(t, acted))
+			Expect(acted).To(BeEmpty())
+		})
+
+		It("should do nothing when the bucket has no rules", func() {
+			t := newTargetLcMock(nil)
+			saveObject(t, "tmp/old", 48*time.Hour)
+
+			Expect(Buckets(t.GetBowner().Get())).To(BeFalse())
+			InitAndRun(newInitLc(t, acted))
+			Expect(acted).To(BeEmpty())
+		})
+
+		It("should apply the first matching rule to the expired objects only", func() {
+			t := newTargetLcMock(rules)
+			saveObject(t, "tmp/new", time.Minute)
+			saveObject(t, "tmp/old", 2*time.Hour)
+			saveObject(t, "tmp/oldest", 48*time.Hour)
+			saveObject(t, "data/old", 2*time.Hour)
+			saveObject(t, "data/oldest", 48*time.Hour)
+
+			Expect(Buckets(t.GetBowner().Get())).To(BeTrue())
+			InitAndRun(newInitLc(t, acted))
+			Expect(acted).To(Equal(map[string]string{
+				"tmp/old":     cmn.LifecycleDelete,
+				"tmp/oldest":  cmn.LifecycleDelete,
+				"data/oldest": cmn.LifecycleTransition,
+			}))
+			for _, objname := range []string{"tmp/new", "data/old"} {
+				_, err := os.Stat(path.Join(filesPath, objname))
+				Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	})
+})
+
+// MOCK TYPES
+
+type statsLcMock struct{}
+
+func (s *statsLcMock) Add(name string, val int64)             {}
+func (s *statsLcMock) AddErrorHTTP(method string, val int64)  {}
+func (s *statsLcMock) AddMany(namedVal64 ...stats.NamedVal64) {}
+func (s *statsLcMock) Register(name string, kind string)      {}
+
+type xactMock struct{}
+
+func (x *xactMock) ID() int64                          { return 0 }
+func (x *xactMock) Kind() string                       { return "" }
+func (x *xactMock) Bucket() string                     { return "" }
+func (x *xactMock) StartTime(s ...time.Time) time.Time { return time.Now() }
+func (x *xactMock) EndTime(e ...time.Time) time.Time   { return time.Now() }
+func (x *xactMock) String() string                     { return "" }
+func (x *xactMock) Abort()                             {}
+func (x *xactMock) ChanAbort() <-chan struct{}         { return nil }
+func (x *xactMock) Finished() bool                     { return false }
@@ -28,6 +28,10 @@ import (
 func (lctx *lructx) jog(wg *sync.WaitGroup, joggers map[string]*lructx, errCh chan struct{}) {
 	defer wg.Done()
 	lctx.bckTypeDir = lctx.mpathInfo.MakePath(lctx.contentType, lctx.bckIsLocal)
+	if lctx.contentType == fs.VersionType {
+		lctx.jogVersions(joggers, errCh)
+		return
+	}
 	if err := lctx.evictSize(); err != nil {
 		return
 	}
@@ -109,6 +113,11 @@ func (lctx *lructx) walk(fqn string, osfi os.FileInfo, err error) error {
 		return nil
 	}
 
+	// not yet uploaded to the Cloud (see cmn.WriteBackConf)
+	if !lom.BckIsLocal && lom.WriteBackPending() {
+		return nil
+	}
+
 	// includes post-rebalancing cleanup
 	if lom.Misplaced() {
 		glog.Infof("misplaced: %s, fqn=%s", lom, fqn)
@@ -204,6 +213,11 @@ func (lctx *lructx) postRemove(capCheck int64, fi *fileInfo) (int64, error) {
 
 func (lctx *lructx) evictObj(fi *fileInfo) (ok bool) {
 	lctx.ini.Namelocker.Lock(fi.lom.Uname(), true)
+	// overwritten (write-back) since the walk
+	if !fi.lom.BckIsLocal && fi.lom.WriteBackPending() {
+		lctx.ini.Namelocker.Unlock(fi.lom.Uname(), true)
+		return
+	}
 	// local replica must be go with the object; the replica, however, is
 	// located in a different local FS and belongs, therefore, to a different LRU jogger
 	// (hence, precise size accounting TODO)
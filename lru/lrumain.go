@@ -30,6 +30,10 @@ import (
 // runs automatically. In order to reduce its impact on the live workload, LRU throttles itself
 // in accordance with the current storage-target's utilization (see xaction_throttle.go).
 //
+// In addition, each LRU run enforces the per-bucket limits on the retained older
+// versions of the objects (VersionConf.MaxVersions and VersionConf.Retention),
+// regardless of the capacity usage (see lruver.go).
+//
 // There's only one API that this module provides to the rest of the code:
 //   - runLRU - to initiate a new LRU extended action on the local target
 // All other methods are private to this module and are used only internally.
@@ -69,6 +73,7 @@ type (
 		newest  time.Time
 		heap    *fileInfoMinHeap
 		oldwork []*fileInfo
+		verSeen map[string]struct{} // objects whose older versions are already trimmed
 		// init-time
 		ini             InitLRU
 		stopCh          chan struct{}
@@ -103,7 +108,7 @@ func InitAndRun(ini *InitLRU) {
 			continue
 		}
 		// TODO: extend LRU for other content types
-		if contentType != fs.WorkfileType && contentType != fs.ObjectType {
+		if contentType != fs.WorkfileType && contentType != fs.ObjectType && contentType != fs.VersionType {
 			glog.Warningf("Skipping content type %q", contentType)
 			continue
 		}
@@ -132,6 +137,15 @@ func InitAndRun(ini *InitLRU) {
 			return
 		}
 
+		// older versions are retained for local buckets only and are subject
+		// to the bucket's history limits rather than capacity watermarks
+		if contentType == fs.VersionType {
+			if aborted := startLRUJoggers(true /*local*/); aborted {
+				break
+			}
+			continue
+		}
+
 		if aborted := startLRUJoggers(false /*cloud*/); aborted {
 			break
 		}
@@ -0,0 +1,115 @@
+// Package lru provides least recently used cache replacement policy for stored objects
+// and serves as a generic garbage-collection mechanism for orhaned workfiles.
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package lru
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/NVIDIA/aistore/3rdparty/glog"
+	"github.com/NVIDIA/aistore/cluster"
+	"github.com/NVIDIA/aistore/cmn"
+	"github.com/NVIDIA/aistore/fs"
+	"github.com/NVIDIA/aistore/stats"
+)
+
+// versions "jogger" traverses the older versions of the objects in local buckets
+// (see cluster/lom_ver.go) and removes those that:
+//   - exceed the bucket's VersionConf.MaxVersions (all of them when versioning is disabled);
+//   - have been superseded longer than VersionConf.Retention ago.
+// Versions stored on a mountpath other than the object's one (e.g., left behind
+// by an interrupted local rebalance) are moved to the object. Versions of the
+// objects that are not stored locally are not orphaned: the object may be
+// elsewhere (e.g., being rebalanced), while deleting the object deletes its
+// versions as well (see cluster.LOM.DelAllVersions).
+
+func (lctx *lructx) jogVersions(joggers map[string]*lructx, errCh chan struct{}) {
+	lctx.joggers = joggers
+	lctx.verSeen = make(map[string]struct{}, 64)
+	if err := filepath.Walk(lctx.bckTypeDir, lctx.walkVersions); err != nil {
+		s := err.Error()
+		if strings.Contains(s, "xaction") {
+			glog.Infof("%s: stopping traversal: %s", lctx.bckTypeDir, s)
+		} else {
+			glog.Errorf("%s: failed to traverse, err: %v", lctx.bckTypeDir, err)
+		}
+	}
+	lctx.verSeen = nil
+	if lctx.aborted {
+		errCh <- struct{}{}
+	}
+}
+
+func (lctx *lructx) walkVersions(fqn string, osfi os.FileInfo, err error) error {
+	if err != nil {
+		if errstr := cmn.PathWalkErr(err); errstr != "" {
+			glog.Error(errstr)
+			return err
+		}
+		return nil
+	}
+	if osfi.Mode().IsDir() {
+		return nil
+	}
+	if err = lctx.yieldTerm(); err != nil {
+		return err
+	}
+	parsedFQN, err := fs.Mountpaths.FQN2Info(fqn)
+	if err != nil {
+		return nil
+	}
+	dir, base := filepath.Split(parsedFQN.Objname)
+	orig, version, ok := fs.ParseVersionFQN(base)
+	if !ok {
+		return nil
+	}
+	objname := dir + orig
+	lom, errstr := cluster.LOM{T: lctx.ini.T, Bucket: parsedFQN.Bucket, Objname: objname,
+		BucketProvider: cmn.LocalBs}.Init(lctx.config)
+	if errstr != "" {
+		return nil
+	}
+	lctx.ini.Namelocker.Lock(lom.Uname(), true)
+	defer lctx.ini.Namelocker.Unlock(lom.Uname(), true)
+
+	if _, errstr = lom.Load(true); errstr != "" {
+		return nil
+	}
+	if lom.VerFQN(version) != fqn {
+		if lom.Exists() {
+			if err := lom.AdoptVersion(fqn, version, nil); err != nil {
+				glog.Errorf("%s: failed to move version %s, err: %v", lom, version, err)
+			}
+		}
+		return nil
+	}
+	if _, ok := lctx.verSeen[objname]; ok {
+		return nil
+	}
+	lctx.verSeen[objname] = struct{}{}
+
+	var (
+		verConf     = lom.VerConf()
+		maxVersions = verConf.MaxVersions
+	)
+	if !verConf.Enabled {
+		maxVersions = 0
+	}
+	n, size, errstr := lom.TrimVersions(maxVersions, verConf.Retention())
+	if errstr != "" {
+		glog.Warningln(errstr)
+	}
+	if n > 0 {
+		if glog.V(4) {
+			glog.Infof("Removed %d older versions of %s", n, lom)
+		}
+		lctx.ini.Statsif.AddMany(
+			stats.NamedVal64{Name: stats.LruEvictCount, Val: n},
+			stats.NamedVal64{Name: stats.LruEvictSize, Val: size})
+	}
+	return nil
+}
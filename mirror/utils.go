@@ -5,6 +5,7 @@
 package mirror
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/NVIDIA/aistore/3rdparty/glog"
@@ -21,24 +22,11 @@ type mpather interface {
 
 func findLeastUtilized(lom *cluster.LOM, mpathers map[string]mpather) (out mpather) {
 	var util int64 = 101
-loop:
 	for _, j := range mpathers {
 		mpathInfo := j.mountpathInfo()
-		if mpathInfo.Path == lom.ParsedFQN.MpathInfo.Path {
+		if !canCopyTo(lom, mpathInfo) {
 			continue
 		}
-		if lom.HasCopies() {
-			for _, cpyfqn := range lom.CopyFQN() {
-				parsedFQN, err := fs.Mountpaths.FQN2Info(cpyfqn) // can be optimized via lom.init
-				if err != nil {
-					glog.Errorf("%s: failed to parse copyFQN %s, err: %v", lom, cpyfqn, err)
-					continue loop
-				}
-				if mpathInfo.Path == parsedFQN.MpathInfo.Path {
-					continue loop
-				}
-			}
-		}
 		if u := fs.Mountpaths.Iostats.GetDiskUtil(mpathInfo.Path); u < util {
 			out = j
 			util = u
@@ -47,6 +35,55 @@ loop:
 	return
 }
 
+// returns true if the mountpath stores neither the object nor its copies
+func canCopyTo(lom *cluster.LOM, mpathInfo *fs.MountpathInfo) bool {
+	if mpathInfo.Path == lom.ParsedFQN.MpathInfo.Path {
+		return false
+	}
+	if lom.HasCopies() {
+		for _, cpyfqn := range lom.CopyFQN() {
+			parsedFQN, err := fs.Mountpaths.FQN2Info(cpyfqn) // can be optimized via lom.init
+			if err != nil {
+				glog.Errorf("%s: failed to parse copyFQN %s, err: %v", lom, cpyfqn, err)
+				return false
+			}
+			if mpathInfo.Path == parsedFQN.MpathInfo.Path {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// MakeCopies synchronously copies the object to the least utilized mountpaths
+// until it has the given number of copies (e.g., to have the object mirrored
+// before its PUT is acknowledged); must be called under the object's lock
+func MakeCopies(lom *cluster.LOM, copies int, buf []byte) error {
+	availablePaths, _ := fs.Mountpaths.Get()
+	for lom.NumCopies() < copies {
+		var (
+			out  *fs.MountpathInfo
+			util int64 = 101
+		)
+		for _, mpathInfo := range availablePaths {
+			if !canCopyTo(lom, mpathInfo) {
+				continue
+			}
+			if u := fs.Mountpaths.Iostats.GetDiskUtil(mpathInfo.Path); u < util {
+				out = mpathInfo
+				util = u
+			}
+		}
+		if out == nil {
+			return fmt.Errorf("%s: insufficient mountpaths for %d copies", lom, copies)
+		}
+		if err := copyTo(lom, out, buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func copyTo(lom *cluster.LOM, mpathInfo *fs.MountpathInfo, buf []byte) (err error) {
 	mp := lom.ParsedFQN.MpathInfo
 	lom.ParsedFQN.MpathInfo = mpathInfo // to generate work fname
@@ -41,6 +41,7 @@ type (
 		num, size int64
 		stopCh    chan struct{}
 		callback  func(lom *cluster.LOM) error
+		finish    func() // optional: runs upon traversal, prior to signaling done
 	}
 )
 
@@ -116,6 +117,9 @@ func (j *joggerBckBase) jog() {
 			glog.Errorln(err)
 		}
 	}
+	if j.finish != nil {
+		j.finish()
+	}
 	j.parent.DoneCh() <- struct{}{}
 }
 
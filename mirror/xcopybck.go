@@ -0,0 +1,172 @@
+// Package mirror provides local mirroring and replica management
+/*
+ * Copyright (c) 2019, NVIDIA CORPORATION. All rights reserved.
+ */
+package mirror
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/NVIDIA/aistore/3rdparty/atomic"
+	"github.com/NVIDIA/aistore/3rdparty/glog"
+	"github.com/NVIDIA/aistore/cluster"
+	"github.com/NVIDIA/aistore/cmn"
+	"github.com/NVIDIA/aistore/fs"
+)
+
+// XactBckCopy runs in a background, traverses all local mountpaths, and copies
+// the bucket's objects that pass the filter into the destination bucket. The
+// copying itself (including locating the destination target and transferring the
+// object over the network) is delegated to the caller-provided send function.
+// For a Cloud bucket, the caller-provided fetch function first brings in the
+// objects that are not stored locally, so that the traversal copies them as well.
+
+type (
+	// CopySendFunc sends (copies) the object to its destination and, once done,
+	// calls the callback - asynchronously or otherwise - unless it returns an error
+	CopySendFunc func(lom *cluster.LOM, cb func(err error)) error
+	// CopyFetchFunc fetches the source objects that are missing locally and returns
+	// the number of objects it failed to fetch; an error aborts the copying
+	CopyFetchFunc func(x *XactBckCopy) (failed int64, err error)
+
+	XactBckCopy struct {
+		xactBckBase
+		dstBucket string
+		match     func(objname string) bool
+		fetch     CopyFetchFunc
+		send      CopySendFunc
+		// stats
+		objects, bytes atomic.Int64
+		errs           atomic.Int64
+	}
+	xcopyBckJogger struct { // one per mountpath
+		joggerBckBase
+		parent *XactBckCopy
+		wg     sync.WaitGroup // in-flight objects
+	}
+)
+
+//
+// public methods
+//
+
+func NewXactBckCopy(id int64, bucket, dstBucket string, t cluster.Target, local bool,
+	match func(objname string) bool, fetch CopyFetchFunc, send CopySendFunc) *XactBckCopy {
+	return &XactBckCopy{
+		xactBckBase: *newXactBckBase(id, cmn.ActCopyBucket, bucket, t, local),
+		dstBucket:   dstBucket,
+		match:       match,
+		fetch:       fetch,
+		send:        send,
+	}
+}
+
+func (r *XactBckCopy) Run() (err error) {
+	var numjs int
+	if r.fetch != nil {
+		var failed int64
+		failed, err = r.fetch(r)
+		r.errs.Add(failed)
+		if err != nil {
+			glog.Errorf("%s: %v", r, err)
+			r.EndTime(time.Now())
+			return err
+		}
+	}
+	if numjs, err = r.init(); err != nil {
+		return
+	}
+	glog.Infoln(r.String(), "=>", r.dstBucket)
+	err = r.xactBckBase.run(numjs)
+	glog.Infof("%s => %s: copied %d objects (%s), failed %d", r, r.dstBucket, r.objects.Load(),
+		cmn.B2S(r.bytes.Load(), 1), r.errs.Load())
+	return
+}
+
+func (r *XactBckCopy) DstBucket() string { return r.dstBucket }
+func (r *XactBckCopy) ObjectsCnt() int64 { return r.objects.Load() }
+func (r *XactBckCopy) BytesCnt() int64   { return r.bytes.Load() }
+func (r *XactBckCopy) ErrorsCnt() int64  { return r.errs.Load() }
+
+//
+// private methods
+//
+
+func (r *XactBckCopy) init() (numjs int, err error) {
+	availablePaths, _ := fs.Mountpaths.Get()
+	if len(availablePaths) == 0 {
+		err = fmt.Errorf("%s: no mountpaths", r)
+		return
+	}
+	r.xactBckBase.init(availablePaths)
+	numjs = len(availablePaths)
+	config := cmn.GCO.Get()
+	for _, mpathInfo := range availablePaths {
+		xcopyBckJogger := newXcopyBckJogger(r, mpathInfo, config)
+		mpathLC := mpathInfo.MakePath(fs.ObjectType, r.BckIsLocal())
+		r.mpathers[mpathLC] = xcopyBckJogger
+		go xcopyBckJogger.jog()
+	}
+	return
+}
+
+//
+// mpath xcopyBckJogger - as mpather
+//
+
+func newXcopyBckJogger(parent *XactBckCopy, mpathInfo *fs.MountpathInfo, config *cmn.Config) *xcopyBckJogger {
+	jbase := joggerBckBase{parent: &parent.xactBckBase, mpathInfo: mpathInfo, config: config}
+	j := &xcopyBckJogger{joggerBckBase: jbase, parent: parent}
+	j.joggerBckBase.callback = j.copyObj
+	j.joggerBckBase.finish = j.wg.Wait
+	return j
+}
+
+//
+// mpath xcopyBckJogger - main
+//
+func (j *xcopyBckJogger) jog() {
+	glog.Infof("jogger[%s/%s] started", j.mpathInfo, j.parent.Bucket())
+	j.joggerBckBase.jog()
+}
+
+func (j *xcopyBckJogger) copyObj(lom *cluster.LOM) error {
+	if j.parent.match != nil && !j.parent.match(lom.Objname) {
+		return nil
+	}
+	size := lom.Size()
+	j.wg.Add(1)
+	cb := func(err error) {
+		if err != nil {
+			j.parent.errs.Inc()
+			glog.Errorf("%s: failed to copy %s => %s, err: %v", j.parent, lom, j.parent.dstBucket, err)
+		} else {
+			j.parent.objects.Inc()
+			j.parent.bytes.Add(size)
+		}
+		j.wg.Done()
+	}
+	if err := j.parent.send(lom, cb); err != nil {
+		cb(err)
+	}
+	j.num++
+	j.size += size
+	if (j.num % throttleNumObjects) == 0 {
+		if j.size > minThrottleSize*throttleNumObjects {
+			j.size = 0
+			if errstop := j.yieldTerm(); errstop != nil {
+				return errstop
+			}
+		}
+		if (j.num % logNumProcessed) == 0 {
+			glog.Infof("jogger[%s/%s] processed %d objects...", j.mpathInfo, j.parent.Bucket(), j.num)
+			j.config = cmn.GCO.Get()
+		}
+	} else {
+		runtime.Gosched()
+	}
+	return nil
+}
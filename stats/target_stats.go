@@ -44,6 +44,25 @@ const (
 	RebLocalSize     = "reb.local.size"
 	ReplPutCount     = "repl.n"
 	DownloadSize     = "dl.size"
+	LcDeleteCount    = "lifecycle.delete.n"
+	LcEvictCount     = "lifecycle.evict.n"
+	LcTransitCount   = "lifecycle.transition.n"
+
+	// KindCounter - write-back uploads (see cmn.WriteBackConf)
+	WriteBackCount    = "wb.n"
+	WriteBackSize     = "wb.size"
+	ErrWriteBackCount = "err.wb.n"
+
+	// KindCounter - multi-tiering (see cmn.BucketProps.NextTierURL)
+	TierHitCount      = "tier.hit.n"      // objects read from the next tier
+	TierMissCount     = "tier.miss.n"     // objects the next tier failed to provide
+	TierPutCount      = "tier.put.n"      // PUTs forwarded to the next tier
+	TierFallbackCount = "tier.fallback.n" // requests not forwarded: next tier down or failed (Cloud buckets)
+
+	// KindCounter - Cloud requests (see cmn.CloudReqConf)
+	CloudThrottleCount    = "cloud.throttle.n"     // requests delayed by the rate limiter
+	CloudRetryCount       = "cloud.retry.n"        // requests retried
+	ErrCloudThrottleCount = "err.cloud.throttle.n" // requests throttled by the Cloud (429, 503)
 
 	// KindLatency
 	PutLatency      = "put.µs"
@@ -54,6 +73,10 @@ const (
 
 	// KindThroughput
 	GetThroughput = "get.bps" // bytes per second
+
+	// KindSpecial - current number and size of the objects pending write-back
+	WriteBackBacklogCount = "wb.backlog.n"
+	WriteBackBacklogSize  = "wb.backlog.size"
 )
 
 //
@@ -68,6 +91,7 @@ type (
 		// inner state
 		timecounts struct {
 			capLimit, capIdx int64 // update capacity: time interval counting
+			lcLimit, lcIdx   int64 // run bucket lifecycle rules: ditto
 		}
 		lines []string
 	}
@@ -106,6 +130,7 @@ func (r *Trunner) Init(daemonStr, daemonID string) {
 	config := cmn.GCO.Get()
 	r.Core.statsTime = config.Periodic.StatsTime
 	r.timecounts.capLimit = cmn.DivCeil(int64(config.LRU.CapacityUpdTime), int64(config.Periodic.StatsTime))
+	r.timecounts.lcLimit = cmn.DivCeil(int64(config.Periodic.LifecycleTime), int64(config.Periodic.StatsTime))
 	r.statsRunner.logLimit = cmn.DivCeil(int64(logsMaxSizeCheckTime), int64(config.Periodic.StatsTime))
 	// subscribe to config changes
 	cmn.GCO.Subscribe(r)
@@ -115,6 +140,7 @@ func (r *Trunner) ConfigUpdate(oldConf, newConf *cmn.Config) {
 	r.statsRunner.ConfigUpdate(oldConf, newConf)
 	r.Core.statsTime = newConf.Periodic.StatsTime
 	r.timecounts.capLimit = cmn.DivCeil(int64(newConf.LRU.CapacityUpdTime), int64(newConf.Periodic.StatsTime))
+	r.timecounts.lcLimit = cmn.DivCeil(int64(newConf.Periodic.LifecycleTime), int64(newConf.Periodic.StatsTime))
 }
 
 func (r *Trunner) GetWhatStats() ([]byte, error) {
@@ -128,6 +154,9 @@ func (r *Trunner) GetWhatStats() ([]byte, error) {
 func (r *Trunner) log() (runlru bool) {
 	// copy stats values while skipping zeros; reset latency stats
 	r.Core.Tracker[Uptime].Value = int64(time.Since(r.starttime) / time.Microsecond)
+	if v, ok := r.Core.Tracker[WriteBackBacklogCount]; ok {
+		v.Value, r.Core.Tracker[WriteBackBacklogSize].Value = r.T.WriteBackBacklog()
+	}
 	r.Core.copyZeroReset(r.ctracker)
 
 	r.lines = r.lines[:0]
@@ -174,6 +203,13 @@ func (r *Trunner) housekeep(runlru bool) {
 		go r.T.Prefetch()
 	}
 
+	// Evaluate bucket lifecycle rules
+	r.timecounts.lcIdx++
+	if r.timecounts.lcIdx >= r.timecounts.lcLimit {
+		r.timecounts.lcIdx = 0
+		go r.T.RunLifecycle()
+	}
+
 	r.statsRunner.housekeep(runlru)
 }
 
@@ -87,6 +87,54 @@ func (s *RebalanceTargetStats) FillFromTrunner(r *Trunner) {
 	vr.RUnlock()
 }
 
+type CopyBckTargetStats struct {
+	BaseXactStats
+	Ext ExtCopyBckStats `json:"ext"`
+}
+
+type ExtCopyBckStats struct {
+	DstBucket      string `json:"dst_bucket"`
+	NumCopiedFiles int64  `json:"num_copied_files"`
+	NumCopiedBytes int64  `json:"num_copied_bytes"`
+	NumErrors      int64  `json:"num_errors"`
+}
+
+type SyncBckTargetStats struct {
+	BaseXactStats
+	Ext ExtSyncBckStats `json:"ext"`
+}
+
+type ExtSyncBckStats struct {
+	NumFetchedFiles int64 `json:"num_fetched_files"`
+	NumFetchedBytes int64 `json:"num_fetched_bytes"`
+	NumEvictedFiles int64 `json:"num_evicted_files"`
+	NumErrors       int64 `json:"num_errors"`
+}
+
+type ScrubECTargetStats struct {
+	BaseXactStats
+	Ext ExtScrubECStats `json:"ext"`
+}
+
+type EncodeECTargetStats struct {
+	BaseXactStats
+	Ext ExtEncodeECStats `json:"ext"`
+}
+
+type ExtEncodeECStats struct {
+	NumEncoded   int64 `json:"num_encoded"`
+	NumReEncoded int64 `json:"num_reencoded"`
+	NumErrors    int64 `json:"num_errors"`
+}
+
+type ExtScrubECStats struct {
+	NumObjs      int64 `json:"num_objs"`
+	NumMissing   int64 `json:"num_missing"`
+	NumCorrupted int64 `json:"num_corrupted"`
+	NumRepaired  int64 `json:"num_repaired"`
+	NumErrors    int64 `json:"num_errors"`
+}
+
 type PrefetchTargetStats struct {
 	BaseXactStats
 	Ext ExtPrefetchStats `json:"ext"`
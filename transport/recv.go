@@ -363,5 +363,15 @@ func extAttrs(off int, from []byte) (n int, attr ObjectAttrs) {
 	off, attr.CksumType = extString(off, from)
 	off, attr.CksumValue = extString(off, from)
 	off, attr.Version = extString(off, from)
+	off, cnt := extInt64(off, from)
+	if cnt > 0 {
+		attr.CustomMD = make(cmn.SimpleKVs, cnt)
+		for i := int64(0); i < cnt; i++ {
+			var k, v string
+			off, k = extString(off, from)
+			off, v = extString(off, from)
+			attr.CustomMD[k] = v
+		}
+	}
 	return off, attr
 }
@@ -28,7 +28,7 @@ import (
 
 // transport defaults
 const (
-	maxHeaderSize  = 1024
+	maxHeaderSize  = 8 * cmn.KiB // NOTE: must accommodate user-defined metadata (see cmn.MaxCustomMDSize)
 	lastMarker     = cmn.MaxInt64
 	tickMarker     = cmn.MaxInt64 ^ 0xa5a5a5a5
 	tickUnit       = time.Second
@@ -111,7 +111,8 @@ type (
 		Size       int64  // size of objects in bytes
 		CksumType  string // checksum type
 		CksumValue string // checksum of the object produced by given checksum type
-		Version    string // version of the object
+		Version    string        // version of the object
+		CustomMD   cmn.SimpleKVs // user-defined metadata
 	}
 
 	// object header
@@ -817,6 +818,11 @@ func insAttrs(off int, to []byte, attr ObjectAttrs) int {
 	off = insString(off, to, attr.CksumType)
 	off = insString(off, to, attr.CksumValue)
 	off = insString(off, to, attr.Version)
+	off = insInt64(off, to, int64(len(attr.CustomMD)))
+	for k, v := range attr.CustomMD {
+		off = insString(off, to, k)
+		off = insString(off, to, v)
+	}
 	return off
 }
 
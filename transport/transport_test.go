@@ -118,8 +118,8 @@ func Example_headers() {
 	sendText(stream, text1, text2)
 	stream.Fin()
 	// Output:
-	// {Bucket:abc Objname:X IsLocal:false Opaque:[] ObjAttrs:{Atime:663346294 Size:231 CksumType:xxhash CksumValue:hash Version:2}} (96)
-	// {Bucket:abracadabra Objname:p/q/s IsLocal:true Opaque:[49 50 51] ObjAttrs:{Atime:663346294 Size:213 CksumType:xxhash CksumValue:hash Version:2}} (111)
+	// {Bucket:abc Objname:X IsLocal:false Opaque:[] ObjAttrs:{Atime:663346294 Size:231 CksumType:xxhash CksumValue:hash Version:2 CustomMD:map[]}} (104)
+	// {Bucket:abracadabra Objname:p/q/s IsLocal:true Opaque:[49 50 51] ObjAttrs:{Atime:663346294 Size:213 CksumType:xxhash CksumValue:hash Version:2 CustomMD:map[]}} (119)
 }
 
 func sendText(stream *transport.Stream, txt1, txt2 string) {
@@ -442,6 +442,14 @@ func Test_ObjAttrs(t *testing.T) {
 			CksumValue: "102412",
 			Version:    "",
 		},
+		transport.ObjectAttrs{
+			Size:       2048,
+			Atime:      2048,
+			CksumType:  cmn.ChecksumXXHash,
+			CksumValue: "204821",
+			Version:    "1",
+			CustomMD:   cmn.SimpleKVs{"color": "blue", "owner": "alice"},
+		},
 	}
 
 	mux := mux.NewServeMux()